@@ -0,0 +1,49 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mateusmacedo/scouts/apps/user-go-service/handlers"
+	gouser "github.com/mateusmacedo/scouts/libs/user-go"
+	"github.com/mateusmacedo/scouts/libs/user-go/gousertest"
+)
+
+// testApp is the Echo app of setupRoutes over an in-memory repository, with
+// a fake clock stopped at gousertest.Epoch, recorded events and a
+// repository that fails on demand
+type testApp struct {
+	*gousertest.Harness
+	Echo       *echo.Echo
+	Repository *gousertest.FaultyRepository
+	Service    *gouser.UserService
+	Events     *gousertest.RecordingEvents
+	Clock      *gousertest.FakeClock
+}
+
+// newTestApp boots the app with opts applied to its user service
+func newTestApp(t *testing.T, opts ...gouser.UserServiceOption) *testApp {
+	t.Helper()
+	e := echo.New()
+	e.HideBanner = true
+	e.Validator = &CustomValidator{}
+
+	clock := gousertest.NewFakeClock(gousertest.Epoch)
+	repository := gousertest.NewFaultyRepository(gouser.NewInMemoryUserRepository(gouser.WithClock(clock)))
+	events := gousertest.NewRecordingEvents()
+	service := gouser.NewUserService(repository, events, append([]gouser.UserServiceOption{gouser.WithServiceClock(clock)}, opts...)...)
+	setupRoutes(e,
+		handlers.NewHealthHandler("1.0.0").WithClock(clock),
+		handlers.NewUserHandler(service),
+		handlers.NewImportHandler(service),
+	)
+
+	return &testApp{
+		Harness:    gousertest.NewHarness(t, e),
+		Echo:       e,
+		Repository: repository,
+		Service:    service,
+		Events:     events,
+		Clock:      clock,
+	}
+}
@@ -0,0 +1,102 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+
+	gouser "github.com/mateusmacedo/scouts/libs/user-go"
+)
+
+// HeaderAPIKey is the request header carrying a service API key
+const HeaderAPIKey = "X-API-Key"
+
+// APIKey is a hashed API key bound to a service principal
+type APIKey struct {
+	// Name identifies the calling service and becomes the principal ID
+	Name string
+	// Hash is the hex-encoded SHA-256 of the raw key
+	Hash  string
+	Roles []string
+}
+
+// APIKeyAuthenticator validates static API keys for service-to-service calls
+type APIKeyAuthenticator struct {
+	keys []apiKeyEntry
+}
+
+// apiKeyEntry holds a decoded key hash
+type apiKeyEntry struct {
+	hash []byte
+	key  APIKey
+}
+
+// NewAPIKeyAuthenticator creates a new API key authenticator
+func NewAPIKeyAuthenticator(keys []APIKey) (*APIKeyAuthenticator, error) {
+	entries := make([]apiKeyEntry, 0, len(keys))
+	for _, key := range keys {
+		hash, err := hex.DecodeString(key.Hash)
+		if err != nil || len(hash) != sha256.Size {
+			return nil, fmt.Errorf("API key %q must be a hex-encoded SHA-256 hash", key.Name)
+		}
+		entries = append(entries, apiKeyEntry{hash: hash, key: key})
+	}
+	return &APIKeyAuthenticator{keys: entries}, nil
+}
+
+// ParseAPIKeys parses "name:sha256hex[:role1|role2]" entries separated by commas
+func ParseAPIKeys(value string) ([]APIKey, error) {
+	keys := make([]APIKey, 0)
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.Split(entry, ":")
+		if len(parts) < 2 || len(parts) > 3 || parts[0] == "" {
+			return nil, fmt.Errorf("API key entry %q must be name:sha256hex[:roles]", entry)
+		}
+		key := APIKey{Name: parts[0], Hash: strings.ToLower(parts[1])}
+		if len(parts) == 3 && parts[2] != "" {
+			key.Roles = strings.Split(parts[2], "|")
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// HashAPIKey returns the hex-encoded SHA-256 of a raw key, as expected in config
+func HashAPIKey(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+// Authenticate validates the X-API-Key header
+func (a *APIKeyAuthenticator) Authenticate(r *http.Request) (*gouser.Principal, error) {
+	raw := r.Header.Get(HeaderAPIKey)
+	if raw == "" {
+		return nil, ErrNoCredentials
+	}
+
+	sum := sha256.Sum256([]byte(raw))
+	var matched *APIKey
+	// Compare against every key so timing does not reveal which one matched
+	for i := range a.keys {
+		if subtle.ConstantTimeCompare(sum[:], a.keys[i].hash) == 1 {
+			matched = &a.keys[i].key
+		}
+	}
+	if matched == nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	return &gouser.Principal{
+		ID:         matched.Name,
+		Type:       gouser.PrincipalTypeService,
+		Roles:      append([]string(nil), matched.Roles...),
+		AuthMethod: "api_key",
+	}, nil
+}
@@ -0,0 +1,89 @@
+package auth
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/mateusmacedo/scouts/apps/user-go-service/handlers"
+	gouser "github.com/mateusmacedo/scouts/libs/user-go"
+)
+
+// PrincipalContextKey is the echo.Context key holding the authenticated principal
+const PrincipalContextKey = "principal"
+
+// Authentication errors
+var (
+	// ErrNoCredentials means the request carries no credentials for an authenticator
+	ErrNoCredentials = errors.New("no credentials provided")
+	// ErrInvalidCredentials means credentials were present but rejected
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// Authenticator resolves the principal of a request. It returns
+// ErrNoCredentials when the request has no credentials it understands, so the
+// next authenticator can be tried.
+type Authenticator interface {
+	Authenticate(r *http.Request) (*gouser.Principal, error)
+}
+
+// Config defines the config for the authentication middleware
+type Config struct {
+	// Skipper defines a function to skip middleware
+	Skipper middleware.Skipper
+	// Authenticators are tried in order until one finds credentials
+	Authenticators []Authenticator
+}
+
+// Middleware returns an authentication middleware. Authenticated principals
+// are stored in the echo.Context and in the request context.Context, where
+// gouser.PrincipalFromContext can read them.
+func Middleware(config Config) echo.MiddlewareFunc {
+	if config.Skipper == nil {
+		config.Skipper = middleware.DefaultSkipper
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) {
+				return next(c)
+			}
+
+			principal, err := authenticate(c.Request(), config.Authenticators)
+			if err != nil {
+				if !errors.Is(err, ErrNoCredentials) && !errors.Is(err, ErrInvalidCredentials) {
+					slog.ErrorContext(c.Request().Context(), "Authentication failed", "error", err)
+				}
+				c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer realm="user-go-service"`)
+				return c.JSON(http.StatusUnauthorized, handlers.ErrorResponse{
+					Error:   "unauthorized",
+					Message: "Missing or invalid credentials",
+				})
+			}
+
+			c.Set(PrincipalContextKey, principal)
+			c.SetRequest(c.Request().WithContext(gouser.WithPrincipal(c.Request().Context(), principal)))
+			return next(c)
+		}
+	}
+}
+
+// authenticate runs the authenticators in order
+func authenticate(r *http.Request, authenticators []Authenticator) (*gouser.Principal, error) {
+	for _, authenticator := range authenticators {
+		principal, err := authenticator.Authenticate(r)
+		if errors.Is(err, ErrNoCredentials) {
+			continue
+		}
+		return principal, err
+	}
+	return nil, ErrNoCredentials
+}
+
+// PrincipalFromEcho returns the principal stored by the middleware
+func PrincipalFromEcho(c echo.Context) (*gouser.Principal, bool) {
+	principal, ok := c.Get(PrincipalContextKey).(*gouser.Principal)
+	return principal, ok && principal != nil
+}
@@ -0,0 +1,318 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	gouser "github.com/mateusmacedo/scouts/libs/user-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testNow = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func encodeSegment(t *testing.T, v interface{}) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func signHS256(t *testing.T, secret []byte, claims map[string]interface{}) string {
+	t.Helper()
+	signed := encodeSegment(t, map[string]string{"alg": AlgHS256, "typ": "JWT"}) + "." + encodeSegment(t, claims)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(signed))
+	return signed + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func signRS256(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]interface{}) string {
+	t.Helper()
+	signed := encodeSegment(t, map[string]string{"alg": AlgRS256, "typ": "JWT", "kid": kid}) + "." + encodeSegment(t, claims)
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	require.NoError(t, err)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func jwksFor(kid string, key *rsa.PublicKey) []byte {
+	doc := jwksDocument{Keys: []JSONWebKey{{
+		Kty: "RSA",
+		Kid: kid,
+		Use: "sig",
+		N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}}}
+	data, _ := json.Marshal(doc)
+	return data
+}
+
+func validClaims() map[string]interface{} {
+	return map[string]interface{}{
+		"sub":   "user-1",
+		"iss":   "https://auth.scouts.local",
+		"aud":   []string{"user-go-service"},
+		"exp":   testNow.Add(time.Hour).Unix(),
+		"iat":   testNow.Unix(),
+		"roles": []string{"admin"},
+	}
+}
+
+func bearerRequest(token string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/users", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	return req
+}
+
+func TestJWTAuthenticator(t *testing.T) {
+	secret := []byte("test-secret")
+	authenticator := NewJWTAuthenticator(JWTConfig{
+		KeySet:    NewHMACKeySet(secret),
+		Issuer:    "https://auth.scouts.local",
+		Audience:  "user-go-service",
+		ClockSkew: 30 * time.Second,
+		Now:       func() time.Time { return testNow },
+	})
+
+	t.Run("should authenticate a valid HS256 token", func(t *testing.T) {
+		principal, err := authenticator.Authenticate(bearerRequest(signHS256(t, secret, validClaims())))
+
+		require.NoError(t, err)
+		assert.Equal(t, "user-1", principal.ID)
+		assert.Equal(t, gouser.PrincipalTypeUser, principal.Type)
+		assert.Equal(t, []string{"admin"}, principal.Roles)
+		assert.Equal(t, "jwt", principal.AuthMethod)
+	})
+
+	t.Run("should report missing credentials", func(t *testing.T) {
+		_, err := authenticator.Authenticate(httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.ErrorIs(t, err, ErrNoCredentials)
+	})
+
+	tests := []struct {
+		name   string
+		mutate func(claims map[string]interface{})
+		token  func(claims map[string]interface{}) string
+	}{
+		{"expired beyond skew", func(c map[string]interface{}) { c["exp"] = testNow.Add(-time.Minute).Unix() }, nil},
+		{"not valid yet", func(c map[string]interface{}) { c["nbf"] = testNow.Add(time.Minute).Unix() }, nil},
+		{"wrong issuer", func(c map[string]interface{}) { c["iss"] = "https://evil.example" }, nil},
+		{"wrong audience", func(c map[string]interface{}) { c["aud"] = "other-service" }, nil},
+		{"missing exp", func(c map[string]interface{}) { delete(c, "exp") }, nil},
+		{"wrong secret", func(c map[string]interface{}) {}, func(c map[string]interface{}) string { return signHS256(t, []byte("other"), c) }},
+		{"malformed", func(c map[string]interface{}) {}, func(c map[string]interface{}) string { return "not.a-token" }},
+	}
+	for _, tt := range tests {
+		t.Run("should reject token: "+tt.name, func(t *testing.T) {
+			claims := validClaims()
+			tt.mutate(claims)
+			token := signHS256(t, secret, claims)
+			if tt.token != nil {
+				token = tt.token(claims)
+			}
+
+			_, err := authenticator.Authenticate(bearerRequest(token))
+			assert.ErrorIs(t, err, ErrInvalidCredentials)
+		})
+	}
+
+	t.Run("should tolerate clock skew", func(t *testing.T) {
+		claims := validClaims()
+		claims["exp"] = testNow.Add(-10 * time.Second).Unix()
+
+		_, err := authenticator.Authenticate(bearerRequest(signHS256(t, secret, claims)))
+		assert.NoError(t, err)
+	})
+}
+
+func TestJWTAuthenticator_RS256(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	t.Run("should verify tokens with a JWKS file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "jwks.json")
+		require.NoError(t, os.WriteFile(path, jwksFor("key-1", &key.PublicKey), 0o600))
+
+		keySet, err := LoadJWKSFile(path)
+		require.NoError(t, err)
+		authenticator := NewJWTAuthenticator(JWTConfig{KeySet: keySet, Now: func() time.Time { return testNow }})
+
+		principal, err := authenticator.Authenticate(bearerRequest(signRS256(t, key, "key-1", validClaims())))
+		require.NoError(t, err)
+		assert.Equal(t, "user-1", principal.ID)
+
+		_, err = authenticator.Authenticate(bearerRequest(signRS256(t, key, "unknown", validClaims())))
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+	})
+
+	t.Run("should verify tokens with a JWKS endpoint", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write(jwksFor("key-1", &key.PublicKey))
+		}))
+		defer server.Close()
+
+		authenticator := NewJWTAuthenticator(JWTConfig{
+			KeySet: NewRemoteKeySet(server.URL, time.Minute),
+			Now:    func() time.Time { return testNow },
+		})
+
+		_, err := authenticator.Authenticate(bearerRequest(signRS256(t, key, "key-1", validClaims())))
+		assert.NoError(t, err)
+	})
+
+	t.Run("should ping the JWKS endpoint", func(t *testing.T) {
+		available := true
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !available {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.Write(jwksFor("key-1", &key.PublicKey))
+		}))
+		defer server.Close()
+		keySet := NewRemoteKeySet(server.URL, time.Minute)
+
+		assert.NoError(t, keySet.Ping(context.Background()))
+		available = false
+		assert.Error(t, keySet.Ping(context.Background()))
+	})
+
+	t.Run("should serve stale keys and throttle refreshes while the endpoint is down", func(t *testing.T) {
+		var fetches atomic.Int32
+		available := atomic.Bool{}
+		available.Store(true)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fetches.Add(1)
+			if !available.Load() {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.Write(jwksFor("key-1", &key.PublicKey))
+		}))
+		defer server.Close()
+		// Every lookup finds the keys past their TTL
+		keySet := NewRemoteKeySet(server.URL, time.Nanosecond)
+
+		_, err := keySet.Key(context.Background(), "key-1", "RS256")
+		require.NoError(t, err)
+		keySet.lastAttempt = time.Time{}
+		available.Store(false)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := keySet.Key(context.Background(), "key-1", "RS256")
+				assert.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+		_, err = keySet.Key(context.Background(), "unknown", "RS256")
+		assert.ErrorIs(t, err, ErrKeyNotFound)
+		assert.Equal(t, int32(2), fetches.Load())
+	})
+
+	t.Run("should not accept an RSA key as HMAC secret", func(t *testing.T) {
+		authenticator := NewJWTAuthenticator(JWTConfig{
+			KeySet: NewRSAKeySet("key-1", &key.PublicKey),
+			Now:    func() time.Time { return testNow },
+		})
+
+		_, err := authenticator.Authenticate(bearerRequest(signHS256(t, key.PublicKey.N.Bytes(), validClaims())))
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+	})
+}
+
+func TestAPIKeyAuthenticator(t *testing.T) {
+	keys, err := ParseAPIKeys("bff-nest:" + HashAPIKey("s3cret") + ":service|reader")
+	require.NoError(t, err)
+	authenticator, err := NewAPIKeyAuthenticator(keys)
+	require.NoError(t, err)
+
+	t.Run("should authenticate a known key", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(HeaderAPIKey, "s3cret")
+
+		principal, err := authenticator.Authenticate(req)
+		require.NoError(t, err)
+		assert.Equal(t, "bff-nest", principal.ID)
+		assert.Equal(t, gouser.PrincipalTypeService, principal.Type)
+		assert.Equal(t, []string{"service", "reader"}, principal.Roles)
+	})
+
+	t.Run("should reject an unknown key", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(HeaderAPIKey, "wrong")
+
+		_, err := authenticator.Authenticate(req)
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+	})
+
+	t.Run("should reject malformed configuration", func(t *testing.T) {
+		_, err := ParseAPIKeys("missing-hash")
+		assert.Error(t, err)
+
+		_, err = NewAPIKeyAuthenticator([]APIKey{{Name: "svc", Hash: "abc"}})
+		assert.Error(t, err)
+	})
+}
+
+func TestMiddleware(t *testing.T) {
+	keys, _ := ParseAPIKeys("svc:" + HashAPIKey("s3cret"))
+	apiKeys, _ := NewAPIKeyAuthenticator(keys)
+
+	e := echo.New()
+	e.Use(Middleware(Config{
+		Skipper:        func(c echo.Context) bool { return c.Path() == "/health" },
+		Authenticators: []Authenticator{apiKeys},
+	}))
+	e.GET("/health", func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+	e.GET("/api/v1/users", func(c echo.Context) error {
+		fromEcho, _ := PrincipalFromEcho(c)
+		fromCtx, _ := gouser.PrincipalFromContext(c.Request().Context())
+		return c.JSON(http.StatusOK, map[string]string{"echo": fromEcho.ID, "ctx": fromCtx.ID})
+	})
+
+	t.Run("should reject requests without credentials", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/users", nil))
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Contains(t, rec.Header().Get(echo.HeaderWWWAuthenticate), "Bearer")
+	})
+
+	t.Run("should store the principal in both contexts", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/users", nil)
+		req.Header.Set(HeaderAPIKey, "s3cret")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"echo":"svc","ctx":"svc"}`, rec.Body.String())
+	})
+
+	t.Run("should skip configured routes", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+}
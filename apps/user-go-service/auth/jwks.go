@@ -0,0 +1,288 @@
+package auth
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// ErrKeyNotFound is returned when no key matches a token header
+var ErrKeyNotFound = errors.New("signing key not found")
+
+// KeySet resolves verification keys for JWTs. Keys are []byte for HMAC
+// algorithms and *rsa.PublicKey for RSA algorithms.
+type KeySet interface {
+	Key(ctx context.Context, kid, alg string) (interface{}, error)
+}
+
+// JSONWebKey is a single entry of a JWKS document
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	Use string `json:"use,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	K   string `json:"k,omitempty"`
+}
+
+// jwksDocument is the JWKS wire format
+type jwksDocument struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// staticKey is a parsed verification key
+type staticKey struct {
+	kid string
+	alg string
+	key interface{}
+}
+
+// StaticKeySet is an immutable set of verification keys
+type StaticKeySet struct {
+	keys []staticKey
+}
+
+// NewHMACKeySet creates a key set holding a single HS256 secret
+func NewHMACKeySet(secret []byte) *StaticKeySet {
+	return &StaticKeySet{keys: []staticKey{{alg: AlgHS256, key: secret}}}
+}
+
+// NewRSAKeySet creates a key set holding a single RS256 public key
+func NewRSAKeySet(kid string, key *rsa.PublicKey) *StaticKeySet {
+	return &StaticKeySet{keys: []staticKey{{kid: kid, alg: AlgRS256, key: key}}}
+}
+
+// ParseJWKS parses a JWKS document, skipping keys that are not usable for
+// signature verification with a supported algorithm.
+func ParseJWKS(data []byte) (*StaticKeySet, error) {
+	var doc jwksDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid JWKS document: %w", err)
+	}
+
+	set := &StaticKeySet{}
+	for _, jwk := range doc.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		switch jwk.Kty {
+		case "RSA":
+			key, err := parseRSAKey(jwk)
+			if err != nil {
+				return nil, fmt.Errorf("invalid RSA key %q: %w", jwk.Kid, err)
+			}
+			set.keys = append(set.keys, staticKey{kid: jwk.Kid, alg: AlgRS256, key: key})
+		case "oct":
+			secret, err := base64.RawURLEncoding.DecodeString(jwk.K)
+			if err != nil {
+				return nil, fmt.Errorf("invalid symmetric key %q: %w", jwk.Kid, err)
+			}
+			set.keys = append(set.keys, staticKey{kid: jwk.Kid, alg: AlgHS256, key: secret})
+		}
+	}
+
+	if len(set.keys) == 0 {
+		return nil, errors.New("JWKS document has no usable keys")
+	}
+	return set, nil
+}
+
+// LoadJWKSFile reads and parses a JWKS document from disk
+func LoadJWKSFile(path string) (*StaticKeySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWKS file: %w", err)
+	}
+	return ParseJWKS(data)
+}
+
+// Key returns the key matching kid and alg. Tokens without kid match the
+// only key of the requested algorithm.
+func (s *StaticKeySet) Key(ctx context.Context, kid, alg string) (interface{}, error) {
+	var match *staticKey
+	for i := range s.keys {
+		key := &s.keys[i]
+		if key.alg != alg {
+			continue
+		}
+		if kid != "" && key.kid == kid {
+			return key.key, nil
+		}
+		if kid == "" {
+			if match != nil {
+				return nil, fmt.Errorf("%w: token has no kid and several %s keys exist", ErrKeyNotFound, alg)
+			}
+			match = key
+		}
+	}
+	if match == nil {
+		return nil, ErrKeyNotFound
+	}
+	return match.key, nil
+}
+
+func parseRSAKey(jwk JSONWebKey) (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+	if err != nil {
+		return nil, err
+	}
+	e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+	if err != nil {
+		return nil, err
+	}
+	if len(n) == 0 || len(e) == 0 {
+		return nil, errors.New("missing modulus or exponent")
+	}
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(n),
+		E: int(new(big.Int).SetBytes(e).Int64()),
+	}, nil
+}
+
+// minRefreshInterval bounds how often the JWKS endpoint is fetched, so
+// unknown kids and an unavailable endpoint do not hammer it
+const minRefreshInterval = 30 * time.Second
+
+// RemoteKeySet fetches keys from a JWKS endpoint and caches them. Stale keys
+// keep being served while the endpoint is unavailable.
+type RemoteKeySet struct {
+	url         string
+	client      *http.Client
+	ttl         time.Duration
+	mutex       sync.Mutex
+	cached      *StaticKeySet
+	fetchedAt   time.Time
+	lastAttempt time.Time
+	lastErr     error
+	inflight    *refreshCall
+}
+
+// refreshCall is a JWKS fetch shared by concurrent callers
+type refreshCall struct {
+	done chan struct{}
+	err  error
+}
+
+// NewRemoteKeySet creates a key set backed by a JWKS endpoint
+func NewRemoteKeySet(url string, ttl time.Duration) *RemoteKeySet {
+	return &RemoteKeySet{
+		url:    url,
+		client: &http.Client{Timeout: 5 * time.Second},
+		ttl:    ttl,
+	}
+}
+
+// WithClient sets the HTTP client used to fetch the JWKS document, e.g. one
+// with a tracing transport
+func (s *RemoteKeySet) WithClient(client *http.Client) *RemoteKeySet {
+	s.client = client
+	return s
+}
+
+// Key returns the key matching kid and alg, refreshing the cache when it is
+// stale or the kid is unknown (key rotation).
+func (s *RemoteKeySet) Key(ctx context.Context, kid, alg string) (interface{}, error) {
+	cached, fresh := s.keys()
+	if !fresh {
+		if err := s.refresh(ctx, true); err != nil && cached == nil {
+			return nil, err
+		}
+		cached, _ = s.keys()
+	}
+	if cached == nil {
+		return nil, ErrKeyNotFound
+	}
+
+	key, err := cached.Key(ctx, kid, alg)
+	if errors.Is(err, ErrKeyNotFound) && s.refresh(ctx, true) == nil {
+		if refreshed, _ := s.keys(); refreshed != cached {
+			return refreshed.Key(ctx, kid, alg)
+		}
+	}
+	return key, err
+}
+
+// Ping refreshes the cached keys, failing when the JWKS endpoint is unavailable
+func (s *RemoteKeySet) Ping(ctx context.Context) error {
+	return s.refresh(ctx, false)
+}
+
+// keys returns the cached keys and whether they are within the TTL
+func (s *RemoteKeySet) keys() (*StaticKeySet, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.cached, s.cached != nil && time.Since(s.fetchedAt) <= s.ttl
+}
+
+// refresh downloads the JWKS document without holding the lock. Concurrent
+// callers share one fetch; throttled callers get the result of the last
+// attempt when it was made within minRefreshInterval.
+func (s *RemoteKeySet) refresh(ctx context.Context, throttle bool) error {
+	s.mutex.Lock()
+	if call := s.inflight; call != nil {
+		s.mutex.Unlock()
+		select {
+		case <-call.done:
+			return call.err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if throttle && !s.lastAttempt.IsZero() && time.Since(s.lastAttempt) < minRefreshInterval {
+		err := s.lastErr
+		s.mutex.Unlock()
+		return err
+	}
+	call := &refreshCall{done: make(chan struct{})}
+	s.inflight = call
+	s.lastAttempt = time.Now()
+	s.mutex.Unlock()
+
+	// The fetch outlives a canceled caller, as others may be waiting on it
+	set, err := s.fetch(context.WithoutCancel(ctx))
+
+	s.mutex.Lock()
+	if err == nil {
+		s.cached = set
+		s.fetchedAt = time.Now()
+	}
+	s.lastErr = err
+	s.inflight = nil
+	s.mutex.Unlock()
+
+	call.err = err
+	close(call.done)
+	return err
+}
+
+// fetch downloads and parses the JWKS document
+func (s *RemoteKeySet) fetch(ctx context.Context) (*StaticKeySet, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch JWKS: unexpected status %d", resp.StatusCode)
+	}
+
+	var raw json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("failed to decode JWKS: %w", err)
+	}
+	return ParseJWKS(raw)
+}
@@ -0,0 +1,236 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	gouser "github.com/mateusmacedo/scouts/libs/user-go"
+)
+
+// Supported JWT signing algorithms
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+)
+
+// JWTConfig configures bearer token validation
+type JWTConfig struct {
+	// KeySet resolves verification keys (HMAC secret, JWKS file or endpoint)
+	KeySet KeySet
+	// Issuer, when set, must match the iss claim
+	Issuer string
+	// Audience, when set, must be present in the aud claim
+	Audience string
+	// ClockSkew tolerates clock drift when checking exp, nbf and iat
+	ClockSkew time.Duration
+	// Now returns the current time. Defaults to time.Now.
+	Now func() time.Time
+}
+
+// JWTAuthenticator validates "Authorization: Bearer <jwt>" headers
+type JWTAuthenticator struct {
+	config JWTConfig
+}
+
+// NewJWTAuthenticator creates a new JWT authenticator
+func NewJWTAuthenticator(config JWTConfig) *JWTAuthenticator {
+	if config.Now == nil {
+		config.Now = time.Now
+	}
+	return &JWTAuthenticator{config: config}
+}
+
+// jwtHeader is the decoded JOSE header
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	Typ string `json:"typ"`
+}
+
+// jwtClaims holds the registered and custom claims read by the authenticator
+type jwtClaims struct {
+	Issuer    string       `json:"iss"`
+	Subject   string       `json:"sub"`
+	Audience  audience     `json:"aud"`
+	ExpiresAt *numericDate `json:"exp"`
+	NotBefore *numericDate `json:"nbf"`
+	IssuedAt  *numericDate `json:"iat"`
+	Roles     []string     `json:"roles"`
+	Role      string       `json:"role"`
+	Type      string       `json:"typ"`
+}
+
+// audience accepts both the string and array forms of the aud claim
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return err
+	}
+	*a = multiple
+	return nil
+}
+
+func (a audience) contains(value string) bool {
+	for _, aud := range a {
+		if aud == value {
+			return true
+		}
+	}
+	return false
+}
+
+// numericDate is a JWT NumericDate (seconds since epoch, possibly fractional)
+type numericDate struct {
+	time.Time
+}
+
+func (d *numericDate) UnmarshalJSON(data []byte) error {
+	var seconds float64
+	if err := json.Unmarshal(data, &seconds); err != nil {
+		return err
+	}
+	d.Time = time.Unix(0, int64(seconds*float64(time.Second)))
+	return nil
+}
+
+// Authenticate validates the bearer token of the request
+func (a *JWTAuthenticator) Authenticate(r *http.Request) (*gouser.Principal, error) {
+	header := r.Header.Get("Authorization")
+	scheme, token, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+		return nil, ErrNoCredentials
+	}
+
+	claims, err := a.verify(r, strings.TrimSpace(token))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+	}
+
+	roles := claims.Roles
+	if len(roles) == 0 && claims.Role != "" {
+		roles = []string{claims.Role}
+	}
+	principalType := gouser.PrincipalTypeUser
+	if claims.Type == gouser.PrincipalTypeService {
+		principalType = gouser.PrincipalTypeService
+	}
+
+	return &gouser.Principal{
+		ID:         claims.Subject,
+		Type:       principalType,
+		Roles:      roles,
+		AuthMethod: "jwt",
+	}, nil
+}
+
+// verify checks signature and registered claims, returning the decoded claims
+func (a *JWTAuthenticator) verify(r *http.Request, token string) (*jwtClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed token")
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("malformed header: %w", err)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("malformed signature: %w", err)
+	}
+
+	if header.Alg != AlgHS256 && header.Alg != AlgRS256 {
+		return nil, fmt.Errorf("unsupported algorithm %q", header.Alg)
+	}
+	key, err := a.config.KeySet.Key(r.Context(), header.Kid, header.Alg)
+	if err != nil {
+		return nil, err
+	}
+
+	signed := []byte(parts[0] + "." + parts[1])
+	digest := sha256.Sum256(signed)
+	switch header.Alg {
+	case AlgHS256:
+		secret, ok := key.([]byte)
+		if !ok {
+			return nil, errors.New("key type does not match algorithm")
+		}
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(signed)
+		if !hmac.Equal(signature, mac.Sum(nil)) {
+			return nil, errors.New("invalid signature")
+		}
+	case AlgRS256:
+		publicKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return nil, errors.New("key type does not match algorithm")
+		}
+		if err := rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, digest[:], signature); err != nil {
+			return nil, errors.New("invalid signature")
+		}
+	}
+
+	var claims jwtClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("malformed claims: %w", err)
+	}
+
+	if err := a.validateClaims(&claims); err != nil {
+		return nil, err
+	}
+	return &claims, nil
+}
+
+// validateClaims checks time-based claims, issuer, audience and subject
+func (a *JWTAuthenticator) validateClaims(claims *jwtClaims) error {
+	now := a.config.Now()
+	skew := a.config.ClockSkew
+
+	if claims.ExpiresAt == nil {
+		return errors.New("missing exp claim")
+	}
+	if now.After(claims.ExpiresAt.Add(skew)) {
+		return errors.New("token expired")
+	}
+	if claims.NotBefore != nil && now.Add(skew).Before(claims.NotBefore.Time) {
+		return errors.New("token not valid yet")
+	}
+	if claims.IssuedAt != nil && now.Add(skew).Before(claims.IssuedAt.Time) {
+		return errors.New("token issued in the future")
+	}
+	if a.config.Issuer != "" && claims.Issuer != a.config.Issuer {
+		return errors.New("unexpected issuer")
+	}
+	if a.config.Audience != "" && !claims.Audience.contains(a.config.Audience) {
+		return errors.New("unexpected audience")
+	}
+	if claims.Subject == "" {
+		return errors.New("missing sub claim")
+	}
+	return nil
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,207 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// Exit codes returned by Main
+const (
+	ExitOK    = 0
+	ExitError = 1
+	ExitUsage = 2
+)
+
+// Command is a command line command. Commands with subcommands parse their
+// own flags and dispatch on the next argument; leaf commands call Run.
+type Command struct {
+	// Name selects the command from its parent
+	Name string
+	// Args describes the positional arguments, e.g. "<id>"
+	Args string
+	// Short is a one-line description
+	Short string
+	// Flags registers the command flags
+	Flags func(fs *flag.FlagSet)
+	// Run executes a leaf command with the positional arguments
+	Run func(ctx context.Context, args []string) error
+	// Commands are the subcommands
+	Commands []*Command
+	// Default names the subcommand run when none is given
+	Default string
+}
+
+// UsageError reports invalid command line arguments
+type UsageError struct {
+	// Command is the full command path, e.g. "user-go-service users get"
+	Command string
+	Err     error
+}
+
+func (e *UsageError) Error() string {
+	return e.Command + ": " + e.Err.Error()
+}
+
+func (e *UsageError) Unwrap() error {
+	return e.Err
+}
+
+// Usagef returns a UsageError for the command path
+func Usagef(command string, format string, args ...any) error {
+	return &UsageError{Command: command, Err: fmt.Errorf(format, args...)}
+}
+
+// Main runs the command with args and returns the process exit code. Errors
+// are written to stderr; usage errors are followed by a help hint.
+func Main(ctx context.Context, root *Command, args []string, stderr io.Writer) int {
+	err := root.Execute(ctx, args, stderr)
+	var usage *UsageError
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.As(err, &usage):
+		fmt.Fprintf(stderr, "error: %v\nRun '%s --help' for usage.\n", usage, usage.Command)
+		return ExitUsage
+	default:
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return ExitError
+	}
+}
+
+// Execute parses args and runs the selected command. Help requested with
+// -h or --help is written to stderr.
+func (c *Command) Execute(ctx context.Context, args []string, stderr io.Writer) error {
+	return c.execute(ctx, c.Name, args, stderr)
+}
+
+func (c *Command) execute(ctx context.Context, path string, args []string, stderr io.Writer) error {
+	fs := flag.NewFlagSet(path, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	if c.Flags != nil {
+		c.Flags(fs)
+	}
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			c.usage(stderr, path, fs)
+			return nil
+		}
+		return &UsageError{Command: path, Err: err}
+	}
+	args = fs.Args()
+
+	if len(c.Commands) == 0 {
+		return c.Run(ctx, args)
+	}
+
+	name := c.Default
+	if len(args) > 0 {
+		name, args = args[0], args[1:]
+	}
+	switch name {
+	case "":
+		return Usagef(path, "missing command")
+	case "help":
+		c.usage(stderr, path, fs)
+		return nil
+	}
+	for _, sub := range c.Commands {
+		if sub.Name == name {
+			return sub.execute(ctx, path+" "+sub.Name, args, stderr)
+		}
+	}
+	return Usagef(path, "unknown command %q", name)
+}
+
+// usage writes the synopsis, subcommands and flags of the command
+func (c *Command) usage(w io.Writer, path string, fs *flag.FlagSet) {
+	synopsis := path
+	if hasFlags(fs) {
+		synopsis += " [flags]"
+	}
+	if len(c.Commands) > 0 {
+		synopsis += " <command>"
+	}
+	if c.Args != "" {
+		synopsis += " " + c.Args
+	}
+	fmt.Fprintf(w, "Usage: %s\n", synopsis)
+	if c.Short != "" {
+		fmt.Fprintf(w, "\n%s\n", c.Short)
+	}
+
+	if len(c.Commands) > 0 {
+		fmt.Fprintln(w, "\nCommands:")
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		for _, sub := range c.Commands {
+			name := sub.Name
+			if sub.Name == c.Default {
+				name += " (default)"
+			}
+			fmt.Fprintf(tw, "  %s\t%s\n", name, sub.Short)
+		}
+		tw.Flush()
+	}
+
+	if hasFlags(fs) {
+		fmt.Fprintln(w, "\nFlags:")
+		fs.SetOutput(w)
+		fs.PrintDefaults()
+	}
+}
+
+func hasFlags(fs *flag.FlagSet) bool {
+	has := false
+	fs.VisitAll(func(*flag.Flag) { has = true })
+	return has
+}
+
+// Output formats
+const (
+	FormatTable = "table"
+	FormatJSON  = "json"
+)
+
+// Table is the tabular form of a command result
+type Table struct {
+	Header []string
+	Rows   [][]string
+}
+
+// Printer writes command results as an aligned table or as JSON
+type Printer struct {
+	W io.Writer
+	// Format is FormatTable or FormatJSON
+	Format string
+}
+
+// ValidateFormat checks a --output value
+func ValidateFormat(format string) error {
+	if format != FormatTable && format != FormatJSON {
+		return fmt.Errorf("output must be %s or %s, got %q", FormatTable, FormatJSON, format)
+	}
+	return nil
+}
+
+// Print writes value as indented JSON, or table as aligned columns
+func (p Printer) Print(value any, table Table) error {
+	if p.Format == FormatJSON {
+		encoder := json.NewEncoder(p.W)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+	}
+
+	tw := tabwriter.NewWriter(p.W, 0, 0, 2, ' ', 0)
+	if len(table.Header) > 0 {
+		fmt.Fprintln(tw, strings.Join(table.Header, "\t"))
+	}
+	for _, row := range table.Rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
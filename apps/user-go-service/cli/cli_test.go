@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommand(t *testing.T) {
+	var ran []string
+	var verbose bool
+	root := &Command{
+		Name:    "app",
+		Flags:   func(fs *flag.FlagSet) { fs.BoolVar(&verbose, "verbose", false, "") },
+		Default: "serve",
+		Commands: []*Command{
+			{Name: "serve", Run: func(ctx context.Context, args []string) error {
+				ran = append(ran, "serve")
+				return nil
+			}},
+			{Name: "users", Commands: []*Command{
+				{Name: "get", Args: "<id>", Run: func(ctx context.Context, args []string) error {
+					ran = append(ran, "get "+args[0])
+					return errors.New("not found")
+				}},
+			}},
+		},
+	}
+
+	t.Run("should run the default command", func(t *testing.T) {
+		ran = nil
+		require.NoError(t, root.Execute(context.Background(), []string{"--verbose"}, &bytes.Buffer{}))
+		assert.Equal(t, []string{"serve"}, ran)
+		assert.True(t, verbose)
+	})
+
+	t.Run("should dispatch to nested commands", func(t *testing.T) {
+		ran = nil
+		var stderr bytes.Buffer
+		code := Main(context.Background(), root, []string{"users", "get", "1"}, &stderr)
+
+		assert.Equal(t, ExitError, code)
+		assert.Equal(t, []string{"get 1"}, ran)
+		assert.Equal(t, "error: not found\n", stderr.String())
+	})
+
+	t.Run("should report usage errors", func(t *testing.T) {
+		var stderr bytes.Buffer
+		assert.Equal(t, ExitUsage, Main(context.Background(), root, []string{"users"}, &stderr))
+		assert.Contains(t, stderr.String(), "app users: missing command")
+
+		stderr.Reset()
+		assert.Equal(t, ExitUsage, Main(context.Background(), root, []string{"--quiet"}, &stderr))
+		assert.Contains(t, stderr.String(), "flag provided but not defined: -quiet")
+	})
+
+	t.Run("should print usage on help", func(t *testing.T) {
+		var stderr bytes.Buffer
+		assert.Equal(t, ExitOK, Main(context.Background(), root, []string{"help"}, &stderr))
+		assert.Contains(t, stderr.String(), "Usage: app [flags] <command>")
+		assert.Contains(t, stderr.String(), "serve (default)")
+		assert.Contains(t, stderr.String(), "-verbose")
+	})
+}
+
+func TestPrinter(t *testing.T) {
+	table := Table{Header: []string{"ID", "NAME"}, Rows: [][]string{{"1", "John Doe"}, {"22", "Jane"}}}
+
+	t.Run("should align table columns", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, Printer{W: &buf, Format: FormatTable}.Print(nil, table))
+		assert.Equal(t, "ID  NAME\n1   John Doe\n22  Jane\n", buf.String())
+	})
+
+	t.Run("should print JSON", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, Printer{W: &buf, Format: FormatJSON}.Print(map[string]int{"count": 2}, table))
+		assert.Equal(t, "{\n  \"count\": 2\n}\n", buf.String())
+	})
+
+	t.Run("should validate formats", func(t *testing.T) {
+		assert.NoError(t, ValidateFormat(FormatJSON))
+		assert.Error(t, ValidateFormat("yaml"))
+	})
+}
@@ -0,0 +1,318 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// CBOR encodes bodies as RFC 8949 CBOR maps keyed by the json field names.
+// Tags are decoded as their content.
+var CBOR Codec = cborCodec{}
+
+type cborCodec struct{}
+
+func (cborCodec) MediaTypes() []string     { return []string{"application/cbor"} }
+func (cborCodec) ProblemMediaType() string { return "application/cbor" }
+
+func (cborCodec) Marshal(v any) ([]byte, error) {
+	tree, err := marshalTree(v)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := writeCBOR(&buf, tree); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (cborCodec) Unmarshal(data []byte, v any) error {
+	tree, err := decodeDocument(data, readCBOR)
+	if err != nil {
+		return fmt.Errorf("cbor: %w", err)
+	}
+	return unmarshalTree(tree, v)
+}
+
+// CBOR major types
+const (
+	cborUint byte = iota << 5
+	cborNegInt
+	cborBytes
+	cborText
+	cborArray
+	cborMap
+	cborTag
+	cborSimple
+)
+
+// cborBreak ends an indefinite length item
+const cborBreak = 0xff
+
+// errCBORBreak is returned by readCBOR for a break, ending indefinite items
+var errCBORBreak = errors.New("unexpected break")
+
+// writeCBOR writes a tree with definite lengths in the shortest form
+func writeCBOR(w *bytes.Buffer, tree any) error {
+	switch v := tree.(type) {
+	case nil:
+		w.WriteByte(cborSimple | 22)
+	case bool:
+		if v {
+			w.WriteByte(cborSimple | 21)
+		} else {
+			w.WriteByte(cborSimple | 20)
+		}
+	case int64:
+		if v >= 0 {
+			writeCBORHead(w, cborUint, uint64(v))
+		} else {
+			writeCBORHead(w, cborNegInt, uint64(-(v + 1)))
+		}
+	case uint64:
+		writeCBORHead(w, cborUint, v)
+	case float64:
+		w.WriteByte(cborSimple | 27)
+		w.Write(binary.BigEndian.AppendUint64(nil, math.Float64bits(v)))
+	case string:
+		writeCBORHead(w, cborText, uint64(len(v)))
+		w.WriteString(v)
+	case []any:
+		writeCBORHead(w, cborArray, uint64(len(v)))
+		for _, value := range v {
+			if err := writeCBOR(w, value); err != nil {
+				return err
+			}
+		}
+	case object:
+		writeCBORHead(w, cborMap, uint64(len(v)))
+		for _, m := range v {
+			writeCBOR(w, m.key)
+			if err := writeCBOR(w, m.value); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("cbor: unsupported value %T", tree)
+	}
+	return nil
+}
+
+// writeCBORHead writes the initial byte of a major type and its argument
+func writeCBORHead(w *bytes.Buffer, major byte, arg uint64) {
+	switch {
+	case arg < 24:
+		w.WriteByte(major | byte(arg))
+	case arg <= math.MaxUint8:
+		w.Write([]byte{major | 24, byte(arg)})
+	case arg <= math.MaxUint16:
+		w.WriteByte(major | 25)
+		w.Write(binary.BigEndian.AppendUint16(nil, uint16(arg)))
+	case arg <= math.MaxUint32:
+		w.WriteByte(major | 26)
+		w.Write(binary.BigEndian.AppendUint32(nil, uint32(arg)))
+	default:
+		w.WriteByte(major | 27)
+		w.Write(binary.BigEndian.AppendUint64(nil, arg))
+	}
+}
+
+// readCBOR reads one CBOR data item as a tree
+func readCBOR(r *reader) (any, error) {
+	b, err := r.byte()
+	if err != nil {
+		return nil, err
+	}
+	if b == cborBreak {
+		return nil, errCBORBreak
+	}
+	major, info := b&0xe0, b&0x1f
+
+	if major == cborSimple {
+		return readCBORSimple(r, info)
+	}
+	indefinite := info == 31
+	var arg uint64
+	if !indefinite {
+		if arg, err = readCBORArgument(r, info); err != nil {
+			return nil, err
+		}
+	} else if major == cborUint || major == cborNegInt || major == cborTag {
+		return nil, fmt.Errorf("invalid indefinite length for major type %d", major>>5)
+	}
+
+	switch major {
+	case cborUint:
+		if arg <= math.MaxInt64 {
+			return int64(arg), nil
+		}
+		return arg, nil
+	case cborNegInt:
+		if arg > math.MaxInt64 {
+			return nil, fmt.Errorf("negative integer -1-%d overflows int64", arg)
+		}
+		return -1 - int64(arg), nil
+	case cborBytes, cborText:
+		data, err := readCBORString(r, major, arg, indefinite)
+		if err != nil {
+			return nil, err
+		}
+		if major == cborText {
+			return string(data), nil
+		}
+		return data, nil
+	case cborArray:
+		return readCBORArray(r, arg, indefinite)
+	case cborMap:
+		return readCBORMap(r, arg, indefinite)
+	default:
+		// Tags annotate their content, which is decoded as is
+		if err := r.enter(); err != nil {
+			return nil, err
+		}
+		defer r.leave()
+		return readCBOR(r)
+	}
+}
+
+// readCBORArgument reads the argument encoded by the additional information
+func readCBORArgument(r *reader, info byte) (uint64, error) {
+	switch {
+	case info < 24:
+		return uint64(info), nil
+	case info <= 27:
+		return r.uint(1 << (info - 24))
+	default:
+		return 0, fmt.Errorf("reserved additional information %d", info)
+	}
+}
+
+func readCBORSimple(r *reader, info byte) (any, error) {
+	switch info {
+	case 20:
+		return false, nil
+	case 21:
+		return true, nil
+	case 22, 23:
+		// null and undefined
+		return nil, nil
+	case 25:
+		u, err := r.uint(2)
+		return halfToFloat(uint16(u)), err
+	case 26:
+		u, err := r.uint(4)
+		return float64(math.Float32frombits(uint32(u))), err
+	case 27:
+		u, err := r.uint(8)
+		return math.Float64frombits(u), err
+	default:
+		return nil, fmt.Errorf("unsupported simple value %d", info)
+	}
+}
+
+// readCBORString reads a byte or text string, joining indefinite chunks
+func readCBORString(r *reader, major byte, n uint64, indefinite bool) ([]byte, error) {
+	if !indefinite {
+		return r.bytes(n)
+	}
+	var data []byte
+	for {
+		b, err := r.byte()
+		if err != nil {
+			return nil, err
+		}
+		if b == cborBreak {
+			return data, nil
+		}
+		if b&0xe0 != major || b&0x1f == 31 {
+			return nil, errors.New("invalid indefinite string chunk")
+		}
+		n, err := readCBORArgument(r, b&0x1f)
+		if err != nil {
+			return nil, err
+		}
+		chunk, err := r.bytes(n)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, chunk...)
+	}
+}
+
+func readCBORArray(r *reader, n uint64, indefinite bool) (any, error) {
+	if err := r.enter(); err != nil {
+		return nil, err
+	}
+	defer r.leave()
+	if !indefinite && n > uint64(len(r.data)) {
+		return nil, fmt.Errorf("array of %d elements exceeds the document", n)
+	}
+	array := []any{}
+	for i := uint64(0); indefinite || i < n; i++ {
+		value, err := readCBOR(r)
+		if indefinite && err == errCBORBreak {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		array = append(array, value)
+	}
+	return array, nil
+}
+
+func readCBORMap(r *reader, n uint64, indefinite bool) (any, error) {
+	if err := r.enter(); err != nil {
+		return nil, err
+	}
+	defer r.leave()
+	if !indefinite && n > uint64(len(r.data))/2 {
+		return nil, fmt.Errorf("map of %d entries exceeds the document", n)
+	}
+	obj := object{}
+	for i := uint64(0); indefinite || i < n; i++ {
+		key, err := readCBOR(r)
+		if indefinite && err == errCBORBreak {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		name, ok := key.(string)
+		if !ok {
+			return nil, fmt.Errorf("map key %v is not a text string", key)
+		}
+		value, err := readCBOR(r)
+		if err != nil {
+			return nil, err
+		}
+		obj = append(obj, member{key: name, value: value})
+	}
+	return obj, nil
+}
+
+// halfToFloat converts an IEEE 754 half precision float
+func halfToFloat(h uint16) float64 {
+	exponent := int(h>>10) & 0x1f
+	mantissa := float64(h & 0x3ff)
+	var value float64
+	switch exponent {
+	case 0:
+		value = math.Ldexp(mantissa, -24)
+	case 31:
+		if mantissa == 0 {
+			value = math.Inf(1)
+		} else {
+			value = math.NaN()
+		}
+	default:
+		value = math.Ldexp(mantissa+1024, exponent-25)
+	}
+	if h&0x8000 != 0 {
+		return -value
+	}
+	return value
+}
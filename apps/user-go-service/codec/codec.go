@@ -0,0 +1,148 @@
+// Package codec encodes request and response bodies in the media types a
+// client negotiates
+package codec
+
+import (
+	"mime"
+	"strconv"
+	"strings"
+)
+
+// Codec encodes and decodes one body format
+type Codec interface {
+	// MediaTypes lists the accepted media types, canonical first
+	MediaTypes() []string
+	// ProblemMediaType is the media type of problem details in this format
+	ProblemMediaType() string
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+// Registry holds the codecs a server negotiates, in order of preference
+type Registry struct {
+	codecs []Codec
+}
+
+// NewRegistry creates a registry preferring codecs in order; the first is
+// used when the client accepts anything
+func NewRegistry(codecs ...Codec) *Registry {
+	return &Registry{codecs: codecs}
+}
+
+// DefaultRegistry negotiates JSON, MessagePack, CBOR and XML
+func DefaultRegistry() *Registry {
+	return NewRegistry(JSON, MessagePack, CBOR, XML)
+}
+
+// Register adds a codec with the lowest preference
+func (r *Registry) Register(codec Codec) *Registry {
+	r.codecs = append(r.codecs, codec)
+	return r
+}
+
+// Default returns the most preferred codec
+func (r *Registry) Default() Codec {
+	return r.codecs[0]
+}
+
+// MediaTypes returns the canonical media type of every codec
+func (r *Registry) MediaTypes() []string {
+	types := make([]string, 0, len(r.codecs))
+	for _, codec := range r.codecs {
+		types = append(types, codec.MediaTypes()[0])
+	}
+	return types
+}
+
+// ForContentType returns the codec decoding a Content-Type
+func (r *Registry) ForContentType(contentType string) (Codec, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, false
+	}
+	for _, codec := range r.codecs {
+		for _, t := range codec.MediaTypes() {
+			if t == mediaType {
+				return codec, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// Negotiate returns the codec an Accept header prefers: the highest
+// quality, then the range listed first, then the registry order. An empty
+// header accepts the default codec.
+func (r *Registry) Negotiate(accept string) (Codec, bool) {
+	if strings.TrimSpace(accept) == "" {
+		return r.Default(), true
+	}
+	ranges := parseAccept(accept)
+
+	var best Codec
+	bestQuality, bestPosition := 0.0, 0
+	for _, codec := range r.codecs {
+		quality, position := acceptQuality(ranges, codec)
+		if quality > bestQuality || (quality == bestQuality && quality > 0 && position < bestPosition) {
+			best, bestQuality, bestPosition = codec, quality, position
+		}
+	}
+	return best, best != nil
+}
+
+// mediaRange is one entry of an Accept header
+type mediaRange struct {
+	mediaType string
+	quality   float64
+}
+
+// parseAccept parses an Accept header, skipping malformed ranges
+func parseAccept(accept string) []mediaRange {
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil || !strings.Contains(mediaType, "/") {
+			continue
+		}
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			parsed, err := strconv.ParseFloat(q, 64)
+			if err != nil || parsed < 0 || parsed > 1 {
+				continue
+			}
+			quality = parsed
+		}
+		ranges = append(ranges, mediaRange{mediaType: mediaType, quality: quality})
+	}
+	return ranges
+}
+
+// acceptQuality returns the quality the most specific matching range gives
+// codec and the position of that range
+func acceptQuality(ranges []mediaRange, codec Codec) (float64, int) {
+	quality, position, specificity := 0.0, len(ranges), -1
+	for i, r := range ranges {
+		for _, t := range codec.MediaTypes() {
+			s := matchSpecificity(r.mediaType, t)
+			if s > specificity {
+				quality, position, specificity = r.quality, i, s
+			}
+		}
+	}
+	return quality, position
+}
+
+// matchSpecificity ranks how closely a range matches a media type: 2 exact,
+// 1 type/*, 0 */*, -1 no match
+func matchSpecificity(mediaRange, mediaType string) int {
+	switch {
+	case mediaRange == mediaType:
+		return 2
+	case mediaRange == "*/*":
+		return 0
+	case strings.HasSuffix(mediaRange, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(mediaRange, "*")):
+		return 1
+	default:
+		return -1
+	}
+}
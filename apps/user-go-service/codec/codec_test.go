@@ -0,0 +1,161 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type sample struct {
+	Name    string   `json:"name" xml:"name"`
+	Count   int      `json:"count" xml:"count"`
+	Big     uint64   `json:"big" xml:"big"`
+	Ratio   float64  `json:"ratio" xml:"ratio"`
+	Active  bool     `json:"active" xml:"active"`
+	Tags    []string `json:"tags" xml:"tag"`
+	Note    *string  `json:"note" xml:"note,omitempty"`
+	Payload []byte   `json:"payload,omitempty" xml:"-"`
+}
+
+func TestRegistry(t *testing.T) {
+	registry := DefaultRegistry()
+
+	t.Run("should negotiate Accept headers", func(t *testing.T) {
+		cases := map[string]Codec{
+			"":                                  JSON,
+			"*/*":                               JSON,
+			"application/cbor":                  CBOR,
+			"application/x-msgpack":             MessagePack,
+			"text/html, application/xml;q=0.9":  XML,
+			"application/xml;q=0.5, */*;q=0.8":  JSON,
+			"application/cbor, application/xml": CBOR,
+			"application/xml, application/cbor": XML,
+			"application/json;q=0, */*":         MessagePack,
+			"text/*":                            XML,
+			"application/*;q=0.5, text/xml":     XML,
+		}
+		for accept, expected := range cases {
+			codec, ok := registry.Negotiate(accept)
+			require.True(t, ok, accept)
+			assert.Equal(t, expected.MediaTypes()[0], codec.MediaTypes()[0], accept)
+		}
+	})
+
+	t.Run("should reject unacceptable Accept headers", func(t *testing.T) {
+		for _, accept := range []string{"text/html", "application/json;q=0", "image/*"} {
+			_, ok := registry.Negotiate(accept)
+			assert.False(t, ok, accept)
+		}
+	})
+
+	t.Run("should find codecs by Content-Type", func(t *testing.T) {
+		codec, ok := registry.ForContentType("application/json; charset=UTF-8")
+		require.True(t, ok)
+		assert.Equal(t, JSON, codec)
+		codec, ok = registry.ForContentType("application/vnd.msgpack")
+		require.True(t, ok)
+		assert.Equal(t, MessagePack, codec)
+
+		_, ok = registry.ForContentType("text/csv")
+		assert.False(t, ok)
+		_, ok = registry.ForContentType("")
+		assert.False(t, ok)
+	})
+
+	t.Run("should register new codecs", func(t *testing.T) {
+		registry := NewRegistry(JSON).Register(CBOR)
+		assert.Equal(t, []string{"application/json", "application/cbor"}, registry.MediaTypes())
+		_, ok := registry.Negotiate("application/xml")
+		assert.False(t, ok)
+	})
+}
+
+func TestCodecs(t *testing.T) {
+	note := "café ☕"
+	value := sample{
+		Name:    "Jane",
+		Count:   -300,
+		Big:     1 << 63,
+		Ratio:   0.25,
+		Active:  true,
+		Tags:    []string{"a", "b"},
+		Note:    &note,
+		Payload: []byte{0, 1, 2},
+	}
+
+	for _, codec := range []Codec{JSON, MessagePack, CBOR, XML} {
+		codec := codec
+		t.Run("should round trip "+codec.MediaTypes()[0], func(t *testing.T) {
+			data, err := codec.Marshal(value)
+			require.NoError(t, err)
+
+			var decoded sample
+			require.NoError(t, codec.Unmarshal(data, &decoded))
+			expected := value
+			if codec == XML {
+				expected.Payload = nil
+			}
+			assert.Equal(t, expected, decoded)
+		})
+	}
+
+	t.Run("should encode in the shortest form", func(t *testing.T) {
+		data, err := MessagePack.Marshal(map[string]int{"a": 1})
+		require.NoError(t, err)
+		assert.Equal(t, []byte{0x81, 0xa1, 'a', 0x01}, data)
+
+		data, err = CBOR.Marshal(map[string]int{"a": 1})
+		require.NoError(t, err)
+		assert.Equal(t, []byte{0xa1, 0x61, 'a', 0x01}, data)
+	})
+
+	t.Run("should keep the field order", func(t *testing.T) {
+		data, err := MessagePack.Marshal(struct {
+			B int `json:"b"`
+			A int `json:"a"`
+		}{})
+		require.NoError(t, err)
+		assert.Equal(t, []byte{0x82, 0xa1, 'b', 0x00, 0xa1, 'a', 0x00}, data)
+	})
+
+	t.Run("should decode other encoders' forms", func(t *testing.T) {
+		var decoded sample
+		// str8 name, uint16 count, float32 ratio
+		data := []byte{0x83, 0xa4, 'n', 'a', 'm', 'e', 0xd9, 0x02, 'J', 'o', 0xa5, 'c', 'o', 'u', 'n', 't', 0xcd, 0x01, 0x00, 0xa5, 'r', 'a', 't', 'i', 'o', 0xca, 0x3e, 0x80, 0x00, 0x00}
+		require.NoError(t, MessagePack.Unmarshal(data, &decoded))
+		assert.Equal(t, sample{Name: "Jo", Count: 256, Ratio: 0.25}, decoded)
+
+		decoded = sample{}
+		// indefinite map and text, half float ratio, tagged count
+		data = []byte{0xbf, 0x64, 'n', 'a', 'm', 'e', 0x7f, 0x61, 'J', 0x61, 'o', 0xff, 0x65, 'r', 'a', 't', 'i', 'o', 0xf9, 0x34, 0x00, 0x65, 'c', 'o', 'u', 'n', 't', 0xc1, 0x18, 0x64, 0xff}
+		require.NoError(t, CBOR.Unmarshal(data, &decoded))
+		assert.Equal(t, sample{Name: "Jo", Count: 100, Ratio: 0.25}, decoded)
+	})
+
+	t.Run("should reject malformed documents", func(t *testing.T) {
+		deep := bytes.Repeat([]byte{0x91}, maxDepth+1)
+		documents := map[Codec][][]byte{
+			MessagePack: {{0x81, 0xa1}, {0xc0, 0xc0}, {0xd4, 0x01, 0x00}, {0x81, 0x01, 0x01}, {0xdd, 0xff, 0xff, 0xff, 0xff}, append(deep, 0xc0)},
+			CBOR:        {{0xa1, 0x61}, {0xf6, 0xf6}, {0xff}, {0xa1, 0x01, 0x01}, {0x9b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, append(bytes.Repeat([]byte{0x81}, maxDepth+1), 0xf6)},
+		}
+		for codec, docs := range documents {
+			for _, doc := range docs {
+				var decoded any
+				assert.Error(t, codec.Unmarshal(doc, &decoded), "%s % x", codec.MediaTypes()[0], doc)
+			}
+		}
+	})
+
+	t.Run("should decode into any as JSON would", func(t *testing.T) {
+		data, err := CBOR.Marshal(value)
+		require.NoError(t, err)
+		var fromCBOR, fromJSON any
+		require.NoError(t, CBOR.Unmarshal(data, &fromCBOR))
+		encoded, _ := json.Marshal(value)
+		require.NoError(t, json.Unmarshal(encoded, &fromJSON))
+		assert.Equal(t, fromJSON, fromCBOR)
+	})
+}
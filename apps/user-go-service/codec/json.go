@@ -0,0 +1,43 @@
+package codec
+
+import (
+	"encoding/json"
+	"encoding/xml"
+)
+
+// JSON encodes bodies with encoding/json
+var JSON Codec = jsonCodec{}
+
+type jsonCodec struct{}
+
+func (jsonCodec) MediaTypes() []string     { return []string{"application/json"} }
+func (jsonCodec) ProblemMediaType() string { return "application/problem+json" }
+
+func (jsonCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+// XML encodes bodies with encoding/xml, so types need xml tags and a root
+// element name
+var XML Codec = xmlCodec{}
+
+type xmlCodec struct{}
+
+func (xmlCodec) MediaTypes() []string     { return []string{"application/xml", "text/xml"} }
+func (xmlCodec) ProblemMediaType() string { return "application/problem+xml" }
+
+func (xmlCodec) Marshal(v any) ([]byte, error) {
+	data, err := xml.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
+
+func (xmlCodec) Unmarshal(data []byte, v any) error {
+	return xml.Unmarshal(data, v)
+}
@@ -0,0 +1,273 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// MessagePack encodes bodies as MessagePack maps keyed by the json field
+// names. Extension types are rejected.
+var MessagePack Codec = msgpackCodec{}
+
+type msgpackCodec struct{}
+
+func (msgpackCodec) MediaTypes() []string {
+	return []string{"application/msgpack", "application/vnd.msgpack", "application/x-msgpack"}
+}
+
+func (msgpackCodec) ProblemMediaType() string { return "application/msgpack" }
+
+func (msgpackCodec) Marshal(v any) ([]byte, error) {
+	tree, err := marshalTree(v)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := writeMsgpack(&buf, tree); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (msgpackCodec) Unmarshal(data []byte, v any) error {
+	tree, err := decodeDocument(data, readMsgpack)
+	if err != nil {
+		return fmt.Errorf("msgpack: %w", err)
+	}
+	return unmarshalTree(tree, v)
+}
+
+// writeMsgpack writes a tree in its most compact MessagePack form
+func writeMsgpack(w *bytes.Buffer, tree any) error {
+	switch v := tree.(type) {
+	case nil:
+		w.WriteByte(0xc0)
+	case bool:
+		if v {
+			w.WriteByte(0xc3)
+		} else {
+			w.WriteByte(0xc2)
+		}
+	case int64:
+		writeMsgpackInt(w, v)
+	case uint64:
+		if v <= math.MaxInt64 {
+			writeMsgpackInt(w, int64(v))
+		} else {
+			w.WriteByte(0xcf)
+			w.Write(binary.BigEndian.AppendUint64(nil, v))
+		}
+	case float64:
+		w.WriteByte(0xcb)
+		w.Write(binary.BigEndian.AppendUint64(nil, math.Float64bits(v)))
+	case string:
+		writeMsgpackHeader(w, len(v), 0xa0, 31, 0xd9, 0xda, 0xdb)
+		w.WriteString(v)
+	case []any:
+		writeMsgpackHeader(w, len(v), 0x90, 15, 0, 0xdc, 0xdd)
+		for _, value := range v {
+			if err := writeMsgpack(w, value); err != nil {
+				return err
+			}
+		}
+	case object:
+		writeMsgpackHeader(w, len(v), 0x80, 15, 0, 0xde, 0xdf)
+		for _, m := range v {
+			writeMsgpack(w, m.key)
+			if err := writeMsgpack(w, m.value); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("msgpack: unsupported value %T", tree)
+	}
+	return nil
+}
+
+func writeMsgpackInt(w *bytes.Buffer, v int64) {
+	switch {
+	case v >= 0 && v <= 0x7f:
+		w.WriteByte(byte(v))
+	case v >= -32 && v < 0:
+		w.WriteByte(byte(v))
+	case v >= 0 && v <= math.MaxUint8:
+		w.Write([]byte{0xcc, byte(v)})
+	case v >= 0 && v <= math.MaxUint16:
+		w.WriteByte(0xcd)
+		w.Write(binary.BigEndian.AppendUint16(nil, uint16(v)))
+	case v >= 0 && v <= math.MaxUint32:
+		w.WriteByte(0xce)
+		w.Write(binary.BigEndian.AppendUint32(nil, uint32(v)))
+	case v >= 0:
+		w.WriteByte(0xcf)
+		w.Write(binary.BigEndian.AppendUint64(nil, uint64(v)))
+	case v >= math.MinInt8:
+		w.Write([]byte{0xd0, byte(v)})
+	case v >= math.MinInt16:
+		w.WriteByte(0xd1)
+		w.Write(binary.BigEndian.AppendUint16(nil, uint16(v)))
+	case v >= math.MinInt32:
+		w.WriteByte(0xd2)
+		w.Write(binary.BigEndian.AppendUint32(nil, uint32(v)))
+	default:
+		w.WriteByte(0xd3)
+		w.Write(binary.BigEndian.AppendUint64(nil, uint64(v)))
+	}
+}
+
+// writeMsgpackHeader writes the length of a string, array or map: fixed
+// within fixMax, otherwise with the 8 (when the family has one), 16 or 32
+// bit marker
+func writeMsgpackHeader(w *bytes.Buffer, n int, fixed byte, fixMax int, marker8, marker16, marker32 byte) {
+	switch {
+	case n <= fixMax:
+		w.WriteByte(fixed | byte(n))
+	case marker8 != 0 && n <= math.MaxUint8:
+		w.Write([]byte{marker8, byte(n)})
+	case n <= math.MaxUint16:
+		w.WriteByte(marker16)
+		w.Write(binary.BigEndian.AppendUint16(nil, uint16(n)))
+	default:
+		w.WriteByte(marker32)
+		w.Write(binary.BigEndian.AppendUint32(nil, uint32(n)))
+	}
+}
+
+// readMsgpack reads one MessagePack value as a tree
+func readMsgpack(r *reader) (any, error) {
+	b, err := r.byte()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case b <= 0x7f:
+		return int64(b), nil
+	case b >= 0xe0:
+		return int64(int8(b)), nil
+	case b >= 0x80 && b <= 0x8f:
+		return readMsgpackMap(r, uint64(b&0x0f))
+	case b >= 0x90 && b <= 0x9f:
+		return readMsgpackArray(r, uint64(b&0x0f))
+	case b >= 0xa0 && b <= 0xbf:
+		return readMsgpackString(r, uint64(b&0x1f))
+	}
+
+	switch b {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xc5, 0xc6:
+		n, err := r.uint(1 << (b - 0xc4))
+		if err != nil {
+			return nil, err
+		}
+		return r.bytes(n)
+	case 0xca:
+		u, err := r.uint(4)
+		return float64(math.Float32frombits(uint32(u))), err
+	case 0xcb:
+		u, err := r.uint(8)
+		return math.Float64frombits(u), err
+	case 0xcc, 0xcd, 0xce:
+		u, err := r.uint(1 << (b - 0xcc))
+		return int64(u), err
+	case 0xcf:
+		u, err := r.uint(8)
+		if u <= math.MaxInt64 {
+			return int64(u), err
+		}
+		return u, err
+	case 0xd0:
+		u, err := r.uint(1)
+		return int64(int8(u)), err
+	case 0xd1:
+		u, err := r.uint(2)
+		return int64(int16(u)), err
+	case 0xd2:
+		u, err := r.uint(4)
+		return int64(int32(u)), err
+	case 0xd3:
+		u, err := r.uint(8)
+		return int64(u), err
+	case 0xd9, 0xda, 0xdb:
+		n, err := r.uint(1 << (b - 0xd9))
+		if err != nil {
+			return nil, err
+		}
+		return readMsgpackString(r, n)
+	case 0xdc, 0xdd:
+		n, err := r.uint(2 << (b - 0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return readMsgpackArray(r, n)
+	case 0xde, 0xdf:
+		n, err := r.uint(2 << (b - 0xde))
+		if err != nil {
+			return nil, err
+		}
+		return readMsgpackMap(r, n)
+	default:
+		return nil, fmt.Errorf("unsupported type 0x%02x", b)
+	}
+}
+
+func readMsgpackString(r *reader, n uint64) (any, error) {
+	b, err := r.bytes(n)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func readMsgpackArray(r *reader, n uint64) (any, error) {
+	if err := r.enter(); err != nil {
+		return nil, err
+	}
+	defer r.leave()
+	// Every element takes at least a byte
+	if n > uint64(len(r.data)) {
+		return nil, fmt.Errorf("array of %d elements exceeds the document", n)
+	}
+	array := make([]any, 0, n)
+	for i := uint64(0); i < n; i++ {
+		value, err := readMsgpack(r)
+		if err != nil {
+			return nil, err
+		}
+		array = append(array, value)
+	}
+	return array, nil
+}
+
+func readMsgpackMap(r *reader, n uint64) (any, error) {
+	if err := r.enter(); err != nil {
+		return nil, err
+	}
+	defer r.leave()
+	if n > uint64(len(r.data))/2 {
+		return nil, fmt.Errorf("map of %d entries exceeds the document", n)
+	}
+	obj := make(object, 0, n)
+	for i := uint64(0); i < n; i++ {
+		key, err := readMsgpack(r)
+		if err != nil {
+			return nil, err
+		}
+		name, ok := key.(string)
+		if !ok {
+			return nil, fmt.Errorf("map key %v is not a string", key)
+		}
+		value, err := readMsgpack(r)
+		if err != nil {
+			return nil, err
+		}
+		obj = append(obj, member{key: name, value: value})
+	}
+	return obj, nil
+}
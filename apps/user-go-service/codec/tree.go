@@ -0,0 +1,213 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+)
+
+// The binary codecs go through encoding/json: values are encoded as JSON
+// and parsed into a tree, and decoded trees are written as JSON and
+// unmarshaled. Every format thus honours the json tags and Marshaler
+// implementations of the types. Trees hold nil, bool, int64, uint64,
+// float64, string, []byte, []any and object.
+
+// maxDepth bounds the nesting of decoded documents
+const maxDepth = 100
+
+// errTooDeep is returned for documents nested deeper than maxDepth
+var errTooDeep = errors.New("document nested too deeply")
+
+// object is a map that keeps the order of its members
+type object []member
+
+type member struct {
+	key   string
+	value any
+}
+
+// marshalTree encodes v as JSON and parses it into a tree
+func marshalTree(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return parseJSON(decoder)
+}
+
+// unmarshalTree writes tree as JSON and unmarshals it into v
+func unmarshalTree(tree any, v any) error {
+	var buf bytes.Buffer
+	if err := writeJSON(&buf, tree); err != nil {
+		return err
+	}
+	return json.Unmarshal(buf.Bytes(), v)
+}
+
+// parseJSON reads the next JSON value of decoder as a tree
+func parseJSON(decoder *json.Decoder) (any, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch t := token.(type) {
+	case json.Delim:
+		if t == '[' {
+			array := []any{}
+			for decoder.More() {
+				value, err := parseJSON(decoder)
+				if err != nil {
+					return nil, err
+				}
+				array = append(array, value)
+			}
+			_, err := decoder.Token()
+			return array, err
+		}
+		obj := object{}
+		for decoder.More() {
+			key, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := parseJSON(decoder)
+			if err != nil {
+				return nil, err
+			}
+			obj = append(obj, member{key: key.(string), value: value})
+		}
+		_, err := decoder.Token()
+		return obj, err
+	case json.Number:
+		if i, err := strconv.ParseInt(string(t), 10, 64); err == nil {
+			return i, nil
+		}
+		if u, err := strconv.ParseUint(string(t), 10, 64); err == nil {
+			return u, nil
+		}
+		return strconv.ParseFloat(string(t), 64)
+	default:
+		return t, nil
+	}
+}
+
+// writeJSON writes a tree as JSON
+func writeJSON(w *bytes.Buffer, tree any) error {
+	switch v := tree.(type) {
+	case nil:
+		w.WriteString("null")
+	case bool:
+		w.WriteString(strconv.FormatBool(v))
+	case int64:
+		w.WriteString(strconv.FormatInt(v, 10))
+	case uint64:
+		w.WriteString(strconv.FormatUint(v, 10))
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return fmt.Errorf("unsupported number %v", v)
+		}
+		w.WriteString(strconv.FormatFloat(v, 'g', -1, 64))
+	case string, []byte:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		w.Write(data)
+	case []any:
+		w.WriteByte('[')
+		for i, value := range v {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			if err := writeJSON(w, value); err != nil {
+				return err
+			}
+		}
+		w.WriteByte(']')
+	case object:
+		w.WriteByte('{')
+		for i, m := range v {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			key, _ := json.Marshal(m.key)
+			w.Write(key)
+			w.WriteByte(':')
+			if err := writeJSON(w, m.value); err != nil {
+				return err
+			}
+		}
+		w.WriteByte('}')
+	default:
+		return fmt.Errorf("unsupported value %T", tree)
+	}
+	return nil
+}
+
+// reader reads a binary document, failing on truncated input
+type reader struct {
+	data  []byte
+	depth int
+}
+
+func (r *reader) byte() (byte, error) {
+	if len(r.data) == 0 {
+		return 0, io.ErrUnexpectedEOF
+	}
+	b := r.data[0]
+	r.data = r.data[1:]
+	return b, nil
+}
+
+func (r *reader) bytes(n uint64) ([]byte, error) {
+	if n > uint64(len(r.data)) {
+		return nil, io.ErrUnexpectedEOF
+	}
+	b := r.data[:n]
+	r.data = r.data[n:]
+	return b, nil
+}
+
+func (r *reader) uint(size int) (uint64, error) {
+	b, err := r.bytes(uint64(size))
+	if err != nil {
+		return 0, err
+	}
+	var u uint64
+	for _, c := range b {
+		u = u<<8 | uint64(c)
+	}
+	return u, nil
+}
+
+// enter tracks the nesting of a container, failing beyond maxDepth
+func (r *reader) enter() error {
+	r.depth++
+	if r.depth > maxDepth {
+		return errTooDeep
+	}
+	return nil
+}
+
+func (r *reader) leave() {
+	r.depth--
+}
+
+// decodeDocument decodes one value with next and rejects trailing data
+func decodeDocument(data []byte, next func(*reader) (any, error)) (any, error) {
+	r := &reader{data: data}
+	tree, err := next(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(r.data) > 0 {
+		return nil, errors.New("unexpected data after document")
+	}
+	return tree, nil
+}
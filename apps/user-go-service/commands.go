@@ -0,0 +1,501 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mateusmacedo/scouts/apps/user-go-service/cli"
+	"github.com/mateusmacedo/scouts/apps/user-go-service/config"
+	"github.com/mateusmacedo/scouts/apps/user-go-service/handlers"
+	"github.com/mateusmacedo/scouts/apps/user-go-service/seed"
+	"github.com/mateusmacedo/scouts/apps/user-go-service/storage"
+	gouser "github.com/mateusmacedo/scouts/libs/user-go"
+	"github.com/mateusmacedo/scouts/libs/user-go/migrate"
+)
+
+// commandLine holds the state shared by the commands: the streams, the
+// configuration flags given before the command and the output format
+type commandLine struct {
+	stdin      io.Reader
+	stdout     io.Writer
+	stderr     io.Writer
+	configArgs *[]string
+	output     string
+}
+
+// newRootCommand builds the user-go-service command line. Without a
+// command it serves the API, so existing deployments keep working.
+func newRootCommand(stdin io.Reader, stdout, stderr io.Writer) *cli.Command {
+	cmd := &commandLine{stdin: stdin, stdout: stdout, stderr: stderr, output: cli.FormatTable}
+	return &cli.Command{
+		Name:  "user-go-service",
+		Short: "User management API and admin commands. Configuration flags go before the command.",
+		Flags: func(fs *flag.FlagSet) {
+			cmd.configArgs = config.RegisterFlags(fs)
+			cmd.outputFlag(fs)
+		},
+		Default: "serve",
+		Commands: []*cli.Command{
+			{
+				Name:  "serve",
+				Short: "Serve the HTTP API",
+				Run: func(ctx context.Context, args []string) error {
+					cfg, err := cmd.loadConfig()
+					if err != nil {
+						return err
+					}
+					serve(ctx, cfg, *cmd.configArgs)
+					return nil
+				},
+			},
+			cmd.migrateCommand(),
+			cmd.seedCommand(),
+			cmd.usersCommand(),
+			cmd.configCommand(),
+		},
+	}
+}
+
+// outputFlag registers --output, accepted before the command and by every
+// command printing results
+func (c *commandLine) outputFlag(fs *flag.FlagSet) {
+	fs.StringVar(&c.output, "output", c.output, "output format: table or json")
+}
+
+// printer validates --output and returns the printer for results
+func (c *commandLine) printer(command string) (cli.Printer, error) {
+	if err := cli.ValidateFormat(c.output); err != nil {
+		return cli.Printer{}, &cli.UsageError{Command: command, Err: err}
+	}
+	return cli.Printer{W: c.stdout, Format: c.output}, nil
+}
+
+// loadConfig loads the configuration with the flags given before the command
+func (c *commandLine) loadConfig() (*config.Config, error) {
+	return config.Load(config.LoadOptions{Args: *c.configArgs})
+}
+
+// openStore opens the configured storage driver
+func (c *commandLine) openStore(ctx context.Context) (*storage.Store, error) {
+	cfg, err := c.loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	return storage.Open(ctx, cfg.Storage)
+}
+
+// withService opens the store and runs fn with a user service without
+// authorization, as an administrator operating the repository directly. It
+// warns when users will not outlive the command.
+func (c *commandLine) withService(ctx context.Context, fn func(service *gouser.UserService) error) error {
+	store, err := c.openStore(ctx)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	if !store.Persistent() {
+		fmt.Fprintln(c.stderr, "warning: the storage driver does not persist users; changes are lost when the command exits")
+	}
+	return fn(gouser.NewUserService(store.Repository, nil))
+}
+
+func (c *commandLine) migrateCommand() *cli.Command {
+	var steps int
+	var dryRun bool
+	dryRunFlags := func(fs *flag.FlagSet) {
+		c.outputFlag(fs)
+		fs.BoolVar(&dryRun, "dry-run", false, "print the SQL instead of executing it")
+	}
+	run := func(name string, action func(ctx context.Context, m *migrate.Migrator) ([]migrate.Status, error)) func(ctx context.Context, args []string) error {
+		return func(ctx context.Context, args []string) error {
+			p, err := c.printer("user-go-service migrate " + name)
+			if err != nil {
+				return err
+			}
+			store, err := c.openStore(ctx)
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			migrator, err := store.Migrator()
+			if errors.Is(err, storage.ErrNoMigrations) {
+				fmt.Fprintln(c.stderr, "nothing to migrate:", err)
+				return p.Print([]migrate.Status{}, migrationTable(nil))
+			}
+			if err != nil {
+				return err
+			}
+			if dryRun {
+				// The SQL is the output of a dry run; the plan goes to stderr
+				migrator = migrator.WithDryRun(c.stdout)
+				p.W = c.stderr
+			}
+			migrations, err := action(ctx, migrator)
+			if err != nil {
+				return err
+			}
+			if migrations == nil {
+				migrations = []migrate.Status{}
+			}
+			return p.Print(migrations, migrationTable(migrations))
+		}
+	}
+
+	return &cli.Command{
+		Name:  "migrate",
+		Short: "Apply, revert or list schema migrations",
+		Commands: []*cli.Command{
+			{
+				Name:  "up",
+				Short: "Apply pending migrations",
+				Flags: dryRunFlags,
+				Run: run("up", func(ctx context.Context, m *migrate.Migrator) ([]migrate.Status, error) {
+					return m.Up(ctx)
+				}),
+			},
+			{
+				Name:  "down",
+				Short: "Revert the last applied migrations",
+				Flags: func(fs *flag.FlagSet) {
+					dryRunFlags(fs)
+					fs.IntVar(&steps, "steps", 1, "number of migrations to revert")
+				},
+				Run: run("down", func(ctx context.Context, m *migrate.Migrator) ([]migrate.Status, error) {
+					if steps < 1 {
+						return nil, cli.Usagef("user-go-service migrate down", "steps must be positive, got %d", steps)
+					}
+					return m.Down(ctx, steps)
+				}),
+			},
+			{
+				Name:  "status",
+				Short: "List migrations and whether they are applied or drifted",
+				Flags: c.outputFlag,
+				Run: run("status", func(ctx context.Context, m *migrate.Migrator) ([]migrate.Status, error) {
+					return m.Status(ctx)
+				}),
+			},
+		},
+	}
+}
+
+func (c *commandLine) seedCommand() *cli.Command {
+	var count int
+	var randomSeed int64
+	return &cli.Command{
+		Name:  "seed",
+		Short: "Create realistic fake users",
+		Flags: func(fs *flag.FlagSet) {
+			c.outputFlag(fs)
+			fs.IntVar(&count, "count", 10, "number of users to create")
+			fs.Int64Var(&randomSeed, "seed", 0, "random seed for reproducible users (default: random)")
+		},
+		Run: func(ctx context.Context, args []string) error {
+			p, err := c.printer("user-go-service seed")
+			if err != nil {
+				return err
+			}
+			if count < 1 {
+				return cli.Usagef("user-go-service seed", "count must be positive, got %d", count)
+			}
+			if randomSeed == 0 {
+				randomSeed = time.Now().UnixNano()
+			}
+
+			return c.withService(ctx, func(service *gouser.UserService) error {
+				generator := seed.NewGenerator(randomSeed)
+				users := make([]*gouser.User, 0, count)
+				skipped := 0
+				for _, data := range generator.Users(count) {
+					user, err := service.Create(ctx, data)
+					if errors.Is(err, gouser.ErrUserAlreadyExists) {
+						skipped++
+						continue
+					}
+					if err != nil {
+						return fmt.Errorf("seeding %s: %w", data.Email, err)
+					}
+					users = append(users, user)
+				}
+				fmt.Fprintf(c.stderr, "seeded %d users (%d already existed, seed %d)\n", len(users), skipped, randomSeed)
+				return p.Print(users, userTable(users))
+			})
+		},
+	}
+}
+
+func (c *commandLine) usersCommand() *cli.Command {
+	var data gouser.CreateUserData
+	var filter gouser.UserFilter
+	var limit int
+	var importFormat string
+	var dryRun bool
+	var exportFormat, exportFields, exportFile string
+
+	return &cli.Command{
+		Name:  "users",
+		Short: "Manage users in the configured repository",
+		Commands: []*cli.Command{
+			{
+				Name:  "create",
+				Short: "Create a user",
+				Flags: func(fs *flag.FlagSet) {
+					c.outputFlag(fs)
+					fs.StringVar(&data.Name, "name", "", "user name (required)")
+					fs.StringVar(&data.Email, "email", "", "user email (required)")
+					fs.StringVar(&data.Phone, "phone", "", "user phone in international format")
+					fs.StringVar(&data.Address, "address", "", "user address")
+				},
+				Run: func(ctx context.Context, args []string) error {
+					p, err := c.printer("user-go-service users create")
+					if err != nil {
+						return err
+					}
+					return c.withService(ctx, func(service *gouser.UserService) error {
+						user, err := service.Create(ctx, data)
+						if err != nil {
+							return err
+						}
+						return p.Print(user, userTable([]*gouser.User{user}))
+					})
+				},
+			},
+			{
+				Name:  "get",
+				Args:  "<id>",
+				Short: "Show a user",
+				Flags: c.outputFlag,
+				Run: func(ctx context.Context, args []string) error {
+					p, err := c.printer("user-go-service users get")
+					if err != nil {
+						return err
+					}
+					if len(args) != 1 {
+						return cli.Usagef("user-go-service users get", "expected a user ID")
+					}
+					return c.withService(ctx, func(service *gouser.UserService) error {
+						user, err := service.FindByID(ctx, args[0])
+						if err != nil {
+							return fmt.Errorf("user %s: %w", args[0], err)
+						}
+						return p.Print(user, userTable([]*gouser.User{user}))
+					})
+				},
+			},
+			{
+				Name:  "list",
+				Short: "List users",
+				Flags: func(fs *flag.FlagSet) {
+					c.outputFlag(fs)
+					fs.StringVar(&filter.Name, "name", "", "only users whose name contains this text")
+					fs.StringVar(&filter.Email, "email", "", "only users whose email contains this text")
+					fs.IntVar(&limit, "limit", 0, "maximum number of users (0 lists all)")
+				},
+				Run: func(ctx context.Context, args []string) error {
+					p, err := c.printer("user-go-service users list")
+					if err != nil {
+						return err
+					}
+					return c.withService(ctx, func(service *gouser.UserService) error {
+						it := service.Iterate(ctx, filter)
+						defer it.Close()
+						users := []*gouser.User{}
+						for (limit <= 0 || len(users) < limit) && it.Next() {
+							users = append(users, it.User())
+						}
+						if err := it.Err(); err != nil {
+							return err
+						}
+						return p.Print(users, userTable(users))
+					})
+				},
+			},
+			{
+				Name:  "delete",
+				Args:  "<id>",
+				Short: "Delete a user",
+				Flags: c.outputFlag,
+				Run: func(ctx context.Context, args []string) error {
+					p, err := c.printer("user-go-service users delete")
+					if err != nil {
+						return err
+					}
+					if len(args) != 1 {
+						return cli.Usagef("user-go-service users delete", "expected a user ID")
+					}
+					return c.withService(ctx, func(service *gouser.UserService) error {
+						if err := service.Delete(ctx, args[0]); err != nil {
+							return fmt.Errorf("user %s: %w", args[0], err)
+						}
+						return p.Print(map[string]any{"id": args[0], "deleted": true}, cli.Table{
+							Header: []string{"ID", "STATUS"},
+							Rows:   [][]string{{args[0], "deleted"}},
+						})
+					})
+				},
+			},
+			{
+				Name:  "import",
+				Args:  "<file|->",
+				Short: "Import users from a CSV or NDJSON file, or stdin",
+				Flags: func(fs *flag.FlagSet) {
+					c.outputFlag(fs)
+					fs.StringVar(&importFormat, "format", "", "csv or ndjson (default: from the file extension)")
+					fs.BoolVar(&dryRun, "dry-run", false, "validate rows without creating users")
+				},
+				Run: func(ctx context.Context, args []string) error {
+					p, err := c.printer("user-go-service users import")
+					if err != nil {
+						return err
+					}
+					if len(args) != 1 {
+						return cli.Usagef("user-go-service users import", "expected a file, or - for stdin")
+					}
+					format := gouser.ImportFormat(importFormat)
+					if format == "" {
+						format = gouser.ImportFormat(strings.TrimPrefix(filepath.Ext(args[0]), "."))
+					}
+
+					var input io.Reader = c.stdin
+					if args[0] != "-" {
+						file, err := os.Open(args[0])
+						if err != nil {
+							return err
+						}
+						defer file.Close()
+						input = file
+					}
+					return c.withService(ctx, func(service *gouser.UserService) error {
+						report, err := gouser.NewUserImporter(service).Import(ctx, input, gouser.ImportOptions{Format: format, DryRun: dryRun})
+						if err != nil {
+							return err
+						}
+						return p.Print(report, importTable(report))
+					})
+				},
+			},
+			{
+				Name:  "export",
+				Short: "Export users as NDJSON or CSV",
+				Flags: func(fs *flag.FlagSet) {
+					fs.StringVar(&exportFormat, "format", handlers.ExportFormatNDJSON, "ndjson or csv")
+					fs.StringVar(&exportFields, "fields", "", "comma-separated fields (default: all)")
+					fs.StringVar(&exportFile, "file", "", "write to this file instead of stdout")
+					fs.StringVar(&filter.Name, "name", "", "only users whose name contains this text")
+					fs.StringVar(&filter.Email, "email", "", "only users whose email contains this text")
+				},
+				Run: func(ctx context.Context, args []string) error {
+					output := c.stdout
+					if exportFile != "" {
+						file, err := os.Create(exportFile)
+						if err != nil {
+							return err
+						}
+						defer file.Close()
+						output = file
+					}
+					return c.withService(ctx, func(service *gouser.UserService) error {
+						it := service.Iterate(ctx, filter)
+						defer it.Close()
+						rows, err := handlers.ExportUsers(output, it, exportFormat, exportFields)
+						if err != nil {
+							return err
+						}
+						fmt.Fprintf(c.stderr, "exported %d users\n", rows)
+						return nil
+					})
+				},
+			},
+		},
+	}
+}
+
+func (c *commandLine) configCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "config",
+		Short: "Inspect the configuration",
+		Commands: []*cli.Command{
+			{
+				Name:  "validate",
+				Short: "Check the configuration without starting the server",
+				Flags: c.outputFlag,
+				Run: func(ctx context.Context, args []string) error {
+					p, err := c.printer("user-go-service config validate")
+					if err != nil {
+						return err
+					}
+					cfg, err := c.loadConfig()
+					var validation *config.ValidationError
+					if errors.As(err, &validation) {
+						rows := make([][]string, len(validation.Problems))
+						for i, problem := range validation.Problems {
+							rows[i] = []string{problem.Key, problem.Message}
+						}
+						if err := p.Print(map[string]any{"valid": false, "problems": validation.Problems}, cli.Table{
+							Header: []string{"KEY", "PROBLEM"},
+							Rows:   rows,
+						}); err != nil {
+							return err
+						}
+						return fmt.Errorf("configuration has %d problems", len(validation.Problems))
+					}
+					if err != nil {
+						return err
+					}
+					return p.Print(map[string]any{"valid": true, "file": cfg.File}, cli.Table{
+						Rows: [][]string{{"configuration is valid"}},
+					})
+				},
+			},
+		},
+	}
+}
+
+// userTable lists users one per row
+func userTable(users []*gouser.User) cli.Table {
+	table := cli.Table{Header: []string{"ID", "NAME", "EMAIL", "PHONE", "CREATED AT"}}
+	for _, user := range users {
+		table.Rows = append(table.Rows, []string{user.ID, user.Name, user.Email, user.Phone, user.CreatedAt.Format(time.RFC3339)})
+	}
+	return table
+}
+
+// migrationTable lists migrations one per row
+func migrationTable(migrations []migrate.Status) cli.Table {
+	table := cli.Table{Header: []string{"VERSION", "NAME", "STATE", "APPLIED AT"}}
+	for _, migration := range migrations {
+		appliedAt := ""
+		if migration.AppliedAt != nil {
+			appliedAt = migration.AppliedAt.Format(time.RFC3339)
+		}
+		table.Rows = append(table.Rows, []string{strconv.FormatInt(migration.Version, 10), migration.Name, string(migration.State), appliedAt})
+	}
+	return table
+}
+
+// importTable summarizes an import report followed by its row errors
+func importTable(report *gouser.ImportReport) cli.Table {
+	table := cli.Table{
+		Header: []string{"PROCESSED", "CREATED", "DUPLICATES", "INVALID", "FAILED", "DRY RUN"},
+		Rows: [][]string{{
+			strconv.Itoa(report.Processed), strconv.Itoa(report.Created), strconv.Itoa(report.Duplicates),
+			strconv.Itoa(report.Invalid), strconv.Itoa(report.Failed), strconv.FormatBool(report.DryRun),
+		}},
+	}
+	if len(report.Errors) > 0 {
+		table.Rows = append(table.Rows, []string{}, []string{"ROW", "EMAIL", "STATUS", "MESSAGE"})
+		for _, rowError := range report.Errors {
+			table.Rows = append(table.Rows, []string{strconv.Itoa(rowError.Row), rowError.Email, string(rowError.Status), rowError.Message})
+		}
+	}
+	return table
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mateusmacedo/scouts/apps/user-go-service/cli"
+	gouser "github.com/mateusmacedo/scouts/libs/user-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runCLI runs the command line with args and returns the exit code, stdout and stderr
+func runCLI(t *testing.T, stdin io.Reader, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := cli.Main(context.Background(), newRootCommand(stdin, &stdout, &stderr), args, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestCommands(t *testing.T) {
+	t.Run("should seed reproducible users as JSON", func(t *testing.T) {
+		code, stdout, stderr := runCLI(t, nil, "--output", "json", "seed", "--count", "3", "--seed", "42")
+		require.Equal(t, cli.ExitOK, code, stderr)
+
+		var users []gouser.User
+		require.NoError(t, json.Unmarshal([]byte(stdout), &users))
+		require.Len(t, users, 3)
+		for _, user := range users {
+			assert.NoError(t, gouser.ValidateCreateUserData(gouser.CreateUserData{Name: user.Name, Email: user.Email, Phone: user.Phone}))
+		}
+		assert.Contains(t, stderr, "does not persist users")
+
+		_, stdout, _ = runCLI(t, nil, "seed", "--count", "3", "--seed", "42", "--output", "json")
+		var again []gouser.User
+		require.NoError(t, json.Unmarshal([]byte(stdout), &again))
+		require.Len(t, again, 3)
+		for i := range users {
+			assert.Equal(t, users[i].Email, again[i].Email)
+			assert.Equal(t, users[i].Phone, again[i].Phone)
+		}
+	})
+
+	t.Run("should create users and print a table", func(t *testing.T) {
+		code, stdout, _ := runCLI(t, nil, "users", "create", "--name", "John Doe", "--email", "john@example.com")
+		require.Equal(t, cli.ExitOK, code)
+
+		lines := strings.Split(strings.TrimSpace(stdout), "\n")
+		require.Len(t, lines, 2)
+		assert.Equal(t, []string{"ID", "NAME", "EMAIL", "PHONE", "CREATED", "AT"}, strings.Fields(lines[0]))
+		assert.Contains(t, lines[1], "john@example.com")
+	})
+
+	t.Run("should report invalid users", func(t *testing.T) {
+		code, _, stderr := runCLI(t, nil, "users", "create", "--name", "John", "--email", "invalid")
+		assert.Equal(t, cli.ExitError, code)
+		assert.Contains(t, stderr, gouser.ErrInvalidEmail.Error())
+	})
+
+	t.Run("should import from stdin", func(t *testing.T) {
+		input := strings.NewReader("name,email\nAna,ana@example.com\nBad,bad\n")
+		code, stdout, _ := runCLI(t, input, "users", "import", "--format", "csv", "--output", "json", "-")
+		require.Equal(t, cli.ExitOK, code)
+
+		var report gouser.ImportReport
+		require.NoError(t, json.Unmarshal([]byte(stdout), &report))
+		assert.Equal(t, 1, report.Created)
+		assert.Equal(t, 1, report.Invalid)
+	})
+
+	t.Run("should export to a file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "users.csv")
+		code, _, stderr := runCLI(t, nil, "users", "export", "--format", "csv", "--fields", "id,email", "--file", path)
+		require.Equal(t, cli.ExitOK, code, stderr)
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "id,email\n", string(data))
+		assert.Contains(t, stderr, "exported 0 users")
+	})
+
+	t.Run("should fail to get unknown users", func(t *testing.T) {
+		code, _, stderr := runCLI(t, nil, "users", "get", "42")
+		assert.Equal(t, cli.ExitError, code)
+		assert.Contains(t, stderr, "user 42: user not found")
+	})
+
+	t.Run("should report an empty migration status", func(t *testing.T) {
+		code, stdout, stderr := runCLI(t, nil, "migrate", "status", "--output", "json")
+		require.Equal(t, cli.ExitOK, code)
+		assert.JSONEq(t, "[]", stdout)
+		assert.Contains(t, stderr, "nothing to migrate")
+	})
+
+	t.Run("should migrate and persist users with sqlite", func(t *testing.T) {
+		dsn := filepath.Join(t.TempDir(), "users.db")
+		sqlite := []string{"--storage-driver", "sqlite", "--storage-dsn", dsn}
+		withSQLite := func(args ...string) []string {
+			return append(append([]string{}, sqlite...), args...)
+		}
+
+		code, stdout, stderr := runCLI(t, nil, withSQLite("migrate", "up", "--dry-run")...)
+		require.Equal(t, cli.ExitOK, code, stderr)
+		assert.Contains(t, stdout, "CREATE TABLE users")
+		assert.Contains(t, stderr, "create_users")
+
+		code, stdout, _ = runCLI(t, nil, withSQLite("migrate", "status")...)
+		require.Equal(t, cli.ExitOK, code)
+		assert.Contains(t, stdout, "pending")
+
+		code, _, stderr = runCLI(t, nil, withSQLite("migrate", "up")...)
+		require.Equal(t, cli.ExitOK, code, stderr)
+
+		code, stdout, _ = runCLI(t, nil, withSQLite("migrate", "status", "--output", "json")...)
+		require.Equal(t, cli.ExitOK, code)
+		var status []struct {
+			Version int64  `json:"version"`
+			State   string `json:"state"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(stdout), &status))
+		require.NotEmpty(t, status)
+		assert.Equal(t, "applied", status[0].State)
+
+		code, _, stderr = runCLI(t, nil, withSQLite("users", "create", "--name", "John Doe", "--email", "john@example.com")...)
+		require.Equal(t, cli.ExitOK, code, stderr)
+		assert.NotContains(t, stderr, "does not persist users")
+
+		code, stdout, _ = runCLI(t, nil, withSQLite("users", "list")...)
+		require.Equal(t, cli.ExitOK, code)
+		assert.Contains(t, stdout, "john@example.com")
+	})
+
+	t.Run("should validate the configuration", func(t *testing.T) {
+		code, stdout, _ := runCLI(t, nil, "config", "validate")
+		assert.Equal(t, cli.ExitOK, code)
+		assert.Contains(t, stdout, "configuration is valid")
+
+		code, stdout, stderr := runCLI(t, nil, "--server-port", "http", "--log-level", "loud", "config", "validate", "--output", "json")
+		assert.Equal(t, cli.ExitError, code)
+		var result struct {
+			Valid    bool `json:"valid"`
+			Problems []struct {
+				Key string `json:"key"`
+			} `json:"problems"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(stdout), &result))
+		assert.False(t, result.Valid)
+		assert.Len(t, result.Problems, 2)
+		assert.Contains(t, stderr, "configuration has 2 problems")
+	})
+
+	t.Run("should reject unknown commands and formats", func(t *testing.T) {
+		code, _, stderr := runCLI(t, nil, "users", "rename")
+		assert.Equal(t, cli.ExitUsage, code)
+		assert.Contains(t, stderr, `unknown command "rename"`)
+
+		code, _, stderr = runCLI(t, nil, "--output", "yaml", "users", "list")
+		assert.Equal(t, cli.ExitUsage, code)
+		assert.Contains(t, stderr, "output must be table or json")
+	})
+
+	t.Run("should print help", func(t *testing.T) {
+		code, _, stderr := runCLI(t, nil, "users", "--help")
+		assert.Equal(t, cli.ExitOK, code)
+		assert.Contains(t, stderr, "Usage: user-go-service users <command>")
+		assert.Contains(t, stderr, "export")
+	})
+}
@@ -2,57 +2,361 @@ package config
 
 import (
 	"fmt"
-	"os"
+	"net"
 	"strconv"
 	"strings"
+	"time"
+
+	gouser "github.com/mateusmacedo/scouts/libs/user-go"
 )
 
-// Config holds the application configuration
+// Config holds the application configuration. Every field with a key tag
+// can be set, in increasing precedence, by the config file (key), an
+// environment variable (env) and a command line flag (the dotted key with
+// dashes, e.g. --server-port).
 type Config struct {
-	Port        string
-	LogLevel    string
-	CORSOrigins []string
-	Environment string
+	Environment string          `key:"environment" env:"ENVIRONMENT"`
+	Server      ServerConfig    `key:"server"`
+	Log         LogConfig       `key:"log"`
+	Storage     StorageConfig   `key:"storage"`
+	Cache       CacheConfig     `key:"cache"`
+	Auth        AuthConfig      `key:"auth"`
+	RateLimit   RateLimitConfig `key:"rate_limit"`
+	Notifier    NotifierConfig  `key:"notifier"`
+	Telemetry   TelemetryConfig `key:"telemetry"`
+	Faults      FaultsConfig    `key:"faults"`
+
+	// File is the config file the configuration was loaded from, if any
+	File string
 }
 
-// LoadConfig loads configuration from environment variables
-func LoadConfig() (*Config, error) {
-	config := &Config{
-		Port:        getEnv("PORT", "8080"),
-		LogLevel:    getEnv("LOG_LEVEL", "info"),
-		CORSOrigins: getCORSOrigins(),
-		Environment: getEnv("ENVIRONMENT", "development"),
-	}
+// ServerConfig holds the HTTP server configuration
+type ServerConfig struct {
+	Port        string   `key:"port" env:"PORT"`
+	CORSOrigins []string `key:"cors_origins" env:"CORS_ORIGINS"`
+	// RequestTimeout bounds every request except streaming exports
+	RequestTimeout time.Duration `key:"request_timeout" env:"REQUEST_TIMEOUT"`
+	// ShutdownTimeout bounds the graceful shutdown
+	ShutdownTimeout time.Duration `key:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT"`
+	// ShutdownDelay keeps serving after readiness starts failing on shutdown
+	ShutdownDelay time.Duration `key:"shutdown_delay" env:"SHUTDOWN_DELAY"`
+	// TrustedProxies lists the CIDR ranges of the proxies whose
+	// X-Forwarded-For is trusted for client IPs; empty uses the peer address
+	TrustedProxies []string `key:"trusted_proxies" env:"TRUSTED_PROXIES"`
+	// CacheControl is sent with user reads; empty omits the header
+	CacheControl string `key:"cache_control" env:"CACHE_CONTROL"`
+}
+
+// LogConfig holds the logging configuration
+type LogConfig struct {
+	Level string `key:"level" env:"LOG_LEVEL"`
+	// File receives JSON logs, rotated by size, instead of stdout
+	File string `key:"file" env:"LOG_FILE"`
+	// RedactionRules is a JSON rule set replacing the default log redaction rules
+	RedactionRules string `key:"redaction_rules" env:"LOG_REDACTION_RULES"`
+}
+
+// Storage drivers
+const (
+	StorageDriverMemory = "memory"
+	StorageDriverSQLite = "sqlite"
+)
+
+// StorageConfig holds the user repository configuration
+type StorageConfig struct {
+	Driver string `key:"driver" env:"STORAGE_DRIVER"`
+	// DSN is the data source name of SQL drivers, e.g. a SQLite file path
+	DSN string `key:"dsn" env:"STORAGE_DSN"`
+	// Migrate applies pending schema migrations when the server starts
+	Migrate bool `key:"migrate" env:"STORAGE_MIGRATE"`
+	// IDStrategy generates user IDs: sequential, uuidv7, ulid or ksuid.
+	// Empty uses the driver default, sequential in memory and uuidv7 in SQL.
+	IDStrategy string `key:"id_strategy" env:"STORAGE_ID_STRATEGY"`
+}
+
+// CacheConfig holds the user lookup cache configuration. Each replica keeps
+// its own cache, so users updated through another replica may be served
+// stale for up to TTL.
+type CacheConfig struct {
+	Enabled bool `key:"enabled" env:"CACHE_ENABLED"`
+	// Size is the maximum number of cached user IDs
+	Size int           `key:"size" env:"CACHE_SIZE"`
+	TTL  time.Duration `key:"ttl" env:"CACHE_TTL"`
+	// NegativeTTL bounds how long unknown IDs are remembered; negative disables it
+	NegativeTTL time.Duration `key:"negative_ttl" env:"CACHE_NEGATIVE_TTL"`
+}
+
+// AuthConfig holds the authentication configuration
+type AuthConfig struct {
+	Enabled     bool          `key:"enabled" env:"AUTH_ENABLED"`
+	JWTSecret   string        `key:"jwt_secret" env:"AUTH_JWT_SECRET"`
+	JWKSFile    string        `key:"jwks_file" env:"AUTH_JWKS_FILE"`
+	JWKSURL     string        `key:"jwks_url" env:"AUTH_JWKS_URL"`
+	JWTIssuer   string        `key:"jwt_issuer" env:"AUTH_JWT_ISSUER"`
+	JWTAudience string        `key:"jwt_audience" env:"AUTH_JWT_AUDIENCE"`
+	ClockSkew   time.Duration `key:"clock_skew" env:"AUTH_CLOCK_SKEW"`
+	// APIKeys holds "name:sha256hex[:role1|role2]" entries separated by commas
+	APIKeys string `key:"api_keys" env:"AUTH_API_KEYS"`
+	// PolicyFile is a JSON RBAC policy enforced on user operations
+	PolicyFile string `key:"policy_file" env:"AUTHZ_POLICY_FILE"`
+}
+
+// RateLimitConfig holds per route group rate limits
+type RateLimitConfig struct {
+	Enabled bool `key:"enabled" env:"RATE_LIMIT_ENABLED"`
+	// IP limits every API request per client IP before authentication, so
+	// rejected credentials are limited too
+	IP RateLimit `key:"ip" env:"RATE_LIMIT_IP"`
+	// Read limits GET requests
+	Read RateLimit `key:"read" env:"RATE_LIMIT_READ"`
+	// Write limits POST, PUT and DELETE requests
+	Write RateLimit `key:"write" env:"RATE_LIMIT_WRITE"`
+	// Import limits bulk import submissions
+	Import RateLimit `key:"import" env:"RATE_LIMIT_IMPORT"`
+}
+
+// RateLimit allows Requests per Period with bursts up to Burst. It is
+// written as "requests/period[/burst]", e.g. "60/1m".
+type RateLimit struct {
+	Requests int           `key:"requests"`
+	Period   time.Duration `key:"period"`
+	Burst    int           `key:"burst"`
+}
+
+// NotifierConfig holds the notifier upstream configuration
+type NotifierConfig struct {
+	// URL is the notifier base URL. Empty disables the notifier.
+	URL     string        `key:"url" env:"NOTIFIER_URL"`
+	Timeout time.Duration `key:"timeout" env:"NOTIFIER_TIMEOUT"`
+}
 
-	if err := config.Validate(); err != nil {
-		return nil, fmt.Errorf("invalid configuration: %w", err)
+// TelemetryConfig holds the observability configuration
+type TelemetryConfig struct {
+	Tracing TracingConfig `key:"tracing"`
+}
+
+// Tracing exporters
+const (
+	TracingExporterNone   = "none"
+	TracingExporterOTLP   = "otlp"
+	TracingExporterStdout = "stdout"
+	TracingExporterFile   = "file"
+)
+
+// TracingConfig holds the OpenTelemetry tracing configuration
+type TracingConfig struct {
+	// Exporter is one of none, otlp, stdout or file
+	Exporter string `key:"exporter" env:"TRACING_EXPORTER"`
+	// Endpoint is the OTLP/HTTP endpoint URL. Empty uses OTEL_EXPORTER_OTLP_* defaults.
+	Endpoint string `key:"endpoint" env:"OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"`
+	// FilePath receives spans when Exporter is file
+	FilePath       string `key:"file" env:"TRACING_FILE"`
+	ServiceName    string `key:"service_name" env:"OTEL_SERVICE_NAME"`
+	ServiceVersion string
+	// SampleRatio is the fraction of new traces sampled, between 0 and 1
+	SampleRatio float64 `key:"sample_ratio" env:"TRACING_SAMPLE_RATIO"`
+}
+
+// FaultsConfig injects storage latency and errors so QA can exercise the
+// failure paths of the API. It is rejected in production.
+type FaultsConfig struct {
+	// Enabled injects faults from startup
+	Enabled bool `key:"enabled" env:"FAULTS_ENABLED"`
+	// Admin exposes GET and PUT /api/v1/admin/faults to toggle and tune the
+	// faults at runtime. It requires authentication and the admin role.
+	Admin bool `key:"admin" env:"FAULTS_ADMIN"`
+	// Seed makes the injected faults reproducible; 0 seeds from the clock
+	Seed        int     `key:"seed" env:"FAULTS_SEED"`
+	ErrorRate   float64 `key:"error_rate" env:"FAULTS_ERROR_RATE"`
+	TimeoutRate float64 `key:"timeout_rate" env:"FAULTS_TIMEOUT_RATE"`
+	// LatencyDistribution is fixed, uniform, normal or exponential; empty adds no latency
+	LatencyDistribution string        `key:"latency_distribution" env:"FAULTS_LATENCY_DISTRIBUTION"`
+	LatencyMean         time.Duration `key:"latency_mean" env:"FAULTS_LATENCY_MEAN"`
+	LatencySpread       time.Duration `key:"latency_spread" env:"FAULTS_LATENCY_SPREAD"`
+}
+
+// Configured reports whether the repository must be wrapped for fault injection
+func (f FaultsConfig) Configured() bool {
+	return f.Enabled || f.Admin
+}
+
+// FaultConfig returns the faults applied to every repository operation
+func (f FaultsConfig) FaultConfig() gouser.FaultConfig {
+	return gouser.FaultConfig{
+		Seed: int64(f.Seed),
+		Default: gouser.FaultRule{
+			ErrorRate:   f.ErrorRate,
+			TimeoutRate: f.TimeoutRate,
+			Latency: gouser.LatencyConfig{
+				Distribution: f.LatencyDistribution,
+				Mean:         f.LatencyMean,
+				Spread:       f.LatencySpread,
+			},
+		},
 	}
+}
 
-	return config, nil
+// Default returns the configuration used when nothing overrides it
+func Default() *Config {
+	return &Config{
+		Environment: "development",
+		Server: ServerConfig{
+			Port:            "8080",
+			CORSOrigins:     []string{"*"},
+			RequestTimeout:  30 * time.Second,
+			ShutdownTimeout: 10 * time.Second,
+			CacheControl:    "private, no-cache",
+		},
+		Log:     LogConfig{Level: "info"},
+		Storage: StorageConfig{Driver: StorageDriverMemory},
+		Cache: CacheConfig{
+			Size:        gouser.DefaultCacheSize,
+			TTL:         gouser.DefaultCacheTTL,
+			NegativeTTL: gouser.DefaultCacheNegativeTTL,
+		},
+		Auth: AuthConfig{ClockSkew: 30 * time.Second},
+		RateLimit: RateLimitConfig{
+			Enabled: true,
+			IP:      RateLimit{Requests: 600, Period: time.Minute},
+			Read:    RateLimit{Requests: 300, Period: time.Minute},
+			Write:   RateLimit{Requests: 60, Period: time.Minute},
+			Import:  RateLimit{Requests: 5, Period: time.Minute},
+		},
+		Notifier: NotifierConfig{Timeout: 5 * time.Second},
+		Telemetry: TelemetryConfig{
+			Tracing: TracingConfig{
+				Exporter:    TracingExporterNone,
+				FilePath:    "traces.jsonl",
+				ServiceName: "user-go-service",
+				SampleRatio: 1,
+			},
+		},
+	}
 }
 
-// Validate validates the configuration
+// Validate validates the configuration, reporting every invalid key
 func (c *Config) Validate() error {
-	if c.Port == "" {
-		return fmt.Errorf("PORT cannot be empty")
-	}
+	var problems Problems
 
 	// Validate port is a number
-	if _, err := strconv.Atoi(c.Port); err != nil {
-		return fmt.Errorf("PORT must be a valid number: %w", err)
+	if c.Server.Port == "" {
+		problems.Add("server.port", "cannot be empty")
+	} else if _, err := strconv.Atoi(c.Server.Port); err != nil {
+		problems.Add("server.port", "must be a valid number")
+	}
+	if c.Server.RequestTimeout <= 0 {
+		problems.Add("server.request_timeout", "must be positive")
+	}
+	if c.Server.ShutdownTimeout <= 0 {
+		problems.Add("server.shutdown_timeout", "must be positive")
+	}
+	if c.Server.ShutdownDelay < 0 {
+		problems.Add("server.shutdown_delay", "cannot be negative")
+	}
+	for _, proxy := range c.Server.TrustedProxies {
+		if _, _, err := net.ParseCIDR(proxy); err != nil {
+			problems.Add("server.trusted_proxies", fmt.Sprintf("%q is not a CIDR range", proxy))
+		}
 	}
 
 	validLogLevels := []string{"debug", "info", "warn", "error"}
-	if !contains(validLogLevels, c.LogLevel) {
-		return fmt.Errorf("LOG_LEVEL must be one of: %s", strings.Join(validLogLevels, ", "))
+	if !contains(validLogLevels, c.Log.Level) {
+		problems.Add("log.level", "must be one of: "+strings.Join(validLogLevels, ", "))
 	}
 
 	validEnvironments := []string{"development", "staging", "production"}
 	if !contains(validEnvironments, c.Environment) {
-		return fmt.Errorf("ENVIRONMENT must be one of: %s", strings.Join(validEnvironments, ", "))
+		problems.Add("environment", "must be one of: "+strings.Join(validEnvironments, ", "))
 	}
 
-	return nil
+	validDrivers := []string{StorageDriverMemory, StorageDriverSQLite}
+	if !contains(validDrivers, c.Storage.Driver) {
+		problems.Add("storage.driver", "must be one of: "+strings.Join(validDrivers, ", "))
+	}
+	if c.Storage.Driver == StorageDriverSQLite && c.Storage.DSN == "" {
+		problems.Add("storage.dsn", "is required by the sqlite driver")
+	}
+	if c.Storage.IDStrategy != "" && !contains(gouser.IDStrategies, c.Storage.IDStrategy) {
+		problems.Add("storage.id_strategy", "must be one of: "+strings.Join(gouser.IDStrategies, ", "))
+	} else if c.Storage.IDStrategy == gouser.IDStrategySequential && c.Storage.Driver != StorageDriverMemory {
+		problems.Add("storage.id_strategy", "sequential IDs restart with the process and would collide with stored users")
+	}
+
+	if c.Cache.Enabled {
+		if c.Cache.Size <= 0 {
+			problems.Add("cache.size", "must be positive")
+		}
+		if c.Cache.TTL <= 0 {
+			problems.Add("cache.ttl", "must be positive")
+		}
+	}
+
+	if c.Auth.Enabled {
+		if c.Auth.JWTSecret == "" && c.Auth.JWKSFile == "" && c.Auth.JWKSURL == "" && c.Auth.APIKeys == "" {
+			problems.Add("auth.enabled", "requires auth.jwt_secret, auth.jwks_file, auth.jwks_url or auth.api_keys")
+		}
+		if c.Auth.ClockSkew < 0 {
+			problems.Add("auth.clock_skew", "cannot be negative")
+		}
+	}
+	// Without authentication there is no principal, so every operation would be denied
+	if c.Auth.PolicyFile != "" && !c.Auth.Enabled {
+		problems.Add("auth.policy_file", "requires auth.enabled")
+	}
+
+	limits := []struct {
+		key   string
+		limit RateLimit
+	}{
+		{"rate_limit.ip", c.RateLimit.IP},
+		{"rate_limit.read", c.RateLimit.Read},
+		{"rate_limit.write", c.RateLimit.Write},
+		{"rate_limit.import", c.RateLimit.Import},
+	}
+	for _, l := range limits {
+		if err := l.limit.validate(); err != nil {
+			problems.Add(l.key, err.Error())
+		}
+	}
+
+	if c.Notifier.URL != "" && !strings.HasPrefix(c.Notifier.URL, "http://") && !strings.HasPrefix(c.Notifier.URL, "https://") {
+		problems.Add("notifier.url", "must be an http or https URL")
+	}
+	if c.Notifier.Timeout <= 0 {
+		problems.Add("notifier.timeout", "must be positive")
+	}
+
+	validExporters := []string{TracingExporterNone, TracingExporterOTLP, TracingExporterStdout, TracingExporterFile}
+	if !contains(validExporters, c.Telemetry.Tracing.Exporter) {
+		problems.Add("telemetry.tracing.exporter", "must be one of: "+strings.Join(validExporters, ", "))
+	}
+	if c.Telemetry.Tracing.SampleRatio < 0 || c.Telemetry.Tracing.SampleRatio > 1 {
+		problems.Add("telemetry.tracing.sample_ratio", "must be between 0 and 1")
+	}
+
+	if c.Faults.Configured() && c.IsProduction() {
+		problems.Add("faults", "fault injection is not allowed in production")
+	}
+	if c.Faults.Admin && !c.Auth.Enabled {
+		problems.Add("faults.admin", "requires auth.enabled")
+	}
+	if c.Faults.ErrorRate < 0 || c.Faults.ErrorRate > 1 {
+		problems.Add("faults.error_rate", "must be between 0 and 1")
+	}
+	if c.Faults.TimeoutRate < 0 || c.Faults.TimeoutRate > 1 {
+		problems.Add("faults.timeout_rate", "must be between 0 and 1")
+	}
+	if c.Faults.LatencyDistribution != "" && !contains(gouser.LatencyDistributions, c.Faults.LatencyDistribution) {
+		problems.Add("faults.latency_distribution", "must be one of: "+strings.Join(gouser.LatencyDistributions, ", "))
+	}
+	if c.Faults.LatencyMean < 0 {
+		problems.Add("faults.latency_mean", "cannot be negative")
+	}
+	if c.Faults.LatencySpread < 0 {
+		problems.Add("faults.latency_spread", "cannot be negative")
+	}
+
+	return problems.Err()
 }
 
 // IsDevelopment returns true if the environment is development
@@ -65,21 +369,51 @@ func (c *Config) IsProduction() bool {
 	return c.Environment == "production"
 }
 
-// getEnv gets an environment variable with a default value
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+// UnmarshalText reads a "requests/period[/burst]" limit such as "60/1m"
+func (l *RateLimit) UnmarshalText(text []byte) error {
+	value := string(text)
+	parts := strings.Split(value, "/")
+	if len(parts) != 2 && len(parts) != 3 {
+		return fmt.Errorf("must be formatted as requests/period[/burst], got %q", value)
 	}
-	return defaultValue
+
+	var limit RateLimit
+	var err error
+	if limit.Requests, err = strconv.Atoi(parts[0]); err != nil || limit.Requests <= 0 {
+		return fmt.Errorf("requests must be a positive integer, got %q", parts[0])
+	}
+	if limit.Period, err = time.ParseDuration(parts[1]); err != nil || limit.Period <= 0 {
+		return fmt.Errorf("period must be a positive duration, got %q", parts[1])
+	}
+	if len(parts) == 3 {
+		if limit.Burst, err = strconv.Atoi(parts[2]); err != nil || limit.Burst <= 0 {
+			return fmt.Errorf("burst must be a positive integer, got %q", parts[2])
+		}
+	}
+	*l = limit
+	return nil
 }
 
-// getCORSOrigins gets CORS origins from environment variable
-func getCORSOrigins() []string {
-	origins := getEnv("CORS_ORIGINS", "*")
-	if origins == "*" {
-		return []string{"*"}
+// String formats the limit as "requests/period[/burst]"
+func (l RateLimit) String() string {
+	s := fmt.Sprintf("%d/%s", l.Requests, l.Period)
+	if l.Burst > 0 {
+		s += fmt.Sprintf("/%d", l.Burst)
 	}
-	return strings.Split(origins, ",")
+	return s
+}
+
+// validate checks limits set field by field in a config file
+func (l RateLimit) validate() error {
+	switch {
+	case l.Requests <= 0:
+		return fmt.Errorf("requests must be a positive integer")
+	case l.Period <= 0:
+		return fmt.Errorf("period must be a positive duration")
+	case l.Burst < 0:
+		return fmt.Errorf("burst cannot be negative")
+	}
+	return nil
 }
 
 // contains checks if a slice contains a string
@@ -0,0 +1,337 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// env returns a LookupEnv reading from values only
+func env(values map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		value, ok := values[key]
+		return value, ok
+	}
+}
+
+// writeFile writes content to name in a temporary directory
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+// problemKeys returns the keys reported by a ValidationError
+func problemKeys(t *testing.T, err error) []string {
+	t.Helper()
+	var validation *ValidationError
+	require.True(t, errors.As(err, &validation), "expected a ValidationError, got %v", err)
+	keys := make([]string, len(validation.Problems))
+	for i, problem := range validation.Problems {
+		keys[i] = problem.Key
+	}
+	return keys
+}
+
+const yamlConfig = `
+environment: staging
+server:
+  port: "9090"
+  cors_origins: [https://app.example.com, https://admin.example.com]
+  request_timeout: 15s
+log:
+  level: debug
+rate_limit:
+  read: 100/1m
+  write:
+    requests: 10
+    period: 1s
+    burst: 20
+telemetry:
+  tracing:
+    exporter: stdout
+    sample_ratio: 0.5
+`
+
+func TestLoad(t *testing.T) {
+	t.Run("should use defaults", func(t *testing.T) {
+		cfg, err := Load(LoadOptions{LookupEnv: env(nil)})
+		require.NoError(t, err)
+
+		assert.Equal(t, Default(), cfg)
+		assert.Equal(t, 30*time.Second, cfg.Server.RequestTimeout)
+		assert.Equal(t, 10*time.Second, cfg.Server.ShutdownTimeout)
+		assert.Equal(t, "private, no-cache", cfg.Server.CacheControl)
+	})
+
+	t.Run("should read a YAML file", func(t *testing.T) {
+		path := writeFile(t, "config.yaml", yamlConfig)
+		cfg, err := Load(LoadOptions{File: path, LookupEnv: env(nil)})
+		require.NoError(t, err)
+
+		assert.Equal(t, path, cfg.File)
+		assert.Equal(t, "staging", cfg.Environment)
+		assert.Equal(t, "9090", cfg.Server.Port)
+		assert.Equal(t, []string{"https://app.example.com", "https://admin.example.com"}, cfg.Server.CORSOrigins)
+		assert.Equal(t, 15*time.Second, cfg.Server.RequestTimeout)
+		assert.Equal(t, "debug", cfg.Log.Level)
+		assert.Equal(t, RateLimit{Requests: 100, Period: time.Minute}, cfg.RateLimit.Read)
+		assert.Equal(t, RateLimit{Requests: 10, Period: time.Second, Burst: 20}, cfg.RateLimit.Write)
+		assert.Equal(t, TracingExporterStdout, cfg.Telemetry.Tracing.Exporter)
+		assert.Equal(t, 0.5, cfg.Telemetry.Tracing.SampleRatio)
+	})
+
+	t.Run("should read a TOML file", func(t *testing.T) {
+		path := writeFile(t, "config.toml", `
+environment = "production"
+
+[server]
+port = 9091
+shutdown_timeout = "20s"
+
+[auth]
+enabled = true
+api_keys = "ci:abc"
+`)
+		cfg, err := Load(LoadOptions{File: path, LookupEnv: env(nil)})
+		require.NoError(t, err)
+
+		assert.Equal(t, "production", cfg.Environment)
+		assert.Equal(t, "9091", cfg.Server.Port)
+		assert.Equal(t, 20*time.Second, cfg.Server.ShutdownTimeout)
+		assert.True(t, cfg.Auth.Enabled)
+	})
+
+	t.Run("should find the file from the flag or CONFIG_FILE", func(t *testing.T) {
+		path := writeFile(t, "config.yml", "log:\n  level: warn\n")
+
+		fromFlag, err := Load(LoadOptions{Args: []string{"--config", path}, LookupEnv: env(nil)})
+		require.NoError(t, err)
+		fromEnv, err := Load(LoadOptions{LookupEnv: env(map[string]string{EnvConfigFile: path})})
+		require.NoError(t, err)
+
+		assert.Equal(t, "warn", fromFlag.Log.Level)
+		assert.Equal(t, "warn", fromEnv.Log.Level)
+	})
+
+	t.Run("should layer file, environment and flags", func(t *testing.T) {
+		path := writeFile(t, "config.yaml", yamlConfig)
+		cfg, err := Load(LoadOptions{
+			File:      path,
+			Args:      []string{"--log-level", "error", "--rate-limit-enabled"},
+			LookupEnv: env(map[string]string{"PORT": "7070", "LOG_LEVEL": "warn", "RATE_LIMIT_ENABLED": "false", "ENVIRONMENT": ""}),
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, "staging", cfg.Environment, "empty variables are ignored")
+		assert.Equal(t, "7070", cfg.Server.Port)
+		assert.Equal(t, "error", cfg.Log.Level)
+		assert.True(t, cfg.RateLimit.Enabled)
+	})
+
+	t.Run("should read secrets from files", func(t *testing.T) {
+		secret := writeFile(t, "jwt", "s3cret\n")
+		keys := writeFile(t, "keys", "ci:abc")
+		path := writeFile(t, "config.yaml", "auth:\n  enabled: true\n  api_keys_file: "+keys+"\n")
+
+		cfg, err := Load(LoadOptions{File: path, LookupEnv: env(map[string]string{"AUTH_JWT_SECRET_FILE": secret})})
+		require.NoError(t, err)
+
+		assert.Equal(t, "s3cret", cfg.Auth.JWTSecret)
+		assert.Equal(t, "ci:abc", cfg.Auth.APIKeys)
+	})
+
+	t.Run("should report every invalid key", func(t *testing.T) {
+		path := writeFile(t, "config.yaml", "server:\n  port: http\n  timeout: 1s\nlog:\n  level: verbose\n")
+		_, err := Load(LoadOptions{
+			File: path,
+			Args: []string{"--notifier-timeout", "soon"},
+			LookupEnv: env(map[string]string{
+				"RATE_LIMIT_READ":      "fast",
+				"AUTH_JWT_SECRET":      "a",
+				"AUTH_JWT_SECRET_FILE": "b",
+			}),
+		})
+		require.Error(t, err)
+
+		assert.ElementsMatch(t, []string{
+			"server.timeout",
+			"rate_limit.read (RATE_LIMIT_READ)",
+			"auth.jwt_secret (AUTH_JWT_SECRET)",
+			"notifier.timeout (--notifier-timeout)",
+			"server.port",
+			"log.level",
+		}, problemKeys(t, err))
+		assert.Contains(t, err.Error(), "server.timeout: unknown key")
+	})
+
+	t.Run("should require a dsn for the sqlite driver", func(t *testing.T) {
+		_, err := Load(LoadOptions{Args: []string{"--storage-driver", "sqlite"}, LookupEnv: env(nil)})
+		assert.Equal(t, []string{"storage.dsn"}, problemKeys(t, err))
+
+		cfg, err := Load(LoadOptions{LookupEnv: env(map[string]string{
+			"STORAGE_DRIVER":  "sqlite",
+			"STORAGE_DSN":     "users.db",
+			"STORAGE_MIGRATE": "true",
+		})})
+		require.NoError(t, err)
+		assert.True(t, cfg.Storage.Migrate)
+	})
+
+	t.Run("should validate the ID strategy", func(t *testing.T) {
+		_, err := Load(LoadOptions{Args: []string{"--storage-id-strategy", "uuidv4"}, LookupEnv: env(nil)})
+		assert.Equal(t, []string{"storage.id_strategy"}, problemKeys(t, err))
+
+		_, err = Load(LoadOptions{
+			Args:      []string{"--storage-id-strategy", "sequential"},
+			LookupEnv: env(map[string]string{"STORAGE_DRIVER": "sqlite", "STORAGE_DSN": "users.db"}),
+		})
+		assert.Equal(t, []string{"storage.id_strategy"}, problemKeys(t, err))
+
+		cfg, err := Load(LoadOptions{Args: []string{"--storage-id-strategy", "ksuid"}, LookupEnv: env(nil)})
+		require.NoError(t, err)
+		assert.Equal(t, "ksuid", cfg.Storage.IDStrategy)
+	})
+
+	t.Run("should validate the cache when enabled", func(t *testing.T) {
+		_, err := Load(LoadOptions{Args: []string{"--cache-enabled", "--cache-size", "0"}, LookupEnv: env(nil)})
+		assert.Equal(t, []string{"cache.size"}, problemKeys(t, err))
+
+		cfg, err := Load(LoadOptions{LookupEnv: env(map[string]string{"CACHE_ENABLED": "true", "CACHE_TTL": "10s"})})
+		require.NoError(t, err)
+		assert.Equal(t, 10*time.Second, cfg.Cache.TTL)
+		assert.Equal(t, 1000, cfg.Cache.Size)
+	})
+
+	t.Run("should reject fault injection in production", func(t *testing.T) {
+		_, err := Load(LoadOptions{Args: []string{"--faults-enabled"}, LookupEnv: env(map[string]string{"ENVIRONMENT": "production"})})
+		assert.Equal(t, []string{"faults"}, problemKeys(t, err))
+
+		_, err = Load(LoadOptions{Args: []string{"--faults-admin"}, LookupEnv: env(nil)})
+		assert.Equal(t, []string{"faults.admin"}, problemKeys(t, err))
+
+		_, err = Load(LoadOptions{Args: []string{"--faults-latency-distribution", "pareto", "--faults-error-rate", "2"}, LookupEnv: env(nil)})
+		assert.ElementsMatch(t, []string{"faults.latency_distribution", "faults.error_rate"}, problemKeys(t, err))
+
+		cfg, err := Load(LoadOptions{LookupEnv: env(map[string]string{
+			"FAULTS_ENABLED":              "true",
+			"FAULTS_ERROR_RATE":           "0.25",
+			"FAULTS_LATENCY_DISTRIBUTION": "normal",
+			"FAULTS_LATENCY_MEAN":         "150ms",
+		})})
+		require.NoError(t, err)
+		assert.Equal(t, 0.25, cfg.Faults.FaultConfig().Default.ErrorRate)
+		assert.Equal(t, 150*time.Millisecond, cfg.Faults.FaultConfig().Default.Latency.Mean)
+	})
+
+	t.Run("should reject unsupported files", func(t *testing.T) {
+		path := writeFile(t, "config.ini", "port=1")
+		_, err := Load(LoadOptions{File: path, LookupEnv: env(nil)})
+		assert.Equal(t, []string{"config"}, problemKeys(t, err))
+	})
+}
+
+func TestRegisterFlags(t *testing.T) {
+	t.Run("should collect configuration flags among other flags", func(t *testing.T) {
+		path := writeFile(t, "config.yaml", "log:\n  level: warn\n")
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		output := fs.String("output", "table", "")
+		args := RegisterFlags(fs)
+
+		require.NoError(t, fs.Parse([]string{"--config", path, "--output", "json", "--server-port", "9090", "--auth-enabled", "--auth-api-keys", "ci:abc", "users", "list"}))
+		cfg, err := Load(LoadOptions{Args: *args, LookupEnv: env(nil)})
+		require.NoError(t, err)
+
+		assert.Equal(t, "json", *output)
+		assert.Equal(t, []string{"users", "list"}, fs.Args())
+		assert.Equal(t, path, cfg.File)
+		assert.Equal(t, "warn", cfg.Log.Level)
+		assert.Equal(t, "9090", cfg.Server.Port)
+		assert.True(t, cfg.Auth.Enabled)
+	})
+}
+
+func TestRateLimit(t *testing.T) {
+	t.Run("should parse and format limits", func(t *testing.T) {
+		var limit RateLimit
+		require.NoError(t, limit.UnmarshalText([]byte("60/1m/120")))
+
+		assert.Equal(t, RateLimit{Requests: 60, Period: time.Minute, Burst: 120}, limit)
+		assert.Equal(t, "60/1m0s/120", limit.String())
+	})
+
+	t.Run("should reject malformed limits", func(t *testing.T) {
+		var limit RateLimit
+		for _, text := range []string{"60", "0/1m", "60/soon", "60/1m/-1", "1/1m/1/1"} {
+			assert.Error(t, limit.UnmarshalText([]byte(text)), text)
+		}
+	})
+}
+
+func TestWatcher(t *testing.T) {
+	path := writeFile(t, "config.yaml", "log:\n  level: info\n")
+	opts := LoadOptions{File: path, LookupEnv: env(nil)}
+	cfg, err := Load(opts)
+	require.NoError(t, err)
+	watcher := NewWatcher(cfg, opts)
+
+	var reloaded *Config
+	watcher.OnReload(func(cfg *Config) { reloaded = cfg })
+
+	t.Run("should apply reloadable keys only", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(path, []byte(`
+log:
+  level: debug
+server:
+  port: "9999"
+  cors_origins: [https://app.example.com]
+rate_limit:
+  write: 1/1s
+`), 0o600))
+
+		changed, err := watcher.Reload()
+		require.NoError(t, err)
+
+		assert.ElementsMatch(t, []string{"log.level", "server.cors_origins", "rate_limit.write"}, changed)
+		assert.Same(t, watcher.Current(), reloaded)
+		assert.Equal(t, "debug", reloaded.Log.Level)
+		assert.Equal(t, []string{"https://app.example.com"}, reloaded.Server.CORSOrigins)
+		assert.Equal(t, RateLimit{Requests: 1, Period: time.Second}, reloaded.RateLimit.Write)
+		assert.Equal(t, "8080", reloaded.Server.Port, "the port requires a restart")
+		assert.Equal(t, "info", cfg.Log.Level, "the previous configuration is not modified")
+	})
+
+	t.Run("should apply the per IP rate limit", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(path, []byte(`
+log:
+  level: debug
+server:
+  cors_origins: [https://app.example.com]
+rate_limit:
+  write: 1/1s
+  ip: 10/1s
+`), 0o600))
+
+		changed, err := watcher.Reload()
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"rate_limit.ip"}, changed)
+		assert.Equal(t, RateLimit{Requests: 10, Period: time.Second}, reloaded.RateLimit.IP)
+	})
+
+	t.Run("should keep the configuration when the file is invalid", func(t *testing.T) {
+		current := watcher.Current()
+		require.NoError(t, os.WriteFile(path, []byte("log:\n  level: loud\n"), 0o600))
+
+		_, err := watcher.Reload()
+		assert.Error(t, err)
+		assert.Same(t, current, watcher.Current())
+	})
+}
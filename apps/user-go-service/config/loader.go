@@ -0,0 +1,393 @@
+package config
+
+import (
+	"encoding"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// EnvConfigFile names the config file when --config is not given
+const EnvConfigFile = "CONFIG_FILE"
+
+// configUsage describes the --config flag
+const configUsage = "YAML, TOML or JSON config file (" + EnvConfigFile + ")"
+
+// SecretFileSuffix marks environment variables and file keys whose value is
+// read from the named file, e.g. AUTH_JWT_SECRET_FILE=/run/secrets/jwt
+const SecretFileSuffix = "_FILE"
+
+// LoadOptions controls where Load reads configuration from
+type LoadOptions struct {
+	// File is a YAML, TOML or JSON config file. Defaults to the --config
+	// flag, then to CONFIG_FILE.
+	File string
+	// Args are command line flags
+	Args []string
+	// LookupEnv reads environment variables. Defaults to os.LookupEnv.
+	LookupEnv func(key string) (string, bool)
+}
+
+// Problem is an invalid configuration key
+type Problem struct {
+	Key     string `json:"key"`
+	Message string `json:"message"`
+}
+
+// Problems collects every invalid key found while loading
+type Problems []Problem
+
+// Add records a problem with key
+func (p *Problems) Add(key, message string) {
+	*p = append(*p, Problem{Key: key, Message: message})
+}
+
+// Err returns a ValidationError listing the problems, or nil
+func (p Problems) Err() error {
+	if len(p) == 0 {
+		return nil
+	}
+	return &ValidationError{Problems: p}
+}
+
+// ValidationError lists every invalid configuration key
+type ValidationError struct {
+	Problems Problems
+}
+
+func (e *ValidationError) Error() string {
+	lines := make([]string, len(e.Problems))
+	for i, problem := range e.Problems {
+		lines[i] = problem.Key + ": " + problem.Message
+	}
+	return strings.Join(lines, "; ")
+}
+
+// leaf is a settable configuration value
+type leaf struct {
+	key   string
+	env   string
+	value reflect.Value
+}
+
+// flagName returns the command line flag for the leaf, e.g. server-port
+func (l leaf) flagName() string {
+	return strings.NewReplacer(".", "-", "_", "-").Replace(l.key)
+}
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Load builds the configuration from the defaults, then the config file,
+// then environment variables, then command line flags, and validates it.
+// Every invalid key is reported in a single ValidationError.
+func Load(opts LoadOptions) (*Config, error) {
+	if opts.LookupEnv == nil {
+		opts.LookupEnv = os.LookupEnv
+	}
+	cfg := Default()
+	leaves := leavesOf(reflect.ValueOf(cfg).Elem(), "")
+	var problems Problems
+
+	// Flags are parsed first to find --config, and applied last
+	fs := flag.NewFlagSet("user-go-service", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	file := fs.String("config", "", configUsage)
+	var flagged []func()
+	defineFlags(fs, leaves, func(l leaf, s string) {
+		flagged = append(flagged, func() {
+			if err := setString(l.value, s); err != nil {
+				problems.Add(l.key+" (--"+l.flagName()+")", err.Error())
+			}
+		})
+	})
+	if err := fs.Parse(opts.Args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil, err
+		}
+		problems.Add("flags", err.Error())
+	}
+
+	cfg.File = opts.File
+	if cfg.File == "" {
+		cfg.File = *file
+	}
+	if cfg.File == "" {
+		cfg.File, _ = opts.LookupEnv(EnvConfigFile)
+	}
+	if cfg.File != "" {
+		loadFile(reflect.ValueOf(cfg).Elem(), cfg.File, &problems)
+	}
+
+	for _, l := range leaves {
+		applyEnv(l, opts.LookupEnv, &problems)
+	}
+	for _, apply := range flagged {
+		apply()
+	}
+
+	if err := cfg.Validate(); err != nil {
+		var validation *ValidationError
+		if errors.As(err, &validation) {
+			problems = append(problems, validation.Problems...)
+		}
+	}
+	if err := problems.Err(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// RegisterFlags registers --config and the configuration flags on fs, so
+// they can be mixed with other flags. The configuration flags given are
+// collected in order, to be passed to Load as LoadOptions.Args.
+func RegisterFlags(fs *flag.FlagSet) *[]string {
+	var args []string
+	fs.Func("config", configUsage, func(s string) error {
+		args = append(args, "--config="+s)
+		return nil
+	})
+	defineFlags(fs, leavesOf(reflect.ValueOf(Default()).Elem(), ""), func(l leaf, s string) {
+		args = append(args, "--"+l.flagName()+"="+s)
+	})
+	return &args
+}
+
+// defineFlags registers a flag per leaf calling set with its value.
+// Boolean flags can be given without a value.
+func defineFlags(fs *flag.FlagSet, leaves []leaf, set func(l leaf, s string)) {
+	for _, l := range leaves {
+		l := l
+		usage := "overrides " + l.key
+		if l.env != "" {
+			usage += " (" + l.env + ")"
+		}
+		apply := func(s string) error {
+			set(l, s)
+			return nil
+		}
+		if l.value.Kind() == reflect.Bool {
+			fs.BoolFunc(l.flagName(), usage, apply)
+		} else {
+			fs.Func(l.flagName(), usage, apply)
+		}
+	}
+}
+
+// leavesOf lists the tagged fields of v, descending into nested sections
+func leavesOf(v reflect.Value, prefix string) []leaf {
+	var leaves []leaf
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key, ok := field.Tag.Lookup("key")
+		if !ok {
+			continue
+		}
+		value := v.Field(i)
+		if isSection(value) {
+			leaves = append(leaves, leavesOf(value, prefix+key+".")...)
+			continue
+		}
+		leaves = append(leaves, leaf{key: prefix + key, env: field.Tag.Get("env"), value: value})
+	}
+	return leaves
+}
+
+// isSection reports whether v is a nested section rather than a value
+func isSection(v reflect.Value) bool {
+	return v.Kind() == reflect.Struct && v.Type() != durationType && !v.Addr().Type().Implements(textUnmarshalerType)
+}
+
+// applyEnv sets the leaf from its environment variable or its _FILE variant
+func applyEnv(l leaf, lookup func(string) (string, bool), problems *Problems) {
+	if l.env == "" {
+		return
+	}
+	value, set := lookup(l.env)
+	path, fromFile := lookup(l.env + SecretFileSuffix)
+	set, fromFile = set && value != "", fromFile && path != ""
+
+	switch {
+	case set && fromFile:
+		problems.Add(l.key+" ("+l.env+")", "set only one of "+l.env+" and "+l.env+SecretFileSuffix)
+	case fromFile:
+		secret, err := readSecret(path)
+		if err == nil {
+			err = setString(l.value, secret)
+		}
+		if err != nil {
+			problems.Add(l.key+" ("+l.env+SecretFileSuffix+")", err.Error())
+		}
+	case set:
+		if err := setString(l.value, value); err != nil {
+			problems.Add(l.key+" ("+l.env+")", err.Error())
+		}
+	}
+}
+
+// loadFile decodes a config file by extension and applies its keys
+func loadFile(v reflect.Value, path string, problems *Problems) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		problems.Add("config", err.Error())
+		return
+	}
+
+	values := map[string]any{}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".toml":
+		err = toml.Unmarshal(data, &values)
+	case ".yaml", ".yml", ".json":
+		err = yaml.Unmarshal(data, &values)
+	default:
+		err = fmt.Errorf("unsupported config file extension %q", ext)
+	}
+	if err != nil {
+		problems.Add("config", fmt.Sprintf("%s: %v", path, err))
+		return
+	}
+	applyMap(v, values, "", problems)
+}
+
+// applyMap sets the fields of section v from decoded file values. Unknown
+// keys are reported, and a key ending in _file reads the value of the key
+// without the suffix from the named file.
+func applyMap(v reflect.Value, values map[string]any, prefix string, problems *Problems) {
+	fields := map[string]reflect.Value{}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if key, ok := t.Field(i).Tag.Lookup("key"); ok {
+			fields[key] = v.Field(i)
+		}
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		raw := values[key]
+		field, ok := fields[key]
+		secretKey := strings.TrimSuffix(key, strings.ToLower(SecretFileSuffix))
+		if !ok && secretKey != key {
+			if field, ok = fields[secretKey]; ok && !isSection(field) {
+				secret, err := readSecret(fmt.Sprint(raw))
+				if err == nil {
+					err = setString(field, secret)
+				}
+				if err != nil {
+					problems.Add(prefix+key, err.Error())
+				}
+				continue
+			}
+		}
+		if !ok {
+			problems.Add(prefix+key, "unknown key")
+			continue
+		}
+
+		if nested, isMap := raw.(map[string]any); isMap && field.Kind() == reflect.Struct && field.Type() != durationType {
+			applyMap(field, nested, prefix+key+".", problems)
+			continue
+		}
+		if isSection(field) {
+			problems.Add(prefix+key, "must be a section")
+			continue
+		}
+		if err := setValue(field, raw); err != nil {
+			problems.Add(prefix+key, err.Error())
+		}
+	}
+}
+
+// setValue sets v from a decoded YAML or TOML value
+func setValue(v reflect.Value, raw any) error {
+	if list, ok := raw.([]any); ok {
+		if v.Kind() != reflect.Slice {
+			return fmt.Errorf("must not be a list")
+		}
+		items := make([]string, len(list))
+		for i, item := range list {
+			items[i] = fmt.Sprint(item)
+		}
+		v.Set(reflect.ValueOf(items))
+		return nil
+	}
+	if raw == nil {
+		return fmt.Errorf("must not be empty")
+	}
+	return setString(v, fmt.Sprint(raw))
+}
+
+// setString parses s into v according to its type
+func setString(v reflect.Value, s string) error {
+	if unmarshaler, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return unmarshaler.UnmarshalText([]byte(s))
+	}
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("must be a duration, got %q", s)
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("must be a boolean, got %q", s)
+		}
+		v.SetBool(b)
+	case reflect.Int:
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return fmt.Errorf("must be an integer, got %q", s)
+		}
+		v.SetInt(int64(n))
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return fmt.Errorf("must be a number, got %q", s)
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(s, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// readSecret reads a secret file, dropping the trailing newline
+func readSecret(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
@@ -0,0 +1,161 @@
+package config
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// ReloadableKeys are the keys applied by Watcher without a restart
+var ReloadableKeys = []string{
+	"log.level",
+	"server.cors_origins",
+	"rate_limit.ip",
+	"rate_limit.read",
+	"rate_limit.write",
+	"rate_limit.import",
+}
+
+// Watcher reloads the configuration on SIGHUP and when the config file
+// changes. Only ReloadableKeys are applied; other changes are logged as
+// requiring a restart.
+type Watcher struct {
+	options  LoadOptions
+	current  atomic.Pointer[Config]
+	mutex    sync.Mutex
+	handlers []func(cfg *Config)
+}
+
+// NewWatcher creates a watcher starting from cfg. Reloads read the same
+// config file cfg was loaded from.
+func NewWatcher(cfg *Config, opts LoadOptions) *Watcher {
+	if opts.File == "" {
+		opts.File = cfg.File
+	}
+	w := &Watcher{options: opts}
+	w.current.Store(cfg)
+	return w
+}
+
+// Current returns the configuration in effect
+func (w *Watcher) Current() *Config {
+	return w.current.Load()
+}
+
+// OnReload registers fn to be called with the new configuration after a
+// reload changes a reloadable key
+func (w *Watcher) OnReload(fn func(cfg *Config)) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.handlers = append(w.handlers, fn)
+}
+
+// Reload loads the configuration again and applies the reloadable keys that
+// changed, returning them. An invalid configuration is rejected as a whole
+// and the current one is kept.
+func (w *Watcher) Reload() ([]string, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	next, err := Load(w.options)
+	if err != nil {
+		return nil, err
+	}
+
+	current := w.current.Load()
+	applied := *current
+	applied.Server.CORSOrigins = append([]string(nil), current.Server.CORSOrigins...)
+
+	currentLeaves := leavesByKey(current)
+	appliedLeaves := leavesByKey(&applied)
+	var changed []string
+	for _, l := range leavesOf(reflect.ValueOf(next).Elem(), "") {
+		if reflect.DeepEqual(l.value.Interface(), currentLeaves[l.key].Interface()) {
+			continue
+		}
+		if !contains(ReloadableKeys, l.key) {
+			slog.Warn("Configuration change requires a restart", "key", l.key)
+			continue
+		}
+		appliedLeaves[l.key].Set(l.value)
+		changed = append(changed, l.key)
+	}
+	if len(changed) == 0 {
+		return nil, nil
+	}
+
+	w.current.Store(&applied)
+	for _, handler := range w.handlers {
+		handler(&applied)
+	}
+	return changed, nil
+}
+
+// Run reloads on SIGHUP and, when there is a config file, whenever its
+// modification time or size changes, checked every interval. It returns
+// when ctx is done.
+func (w *Watcher) Run(ctx context.Context, interval time.Duration) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	last := w.stat()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hangup:
+			last = w.stat()
+		case <-ticker.C:
+			info := w.stat()
+			if info == last {
+				continue
+			}
+			last = info
+		}
+
+		changed, err := w.Reload()
+		switch {
+		case err != nil:
+			slog.Error("Configuration reload rejected", "error", err)
+		case len(changed) > 0:
+			slog.Info("Configuration reloaded", "keys", changed)
+		}
+	}
+}
+
+// fileState identifies a version of the config file
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// stat returns the state of the config file, or the zero state without one
+func (w *Watcher) stat() fileState {
+	if w.options.File == "" {
+		return fileState{}
+	}
+	info, err := os.Stat(w.options.File)
+	if err != nil {
+		return fileState{}
+	}
+	return fileState{modTime: info.ModTime(), size: info.Size()}
+}
+
+// leavesByKey indexes the settable values of cfg by key
+func leavesByKey(cfg *Config) map[string]reflect.Value {
+	leaves := map[string]reflect.Value{}
+	for _, l := range leavesOf(reflect.ValueOf(cfg).Elem(), "") {
+		leaves[l.key] = l.value
+	}
+	return leaves
+}
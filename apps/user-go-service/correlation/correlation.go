@@ -0,0 +1,88 @@
+package correlation
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	gologger "github.com/mateusmacedo/scouts/libs/logger-go"
+)
+
+// HeaderCorrelationID is the correlation header shared with notifier-express
+// and bff-nest
+const HeaderCorrelationID = "X-Correlation-ID"
+
+// Config defines the config for the correlation middleware
+type Config struct {
+	// Skipper defines a function to skip middleware
+	Skipper middleware.Skipper
+	// Header carries the correlation ID. Defaults to HeaderCorrelationID.
+	Header string
+}
+
+// Middleware accepts the caller's correlation ID, or generates one when it is
+// missing or invalid, stores it in the request context and echoes it on the
+// response like notifier-express does.
+func Middleware(config Config) echo.MiddlewareFunc {
+	if config.Skipper == nil {
+		config.Skipper = middleware.DefaultSkipper
+	}
+	if config.Header == "" {
+		config.Header = HeaderCorrelationID
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) {
+				return next(c)
+			}
+
+			req := c.Request()
+			correlationID := gologger.EnsureCorrelationID(req.Header.Get(config.Header))
+			req.Header.Set(config.Header, correlationID)
+			c.Response().Header().Set(config.Header, correlationID)
+			c.SetRequest(req.WithContext(WithID(req.Context(), correlationID)))
+
+			return next(c)
+		}
+	}
+}
+
+// WithID returns a context carrying the correlation ID
+func WithID(ctx context.Context, correlationID string) context.Context {
+	return gologger.WithCorrelationID(ctx, correlationID)
+}
+
+// FromContext returns the correlation ID stored in ctx, if any
+func FromContext(ctx context.Context) string {
+	return gologger.CorrelationIDFromContext(ctx)
+}
+
+// Transport is an http.RoundTripper that forwards the correlation ID of the
+// request context to the callee
+type Transport struct {
+	base   http.RoundTripper
+	header string
+}
+
+// NewTransport wraps base, defaulting to http.DefaultTransport
+func NewTransport(base http.RoundTripper) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &Transport{base: base, header: HeaderCorrelationID}
+}
+
+// RoundTrip sets the correlation header unless the caller already did
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	correlationID := FromContext(req.Context())
+	if correlationID == "" || req.Header.Get(t.header) != "" {
+		return t.base.RoundTrip(req)
+	}
+
+	// RoundTrippers must not modify the caller's request
+	req = req.Clone(req.Context())
+	req.Header.Set(t.header, correlationID)
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,98 @@
+package correlation
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware(t *testing.T) {
+	e := echo.New()
+	e.HideBanner = true
+	e.Use(Middleware(Config{
+		Skipper: func(c echo.Context) bool { return c.Path() == "/health" },
+	}))
+	e.GET("/id", func(c echo.Context) error {
+		return c.String(http.StatusOK, FromContext(c.Request().Context()))
+	})
+	e.GET("/health", func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+
+	t.Run("should accept and echo the caller's ID", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/id", nil)
+		req.Header.Set(HeaderCorrelationID, "bff-123")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, "bff-123", rec.Body.String())
+		assert.Equal(t, "bff-123", rec.Header().Get(HeaderCorrelationID))
+	})
+
+	t.Run("should generate an ID when missing or invalid", func(t *testing.T) {
+		for _, incoming := range []string{"", "<script>", strings.Repeat(" ", 3)} {
+			req := httptest.NewRequest(http.MethodGet, "/id", nil)
+			req.Header.Set(HeaderCorrelationID, incoming)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			generated := rec.Header().Get(HeaderCorrelationID)
+			assert.Len(t, generated, 36)
+			assert.Equal(t, generated, rec.Body.String())
+		}
+	})
+
+	t.Run("should skip configured routes", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+		assert.Empty(t, rec.Header().Get(HeaderCorrelationID))
+	})
+}
+
+func TestTransport(t *testing.T) {
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, r.Header.Get(HeaderCorrelationID))
+	}))
+	defer server.Close()
+	client := &http.Client{Transport: NewTransport(nil)}
+
+	send := func(ctx context.Context, header string) *http.Request {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		require.NoError(t, err)
+		if header != "" {
+			req.Header.Set(HeaderCorrelationID, header)
+		}
+		res, err := client.Do(req)
+		require.NoError(t, err)
+		res.Body.Close()
+		return req
+	}
+
+	t.Run("should forward the context ID", func(t *testing.T) {
+		received = nil
+		req := send(WithID(context.Background(), "cid-1"), "")
+
+		assert.Equal(t, []string{"cid-1"}, received)
+		assert.Empty(t, req.Header.Get(HeaderCorrelationID), "caller's request must not be modified")
+	})
+
+	t.Run("should keep an explicit header", func(t *testing.T) {
+		received = nil
+		send(WithID(context.Background(), "cid-1"), "explicit")
+
+		assert.Equal(t, []string{"explicit"}, received)
+	})
+
+	t.Run("should send nothing without an ID", func(t *testing.T) {
+		received = nil
+		send(context.Background(), "")
+
+		assert.Equal(t, []string{""}, received)
+	})
+}
@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	gouser "github.com/mateusmacedo/scouts/libs/user-go"
+)
+
+// Supported export formats
+const (
+	ExportFormatNDJSON = "ndjson"
+	ExportFormatCSV    = "csv"
+)
+
+// exportFields lists the exportable fields in their default order
+var exportFields = []string{"id", "name", "email", "phone", "address", "createdAt", "updatedAt"}
+
+// Export handles GET /api/v1/users/export
+func (h *UserHandler) Export(c echo.Context) error {
+	format := c.QueryParam("format")
+	if format == "" {
+		format = ExportFormatNDJSON
+	}
+
+	var contentType string
+	switch format {
+	case ExportFormatNDJSON:
+		contentType = "application/x-ndjson"
+	case ExportFormatCSV:
+		contentType = "text/csv; charset=utf-8"
+	default:
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_format",
+			Message: "Export format must be ndjson or csv",
+		})
+	}
+
+	fields, err := parseExportFields(c.QueryParam("fields"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_fields",
+			Message: err.Error(),
+		})
+	}
+
+	it := h.userService.Iterate(c.Request().Context(), userFilterFromQuery(c))
+	defer it.Close()
+	if err := it.Err(); errors.Is(err, gouser.ErrForbidden) {
+		return forbidden(c, err)
+	}
+
+	filename := fmt.Sprintf("users-%s.%s", gouser.Timestamp(h.userService.Clock()).Format("20060102T150405Z"), format)
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, contentType)
+	res.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+	res.WriteHeader(http.StatusOK)
+
+	rows, err := ExportUsers(flushWriter{res}, it, format, strings.Join(fields, ","))
+	if err != nil {
+		// Headers are already sent, so the client sees a truncated stream
+		slog.WarnContext(c.Request().Context(), "User export aborted", "rows", rows, "error", err)
+	}
+	return nil
+}
+
+// flushWriter pushes every write to the client. ExportUsers buffers rows,
+// so each write is a chunk of several rows.
+type flushWriter struct {
+	w http.ResponseWriter
+}
+
+func (f flushWriter) Write(p []byte) (int, error) {
+	n, err := f.w.Write(p)
+	if err == nil {
+		flush(f.w)
+	}
+	return n, err
+}
+
+// ExportUsers writes the users of it to w as the export endpoint does, with
+// the comma-separated fields selection, and returns the rows written. Rows
+// are buffered, so w receives chunks of several rows.
+func ExportUsers(w io.Writer, it gouser.UserIterator, format, fieldList string) (int, error) {
+	if format != ExportFormatNDJSON && format != ExportFormatCSV {
+		return 0, fmt.Errorf("export format must be %s or %s, got %q", ExportFormatNDJSON, ExportFormatCSV, format)
+	}
+	fields, err := parseExportFields(fieldList)
+	if err != nil {
+		return 0, err
+	}
+
+	var writer *csv.Writer
+	var buf bytes.Buffer
+	rowsOut := bufio.NewWriter(w)
+	if format == ExportFormatCSV {
+		writer = csv.NewWriter(w)
+		if err := writer.Write(fields); err != nil {
+			return 0, err
+		}
+	}
+	record := make([]string, len(fields))
+	rows := 0
+	for it.Next() {
+		user := it.User()
+		if writer != nil {
+			for i, field := range fields {
+				record[i] = exportValue(user, field)
+			}
+			err = writer.Write(record)
+		} else {
+			buf.Reset()
+			writeNDJSONRow(&buf, user, fields)
+			_, err = rowsOut.Write(buf.Bytes())
+		}
+		if err != nil {
+			return rows, err
+		}
+		rows++
+	}
+	if err := it.Err(); err != nil {
+		return rows, err
+	}
+	if writer != nil {
+		writer.Flush()
+		return rows, writer.Error()
+	}
+	return rows, rowsOut.Flush()
+}
+
+// parseExportFields validates the comma-separated field selection
+func parseExportFields(value string) ([]string, error) {
+	if strings.TrimSpace(value) == "" {
+		return exportFields, nil
+	}
+
+	fields := make([]string, 0, len(exportFields))
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		if !contains(exportFields, field) {
+			return nil, fmt.Errorf("unknown field %q, expected any of: %s", field, strings.Join(exportFields, ", "))
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// exportValue returns the string representation of a user field
+func exportValue(user *gouser.User, field string) string {
+	switch field {
+	case "id":
+		return user.ID
+	case "name":
+		return user.Name
+	case "email":
+		return user.Email
+	case "phone":
+		return user.Phone
+	case "address":
+		return user.Address
+	case "createdAt":
+		return user.CreatedAt.Format("2006-01-02T15:04:05Z07:00")
+	case "updatedAt":
+		return user.UpdatedAt.Format("2006-01-02T15:04:05Z07:00")
+	default:
+		return ""
+	}
+}
+
+// writeNDJSONRow writes the selected fields as a JSON object, keeping field order
+func writeNDJSONRow(buf *bytes.Buffer, user *gouser.User, fields []string) {
+	buf.WriteByte('{')
+	for i, field := range fields {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(field)
+		value, _ := json.Marshal(exportValue(user, field))
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteString("}\n")
+}
+
+// flush pushes buffered bytes to the client when the writer supports it
+func flush(w http.ResponseWriter) {
+	_ = http.NewResponseController(w).Flush()
+}
+
+// contains checks if a slice contains a string
+func contains(slice []string, item string) bool {
+	for _, s := range slice {
+		if s == item {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	gouser "github.com/mateusmacedo/scouts/libs/user-go"
+)
+
+// RoleAdmin is the principal role allowed to read and change injected faults
+const RoleAdmin = "admin"
+
+// FaultsResponse represents the fault injection state
+type FaultsResponse struct {
+	Enabled bool               `json:"enabled"`
+	Config  gouser.FaultConfig `json:"config"`
+}
+
+// UpdateFaultsRequest represents the request body for changing the fault
+// injection state. Omitted fields are kept.
+type UpdateFaultsRequest struct {
+	Enabled *bool               `json:"enabled,omitempty"`
+	Config  *gouser.FaultConfig `json:"config,omitempty"`
+}
+
+// FaultsHandler exposes the storage fault injection of non-production
+// deployments to QA
+type FaultsHandler struct {
+	faults *gouser.FaultInjectingRepository
+}
+
+// NewFaultsHandler creates a new faults handler
+func NewFaultsHandler(faults *gouser.FaultInjectingRepository) *FaultsHandler {
+	return &FaultsHandler{faults: faults}
+}
+
+// Get handles GET /api/v1/admin/faults
+func (h *FaultsHandler) Get(c echo.Context) error {
+	if err := authorizeAdmin(c); err != nil {
+		return forbidden(c, err)
+	}
+	return c.JSON(http.StatusOK, h.response())
+}
+
+// Update handles PUT /api/v1/admin/faults
+func (h *FaultsHandler) Update(c echo.Context) error {
+	if err := authorizeAdmin(c); err != nil {
+		return forbidden(c, err)
+	}
+
+	var req UpdateFaultsRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Invalid request body",
+		})
+	}
+
+	if req.Config != nil {
+		if err := h.faults.Configure(*req.Config); err != nil {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "validation_error",
+				Message: err.Error(),
+			})
+		}
+	}
+	if req.Enabled != nil {
+		h.faults.SetEnabled(*req.Enabled)
+	}
+
+	return c.JSON(http.StatusOK, h.response())
+}
+
+func (h *FaultsHandler) response() FaultsResponse {
+	return FaultsResponse{Enabled: h.faults.Enabled(), Config: h.faults.Config()}
+}
+
+// authorizeAdmin requires an authenticated principal with the admin role.
+// Requests without a principal are refused; config validation already
+// rejects faults.admin unless auth.enabled is set.
+func authorizeAdmin(c echo.Context) error {
+	principal, ok := gouser.PrincipalFromContext(c.Request().Context())
+	if !ok {
+		return errors.New("an authenticated admin is required")
+	}
+	if !principal.HasRole(RoleAdmin) {
+		return errors.New("the admin role is required")
+	}
+	return nil
+}
@@ -5,6 +5,9 @@ import (
 	"time"
 
 	"github.com/labstack/echo/v4"
+	gohealth "github.com/mateusmacedo/scouts/libs/health-go"
+	"github.com/mateusmacedo/scouts/libs/health-go/echohealth"
+	gouser "github.com/mateusmacedo/scouts/libs/user-go"
 )
 
 // HealthResponse represents the health check response
@@ -14,88 +17,60 @@ type HealthResponse struct {
 	Version   string    `json:"version"`
 }
 
-// ReadinessResponse represents the readiness check response
-type ReadinessResponse struct {
-	Status    string    `json:"status"`
-	Timestamp time.Time `json:"timestamp"`
-	Checks    []Check   `json:"checks"`
-}
-
-// Check represents a health check
-type Check struct {
-	Name   string `json:"name"`
-	Status string `json:"status"`
-	Error  string `json:"error,omitempty"`
-}
-
 // HealthHandler handles health check endpoints
 type HealthHandler struct {
 	version string
+	checks  *gohealth.Health
+	clock   gouser.Clock
 }
 
-// NewHealthHandler creates a new health handler
+// NewHealthHandler creates a new health handler without dependency checks
 func NewHealthHandler(version string) *HealthHandler {
+	checks := gohealth.New()
+	checks.MarkStarted()
 	return &HealthHandler{
 		version: version,
+		checks:  checks,
+		clock:   gouser.SystemClock,
 	}
 }
 
+// WithChecks sets the checks run by the liveness, readiness and startup probes
+func (h *HealthHandler) WithChecks(checks *gohealth.Health) *HealthHandler {
+	h.checks = checks
+	return h
+}
+
+// WithClock sets the clock of the timestamp reported by Health
+func (h *HealthHandler) WithClock(clock gouser.Clock) *HealthHandler {
+	h.clock = clock
+	return h
+}
+
 // Health handles GET /health
 func (h *HealthHandler) Health(c echo.Context) error {
 	response := HealthResponse{
 		Status:    "ok",
-		Timestamp: time.Now(),
+		Timestamp: gouser.Timestamp(h.clock),
 		Version:   h.version,
 	}
 
 	return c.JSON(http.StatusOK, response)
 }
 
-// Ready handles GET /health/ready
+// Ready handles GET /health/ready. It returns 503 when a critical check fails
+// or shutdown has started.
 func (h *HealthHandler) Ready(c echo.Context) error {
-	checks := []Check{
-		{
-			Name:   "application",
-			Status: "ok",
-		},
-		{
-			Name:   "memory",
-			Status: "ok",
-		},
-	}
-
-	// Check if all checks are ok
-	allOk := true
-	for _, check := range checks {
-		if check.Status != "ok" {
-			allOk = false
-			break
-		}
-	}
-
-	status := "ok"
-	httpStatus := http.StatusOK
-	if !allOk {
-		status = "error"
-		httpStatus = http.StatusServiceUnavailable
-	}
-
-	response := ReadinessResponse{
-		Status:    status,
-		Timestamp: time.Now(),
-		Checks:    checks,
-	}
-
-	return c.JSON(httpStatus, response)
+	return echohealth.Handler(h.checks, gohealth.ProbeReadiness)(c)
 }
 
 // Live handles GET /health/live
 func (h *HealthHandler) Live(c echo.Context) error {
-	response := HealthResponse{
-		Status:    "ok",
-		Timestamp: time.Now(),
-		Version:   h.version,
-	}
+	return echohealth.Handler(h.checks, gohealth.ProbeLiveness)(c)
+}
 
-	return c.JSON(http.StatusOK, response)
+// Startup handles GET /health/startup. It returns 503 until the service has
+// finished initializing.
+func (h *HealthHandler) Startup(c echo.Context) error {
+	return echohealth.Handler(h.checks, gohealth.ProbeStartup)(c)
 }
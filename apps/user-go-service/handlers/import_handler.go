@@ -0,0 +1,294 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	gouser "github.com/mateusmacedo/scouts/libs/user-go"
+)
+
+// DefaultMaxImportSize is the largest upload accepted by the import endpoint
+const DefaultMaxImportSize int64 = 50 << 20
+
+// importJobRetention is how long finished jobs remain available for polling
+const importJobRetention = time.Hour
+
+// ImportJobStatus represents the lifecycle state of an import job
+type ImportJobStatus string
+
+// Import job states
+const (
+	ImportJobPending   ImportJobStatus = "pending"
+	ImportJobRunning   ImportJobStatus = "running"
+	ImportJobCompleted ImportJobStatus = "completed"
+	ImportJobFailed    ImportJobStatus = "failed"
+)
+
+// ImportJobResponse represents the response for import job operations
+type ImportJobResponse struct {
+	ID         string                `json:"id"`
+	Status     ImportJobStatus       `json:"status"`
+	Format     gouser.ImportFormat   `json:"format"`
+	DryRun     bool                  `json:"dryRun"`
+	Progress   gouser.ImportProgress `json:"progress"`
+	Report     *gouser.ImportReport  `json:"report,omitempty"`
+	Error      string                `json:"error,omitempty"`
+	CreatedAt  string                `json:"createdAt"`
+	FinishedAt string                `json:"finishedAt,omitempty"`
+}
+
+// importJob tracks a running or finished import
+type importJob struct {
+	id         string
+	status     ImportJobStatus
+	format     gouser.ImportFormat
+	dryRun     bool
+	progress   gouser.ImportProgress
+	report     *gouser.ImportReport
+	err        string
+	createdAt  time.Time
+	finishedAt time.Time
+	// owner is the principal that submitted the job, nil without authentication
+	owner *gouser.Principal
+}
+
+// ImportHandler handles bulk user import requests
+type ImportHandler struct {
+	importer *gouser.UserImporter
+	clock    gouser.Clock
+	maxSize  int64
+	jobs     map[string]*importJob
+	mutex    sync.RWMutex
+}
+
+// NewImportHandler creates a new import handler. Jobs are timed with the
+// clock of userService.
+func NewImportHandler(userService *gouser.UserService) *ImportHandler {
+	return &ImportHandler{
+		importer: gouser.NewUserImporter(userService),
+		clock:    userService.Clock(),
+		maxSize:  DefaultMaxImportSize,
+		jobs:     make(map[string]*importJob),
+	}
+}
+
+// Create handles POST /api/v1/users/import
+func (h *ImportHandler) Create(c echo.Context) error {
+	format, ok := importFormat(c)
+	if !ok {
+		return c.JSON(http.StatusUnsupportedMediaType, ErrorResponse{
+			Error:   "unsupported_format",
+			Message: "Import format must be csv or ndjson",
+		})
+	}
+
+	dryRun := false
+	if value := c.QueryParam("dryRun"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_request",
+				Message: "dryRun must be a boolean",
+			})
+		}
+		dryRun = parsed
+	}
+
+	// Spool the upload to disk so the job can outlive the request without
+	// holding the whole payload in memory.
+	file, err := os.CreateTemp("", "user-import-*")
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to store import payload",
+		})
+	}
+
+	body := http.MaxBytesReader(c.Response(), c.Request().Body, h.maxSize)
+	if _, err := io.Copy(file, body); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			return c.JSON(http.StatusRequestEntityTooLarge, ErrorResponse{
+				Error:   "payload_too_large",
+				Message: "Import payload exceeds the maximum size",
+			})
+		}
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Failed to read import payload",
+		})
+	}
+
+	owner, _ := gouser.PrincipalFromContext(c.Request().Context())
+	job := &importJob{
+		owner:     owner,
+		id:        newImportJobID(),
+		status:    ImportJobPending,
+		format:    format,
+		dryRun:    dryRun,
+		createdAt: gouser.Timestamp(h.clock),
+	}
+
+	h.mutex.Lock()
+	h.pruneJobs()
+	h.jobs[job.id] = job
+	response := job.response()
+	h.mutex.Unlock()
+
+	go h.run(context.WithoutCancel(c.Request().Context()), job, file)
+
+	c.Response().Header().Set(echo.HeaderLocation, c.Request().URL.Path+"/"+job.id)
+	return c.JSON(http.StatusAccepted, response)
+}
+
+// Get handles GET /api/v1/users/import/:jobId. Jobs of other principals
+// are reported as not found, except to admins, since reports hold the
+// imported rows.
+func (h *ImportHandler) Get(c echo.Context) error {
+	principal, _ := gouser.PrincipalFromContext(c.Request().Context())
+
+	h.mutex.RLock()
+	job, exists := h.jobs[c.Param("jobId")]
+	exists = exists && job.visibleTo(principal)
+	var response ImportJobResponse
+	if exists {
+		response = job.response()
+	}
+	h.mutex.RUnlock()
+
+	if !exists {
+		return c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "import_job_not_found",
+			Message: "Import job not found",
+		})
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// run executes the import and records its outcome on the job
+func (h *ImportHandler) run(ctx context.Context, job *importJob, file *os.File) {
+	defer func() {
+		file.Close()
+		os.Remove(file.Name())
+	}()
+
+	h.mutex.Lock()
+	job.status = ImportJobRunning
+	h.mutex.Unlock()
+
+	var report *gouser.ImportReport
+	_, err := file.Seek(0, io.SeekStart)
+	if err == nil {
+		report, err = h.importer.Import(ctx, file, gouser.ImportOptions{
+			Format: job.format,
+			DryRun: job.dryRun,
+			OnProgress: func(progress gouser.ImportProgress) {
+				h.mutex.Lock()
+				job.progress = progress
+				h.mutex.Unlock()
+			},
+		})
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	job.finishedAt = gouser.Timestamp(h.clock)
+	job.report = report
+	if report != nil {
+		job.progress = report.ImportProgress
+	}
+	if err != nil {
+		job.status = ImportJobFailed
+		job.err = err.Error()
+		slog.ErrorContext(ctx, "Import job failed", "jobId", job.id, "error", err)
+		return
+	}
+	job.status = ImportJobCompleted
+}
+
+// pruneJobs drops finished jobs past their retention. Callers must hold the lock.
+func (h *ImportHandler) pruneJobs() {
+	cutoff := h.clock.Now().Add(-importJobRetention)
+	for id, job := range h.jobs {
+		if !job.finishedAt.IsZero() && job.finishedAt.Before(cutoff) {
+			delete(h.jobs, id)
+		}
+	}
+}
+
+// visibleTo reports whether principal submitted the job or is an admin
+func (j *importJob) visibleTo(principal *gouser.Principal) bool {
+	if principal.HasRole(RoleAdmin) {
+		return true
+	}
+	if j.owner == nil || principal == nil {
+		return j.owner == nil && principal == nil
+	}
+	return j.owner.Type == principal.Type && j.owner.ID == principal.ID
+}
+
+// response builds the API representation of the job. Callers must hold the lock.
+func (j *importJob) response() ImportJobResponse {
+	response := ImportJobResponse{
+		ID:        j.id,
+		Status:    j.status,
+		Format:    j.format,
+		DryRun:    j.dryRun,
+		Progress:  j.progress,
+		Report:    j.report,
+		Error:     j.err,
+		CreatedAt: j.createdAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+	if !j.finishedAt.IsZero() {
+		response.FinishedAt = j.finishedAt.Format("2006-01-02T15:04:05Z07:00")
+	}
+	return response
+}
+
+// importFormat resolves the import format from the query string or Content-Type
+func importFormat(c echo.Context) (gouser.ImportFormat, bool) {
+	switch gouser.ImportFormat(c.QueryParam("format")) {
+	case gouser.ImportFormatCSV:
+		return gouser.ImportFormatCSV, true
+	case gouser.ImportFormatNDJSON:
+		return gouser.ImportFormatNDJSON, true
+	case "":
+	default:
+		return "", false
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(c.Request().Header.Get(echo.HeaderContentType))
+	switch mediaType {
+	case "text/csv":
+		return gouser.ImportFormatCSV, true
+	case "application/x-ndjson", "application/ndjson", "application/jsonl":
+		return gouser.ImportFormatNDJSON, true
+	default:
+		return "", false
+	}
+}
+
+// newImportJobID generates a random job identifier
+func newImportJobID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(b)
+}
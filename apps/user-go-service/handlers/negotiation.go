@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mateusmacedo/scouts/apps/user-go-service/codec"
+)
+
+// Context keys of the codecs chosen by Negotiate
+const (
+	responseCodecKey = "handlers.responseCodec"
+	requestCodecKey  = "handlers.requestCodec"
+)
+
+// Negotiate picks the response codec from Accept and the request codec
+// from Content-Type, answering 406 or 415 when the registry has none.
+// Handlers then bind and render through the chosen codecs, falling back to
+// JSON on routes without the middleware.
+func Negotiate(codecs *codec.Registry) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			c.Response().Header().Add(echo.HeaderVary, echo.HeaderAccept)
+
+			response, ok := codecs.Negotiate(req.Header.Get(echo.HeaderAccept))
+			if !ok {
+				return c.JSON(http.StatusNotAcceptable, ErrorResponse{
+					Error:   "not_acceptable",
+					Message: "Response can be encoded as " + strings.Join(codecs.MediaTypes(), ", "),
+				})
+			}
+			c.Set(responseCodecKey, response)
+
+			if hasBody(req) {
+				request, ok := codecs.ForContentType(req.Header.Get(echo.HeaderContentType))
+				if !ok {
+					c.Response().Header().Set(echo.HeaderAccept, strings.Join(codecs.MediaTypes(), ", "))
+					return render(c, http.StatusUnsupportedMediaType, ErrorResponse{
+						Error:   "unsupported_media_type",
+						Message: "Request body must be " + strings.Join(codecs.MediaTypes(), ", "),
+					})
+				}
+				c.Set(requestCodecKey, request)
+			}
+			return next(c)
+		}
+	}
+}
+
+// hasBody reports whether a request carries a body
+func hasBody(req *http.Request) bool {
+	return req.Body != nil && req.Body != http.NoBody && req.ContentLength != 0
+}
+
+// responseCodec returns the codec negotiated for the response
+func responseCodec(c echo.Context) codec.Codec {
+	if response, ok := c.Get(responseCodecKey).(codec.Codec); ok {
+		return response
+	}
+	return codec.JSON
+}
+
+// render writes v with the negotiated codec
+func render(c echo.Context, status int, v interface{}) error {
+	response := responseCodec(c)
+	data, err := response.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.Blob(status, response.MediaTypes()[0], data)
+}
+
+// bind decodes the request body with the negotiated codec
+func bind(c echo.Context, v interface{}) error {
+	request, ok := c.Get(requestCodecKey).(codec.Codec)
+	if !ok {
+		return c.Bind(v)
+	}
+	data, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return err
+	}
+	return request.Unmarshal(data, v)
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"encoding/xml"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// MIMEApplicationProblemJSON is the RFC 7807 problem details media type
+const MIMEApplicationProblemJSON = "application/problem+json"
+
+// ProblemDetails represents an RFC 7807 problem response
+type ProblemDetails struct {
+	XMLName  xml.Name `json:"-" xml:"urn:ietf:rfc:7807 problem"`
+	Type     string   `json:"type" xml:"type"`
+	Title    string   `json:"title" xml:"title"`
+	Status   int      `json:"status" xml:"status"`
+	Detail   string   `json:"detail,omitempty" xml:"detail,omitempty"`
+	Instance string   `json:"instance,omitempty" xml:"instance,omitempty"`
+	// Code mirrors ErrorResponse.Error so clients can switch on one field
+	Code string `json:"code,omitempty" xml:"code,omitempty"`
+}
+
+// Problem writes a problem details response in the negotiated format,
+// application/problem+json by default
+func Problem(c echo.Context, problem ProblemDetails) error {
+	if problem.Type == "" {
+		problem.Type = "about:blank"
+	}
+	if problem.Title == "" {
+		problem.Title = http.StatusText(problem.Status)
+	}
+	if problem.Instance == "" {
+		problem.Instance = c.Request().URL.Path
+	}
+
+	response := responseCodec(c)
+	data, err := response.Marshal(problem)
+	if err != nil {
+		return err
+	}
+	return c.Blob(problem.Status, response.ProblemMediaType(), data)
+}
+
+// forbidden writes the 403 problem returned when a policy denies an operation
+func forbidden(c echo.Context, err error) error {
+	return Problem(c, ProblemDetails{
+		Type:   "https://scouts.dev/problems/forbidden",
+		Title:  "Forbidden",
+		Status: http.StatusForbidden,
+		Detail: err.Error(),
+		Code:   "forbidden",
+	})
+}
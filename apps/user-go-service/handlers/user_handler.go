@@ -1,69 +1,155 @@
 package handlers
 
 import (
+	"encoding/xml"
+	"errors"
 	"net/http"
+	"time"
 
 	"github.com/labstack/echo/v4"
+	"github.com/mateusmacedo/scouts/apps/user-go-service/codec"
 	gouser "github.com/mateusmacedo/scouts/libs/user-go"
 )
 
 // UserHandler handles user-related HTTP requests
 type UserHandler struct {
-	userService *gouser.UserService
+	userService  *gouser.UserService
+	validID      func(id string) bool
+	cacheControl string
+	codecs       *codec.Registry
 }
 
 // NewUserHandler creates a new user handler
 func NewUserHandler(userService *gouser.UserService) *UserHandler {
 	return &UserHandler{
-		userService: userService,
+		userService:  userService,
+		cacheControl: DefaultCacheControl,
+		codecs:       codec.DefaultRegistry(),
 	}
 }
 
+// WithIDValidator rejects :id params that validID does not accept with 400,
+// e.g. gouser.WellFormedID. The ValidID method of the current IDGenerator
+// would also reject users created with earlier strategies.
+func (h *UserHandler) WithIDValidator(validID func(id string) bool) *UserHandler {
+	h.validID = validID
+	return h
+}
+
+// WithCacheControl sets the Cache-Control of user reads; empty omits it
+func (h *UserHandler) WithCacheControl(cacheControl string) *UserHandler {
+	h.cacheControl = cacheControl
+	return h
+}
+
+// WithCodecs sets the body formats the handler negotiates
+func (h *UserHandler) WithCodecs(codecs *codec.Registry) *UserHandler {
+	h.codecs = codecs
+	return h
+}
+
+// Negotiate is the Negotiate middleware over the codecs of the handler
+func (h *UserHandler) Negotiate(next echo.HandlerFunc) echo.HandlerFunc {
+	return Negotiate(h.codecs)(next)
+}
+
+// userID reads the :id param, returning the 400 response body when it is
+// missing or malformed
+func (h *UserHandler) userID(c echo.Context) (string, *ErrorResponse) {
+	id := c.Param("id")
+	if id == "" {
+		return "", &ErrorResponse{
+			Error:   "invalid_request",
+			Message: "User ID is required",
+		}
+	}
+	if h.validID != nil && !h.validID(id) {
+		return "", &ErrorResponse{
+			Error:   "invalid_id",
+			Message: "User ID is malformed",
+		}
+	}
+	return id, nil
+}
+
 // CreateUserRequest represents the request body for creating a user
 type CreateUserRequest struct {
-	Name    string `json:"name" validate:"required,min=2,max=100"`
-	Email   string `json:"email" validate:"required,email"`
-	Phone   string `json:"phone,omitempty" validate:"omitempty,max=20"`
-	Address string `json:"address,omitempty" validate:"omitempty,max=500"`
+	XMLName xml.Name `json:"-" xml:"user"`
+	Name    string   `json:"name" xml:"name" validate:"required,min=2,max=100"`
+	Email   string   `json:"email" xml:"email" validate:"required,email"`
+	Phone   string   `json:"phone,omitempty" xml:"phone,omitempty" validate:"omitempty,max=20"`
+	Address string   `json:"address,omitempty" xml:"address,omitempty" validate:"omitempty,max=500"`
 }
 
 // UpdateUserRequest represents the request body for updating a user
 type UpdateUserRequest struct {
-	Name    *string `json:"name,omitempty" validate:"omitempty,min=2,max=100"`
-	Email   *string `json:"email,omitempty" validate:"omitempty,email"`
-	Phone   *string `json:"phone,omitempty" validate:"omitempty,max=20"`
-	Address *string `json:"address,omitempty" validate:"omitempty,max=500"`
+	XMLName xml.Name `json:"-" xml:"user"`
+	Name    *string  `json:"name,omitempty" xml:"name,omitempty" validate:"omitempty,min=2,max=100"`
+	Email   *string  `json:"email,omitempty" xml:"email,omitempty" validate:"omitempty,email"`
+	Phone   *string  `json:"phone,omitempty" xml:"phone,omitempty" validate:"omitempty,max=20"`
+	Address *string  `json:"address,omitempty" xml:"address,omitempty" validate:"omitempty,max=500"`
 }
 
 // UserResponse represents the response for user operations
 type UserResponse struct {
-	ID        string `json:"id"`
-	Name      string `json:"name"`
-	Email     string `json:"email"`
-	Phone     string `json:"phone,omitempty"`
-	Address   string `json:"address,omitempty"`
-	CreatedAt string `json:"createdAt"`
-	UpdatedAt string `json:"updatedAt"`
+	XMLName   xml.Name `json:"-" xml:"user"`
+	ID        string   `json:"id" xml:"id"`
+	Name      string   `json:"name" xml:"name"`
+	Email     string   `json:"email" xml:"email"`
+	Phone     string   `json:"phone,omitempty" xml:"phone,omitempty"`
+	Address   string   `json:"address,omitempty" xml:"address,omitempty"`
+	CreatedAt string   `json:"createdAt" xml:"createdAt"`
+	UpdatedAt string   `json:"updatedAt" xml:"updatedAt"`
+}
+
+// UserListResponse is a list of users, encoded in XML as a users element
+type UserListResponse []UserResponse
+
+// MarshalXML wraps the users in a users element
+func (l UserListResponse) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start = xml.StartElement{Name: xml.Name{Local: "users"}}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	for _, user := range l {
+		if err := e.Encode(user); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+// UnmarshalXML reads the users of a users element
+func (l *UserListResponse) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var list struct {
+		Users []UserResponse `xml:"user"`
+	}
+	if err := d.DecodeElement(&list, &start); err != nil {
+		return err
+	}
+	*l = list.Users
+	return nil
 }
 
 // ErrorResponse represents an error response
 type ErrorResponse struct {
-	Error   string `json:"error"`
-	Message string `json:"message"`
+	XMLName xml.Name `json:"-" xml:"error"`
+	Error   string   `json:"error" xml:"error"`
+	Message string   `json:"message" xml:"message"`
 }
 
 // Create handles POST /api/v1/users
 func (h *UserHandler) Create(c echo.Context) error {
 	var req CreateUserRequest
-	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, ErrorResponse{
+	if err := bind(c, &req); err != nil {
+		return render(c, http.StatusBadRequest, ErrorResponse{
 			Error:   "invalid_request",
 			Message: "Invalid request body",
 		})
 	}
 
 	if err := c.Validate(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, ErrorResponse{
+		return render(c, http.StatusBadRequest, ErrorResponse{
 			Error:   "validation_error",
 			Message: err.Error(),
 		})
@@ -78,37 +164,40 @@ func (h *UserHandler) Create(c echo.Context) error {
 
 	user, err := h.userService.Create(c.Request().Context(), userData)
 	if err != nil {
+		if errors.Is(err, gouser.ErrForbidden) {
+			return forbidden(c, err)
+		}
 		if err == gouser.ErrUserAlreadyExists {
-			return c.JSON(http.StatusConflict, ErrorResponse{
+			return render(c, http.StatusConflict, ErrorResponse{
 				Error:   "user_already_exists",
 				Message: "User with this email already exists",
 			})
 		}
 		if err == gouser.ErrInvalidEmail {
-			return c.JSON(http.StatusBadRequest, ErrorResponse{
+			return render(c, http.StatusBadRequest, ErrorResponse{
 				Error:   "invalid_email",
 				Message: "Invalid email format",
 			})
 		}
 		if err == gouser.ErrEmptyName {
-			return c.JSON(http.StatusBadRequest, ErrorResponse{
+			return render(c, http.StatusBadRequest, ErrorResponse{
 				Error:   "empty_name",
 				Message: "Name cannot be empty",
 			})
 		}
 		if err == gouser.ErrInvalidPhone {
-			return c.JSON(http.StatusBadRequest, ErrorResponse{
+			return render(c, http.StatusBadRequest, ErrorResponse{
 				Error:   "invalid_phone",
 				Message: "Invalid phone format",
 			})
 		}
 		if err == gouser.ErrEmptyEmail {
-			return c.JSON(http.StatusBadRequest, ErrorResponse{
+			return render(c, http.StatusBadRequest, ErrorResponse{
 				Error:   "empty_email",
 				Message: "Email cannot be empty",
 			})
 		}
-		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+		return render(c, http.StatusInternalServerError, ErrorResponse{
 			Error:   "internal_error",
 			Message: "Failed to create user",
 		})
@@ -124,22 +213,19 @@ func (h *UserHandler) Create(c echo.Context) error {
 		UpdatedAt: user.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
 	}
 
-	return c.JSON(http.StatusCreated, response)
+	return render(c, http.StatusCreated, response)
 }
 
-// GetAll handles GET /api/v1/users
+// GetAll handles GET and HEAD /api/v1/users. Lists carry no Last-Modified:
+// deleting a user changes the list without changing any UpdatedAt.
 func (h *UserHandler) GetAll(c echo.Context) error {
-	users, err := h.userService.FindAll(c.Request().Context())
-	if err != nil {
-		return c.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error:   "internal_error",
-			Message: "Failed to retrieve users",
-		})
-	}
+	it := h.userService.Iterate(c.Request().Context(), userFilterFromQuery(c))
+	defer it.Close()
 
-	responses := make([]UserResponse, len(users))
-	for i, user := range users {
-		responses[i] = UserResponse{
+	responses := make(UserListResponse, 0)
+	for it.Next() {
+		user := it.User()
+		responses = append(responses, UserResponse{
 			ID:        user.ID,
 			Name:      user.Name,
 			Email:     user.Email,
@@ -147,31 +233,48 @@ func (h *UserHandler) GetAll(c echo.Context) error {
 			Address:   user.Address,
 			CreatedAt: user.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
 			UpdatedAt: user.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		})
+	}
+	if err := it.Err(); err != nil {
+		if errors.Is(err, gouser.ErrForbidden) {
+			return forbidden(c, err)
 		}
+		return render(c, http.StatusInternalServerError, ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to retrieve users",
+		})
 	}
 
-	return c.JSON(http.StatusOK, responses)
+	return conditional(c, h.cacheControl, responses, time.Time{})
+}
+
+// userFilterFromQuery builds the listing filter shared by GetAll and Export
+func userFilterFromQuery(c echo.Context) gouser.UserFilter {
+	return gouser.UserFilter{
+		Name:  c.QueryParam("name"),
+		Email: c.QueryParam("email"),
+	}
 }
 
-// GetByID handles GET /api/v1/users/:id
+// GetByID handles GET and HEAD /api/v1/users/:id
 func (h *UserHandler) GetByID(c echo.Context) error {
-	id := c.Param("id")
-	if id == "" {
-		return c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "invalid_request",
-			Message: "User ID is required",
-		})
+	id, invalid := h.userID(c)
+	if invalid != nil {
+		return render(c, http.StatusBadRequest, invalid)
 	}
 
 	user, err := h.userService.FindByID(c.Request().Context(), id)
 	if err != nil {
+		if errors.Is(err, gouser.ErrForbidden) {
+			return forbidden(c, err)
+		}
 		if err == gouser.ErrUserNotFound {
-			return c.JSON(http.StatusNotFound, ErrorResponse{
+			return render(c, http.StatusNotFound, ErrorResponse{
 				Error:   "user_not_found",
 				Message: "User not found",
 			})
 		}
-		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+		return render(c, http.StatusInternalServerError, ErrorResponse{
 			Error:   "internal_error",
 			Message: "Failed to retrieve user",
 		})
@@ -187,29 +290,26 @@ func (h *UserHandler) GetByID(c echo.Context) error {
 		UpdatedAt: user.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
 	}
 
-	return c.JSON(http.StatusOK, response)
+	return conditional(c, h.cacheControl, response, user.UpdatedAt)
 }
 
 // Update handles PUT /api/v1/users/:id
 func (h *UserHandler) Update(c echo.Context) error {
-	id := c.Param("id")
-	if id == "" {
-		return c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "invalid_request",
-			Message: "User ID is required",
-		})
+	id, invalid := h.userID(c)
+	if invalid != nil {
+		return render(c, http.StatusBadRequest, invalid)
 	}
 
 	var req UpdateUserRequest
-	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, ErrorResponse{
+	if err := bind(c, &req); err != nil {
+		return render(c, http.StatusBadRequest, ErrorResponse{
 			Error:   "invalid_request",
 			Message: "Invalid request body",
 		})
 	}
 
 	if err := c.Validate(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, ErrorResponse{
+		return render(c, http.StatusBadRequest, ErrorResponse{
 			Error:   "validation_error",
 			Message: err.Error(),
 		})
@@ -224,43 +324,46 @@ func (h *UserHandler) Update(c echo.Context) error {
 
 	user, err := h.userService.Update(c.Request().Context(), id, updateData)
 	if err != nil {
+		if errors.Is(err, gouser.ErrForbidden) {
+			return forbidden(c, err)
+		}
 		if err == gouser.ErrUserNotFound {
-			return c.JSON(http.StatusNotFound, ErrorResponse{
+			return render(c, http.StatusNotFound, ErrorResponse{
 				Error:   "user_not_found",
 				Message: "User not found",
 			})
 		}
 		if err == gouser.ErrUserAlreadyExists {
-			return c.JSON(http.StatusConflict, ErrorResponse{
+			return render(c, http.StatusConflict, ErrorResponse{
 				Error:   "user_already_exists",
 				Message: "User with this email already exists",
 			})
 		}
 		if err == gouser.ErrInvalidEmail {
-			return c.JSON(http.StatusBadRequest, ErrorResponse{
+			return render(c, http.StatusBadRequest, ErrorResponse{
 				Error:   "invalid_email",
 				Message: "Invalid email format",
 			})
 		}
 		if err == gouser.ErrEmptyName {
-			return c.JSON(http.StatusBadRequest, ErrorResponse{
+			return render(c, http.StatusBadRequest, ErrorResponse{
 				Error:   "empty_name",
 				Message: "Name cannot be empty",
 			})
 		}
 		if err == gouser.ErrInvalidPhone {
-			return c.JSON(http.StatusBadRequest, ErrorResponse{
+			return render(c, http.StatusBadRequest, ErrorResponse{
 				Error:   "invalid_phone",
 				Message: "Invalid phone format",
 			})
 		}
 		if err == gouser.ErrEmptyEmail {
-			return c.JSON(http.StatusBadRequest, ErrorResponse{
+			return render(c, http.StatusBadRequest, ErrorResponse{
 				Error:   "empty_email",
 				Message: "Email cannot be empty",
 			})
 		}
-		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+		return render(c, http.StatusInternalServerError, ErrorResponse{
 			Error:   "internal_error",
 			Message: "Failed to update user",
 		})
@@ -276,28 +379,28 @@ func (h *UserHandler) Update(c echo.Context) error {
 		UpdatedAt: user.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
 	}
 
-	return c.JSON(http.StatusOK, response)
+	return render(c, http.StatusOK, response)
 }
 
 // Delete handles DELETE /api/v1/users/:id
 func (h *UserHandler) Delete(c echo.Context) error {
-	id := c.Param("id")
-	if id == "" {
-		return c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "invalid_request",
-			Message: "User ID is required",
-		})
+	id, invalid := h.userID(c)
+	if invalid != nil {
+		return render(c, http.StatusBadRequest, invalid)
 	}
 
 	err := h.userService.Delete(c.Request().Context(), id)
 	if err != nil {
+		if errors.Is(err, gouser.ErrForbidden) {
+			return forbidden(c, err)
+		}
 		if err == gouser.ErrUserNotFound {
-			return c.JSON(http.StatusNotFound, ErrorResponse{
+			return render(c, http.StatusNotFound, ErrorResponse{
 				Error:   "user_not_found",
 				Message: "User not found",
 			})
 		}
-		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+		return render(c, http.StatusInternalServerError, ErrorResponse{
 			Error:   "internal_error",
 			Message: "Failed to delete user",
 		})
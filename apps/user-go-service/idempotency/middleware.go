@@ -0,0 +1,327 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/mateusmacedo/scouts/apps/user-go-service/handlers"
+	gouser "github.com/mateusmacedo/scouts/libs/user-go"
+)
+
+// HeaderIdempotencyKey is the request header carrying the client key
+const HeaderIdempotencyKey = "Idempotency-Key"
+
+// HeaderIdempotentReplayed marks responses served from the store
+const HeaderIdempotentReplayed = "Idempotent-Replayed"
+
+// maxKeyLength bounds the accepted key size
+const maxKeyLength = 255
+
+// replayedHeaders are the response headers stored alongside the body
+var replayedHeaders = []string{echo.HeaderContentType, echo.HeaderLocation}
+
+// Config defines the config for the idempotency middleware
+type Config struct {
+	// Skipper defines a function to skip middleware. Defaults to skipping
+	// every method other than POST.
+	Skipper middleware.Skipper
+	// Store persists keys and responses. Defaults to a MemoryStore.
+	Store Store
+	// TTL is how long a completed response is replayed. Defaults to 24h.
+	TTL time.Duration
+	// LockTTL bounds how long an in-flight request holds its key, so a key
+	// whose owner died is freed before TTL. It should exceed the request
+	// timeout. Defaults to 1m.
+	LockTTL time.Duration
+	// WaitTimeout is how long a duplicate waits for an in-flight request to
+	// finish before getting 409. Zero rejects duplicates immediately.
+	WaitTimeout time.Duration
+	// PollInterval is how often a waiting duplicate checks the store
+	PollInterval time.Duration
+	// MaxBodySize bounds the bodies read into memory for the fingerprint;
+	// larger keyed requests get 413. Skip streaming uploads instead.
+	MaxBodySize int64
+}
+
+// DefaultConfig is the default idempotency middleware config
+var DefaultConfig = Config{
+	Skipper: func(c echo.Context) bool {
+		return c.Request().Method != http.MethodPost
+	},
+	TTL:          24 * time.Hour,
+	LockTTL:      time.Minute,
+	WaitTimeout:  5 * time.Second,
+	PollInterval: 50 * time.Millisecond,
+	MaxBodySize:  1 << 20,
+}
+
+// Middleware returns an idempotency middleware with the default config
+func Middleware() echo.MiddlewareFunc {
+	return MiddlewareWithConfig(DefaultConfig)
+}
+
+// MiddlewareWithConfig returns an idempotency middleware. Requests carrying an
+// Idempotency-Key have their response stored for TTL and replayed for retries
+// with the same body; reusing a key with a different body yields 422.
+func MiddlewareWithConfig(config Config) echo.MiddlewareFunc {
+	if config.Skipper == nil {
+		config.Skipper = DefaultConfig.Skipper
+	}
+	if config.Store == nil {
+		config.Store = NewMemoryStore()
+	}
+	if config.TTL <= 0 {
+		config.TTL = DefaultConfig.TTL
+	}
+	if config.LockTTL <= 0 {
+		config.LockTTL = DefaultConfig.LockTTL
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = DefaultConfig.PollInterval
+	}
+	if config.MaxBodySize <= 0 {
+		config.MaxBodySize = DefaultConfig.MaxBodySize
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) {
+				return next(c)
+			}
+
+			key := c.Request().Header.Get(HeaderIdempotencyKey)
+			if key == "" {
+				return next(c)
+			}
+			if len(key) > maxKeyLength {
+				return c.JSON(http.StatusBadRequest, handlers.ErrorResponse{
+					Error:   "invalid_idempotency_key",
+					Message: "Idempotency-Key must be at most 255 characters",
+				})
+			}
+
+			fingerprint, err := fingerprintRequest(c, config.MaxBodySize)
+			if errors.Is(err, errBodyTooLarge) {
+				return c.JSON(http.StatusRequestEntityTooLarge, handlers.ErrorResponse{
+					Error:   "request_too_large",
+					Message: fmt.Sprintf("Requests with an Idempotency-Key must be at most %d bytes", config.MaxBodySize),
+				})
+			}
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, handlers.ErrorResponse{
+					Error:   "invalid_request",
+					Message: "Invalid request body",
+				})
+			}
+
+			// Keys are scoped to the authenticated caller so clients cannot
+			// collide with or replay each other's responses
+			ctx := c.Request().Context()
+			if principal, ok := gouser.PrincipalFromContext(ctx); ok {
+				key = scopedKey(principal, key)
+			}
+
+			existing, acquired, err := config.Store.Begin(ctx, key, fingerprint, config.LockTTL)
+			if err != nil {
+				return storeUnavailable(c, err)
+			}
+			if !acquired {
+				return handleExisting(c, next, config, key, fingerprint, existing)
+			}
+
+			return execute(c, next, config, key, fingerprint)
+		}
+	}
+}
+
+// execute runs the handler while capturing its response for later replays
+func execute(c echo.Context, next echo.HandlerFunc, config Config, key, fingerprint string) error {
+	res := c.Response()
+	recorder := &responseRecorder{ResponseWriter: res.Writer}
+	res.Writer = recorder
+
+	// A panicking handler must not hold the key until LockTTL; the panic
+	// goes on to the Recover middleware
+	defer func() {
+		if p := recover(); p != nil {
+			res.Writer = recorder.ResponseWriter
+			release(context.WithoutCancel(c.Request().Context()), config, key)
+			panic(p)
+		}
+	}()
+
+	err := next(c)
+	res.Writer = recorder.ResponseWriter
+	if err != nil {
+		// Let Echo's error handler respond; the key can be retried
+		c.Error(err)
+	}
+
+	// Server errors are not stored so clients can retry with the same key
+	ctx := context.WithoutCancel(c.Request().Context())
+	if res.Status >= http.StatusInternalServerError || !res.Committed {
+		release(ctx, config, key)
+		return nil
+	}
+
+	header := make(http.Header)
+	for _, name := range replayedHeaders {
+		if value := res.Header().Get(name); value != "" {
+			header.Set(name, value)
+		}
+	}
+	record := &Record{
+		Key:         key,
+		Fingerprint: fingerprint,
+		StatusCode:  res.Status,
+		Header:      header,
+		Body:        recorder.body.Bytes(),
+	}
+	if storeErr := config.Store.Complete(ctx, record, config.TTL); storeErr != nil {
+		slog.ErrorContext(ctx, "Failed to store idempotent response", "key", key, "error", storeErr)
+	}
+	return nil
+}
+
+// release drops the in-flight reservation of key so it can be retried
+func release(ctx context.Context, config Config, key string) {
+	if err := config.Store.Release(ctx, key); err != nil {
+		slog.ErrorContext(ctx, "Failed to release idempotency key", "key", key, "error", err)
+	}
+}
+
+// handleExisting replays, waits for or rejects a request whose key is known
+func handleExisting(c echo.Context, next echo.HandlerFunc, config Config, key, fingerprint string, record *Record) error {
+	ctx := c.Request().Context()
+	deadline := time.Now().Add(config.WaitTimeout)
+	for !record.Completed {
+		if record.Fingerprint != fingerprint {
+			return keyReused(c)
+		}
+		if !time.Now().Before(deadline) {
+			return c.JSON(http.StatusConflict, handlers.ErrorResponse{
+				Error:   "idempotency_key_in_use",
+				Message: "A request with this Idempotency-Key is still being processed",
+			})
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(config.PollInterval):
+		}
+
+		current, err := config.Store.Get(ctx, key)
+		if err != nil {
+			return storeUnavailable(c, err)
+		}
+		if current == nil {
+			// The original request failed and released the key, so this
+			// duplicate becomes the new owner if nobody else took it.
+			existing, acquired, err := config.Store.Begin(ctx, key, fingerprint, config.LockTTL)
+			if err != nil {
+				return storeUnavailable(c, err)
+			}
+			if acquired {
+				return execute(c, next, config, key, fingerprint)
+			}
+			current = existing
+		}
+		record = current
+	}
+
+	if record.Fingerprint != fingerprint {
+		return keyReused(c)
+	}
+
+	res := c.Response()
+	for name, values := range record.Header {
+		for _, value := range values {
+			res.Header().Add(name, value)
+		}
+	}
+	res.Header().Set(HeaderIdempotentReplayed, "true")
+	res.WriteHeader(record.StatusCode)
+	_, err := res.Write(record.Body)
+	return err
+}
+
+func keyReused(c echo.Context) error {
+	return c.JSON(http.StatusUnprocessableEntity, handlers.ErrorResponse{
+		Error:   "idempotency_key_reused",
+		Message: "Idempotency-Key was already used with a different request",
+	})
+}
+
+func storeUnavailable(c echo.Context, err error) error {
+	slog.ErrorContext(c.Request().Context(), "Idempotency store error", "error", err)
+	return c.JSON(http.StatusServiceUnavailable, handlers.ErrorResponse{
+		Error:   "idempotency_unavailable",
+		Message: "Idempotency store is unavailable",
+	})
+}
+
+// errBodyTooLarge is returned by fingerprintRequest beyond the body limit
+var errBodyTooLarge = errors.New("request body too large")
+
+// fingerprintRequest hashes method, path, query and body, restoring the
+// body afterwards. Bodies over maxBodySize are not read into memory.
+func fingerprintRequest(c echo.Context, maxBodySize int64) (string, error) {
+	req := c.Request()
+	if req.ContentLength > maxBodySize {
+		return "", errBodyTooLarge
+	}
+
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(io.LimitReader(req.Body, maxBodySize+1))
+		if err != nil {
+			return "", err
+		}
+		if int64(len(body)) > maxBodySize {
+			return "", errBodyTooLarge
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	hash := sha256.New()
+	for _, part := range []string{req.Method, req.URL.Path, req.URL.RawQuery} {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// scopedKey prefixes key with the principal, length-prefixing each part so
+// different principals and keys cannot encode to the same string
+func scopedKey(principal *gouser.Principal, key string) string {
+	return fmt.Sprintf("%d:%s%d:%s%s", len(principal.Type), principal.Type, len(principal.ID), principal.ID, key)
+}
+
+// responseRecorder tees the response body into a buffer
+type responseRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,240 @@
+package idempotency
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	gouser "github.com/mateusmacedo/scouts/libs/user-go"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestServer registers a counting POST handler behind the middleware
+func newTestServer(config Config, handler echo.HandlerFunc) *echo.Echo {
+	e := echo.New()
+	e.HideBanner = true
+	e.Use(MiddlewareWithConfig(config))
+	e.POST("/users", handler)
+	return e
+}
+
+func doPost(e *echo.Echo, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	if key != "" {
+		req.Header.Set(HeaderIdempotencyKey, key)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestMiddleware(t *testing.T) {
+	t.Run("should replay the stored response for identical retries", func(t *testing.T) {
+		var calls int32
+		e := newTestServer(Config{}, func(c echo.Context) error {
+			n := atomic.AddInt32(&calls, 1)
+			c.Response().Header().Set(echo.HeaderLocation, "/users/1")
+			return c.JSON(http.StatusCreated, map[string]int32{"call": n})
+		})
+
+		first := doPost(e, "key-1", `{"name":"John"}`)
+		second := doPost(e, "key-1", `{"name":"John"}`)
+
+		assert.Equal(t, http.StatusCreated, first.Code)
+		assert.Equal(t, http.StatusCreated, second.Code)
+		assert.Equal(t, first.Body.String(), second.Body.String())
+		assert.Equal(t, "/users/1", second.Header().Get(echo.HeaderLocation))
+		assert.Equal(t, "true", second.Header().Get(HeaderIdempotentReplayed))
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("should reject key reuse with a different body", func(t *testing.T) {
+		e := newTestServer(Config{}, func(c echo.Context) error {
+			return c.NoContent(http.StatusCreated)
+		})
+
+		doPost(e, "key-1", `{"name":"John"}`)
+		rec := doPost(e, "key-1", `{"name":"Jane"}`)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		assert.Contains(t, rec.Body.String(), "idempotency_key_reused")
+	})
+
+	t.Run("should pass through requests without key", func(t *testing.T) {
+		var calls int32
+		e := newTestServer(Config{}, func(c echo.Context) error {
+			atomic.AddInt32(&calls, 1)
+			return c.NoContent(http.StatusCreated)
+		})
+
+		doPost(e, "", `{}`)
+		doPost(e, "", `{}`)
+
+		assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("should not store server errors", func(t *testing.T) {
+		var calls int32
+		e := newTestServer(Config{}, func(c echo.Context) error {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				return echo.NewHTTPError(http.StatusInternalServerError)
+			}
+			return c.NoContent(http.StatusCreated)
+		})
+
+		first := doPost(e, "key-1", `{}`)
+		second := doPost(e, "key-1", `{}`)
+
+		assert.Equal(t, http.StatusInternalServerError, first.Code)
+		assert.Equal(t, http.StatusCreated, second.Code)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("should release the key when the handler panics", func(t *testing.T) {
+		var calls int32
+		e := echo.New()
+		e.Use(middleware.Recover())
+		e.Use(MiddlewareWithConfig(Config{}))
+		e.POST("/users", func(c echo.Context) error {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				panic("boom")
+			}
+			return c.NoContent(http.StatusCreated)
+		})
+
+		first := doPost(e, "key-1", `{}`)
+		second := doPost(e, "key-1", `{}`)
+
+		assert.Equal(t, http.StatusInternalServerError, first.Code)
+		assert.Equal(t, http.StatusCreated, second.Code)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("should free abandoned reservations after LockTTL", func(t *testing.T) {
+		store := NewMemoryStore()
+		e := newTestServer(Config{Store: store, LockTTL: time.Millisecond}, func(c echo.Context) error {
+			return c.NoContent(http.StatusCreated)
+		})
+		// A reservation left behind by a replica that died mid-request
+		store.Begin(context.Background(), "key-1", "other", time.Millisecond)
+		time.Sleep(5 * time.Millisecond)
+
+		assert.Equal(t, http.StatusCreated, doPost(e, "key-1", `{}`).Code)
+		record, _ := store.Get(context.Background(), "key-1")
+		if assert.NotNil(t, record) {
+			assert.True(t, record.Completed)
+			assert.True(t, time.Until(record.ExpiresAt) > time.Hour, "completed responses are kept for TTL")
+		}
+	})
+
+	t.Run("should make concurrent duplicates wait for the in-flight request", func(t *testing.T) {
+		var calls int32
+		release := make(chan struct{})
+		e := newTestServer(Config{WaitTimeout: time.Second, PollInterval: 5 * time.Millisecond}, func(c echo.Context) error {
+			atomic.AddInt32(&calls, 1)
+			<-release
+			return c.JSON(http.StatusCreated, map[string]string{"id": "1"})
+		})
+
+		var wg sync.WaitGroup
+		results := make([]*httptest.ResponseRecorder, 2)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[0] = doPost(e, "key-1", `{}`)
+		}()
+
+		// Let the first request acquire the key before the duplicate arrives
+		assert.Eventually(t, func() bool { return atomic.LoadInt32(&calls) == 1 }, time.Second, time.Millisecond)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[1] = doPost(e, "key-1", `{}`)
+		}()
+		time.Sleep(20 * time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		assert.Equal(t, http.StatusCreated, results[0].Code)
+		assert.Equal(t, http.StatusCreated, results[1].Code)
+		assert.Equal(t, "true", results[1].Header().Get(HeaderIdempotentReplayed))
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("should conflict when the in-flight request does not finish in time", func(t *testing.T) {
+		release := make(chan struct{})
+		defer close(release)
+		started := make(chan struct{})
+		e := newTestServer(Config{PollInterval: time.Millisecond}, func(c echo.Context) error {
+			close(started)
+			<-release
+			return c.NoContent(http.StatusCreated)
+		})
+
+		go doPost(e, "key-1", `{}`)
+		<-started
+
+		rec := doPost(e, "key-1", `{}`)
+		assert.Equal(t, http.StatusConflict, rec.Code)
+	})
+}
+
+func TestFingerprint(t *testing.T) {
+	e := newTestServer(Config{MaxBodySize: 16}, func(c echo.Context) error {
+		return c.NoContent(http.StatusCreated)
+	})
+	post := func(target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+		req.Header.Set(HeaderIdempotencyKey, "key-1")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("should tell requests apart by query", func(t *testing.T) {
+		assert.Equal(t, http.StatusCreated, post("/users?dryRun=true", "{}").Code)
+		assert.Equal(t, http.StatusUnprocessableEntity, post("/users", "{}").Code)
+	})
+
+	t.Run("should reject bodies over the limit", func(t *testing.T) {
+		rec := post("/users", strings.Repeat("x", 17))
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+		assert.Contains(t, rec.Body.String(), "request_too_large")
+	})
+
+	t.Run("should scope keys without collisions", func(t *testing.T) {
+		a := scopedKey(&gouser.Principal{Type: "user", ID: "1:x"}, "k")
+		b := scopedKey(&gouser.Principal{Type: "user", ID: "1"}, "x:k")
+		assert.NotEqual(t, a, b)
+	})
+}
+
+func TestMemoryStore(t *testing.T) {
+	t.Run("should expire records after TTL", func(t *testing.T) {
+		store := NewMemoryStore()
+		ctx := context.Background()
+
+		_, acquired, err := store.Begin(ctx, "key", "fp", time.Millisecond)
+		assert.NoError(t, err)
+		assert.True(t, acquired)
+
+		time.Sleep(5 * time.Millisecond)
+
+		record, err := store.Get(ctx, "key")
+		assert.NoError(t, err)
+		assert.Nil(t, record)
+
+		_, acquired, err = store.Begin(ctx, "key", "fp", time.Minute)
+		assert.NoError(t, err)
+		assert.True(t, acquired)
+	})
+}
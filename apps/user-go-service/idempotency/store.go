@@ -0,0 +1,136 @@
+package idempotency
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Record is the stored state of an idempotency key
+type Record struct {
+	Key         string
+	Fingerprint string
+	// Completed is false while the original request is still in flight
+	Completed  bool
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	ExpiresAt  time.Time
+}
+
+// Store persists idempotency records. Implementations must make Begin atomic
+// so that only one request can own a key at a time.
+type Store interface {
+	// Begin reserves key for an in-flight request. When the key is already
+	// known it returns the existing record and acquired=false.
+	Begin(ctx context.Context, key, fingerprint string, ttl time.Duration) (existing *Record, acquired bool, err error)
+	// Get returns the record for key or nil when it does not exist
+	Get(ctx context.Context, key string) (*Record, error)
+	// Complete stores the final response of the request owning key
+	Complete(ctx context.Context, record *Record, ttl time.Duration) error
+	// Release drops an in-flight reservation so the key can be retried
+	Release(ctx context.Context, key string) error
+}
+
+// sweepInterval bounds how often the memory store scans for expired records
+const sweepInterval = time.Minute
+
+// MemoryStore is a thread-safe in-memory implementation of Store
+type MemoryStore struct {
+	records   map[string]*Record
+	lastSweep time.Time
+	mutex     sync.Mutex
+}
+
+// NewMemoryStore creates a new in-memory idempotency store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		records: make(map[string]*Record),
+	}
+}
+
+// Begin reserves key for an in-flight request
+func (s *MemoryStore) Begin(ctx context.Context, key, fingerprint string, ttl time.Duration) (*Record, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, false, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+
+	if record, exists := s.records[key]; exists && now.Before(record.ExpiresAt) {
+		return copyRecord(record), false, nil
+	}
+
+	s.records[key] = &Record{
+		Key:         key,
+		Fingerprint: fingerprint,
+		ExpiresAt:   now.Add(ttl),
+	}
+	return nil, true, nil
+}
+
+// Get returns the record for key or nil when it does not exist
+func (s *MemoryStore) Get(ctx context.Context, key string) (*Record, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	record, exists := s.records[key]
+	if !exists || !time.Now().Before(record.ExpiresAt) {
+		return nil, nil
+	}
+	return copyRecord(record), nil
+}
+
+// Complete stores the final response of the request owning key
+func (s *MemoryStore) Complete(ctx context.Context, record *Record, ttl time.Duration) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	stored := copyRecord(record)
+	stored.Completed = true
+	stored.ExpiresAt = time.Now().Add(ttl)
+	s.records[record.Key] = stored
+	return nil
+}
+
+// Release drops an in-flight reservation so the key can be retried
+func (s *MemoryStore) Release(ctx context.Context, key string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if record, exists := s.records[key]; exists && !record.Completed {
+		delete(s.records, key)
+	}
+	return nil
+}
+
+// sweep removes expired records. Callers must hold the lock.
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for key, record := range s.records {
+		if !now.Before(record.ExpiresAt) {
+			delete(s.records, key)
+		}
+	}
+}
+
+// copyRecord returns a deep copy so callers cannot mutate stored state
+func copyRecord(record *Record) *Record {
+	recordCopy := *record
+	recordCopy.Header = record.Header.Clone()
+	recordCopy.Body = append([]byte(nil), record.Body...)
+	return &recordCopy
+}
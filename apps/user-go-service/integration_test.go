@@ -2,16 +2,28 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/labstack/echo/v4"
+	"github.com/mateusmacedo/scouts/apps/user-go-service/auth"
+	"github.com/mateusmacedo/scouts/apps/user-go-service/codec"
+	"github.com/mateusmacedo/scouts/apps/user-go-service/correlation"
 	"github.com/mateusmacedo/scouts/apps/user-go-service/handlers"
+	gohealth "github.com/mateusmacedo/scouts/libs/health-go"
 	gouser "github.com/mateusmacedo/scouts/libs/user-go"
+	"github.com/mateusmacedo/scouts/libs/user-go/gousertest"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestUserGoServiceIntegration(t *testing.T) {
@@ -20,15 +32,16 @@ func TestUserGoServiceIntegration(t *testing.T) {
 
 	// Initialize services
 	userRepository := gouser.NewInMemoryUserRepository()
-	userEvents := &TestUserEventsLogger{}
+	userEvents := gousertest.NewRecordingEvents()
 	userService := gouser.NewUserService(userRepository, userEvents)
 
 	// Initialize handlers
 	healthHandler := handlers.NewHealthHandler("1.0.0")
 	userHandler := handlers.NewUserHandler(userService)
+	importHandler := handlers.NewImportHandler(userService)
 
 	// Setup routes
-	setupRoutes(e, healthHandler, userHandler)
+	setupRoutes(e, healthHandler, userHandler, importHandler)
 
 	t.Run("Health Check", func(t *testing.T) {
 		// This test just verifies the setup works
@@ -56,24 +69,20 @@ func TestUserCRUDFlow(t *testing.T) {
 
 	// Initialize services
 	userRepository := gouser.NewInMemoryUserRepository()
-	userEvents := &TestUserEventsLogger{}
+	userEvents := gousertest.NewRecordingEvents()
 	userService := gouser.NewUserService(userRepository, userEvents)
 
 	// Initialize handlers
 	healthHandler := handlers.NewHealthHandler("1.0.0")
 	userHandler := handlers.NewUserHandler(userService)
+	importHandler := handlers.NewImportHandler(userService)
 
 	// Setup routes
-	setupRoutes(e, healthHandler, userHandler)
+	setupRoutes(e, healthHandler, userHandler, importHandler)
 
 	// Test: Create user
 	t.Run("Create User", func(t *testing.T) {
-		userData := gouser.CreateUserData{
-			Name:    "John Doe",
-			Email:   "john@example.com",
-			Phone:   "123456789",
-			Address: "123 Main St",
-		}
+		userData := gousertest.NewUser().WithPhone("123456789").WithAddress("123 Main St").Data()
 
 		jsonData, _ := json.Marshal(userData)
 		req := httptest.NewRequest(http.MethodPost, "/api/v1/users", bytes.NewReader(jsonData))
@@ -93,6 +102,7 @@ func TestUserCRUDFlow(t *testing.T) {
 		assert.Equal(t, "John Doe", response["name"])
 		assert.Equal(t, "john@example.com", response["email"])
 		assert.NotEmpty(t, response["id"])
+		userEvents.AssertCreated(t, "john@example.com")
 	})
 
 	// Test: Get all users
@@ -217,6 +227,7 @@ func TestUserCRUDFlow(t *testing.T) {
 		e.ServeHTTP(rec, req)
 
 		assert.Equal(t, http.StatusNoContent, rec.Code)
+		userEvents.AssertDeleted(t, userID)
 
 		// Verify user is deleted
 		req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/users/%s", userID), nil)
@@ -261,15 +272,16 @@ func TestHealthChecks(t *testing.T) {
 
 	// Initialize services
 	userRepository := gouser.NewInMemoryUserRepository()
-	userEvents := &TestUserEventsLogger{}
+	userEvents := gousertest.NewRecordingEvents()
 	userService := gouser.NewUserService(userRepository, userEvents)
 
 	// Initialize handlers
 	healthHandler := handlers.NewHealthHandler("1.0.0")
 	userHandler := handlers.NewUserHandler(userService)
+	importHandler := handlers.NewImportHandler(userService)
 
 	// Setup routes
-	setupRoutes(e, healthHandler, userHandler)
+	setupRoutes(e, healthHandler, userHandler, importHandler)
 
 	// Test: Health endpoint
 	t.Run("Health Endpoint", func(t *testing.T) {
@@ -318,17 +330,847 @@ func TestHealthChecks(t *testing.T) {
 	})
 }
 
-// TestUserEventsLogger is a test implementation of UserEvents
-type TestUserEventsLogger struct{}
+func TestUserImportFlow(t *testing.T) {
+	e := echo.New()
+	e.HideBanner = true
 
-func (l *TestUserEventsLogger) OnUserCreated(user *gouser.User) {
-	// Test implementation - do nothing
+	// Configure validator
+	e.Validator = &CustomValidator{}
+
+	// Initialize services
+	userRepository := gouser.NewInMemoryUserRepository()
+	userEvents := gousertest.NewRecordingEvents()
+	userService := gouser.NewUserService(userRepository, userEvents)
+
+	// Initialize handlers
+	healthHandler := handlers.NewHealthHandler("1.0.0")
+	userHandler := handlers.NewUserHandler(userService)
+	importHandler := handlers.NewImportHandler(userService)
+
+	// Setup routes
+	setupRoutes(e, healthHandler, userHandler, importHandler)
+
+	// waitForJob polls the job until it leaves the pending/running states
+	waitForJob := func(t *testing.T, location string) handlers.ImportJobResponse {
+		var job handlers.ImportJobResponse
+		assert.Eventually(t, func() bool {
+			req := httptest.NewRequest(http.MethodGet, location, nil)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+			if rec.Code != http.StatusOK {
+				return false
+			}
+			json.Unmarshal(rec.Body.Bytes(), &job)
+			return job.Status == handlers.ImportJobCompleted || job.Status == handlers.ImportJobFailed
+		}, 2*time.Second, 10*time.Millisecond)
+		return job
+	}
+
+	// Test: Dry-run CSV import
+	t.Run("Dry Run CSV Import", func(t *testing.T) {
+		body := "name,email\nJohn Doe,john@example.com\nInvalid,not-an-email\n"
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/users/import?dryRun=true", bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "text/csv")
+		rec := httptest.NewRecorder()
+
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusAccepted, rec.Code)
+		location := rec.Header().Get("Location")
+		assert.NotEmpty(t, location)
+
+		job := waitForJob(t, location)
+		assert.Equal(t, handlers.ImportJobCompleted, job.Status)
+		assert.True(t, job.DryRun)
+		assert.Equal(t, 1, job.Progress.Created)
+		assert.Equal(t, 1, job.Progress.Invalid)
+		if assert.NotNil(t, job.Report) {
+			assert.Len(t, job.Report.Errors, 1)
+		}
+
+		users, _ := userRepository.FindAll(context.Background())
+		assert.Len(t, users, 0)
+	})
+
+	// Test: NDJSON import
+	t.Run("NDJSON Import", func(t *testing.T) {
+		body := `{"name":"John Doe","email":"john@example.com"}` + "\n" + `{"name":"Jane Doe","email":"jane@example.com"}`
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/users/import?format=ndjson", bytes.NewReader([]byte(body)))
+		rec := httptest.NewRecorder()
+
+		e.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusAccepted, rec.Code)
+
+		job := waitForJob(t, rec.Header().Get("Location"))
+		assert.Equal(t, handlers.ImportJobCompleted, job.Status)
+		assert.Equal(t, 2, job.Progress.Created)
+
+		users, _ := userRepository.FindAll(context.Background())
+		assert.Len(t, users, 2)
+	})
+
+	// Test: Error cases
+	t.Run("Error Cases", func(t *testing.T) {
+		// Test: Unsupported format
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/users/import", bytes.NewReader([]byte("{}")))
+		req.Header.Set("Content-Type", "application/xml")
+		rec := httptest.NewRecorder()
+
+		e.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+
+		// Test: Missing email column fails the job
+		req = httptest.NewRequest(http.MethodPost, "/api/v1/users/import?format=csv", bytes.NewReader([]byte("name\nJohn\n")))
+		rec = httptest.NewRecorder()
+
+		e.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusAccepted, rec.Code)
+
+		job := waitForJob(t, rec.Header().Get("Location"))
+		assert.Equal(t, handlers.ImportJobFailed, job.Status)
+		assert.NotEmpty(t, job.Error)
+
+		// Test: Unknown job
+		req = httptest.NewRequest(http.MethodGet, "/api/v1/users/import/unknown", nil)
+		rec = httptest.NewRecorder()
+
+		e.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
 }
 
-func (l *TestUserEventsLogger) OnUserUpdated(user *gouser.User) {
-	// Test implementation - do nothing
+func TestUserExportFlow(t *testing.T) {
+	e := echo.New()
+	e.HideBanner = true
+
+	// Initialize services
+	userRepository := gouser.NewInMemoryUserRepository()
+	userEvents := gousertest.NewRecordingEvents()
+	userService := gouser.NewUserService(userRepository, userEvents)
+
+	// Initialize handlers
+	healthHandler := handlers.NewHealthHandler("1.0.0")
+	userHandler := handlers.NewUserHandler(userService)
+	importHandler := handlers.NewImportHandler(userService)
+
+	// Setup routes
+	setupRoutes(e, healthHandler, userHandler, importHandler)
+
+	ctx := context.Background()
+	userService.Create(ctx, gouser.CreateUserData{Name: "John Doe", Email: "john@example.com", Phone: "+1234567890"})
+	userService.Create(ctx, gouser.CreateUserData{Name: "Jane Doe", Email: "jane@example.com"})
+	userService.Create(ctx, gouser.CreateUserData{Name: "Mary Smith", Email: "mary@example.org"})
+
+	// Test: NDJSON export with field selection
+	t.Run("NDJSON Export", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/users/export?fields=name,email", nil)
+		rec := httptest.NewRecorder()
+
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "application/x-ndjson", rec.Header().Get("Content-Type"))
+		assert.Contains(t, rec.Header().Get("Content-Disposition"), "attachment; filename=")
+
+		lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+		assert.Len(t, lines, 3)
+		assert.Equal(t, `{"name":"John Doe","email":"john@example.com"}`, lines[0])
+	})
+
+	// Test: CSV export honoring listing filters
+	t.Run("CSV Export With Filters", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/users/export?format=csv&email=example.com&fields=email,phone", nil)
+		rec := httptest.NewRecorder()
+
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "email,phone\njohn@example.com,+1234567890\njane@example.com,\n", rec.Body.String())
+	})
+
+	// Test: Listing uses the same filters
+	t.Run("List With Filters", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/users?name=smith", nil)
+		rec := httptest.NewRecorder()
+
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response []map[string]interface{}
+		err := json.Unmarshal(rec.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Len(t, response, 1)
+		assert.Equal(t, "Mary Smith", response[0]["name"])
+	})
+
+	// Test: Error cases
+	t.Run("Error Cases", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/users/export?format=xlsx", nil)
+		rec := httptest.NewRecorder()
+
+		e.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+		req = httptest.NewRequest(http.MethodGet, "/api/v1/users/export?fields=password", nil)
+		rec = httptest.NewRecorder()
+
+		e.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
 }
 
-func (l *TestUserEventsLogger) OnUserDeleted(userID string) {
-	// Test implementation - do nothing
+func TestUserAuthorizationFlow(t *testing.T) {
+	e := echo.New()
+	e.HideBanner = true
+	e.Validator = &CustomValidator{}
+
+	// Principals come from test headers; the policy is the shipped example
+	e.Use(auth.Middleware(auth.Config{
+		Authenticators: []auth.Authenticator{testHeaderAuthenticator{}},
+	}))
+
+	policy, err := gouser.LoadRBACPolicy("config/rbac.example.json")
+	require.NoError(t, err)
+
+	// Initialize services
+	userRepository := gouser.NewInMemoryUserRepository()
+	userEvents := gousertest.NewRecordingEvents()
+	userService := gouser.NewUserService(userRepository, userEvents, gouser.WithPolicy(policy))
+
+	// Initialize handlers
+	healthHandler := handlers.NewHealthHandler("1.0.0")
+	userHandler := handlers.NewUserHandler(userService)
+	importHandler := handlers.NewImportHandler(userService)
+
+	// Setup routes
+	setupRoutes(e, healthHandler, userHandler, importHandler)
+
+	adminCtx := gouser.WithPrincipal(context.Background(), &gouser.Principal{ID: "admin", Roles: []string{"admin"}})
+	john, err := userService.Create(adminCtx, gouser.CreateUserData{Name: "John Doe", Email: "john@example.com"})
+	require.NoError(t, err)
+	jane, err := userService.Create(adminCtx, gouser.CreateUserData{Name: "Jane Doe", Email: "jane@example.com"})
+	require.NoError(t, err)
+
+	send := func(method, path, body, principal, roles string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set("X-Test-Principal", principal)
+		req.Header.Set("X-Test-Roles", roles)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// Test: Users manage their own profile
+	t.Run("Self Access", func(t *testing.T) {
+		rec := send(http.MethodGet, "/api/v1/users/"+john.ID, "", john.ID, "user")
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		rec = send(http.MethodPut, "/api/v1/users/"+john.ID, `{"name":"John Smith"}`, john.ID, "user")
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	// Test: Denied operations return a 403 problem
+	t.Run("Forbidden Operations", func(t *testing.T) {
+		rec := send(http.MethodPut, "/api/v1/users/"+john.ID, `{"email":"john.smith@example.com"}`, john.ID, "user")
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.Equal(t, handlers.MIMEApplicationProblemJSON, rec.Header().Get(echo.HeaderContentType))
+
+		var problem handlers.ProblemDetails
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &problem))
+		assert.Equal(t, http.StatusForbidden, problem.Status)
+		assert.Equal(t, "forbidden", problem.Code)
+		assert.Equal(t, "/api/v1/users/"+john.ID, problem.Instance)
+		assert.Contains(t, problem.Detail, "email")
+
+		rec = send(http.MethodDelete, "/api/v1/users/"+jane.ID, "", john.ID, "user")
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+
+		rec = send(http.MethodGet, "/api/v1/users", "", john.ID, "user")
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+
+		rec = send(http.MethodGet, "/api/v1/users/export", "", john.ID, "user")
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	// Test: Users out of reach look missing, so IDs cannot be probed
+	t.Run("Hidden Users", func(t *testing.T) {
+		rec := send(http.MethodGet, "/api/v1/users/"+jane.ID, "", john.ID, "user")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+
+		missing := send(http.MethodGet, "/api/v1/users/missing", "", john.ID, "user")
+		assert.Equal(t, http.StatusNotFound, missing.Code)
+		assert.Equal(t, missing.Body.String(), rec.Body.String())
+	})
+
+	// Test: Import jobs are visible to their submitter and admins only
+	t.Run("Import Jobs", func(t *testing.T) {
+		rec := send(http.MethodPost, "/api/v1/users/import?format=csv&dryRun=true", "name,email\nBob,bob@example.com\n", john.ID, "user")
+		require.Equal(t, http.StatusAccepted, rec.Code)
+		location := rec.Header().Get(echo.HeaderLocation)
+
+		assert.Equal(t, http.StatusOK, send(http.MethodGet, location, "", john.ID, "user").Code)
+		assert.Equal(t, http.StatusOK, send(http.MethodGet, location, "", "admin", "admin").Code)
+
+		other := send(http.MethodGet, location, "", jane.ID, "user")
+		assert.Equal(t, http.StatusNotFound, other.Code)
+		missing := send(http.MethodGet, "/api/v1/users/import/missing", "", jane.ID, "user")
+		assert.Equal(t, missing.Body.String(), other.Body.String())
+	})
+
+	// Test: Admins can do anything
+	t.Run("Admin Access", func(t *testing.T) {
+		rec := send(http.MethodPut, "/api/v1/users/"+john.ID, `{"email":"john.smith@example.com"}`, "admin", "admin")
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		rec = send(http.MethodDelete, "/api/v1/users/"+jane.ID, "", "admin", "admin")
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+	})
+}
+
+// testHeaderAuthenticator trusts X-Test-Principal and X-Test-Roles headers
+type testHeaderAuthenticator struct{}
+
+func (testHeaderAuthenticator) Authenticate(r *http.Request) (*gouser.Principal, error) {
+	id := r.Header.Get("X-Test-Principal")
+	if id == "" {
+		return nil, auth.ErrNoCredentials
+	}
+	return &gouser.Principal{
+		ID:         id,
+		Type:       gouser.PrincipalTypeUser,
+		Roles:      strings.Split(r.Header.Get("X-Test-Roles"), ","),
+		AuthMethod: "test",
+	}, nil
+}
+
+func TestCorrelationPropagation(t *testing.T) {
+	// Stand-in notifier-express: records and echoes the correlation header
+	var mu sync.Mutex
+	var notified []string
+	notifier := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		correlationID := r.Header.Get(correlation.HeaderCorrelationID)
+		mu.Lock()
+		notified = append(notified, correlationID)
+		mu.Unlock()
+		w.Header().Set(correlation.HeaderCorrelationID, correlationID)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer notifier.Close()
+
+	e := echo.New()
+	e.HideBanner = true
+	e.Validator = &CustomValidator{}
+	e.Use(correlation.Middleware(correlation.Config{}))
+
+	userEvents := &notifierUserEvents{
+		url:    notifier.URL,
+		client: &http.Client{Transport: correlation.NewTransport(nil)},
+	}
+	userService := gouser.NewUserService(gouser.NewInMemoryUserRepository(), userEvents)
+	setupRoutes(e, handlers.NewHealthHandler("1.0.0"), handlers.NewUserHandler(userService), handlers.NewImportHandler(userService))
+
+	create := func(email, correlationID string) *httptest.ResponseRecorder {
+		body := fmt.Sprintf(`{"name":"John Doe","email":%q}`, email)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/users", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		if correlationID != "" {
+			req.Header.Set(correlation.HeaderCorrelationID, correlationID)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+	lastNotified := func() string {
+		mu.Lock()
+		defer mu.Unlock()
+		require.NotEmpty(t, notified)
+		return notified[len(notified)-1]
+	}
+
+	// Test: the bff-nest correlation ID reaches the notifier
+	t.Run("Forwards Incoming ID", func(t *testing.T) {
+		rec := create("john@example.com", "bff-nest-42")
+		require.Equal(t, http.StatusCreated, rec.Code)
+
+		assert.Equal(t, "bff-nest-42", rec.Header().Get(correlation.HeaderCorrelationID))
+		assert.Equal(t, "bff-nest-42", lastNotified())
+	})
+
+	// Test: a generated ID is echoed and forwarded
+	t.Run("Forwards Generated ID", func(t *testing.T) {
+		rec := create("jane@example.com", "")
+		require.Equal(t, http.StatusCreated, rec.Code)
+
+		generated := rec.Header().Get(correlation.HeaderCorrelationID)
+		assert.NotEmpty(t, generated)
+		assert.Equal(t, generated, lastNotified())
+	})
+}
+
+// notifierUserEvents posts user events to a notifier over HTTP
+type notifierUserEvents struct {
+	url    string
+	client *http.Client
+}
+
+func (n *notifierUserEvents) OnUserCreated(user *gouser.User) {
+	n.OnUserCreatedContext(context.Background(), user)
+}
+
+func (n *notifierUserEvents) OnUserUpdated(user *gouser.User) {
+	n.OnUserUpdatedContext(context.Background(), user)
+}
+
+func (n *notifierUserEvents) OnUserDeleted(userID string) {
+	n.OnUserDeletedContext(context.Background(), userID)
+}
+
+func (n *notifierUserEvents) OnUserCreatedContext(ctx context.Context, user *gouser.User) {
+	n.notify(ctx, "user.created", user.ID)
+}
+
+func (n *notifierUserEvents) OnUserUpdatedContext(ctx context.Context, user *gouser.User) {
+	n.notify(ctx, "user.updated", user.ID)
+}
+
+func (n *notifierUserEvents) OnUserDeletedContext(ctx context.Context, userID string) {
+	n.notify(ctx, "user.deleted", userID)
+}
+
+func (n *notifierUserEvents) notify(ctx context.Context, event, userID string) {
+	body := fmt.Sprintf(`{"event":%q,"userId":%q}`, event, userID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, strings.NewReader(body))
+	if err != nil {
+		return
+	}
+	res, err := n.client.Do(req)
+	if err != nil {
+		return
+	}
+	res.Body.Close()
+}
+
+func TestReadiness(t *testing.T) {
+	e := echo.New()
+	e.HideBanner = true
+
+	available := true
+	userService := gouser.NewUserService(gouser.NewInMemoryUserRepository(), gousertest.NewRecordingEvents())
+	checks := gohealth.New()
+	checks.Register(gohealth.Check{
+		Name: "repository",
+		Checker: gohealth.CheckerFunc(func(ctx context.Context) (gohealth.Details, error) {
+			if !available {
+				return nil, errors.New("connection refused")
+			}
+			return nil, nil
+		}),
+		Critical: true,
+	})
+	checks.Register(gohealth.Check{
+		Name: "notifier",
+		Checker: gohealth.CheckerFunc(func(context.Context) (gohealth.Details, error) {
+			return nil, errors.New("timeout")
+		}),
+	})
+	healthHandler := handlers.NewHealthHandler("1.0.0").WithChecks(checks)
+	setupRoutes(e, healthHandler, handlers.NewUserHandler(userService), handlers.NewImportHandler(userService))
+
+	probe := func(path string) (*httptest.ResponseRecorder, gohealth.Result) {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		var result gohealth.Result
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+		return rec, result
+	}
+
+	// Test: non-critical failures are reported without removing the pod
+	t.Run("Non-Critical Failure", func(t *testing.T) {
+		rec, result := probe("/health/ready")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, gohealth.StatusOK, result.Status)
+		assert.Equal(t, gohealth.StatusUp, result.Info["repository"]["status"])
+		assert.Equal(t, "timeout", result.Error["notifier"]["message"])
+		assert.Len(t, result.Details, 2)
+	})
+
+	// Test: a critical failure makes the service unavailable
+	t.Run("Storage Down", func(t *testing.T) {
+		available = false
+		defer func() { available = true }()
+
+		rec, result := probe("/health/ready")
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		assert.Equal(t, gohealth.StatusError, result.Status)
+		assert.Equal(t, "connection refused", result.Error["repository"]["message"])
+	})
+
+	// Test: startup waits for initialization
+	t.Run("Startup", func(t *testing.T) {
+		rec, _ := probe("/health/startup")
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+		checks.MarkStarted()
+		rec, _ = probe("/health/startup")
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	// Test: readiness flips as soon as shutdown starts while liveness holds
+	t.Run("Shutting Down", func(t *testing.T) {
+		checks.Shutdown()
+
+		rec, result := probe("/health/ready")
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		assert.Equal(t, gohealth.StatusShuttingDown, result.Status)
+
+		rec, _ = probe("/health/live")
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+}
+
+func TestUserIDValidation(t *testing.T) {
+	e := echo.New()
+	e.HideBanner = true
+	e.Validator = &CustomValidator{}
+
+	ids := gouser.ULIDGenerator{}
+	userService := gouser.NewUserService(gouser.NewInMemoryUserRepository(gouser.WithIDGenerator(ids)), gousertest.NewRecordingEvents())
+	userHandler := handlers.NewUserHandler(userService).WithIDValidator(gouser.WellFormedID)
+	setupRoutes(e, handlers.NewHealthHandler("1.0.0"), userHandler, handlers.NewImportHandler(userService))
+
+	// Test: malformed IDs are rejected before reaching the repository
+	t.Run("Malformed ID", func(t *testing.T) {
+		for _, method := range []string{http.MethodGet, http.MethodPut, http.MethodDelete} {
+			req := httptest.NewRequest(method, "/api/v1/users/not.an.id", strings.NewReader("{}"))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+
+			e.ServeHTTP(rec, req)
+			assert.Equal(t, http.StatusBadRequest, rec.Code, method)
+			assert.Contains(t, rec.Body.String(), "invalid_id", method)
+		}
+	})
+
+	// Test: well-formed unknown IDs are still not found, whatever their strategy
+	t.Run("Unknown ID", func(t *testing.T) {
+		for _, id := range []string{ids.NewID(), "999", gouser.UUIDv7Generator{}.NewID()} {
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/users/"+id, nil))
+			assert.Equal(t, http.StatusNotFound, rec.Code, id)
+		}
+	})
+}
+
+func TestClock(t *testing.T) {
+	app := newTestApp(t)
+
+	// Test: the health timestamp comes from the clock
+	t.Run("Health Timestamp", func(t *testing.T) {
+		var response handlers.HealthResponse
+		app.Get("/health").Decode(&response)
+		assert.True(t, gousertest.Epoch.Equal(response.Timestamp))
+	})
+
+	// Test: user timestamps come from the clock
+	t.Run("User Timestamps", func(t *testing.T) {
+		var user handlers.UserResponse
+		app.Post("/api/v1/users", gousertest.NewUser().Data()).AssertStatus(http.StatusCreated).Decode(&user)
+		assert.Equal(t, "2024-01-01T00:00:00Z", user.CreatedAt)
+		assert.Equal(t, "2024-01-01T00:00:00Z", user.UpdatedAt)
+	})
+
+	// Test: finished import jobs expire after the retention
+	t.Run("Import Job Retention", func(t *testing.T) {
+		importJob := func() string {
+			res := app.Post("/api/v1/users/import?format=ndjson&dryRun=true", `{"name":"Jane Doe","email":"jane@example.com"}`)
+			require.Equal(t, http.StatusAccepted, res.Code)
+			return res.Header().Get("Location")
+		}
+
+		first := importJob()
+		assert.Eventually(t, func() bool {
+			return strings.Contains(app.Get(first).Body.String(), `"finishedAt"`)
+		}, 2*time.Second, 10*time.Millisecond)
+
+		app.Clock.Advance(2 * time.Hour)
+		importJob()
+		app.Get(first).AssertStatus(http.StatusNotFound)
+	})
+}
+
+func TestRepositoryFailures(t *testing.T) {
+	app := newTestApp(t)
+	user := gousertest.NewUser().Create(t, app.Service)
+
+	// Test: storage errors surface as 500 without events
+	t.Run("Internal Errors", func(t *testing.T) {
+		app.Repository.FailOn(gouser.RepositoryOpFindByID, nil).FailOn(gouser.RepositoryOpFindAll, nil).FailOn(gouser.RepositoryOpDelete, nil)
+		defer app.Repository.Heal()
+		app.Events.Reset()
+
+		app.Get("/api/v1/users").AssertStatus(http.StatusInternalServerError)
+		app.Get("/api/v1/users/" + user.ID).AssertStatus(http.StatusInternalServerError)
+		app.Put("/api/v1/users/"+user.ID, gousertest.NewUpdate().WithName("Jane Doe").Data()).AssertStatus(http.StatusInternalServerError)
+		app.Delete("/api/v1/users/" + user.ID).AssertStatus(http.StatusInternalServerError)
+		app.Events.AssertNoEvents(t)
+	})
+
+	// Test: a transient failure does not affect the next request
+	t.Run("Transient Failure", func(t *testing.T) {
+		app.Repository.FailTimes(gouser.RepositoryOpCreate, 1, nil)
+
+		data := gousertest.NewFaker(1).User()
+		app.Post("/api/v1/users", data).AssertStatus(http.StatusInternalServerError)
+		app.Post("/api/v1/users", data).AssertStatus(http.StatusCreated)
+		app.Events.AssertCreated(t, data.Email)
+	})
+}
+
+func TestFaultInjection(t *testing.T) {
+	e := echo.New()
+	e.HideBanner = true
+	e.Validator = &CustomValidator{}
+	e.Use(auth.Middleware(auth.Config{
+		Authenticators: []auth.Authenticator{testHeaderAuthenticator{}},
+	}))
+
+	faults := gouser.NewFaultInjectingRepository(gouser.NewInMemoryUserRepository(), gouser.FaultConfig{})
+	faults.SetEnabled(false)
+	userService := gouser.NewUserService(faults, gousertest.NewRecordingEvents())
+	setupRoutes(e, handlers.NewHealthHandler("1.0.0"), handlers.NewUserHandler(userService), handlers.NewImportHandler(userService))
+	setupFaultRoutes(e, handlers.NewFaultsHandler(faults))
+
+	app := gousertest.NewHarness(t, e)
+	app.Header.Set("X-Test-Principal", "qa")
+	app.Header.Set("X-Test-Roles", "admin")
+
+	// Test: enabling faults through the admin endpoint fails user requests
+	t.Run("Enable Faults", func(t *testing.T) {
+		var state handlers.FaultsResponse
+		app.Put("/api/v1/admin/faults", map[string]any{
+			"enabled": true,
+			"config": map[string]any{
+				"seed":    1,
+				"default": map[string]any{"errorRate": 1, "latency": map[string]any{"distribution": "fixed", "mean": "1ms"}},
+			},
+		}).AssertStatus(http.StatusOK).Decode(&state)
+		assert.True(t, state.Enabled)
+		assert.Equal(t, time.Millisecond, state.Config.Default.Latency.Mean)
+
+		app.Get("/api/v1/users").AssertStatus(http.StatusInternalServerError)
+		app.Post("/api/v1/users", gousertest.NewUser().Data()).AssertStatus(http.StatusInternalServerError)
+	})
+
+	// Test: disabling faults restores the repository
+	t.Run("Disable Faults", func(t *testing.T) {
+		app.Put("/api/v1/admin/faults", map[string]any{"enabled": false}).AssertStatus(http.StatusOK)
+
+		app.Post("/api/v1/users", gousertest.NewUser().Data()).AssertStatus(http.StatusCreated)
+		assert.Equal(t, 1.0, faults.Config().Default.ErrorRate, "the configuration is kept")
+	})
+
+	// Test: invalid configurations are rejected
+	t.Run("Invalid Config", func(t *testing.T) {
+		res := app.Put("/api/v1/admin/faults", map[string]any{"config": map[string]any{"default": map[string]any{"errorRate": 2}}})
+		res.AssertStatus(http.StatusBadRequest)
+		assert.Contains(t, res.Body.String(), "validation_error")
+	})
+
+	// Test: only admins change faults
+	t.Run("Non Admin", func(t *testing.T) {
+		app.Header.Set("X-Test-Roles", "user")
+		defer app.Header.Set("X-Test-Roles", "admin")
+
+		app.Put("/api/v1/admin/faults", map[string]any{"enabled": true}).AssertStatus(http.StatusForbidden)
+		assert.False(t, faults.Enabled())
+	})
+
+	// Test: without authentication nobody changes faults
+	t.Run("Unauthenticated", func(t *testing.T) {
+		e := echo.New()
+		setupFaultRoutes(e, handlers.NewFaultsHandler(faults))
+		anonymous := gousertest.NewHarness(t, e)
+
+		anonymous.Get("/api/v1/admin/faults").AssertStatus(http.StatusForbidden)
+		anonymous.Put("/api/v1/admin/faults", map[string]any{"enabled": true}).AssertStatus(http.StatusForbidden)
+		assert.False(t, faults.Enabled())
+	})
+}
+
+func TestConditionalRequests(t *testing.T) {
+	app := newTestApp(t)
+	user := gousertest.NewUser().Create(t, app.Service)
+	target := "/api/v1/users/" + user.ID
+
+	first := app.Get(target).AssertStatus(http.StatusOK)
+	etag := first.Header().Get(handlers.HeaderETag)
+	require.NotEmpty(t, etag)
+	assert.False(t, strings.HasPrefix(etag, "W/"), "ETags should be strong")
+	assert.Equal(t, gousertest.Epoch.Format(http.TimeFormat), first.Header().Get(echo.HeaderLastModified))
+	assert.Equal(t, handlers.DefaultCacheControl, first.Header().Get(echo.HeaderCacheControl))
+
+	// Test: a matching If-None-Match answers 304 without a body
+	t.Run("If-None-Match", func(t *testing.T) {
+		app.Header.Set(handlers.HeaderIfNoneMatch, `"other", `+etag)
+		defer app.Header.Del(handlers.HeaderIfNoneMatch)
+
+		rec := app.Get(target).AssertStatus(http.StatusNotModified)
+		assert.Empty(t, rec.Body.String())
+		assert.Equal(t, etag, rec.Header().Get(handlers.HeaderETag))
+
+		app.Header.Set(handlers.HeaderIfNoneMatch, `"other"`)
+		app.Get(target).AssertStatus(http.StatusOK)
+	})
+
+	// Test: If-Modified-Since compares with UpdatedAt
+	t.Run("If-Modified-Since", func(t *testing.T) {
+		app.Header.Set(handlers.HeaderIfModifiedSince, gousertest.Epoch.Format(http.TimeFormat))
+		defer app.Header.Del(handlers.HeaderIfModifiedSince)
+
+		app.Get(target).AssertStatus(http.StatusNotModified)
+		app.Header.Set(handlers.HeaderIfModifiedSince, gousertest.Epoch.Add(-time.Second).Format(http.TimeFormat))
+		app.Get(target).AssertStatus(http.StatusOK)
+	})
+
+	// Test: HEAD sends the GET headers without the body
+	t.Run("HEAD", func(t *testing.T) {
+		rec := app.Do(http.MethodHead, target, nil).AssertStatus(http.StatusOK)
+		assert.Empty(t, rec.Body.String())
+		assert.Equal(t, etag, rec.Header().Get(handlers.HeaderETag))
+		assert.Equal(t, strconv.Itoa(first.Body.Len()), rec.Header().Get(echo.HeaderContentLength))
+
+		list := app.Get("/api/v1/users").AssertStatus(http.StatusOK)
+		head := app.Do(http.MethodHead, "/api/v1/users", nil).AssertStatus(http.StatusOK)
+		assert.Equal(t, list.Header().Get(handlers.HeaderETag), head.Header().Get(handlers.HeaderETag))
+		assert.Empty(t, head.Body.String())
+	})
+
+	// Test: changes produce new validators for the user and the list
+	t.Run("Changes", func(t *testing.T) {
+		listETag := app.Get("/api/v1/users").Header().Get(handlers.HeaderETag)
+		app.Clock.Advance(time.Minute)
+		app.Put(target, gousertest.NewUpdate().WithName("Jane Doe").Data()).AssertStatus(http.StatusOK)
+
+		app.Header.Set(handlers.HeaderIfNoneMatch, etag)
+		defer app.Header.Del(handlers.HeaderIfNoneMatch)
+		rec := app.Get(target).AssertStatus(http.StatusOK)
+		assert.NotEqual(t, etag, rec.Header().Get(handlers.HeaderETag))
+		assert.Equal(t, gousertest.Epoch.Add(time.Minute).Format(http.TimeFormat), rec.Header().Get(echo.HeaderLastModified))
+
+		app.Header.Set(handlers.HeaderIfNoneMatch, listETag)
+		app.Get("/api/v1/users").AssertStatus(http.StatusOK)
+	})
+}
+
+func TestContentNegotiation(t *testing.T) {
+	app := newTestApp(t)
+	registry := codec.DefaultRegistry()
+
+	// do sends body encoded with contentType, accepting accept
+	do := func(method, target string, body interface{}, contentType, accept string) *gousertest.Response {
+		t.Helper()
+		var data []byte
+		if body != nil {
+			cd, ok := registry.ForContentType(contentType)
+			require.True(t, ok)
+			var err error
+			data, err = cd.Marshal(body)
+			require.NoError(t, err)
+		}
+		app.Header.Set(echo.HeaderContentType, contentType)
+		app.Header.Set(echo.HeaderAccept, accept)
+		defer app.Header.Del(echo.HeaderContentType)
+		defer app.Header.Del(echo.HeaderAccept)
+		return app.Do(method, target, data)
+	}
+	// decode decodes a response with the codec of its Content-Type
+	decode := func(rec *gousertest.Response, v interface{}) {
+		t.Helper()
+		cd, ok := registry.ForContentType(rec.Header().Get(echo.HeaderContentType))
+		require.True(t, ok, rec.Header().Get(echo.HeaderContentType))
+		require.NoError(t, cd.Unmarshal(rec.Body.Bytes(), v))
+	}
+
+	var created handlers.UserResponse
+	data := gousertest.NewFaker(1).User()
+
+	// Test: every format binds requests and renders responses
+	t.Run("Formats", func(t *testing.T) {
+		rec := do(http.MethodPost, "/api/v1/users", handlers.CreateUserRequest{Name: data.Name, Email: data.Email}, "application/msgpack", "application/cbor")
+		rec.AssertStatus(http.StatusCreated)
+		assert.Equal(t, "application/cbor", rec.Header().Get(echo.HeaderContentType))
+		decode(rec, &created)
+		assert.Equal(t, data.Email, created.Email)
+
+		name := "Jane Doe"
+		rec = do(http.MethodPut, "/api/v1/users/"+created.ID, handlers.UpdateUserRequest{Name: &name}, "application/xml", "application/msgpack")
+		rec.AssertStatus(http.StatusOK)
+		var updated handlers.UserResponse
+		decode(rec, &updated)
+		assert.Equal(t, name, updated.Name)
+		assert.Equal(t, data.Email, updated.Email)
+
+		rec = do(http.MethodGet, "/api/v1/users/"+created.ID, nil, "", "application/xml")
+		rec.AssertStatus(http.StatusOK)
+		assert.True(t, strings.HasPrefix(rec.Body.String(), "<?xml"))
+		var found handlers.UserResponse
+		decode(rec, &found)
+		assert.Equal(t, created.ID, found.ID)
+
+		rec = do(http.MethodGet, "/api/v1/users", nil, "", "text/xml;q=0.9, application/json;q=0.1")
+		rec.AssertStatus(http.StatusOK)
+		assert.Contains(t, rec.Body.String(), "<users><user>")
+		var list handlers.UserListResponse
+		decode(rec, &list)
+		require.Len(t, list, 1)
+		assert.Equal(t, name, list[0].Name)
+	})
+
+	// Test: each representation has its own ETag
+	t.Run("ETags", func(t *testing.T) {
+		jsonETag := do(http.MethodGet, "/api/v1/users/"+created.ID, nil, "", "application/json").Header().Get(handlers.HeaderETag)
+		rec := do(http.MethodGet, "/api/v1/users/"+created.ID, nil, "", "application/cbor")
+		assert.NotEqual(t, jsonETag, rec.Header().Get(handlers.HeaderETag))
+		assert.Contains(t, rec.Header().Values(echo.HeaderVary), echo.HeaderAccept)
+	})
+
+	// Test: unsupported formats get 406 and 415
+	t.Run("Unsupported", func(t *testing.T) {
+		rec := do(http.MethodGet, "/api/v1/users", nil, "", "text/html")
+		rec.AssertStatus(http.StatusNotAcceptable)
+		var body handlers.ErrorResponse
+		rec.Decode(&body)
+		assert.Equal(t, "not_acceptable", body.Error)
+
+		app.Header.Set(echo.HeaderContentType, "text/plain")
+		app.Header.Set(echo.HeaderAccept, "application/xml")
+		rec = app.Post("/api/v1/users", "name=Jane")
+		app.Header.Del(echo.HeaderContentType)
+		app.Header.Del(echo.HeaderAccept)
+		rec.AssertStatus(http.StatusUnsupportedMediaType)
+		assert.Contains(t, rec.Header().Get(echo.HeaderAccept), "application/cbor")
+		decode(rec, &body)
+		assert.Equal(t, "unsupported_media_type", body.Error)
+	})
+
+	// Test: problem details follow the negotiated format
+	t.Run("Problem Details", func(t *testing.T) {
+		policy, err := gouser.NewRBACPolicy(gouser.RBACConfig{})
+		require.NoError(t, err)
+		denied := newTestApp(t, gouser.WithPolicy(policy))
+		denied.Header.Set(echo.HeaderAccept, "application/xml")
+
+		rec := denied.Get("/api/v1/users").AssertStatus(http.StatusForbidden)
+		assert.Equal(t, "application/problem+xml", rec.Header().Get(echo.HeaderContentType))
+		assert.Contains(t, rec.Body.String(), `<problem xmlns="urn:ietf:rfc:7807">`)
+		var problem handlers.ProblemDetails
+		require.NoError(t, codec.XML.Unmarshal(rec.Body.Bytes(), &problem))
+		assert.Equal(t, "forbidden", problem.Code)
+
+		denied.Header.Del(echo.HeaderAccept)
+		rec = denied.Get("/api/v1/users").AssertStatus(http.StatusForbidden)
+		assert.Equal(t, handlers.MIMEApplicationProblemJSON, rec.Header().Get(echo.HeaderContentType))
+	})
 }
@@ -0,0 +1,71 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+
+	gologger "github.com/mateusmacedo/scouts/libs/logger-go"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type requestIDKey struct{}
+
+// WithRequestID returns a context carrying the request ID
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext returns the request ID stored in ctx, if any
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// WithCorrelationID returns a context carrying the correlation ID. It shares
+// the logger-go key so sinks and outbound clients see the same value.
+func WithCorrelationID(ctx context.Context, correlationID string) context.Context {
+	return gologger.WithCorrelationID(ctx, correlationID)
+}
+
+// CorrelationIDFromContext returns the correlation ID stored in ctx, if any
+func CorrelationIDFromContext(ctx context.Context) string {
+	return gologger.CorrelationIDFromContext(ctx)
+}
+
+// ContextHandler adds request, correlation and trace IDs found in the
+// record's context to every record
+type ContextHandler struct {
+	slog.Handler
+}
+
+// NewContextHandler wraps handler
+func NewContextHandler(handler slog.Handler) *ContextHandler {
+	return &ContextHandler{Handler: handler}
+}
+
+// Handle adds the context IDs and delegates to the wrapped handler
+func (h *ContextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if requestID := RequestIDFromContext(ctx); requestID != "" {
+		record.AddAttrs(slog.String(KeyRequestID, requestID))
+	}
+	if correlationID := CorrelationIDFromContext(ctx); correlationID != "" {
+		record.AddAttrs(slog.String(KeyCorrelationID, correlationID))
+	}
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		record.AddAttrs(
+			slog.String(KeyTraceID, spanContext.TraceID().String()),
+			slog.String(KeySpanID, spanContext.SpanID().String()),
+		)
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+// WithAttrs keeps the context handler around the derived handler
+func (h *ContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &ContextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup keeps the context handler around the derived handler
+func (h *ContextHandler) WithGroup(name string) slog.Handler {
+	return &ContextHandler{Handler: h.Handler.WithGroup(name)}
+}
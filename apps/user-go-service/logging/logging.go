@@ -0,0 +1,145 @@
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/mateusmacedo/scouts/apps/user-go-service/redaction"
+	gologger "github.com/mateusmacedo/scouts/libs/logger-go"
+)
+
+// Field keys shared with the logger-node schema
+const (
+	KeyTimestamp     = "timestamp"
+	KeyMessage       = "message"
+	KeyService       = "service"
+	KeyRequestID     = "requestId"
+	KeyCorrelationID = "correlationId"
+	KeyTraceID       = "traceId"
+	KeySpanID        = "spanId"
+)
+
+// Output formats
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// Options configures New
+type Options struct {
+	// Level is one of debug, info, warn or error. Defaults to info.
+	Level string
+	// Leveler overrides Level, e.g. a *slog.LevelVar changed on config reload
+	Leveler slog.Leveler
+	// Format is json or text. Defaults to json.
+	Format string
+	// Output receives the log records. Defaults to os.Stdout.
+	Output io.Writer
+	// Sink receives the records as logger-node entries instead of Output.
+	// Format is ignored when it is set.
+	Sink gologger.Sink
+	// Service is added to every record when set
+	Service string
+	// Redactor masks sensitive attributes. Defaults to the rules shared with logger-node.
+	Redactor *redaction.Redactor
+}
+
+// New creates a structured logger whose records carry request, correlation
+// and trace IDs from the context passed to the *Context logging methods.
+// Sensitive attributes are masked before they are written.
+func New(opts Options) *slog.Logger {
+	if opts.Output == nil {
+		opts.Output = os.Stdout
+	}
+	if opts.Redactor == nil {
+		opts.Redactor = redaction.Default()
+	}
+
+	if opts.Leveler == nil {
+		opts.Leveler = ParseLevel(opts.Level)
+	}
+	handlerOpts := &slog.HandlerOptions{
+		Level:       opts.Leveler,
+		ReplaceAttr: replaceAttr,
+	}
+
+	var handler slog.Handler
+	switch {
+	case opts.Sink != nil:
+		// The logger-go level is fixed, so the leveler is checked in front of it
+		logger := gologger.New(opts.Sink, gologger.WithLevel(gologger.LevelTrace))
+		handler = &levelHandler{Handler: gologger.NewSlogHandler(logger), leveler: opts.Leveler}
+	case opts.Format == FormatText:
+		handler = slog.NewTextHandler(opts.Output, handlerOpts)
+	default:
+		handler = slog.NewJSONHandler(opts.Output, handlerOpts)
+	}
+
+	// Context IDs are added after redaction so trace IDs are never mistaken for tokens
+	logger := slog.New(redaction.NewHandler(NewContextHandler(handler), opts.Redactor))
+	if opts.Service != "" {
+		logger = logger.With(KeyService, opts.Service)
+	}
+	return logger
+}
+
+// levelHandler drops records below the current level of leveler
+type levelHandler struct {
+	slog.Handler
+	leveler slog.Leveler
+}
+
+func (h *levelHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.leveler.Level() && h.Handler.Enabled(ctx, level)
+}
+
+func (h *levelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &levelHandler{Handler: h.Handler.WithAttrs(attrs), leveler: h.leveler}
+}
+
+func (h *levelHandler) WithGroup(name string) slog.Handler {
+	return &levelHandler{Handler: h.Handler.WithGroup(name), leveler: h.leveler}
+}
+
+// FormatFor returns text for development and json for other environments
+func FormatFor(environment string) string {
+	if environment == "development" {
+		return FormatText
+	}
+	return FormatJSON
+}
+
+// ParseLevel maps a LOG_LEVEL value to a slog.Level, defaulting to info
+func ParseLevel(level string) slog.Level {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// replaceAttr renames the built-in keys and lowercases levels to match logger-node
+func replaceAttr(groups []string, attr slog.Attr) slog.Attr {
+	if len(groups) > 0 {
+		return attr
+	}
+	switch attr.Key {
+	case slog.TimeKey:
+		attr.Key = KeyTimestamp
+	case slog.MessageKey:
+		attr.Key = KeyMessage
+	case slog.LevelKey:
+		if level, ok := attr.Value.Any().(slog.Level); ok {
+			attr.Value = slog.StringValue(strings.ToLower(level.String()))
+		}
+	}
+	return attr
+}
@@ -0,0 +1,213 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/mateusmacedo/scouts/apps/user-go-service/correlation"
+	gologger "github.com/mateusmacedo/scouts/libs/logger-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// decodeLines parses JSON log output into one map per record
+func decodeLines(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var records []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var record map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &record))
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestNew(t *testing.T) {
+	t.Run("should use the logger-node schema", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := New(Options{Level: "info", Output: &buf, Service: "user-go-service"})
+
+		logger.Info("hello", "userId", "1")
+		records := decodeLines(t, &buf)
+
+		require.Len(t, records, 1)
+		assert.Equal(t, "info", records[0]["level"])
+		assert.Equal(t, "hello", records[0]["message"])
+		assert.Equal(t, "user-go-service", records[0]["service"])
+		assert.Contains(t, records[0], "timestamp")
+		assert.Equal(t, "1", records[0]["userId"])
+	})
+
+	t.Run("should honor the level", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := New(Options{Level: "warn", Output: &buf})
+
+		logger.Info("dropped")
+		logger.Warn("kept")
+
+		records := decodeLines(t, &buf)
+		require.Len(t, records, 1)
+		assert.Equal(t, "kept", records[0]["message"])
+	})
+
+	t.Run("should follow level changes", func(t *testing.T) {
+		for name, opts := range map[string]func(*bytes.Buffer) Options{
+			"output": func(buf *bytes.Buffer) Options { return Options{Output: buf} },
+			"sink":   func(buf *bytes.Buffer) Options { return Options{Sink: gologger.NewWriterSink(buf)} },
+		} {
+			var buf bytes.Buffer
+			level := new(slog.LevelVar)
+			o := opts(&buf)
+			o.Leveler = level
+			logger := New(o).With("request", "1")
+
+			logger.Debug("dropped")
+			level.Set(slog.LevelDebug)
+			logger.Debug("kept")
+
+			assert.NotContains(t, buf.String(), "dropped", name)
+			assert.Contains(t, buf.String(), "kept", name)
+		}
+	})
+
+	t.Run("should write text in development", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := New(Options{Format: FormatFor("development"), Output: &buf})
+
+		logger.Info("hello")
+		assert.Contains(t, buf.String(), "message=hello")
+		assert.Equal(t, FormatJSON, FormatFor("production"))
+	})
+
+	t.Run("should redact sensitive attributes", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := New(Options{Output: &buf})
+
+		logger.Info("User created", "email", "john@example.com", "password", "secret")
+		records := decodeLines(t, &buf)
+
+		require.Len(t, records, 1)
+		assert.Equal(t, "***", records[0]["email"])
+		assert.Equal(t, "***", records[0]["password"])
+	})
+
+	t.Run("should write logger-node entries to a sink", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := New(Options{Level: "info", Sink: gologger.NewWriterSink(&buf), Service: "user-go-service"})
+
+		ctx := WithCorrelationID(context.Background(), "corr-1")
+		logger.DebugContext(ctx, "dropped")
+		logger.InfoContext(ctx, "User created", "email", "john@example.com")
+		records := decodeLines(t, &buf)
+
+		require.Len(t, records, 1)
+		assert.Equal(t, "info", records[0]["level"])
+		assert.Equal(t, "success", records[0]["outcome"])
+		assert.Equal(t, "corr-1", records[0]["correlationId"])
+		args := records[0]["args"].([]interface{})
+		require.Len(t, args, 2)
+		assert.Equal(t, "User created", args[0])
+		fields := args[1].(map[string]interface{})
+		assert.Equal(t, "user-go-service", fields["service"])
+		assert.Equal(t, "***", fields["email"])
+	})
+
+	t.Run("should attach context IDs", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := New(Options{Output: &buf})
+
+		traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+		spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+		ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID: traceID,
+			SpanID:  spanID,
+		}))
+		ctx = WithRequestID(WithCorrelationID(ctx, "corr-1"), "req-1")
+
+		logger.With("component", "test").InfoContext(ctx, "hello")
+		records := decodeLines(t, &buf)
+
+		require.Len(t, records, 1)
+		assert.Equal(t, "req-1", records[0]["requestId"])
+		assert.Equal(t, "corr-1", records[0]["correlationId"])
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", records[0]["traceId"])
+		assert.Equal(t, "00f067aa0ba902b7", records[0]["spanId"])
+	})
+}
+
+func TestMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(Options{Level: "debug", Output: &buf})
+
+	e := echo.New()
+	e.HideBanner = true
+	e.Use(middleware.RequestID())
+	e.Use(Middleware(Config{
+		Logger:  logger,
+		Skipper: func(c echo.Context) bool { return c.Path() == "/health" },
+	}))
+	e.GET("/users/:id", func(c echo.Context) error {
+		slog.New(logger.Handler()).InfoContext(c.Request().Context(), "handler")
+		return c.String(http.StatusOK, "ok")
+	})
+	e.GET("/fail", func(c echo.Context) error { return errors.New("boom") })
+	e.GET("/health", func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+
+	t.Run("should log responses with request and correlation IDs", func(t *testing.T) {
+		buf.Reset()
+		req := httptest.NewRequest(http.MethodGet, "/users/1?x=1", nil)
+		req.Header.Set(correlation.HeaderCorrelationID, "corr-1")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		records := decodeLines(t, &buf)
+		require.Len(t, records, 2)
+		requestID := rec.Header().Get(echo.HeaderXRequestID)
+
+		assert.Equal(t, "handler", records[0]["message"])
+		assert.Equal(t, requestID, records[0]["requestId"])
+
+		access := records[1]
+		assert.Equal(t, "HTTP Response", access["message"])
+		assert.Equal(t, "info", access["level"])
+		assert.Equal(t, "GET", access["method"])
+		assert.Equal(t, "/users/1?x=1", access["url"])
+		assert.Equal(t, "/users/:id", access["route"])
+		assert.Equal(t, float64(200), access["statusCode"])
+		assert.Contains(t, access, "duration")
+		assert.Equal(t, requestID, access["requestId"])
+		assert.Equal(t, "corr-1", access["correlationId"])
+	})
+
+	t.Run("should log errors and warn on failed responses", func(t *testing.T) {
+		buf.Reset()
+		e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/fail", nil))
+
+		records := decodeLines(t, &buf)
+		require.Len(t, records, 2)
+		assert.Equal(t, "HTTP Error", records[0]["message"])
+		assert.Equal(t, "boom", records[0]["error"])
+		assert.Equal(t, "warn", records[1]["level"])
+		assert.Equal(t, float64(500), records[1]["statusCode"])
+	})
+
+	t.Run("should skip configured routes", func(t *testing.T) {
+		buf.Reset()
+		e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health", nil))
+
+		assert.Empty(t, buf.String())
+	})
+}
@@ -0,0 +1,90 @@
+package logging
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/mateusmacedo/scouts/apps/user-go-service/correlation"
+)
+
+// Config defines the config for the access log middleware
+type Config struct {
+	// Skipper defines a function to skip middleware
+	Skipper middleware.Skipper
+	// Logger receives the access log records. Defaults to slog.Default().
+	Logger *slog.Logger
+}
+
+// Middleware stores the request and correlation IDs in the request context
+// and writes one "HTTP Response" record per request, using the logger-node
+// field names. Responses with status >= 400 are logged at warn level and
+// handler errors additionally produce an "HTTP Error" record.
+//
+// It must run after middleware.RequestID and correlation.Middleware so the
+// generated IDs are available.
+func Middleware(config Config) echo.MiddlewareFunc {
+	if config.Skipper == nil {
+		config.Skipper = middleware.DefaultSkipper
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			logger := config.Logger
+			if logger == nil {
+				logger = slog.Default()
+			}
+
+			req := c.Request()
+			ctx := req.Context()
+			requestID := req.Header.Get(echo.HeaderXRequestID)
+			if requestID == "" {
+				requestID = c.Response().Header().Get(echo.HeaderXRequestID)
+			}
+			if requestID != "" {
+				ctx = WithRequestID(ctx, requestID)
+			}
+			if CorrelationIDFromContext(ctx) == "" {
+				if correlationID := req.Header.Get(correlation.HeaderCorrelationID); correlationID != "" {
+					ctx = WithCorrelationID(ctx, correlationID)
+				}
+			}
+			c.SetRequest(req.WithContext(ctx))
+
+			if config.Skipper(c) {
+				return next(c)
+			}
+
+			start := time.Now()
+			err := next(c)
+			if err != nil {
+				logger.LogAttrs(ctx, slog.LevelError, "HTTP Error",
+					slog.String("method", req.Method),
+					slog.String("url", req.RequestURI),
+					slog.String("error", err.Error()),
+				)
+				// Let Echo's error handler set the final status before logging
+				c.Error(err)
+			}
+
+			status := c.Response().Status
+			level := slog.LevelInfo
+			if status >= http.StatusBadRequest {
+				level = slog.LevelWarn
+			}
+			logger.LogAttrs(ctx, level, "HTTP Response",
+				slog.String("method", req.Method),
+				slog.String("url", req.RequestURI),
+				slog.String("route", c.Path()),
+				slog.Int("statusCode", status),
+				slog.Int64("duration", time.Since(start).Milliseconds()),
+				slog.Int64("bytesOut", c.Response().Size),
+				slog.String("userAgent", req.UserAgent()),
+				slog.String("ip", c.RealIP()),
+			)
+			return nil
+		}
+	}
+}
@@ -2,17 +2,34 @@ package main
 
 import (
 	"context"
-	"log"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
+	"sync/atomic"
 	"syscall"
 	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
+	"github.com/mateusmacedo/scouts/apps/user-go-service/auth"
+	"github.com/mateusmacedo/scouts/apps/user-go-service/cli"
 	"github.com/mateusmacedo/scouts/apps/user-go-service/config"
+	"github.com/mateusmacedo/scouts/apps/user-go-service/correlation"
 	"github.com/mateusmacedo/scouts/apps/user-go-service/handlers"
+	"github.com/mateusmacedo/scouts/apps/user-go-service/idempotency"
+	"github.com/mateusmacedo/scouts/apps/user-go-service/logging"
+	"github.com/mateusmacedo/scouts/apps/user-go-service/metrics"
+	"github.com/mateusmacedo/scouts/apps/user-go-service/ratelimit"
+	"github.com/mateusmacedo/scouts/apps/user-go-service/redaction"
+	"github.com/mateusmacedo/scouts/apps/user-go-service/storage"
+	"github.com/mateusmacedo/scouts/apps/user-go-service/tracing"
+	gohealth "github.com/mateusmacedo/scouts/libs/health-go"
+	gologger "github.com/mateusmacedo/scouts/libs/logger-go"
 	gouser "github.com/mateusmacedo/scouts/libs/user-go"
 )
 
@@ -30,84 +47,498 @@ func (cv *CustomValidator) Validate(i interface{}) error {
 }
 
 func main() {
-	// Load configuration
-	cfg, err := config.LoadConfig()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	code := cli.Main(ctx, newRootCommand(os.Stdin, os.Stdout, os.Stderr), os.Args[1:], os.Stderr)
+	stop()
+	os.Exit(code)
+}
+
+// serve runs the HTTP API until the process is signalled. configArgs are the
+// configuration flags, reapplied when the configuration is reloaded.
+func serve(ctx context.Context, cfg *config.Config, configArgs []string) {
+	// Structured logging: JSON in production, text in development
+	redactor, err := newRedactor(cfg.Log.RedactionRules)
+	if err != nil {
+		fatal("Failed to load redaction rules", err)
+	}
+	if logSink, err = newLogSink(cfg); err != nil {
+		fatal("Failed to open log file", err)
+	}
+	logLevel := new(slog.LevelVar)
+	logLevel.Set(logging.ParseLevel(cfg.Log.Level))
+	slog.SetDefault(logging.New(logging.Options{
+		Leveler:  logLevel,
+		Format:   logging.FormatFor(cfg.Environment),
+		Service:  "user-go-service",
+		Redactor: redactor,
+		Sink:     logSink,
+	}))
+
+	// Tracing is set up first so outbound clients pick up the global provider
+	cfg.Telemetry.Tracing.ServiceVersion = version
+	tracerProvider, err := tracing.Setup(ctx, cfg.Telemetry.Tracing)
 	if err != nil {
-		log.Fatalf("Failed to load configuration: %v", err)
+		fatal("Failed to configure tracing", err)
 	}
 
 	// Create Echo instance
 	e := echo.New()
 	e.HideBanner = true
+	e.HidePort = true
 
 	// Configure validator
 	e.Validator = &CustomValidator{}
 
-	// Middleware
-	e.Use(middleware.Logger())
-	e.Use(middleware.Recover())
+	// Metrics wrap every other middleware so they see final status codes
+	appMetrics := metrics.New()
+	e.Use(appMetrics.Middleware(func(c echo.Context) bool {
+		return c.Path() == "/metrics"
+	}))
+
+	// Middleware; allowed origins are reloadable
+	corsOrigins := newOriginList(cfg.Server.CORSOrigins)
 	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
-		AllowOrigins: cfg.CORSOrigins,
-		AllowMethods: []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete, http.MethodOptions},
-		AllowHeaders: []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization},
+		AllowOriginFunc: corsOrigins.Allow,
+		AllowMethods:    []string{http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodDelete, http.MethodOptions},
+		AllowHeaders:    []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization, handlers.HeaderIfNoneMatch, handlers.HeaderIfModifiedSince, auth.HeaderAPIKey, idempotency.HeaderIdempotencyKey, correlation.HeaderCorrelationID},
+		ExposeHeaders:   []string{correlation.HeaderCorrelationID, handlers.HeaderETag, echo.HeaderLastModified, ratelimit.HeaderRateLimitLimit, ratelimit.HeaderRateLimitRemaining, ratelimit.HeaderRateLimitReset, ratelimit.HeaderRateLimitPolicy, ratelimit.HeaderRetryAfter},
 	}))
 
 	// Request ID middleware
 	e.Use(middleware.RequestID())
 
+	// Correlation ID shared with notifier-express and bff-nest
+	e.Use(correlation.Middleware(correlation.Config{}))
+
+	// Tracing continues the caller's W3C trace context
+	e.Use(tracing.Middleware(tracing.Config{
+		Skipper: func(c echo.Context) bool {
+			return c.Path() == "/metrics"
+		},
+	}))
+
+	// Access log with request, correlation and trace IDs; Recover runs inside
+	// it so panics are logged as 500 responses
+	e.Use(logging.Middleware(logging.Config{}))
+	e.Use(middleware.Recover())
+
 	// Timeout middleware
 	e.Use(middleware.TimeoutWithConfig(middleware.TimeoutConfig{
-		Timeout: 30 * time.Second,
+		// The timeout handler buffers the whole response, which would defeat streaming exports
+		Skipper: func(c echo.Context) bool {
+			return c.Path() == "/api/v1/users/export"
+		},
+		Timeout: cfg.Server.RequestTimeout,
 	}))
 
+	// Health checks probe dependencies; readiness fails once shutdown starts
+	checks := gohealth.New()
+	checks.Register(gohealth.Check{Name: "memory_heap", Checker: gohealth.Memory(512 << 20)}, gohealth.ProbeLiveness)
+	checks.Register(gohealth.Check{Name: "goroutines", Checker: gohealth.Goroutines(10000)}, gohealth.ProbeLiveness)
+
+	// Client IPs come from the peer address unless it is a trusted proxy, so
+	// callers cannot pick their rate limit bucket with X-Forwarded-For
+	e.IPExtractor = ipExtractor(cfg.Server.TrustedProxies)
+
+	// The per IP limit runs before authentication so rejected credentials
+	// are limited too
+	ipRateLimitRuleSet := ratelimit.NewRuleSet(ipRateLimitRules(cfg.RateLimit))
+	if cfg.RateLimit.Enabled {
+		e.Use(ratelimit.Middleware(ratelimit.Config{
+			Skipper: func(c echo.Context) bool {
+				return !strings.HasPrefix(c.Path(), "/api/")
+			},
+			KeyFunc: ratelimit.IPKey,
+			RuleSet: ipRateLimitRuleSet,
+		}))
+	}
+
+	// Authentication for API routes
+	if cfg.Auth.Enabled {
+		authenticators, err := buildAuthenticators(cfg.Auth, checks)
+		if err != nil {
+			fatal("Failed to configure authentication", err)
+		}
+		e.Use(auth.Middleware(auth.Config{
+			Skipper: func(c echo.Context) bool {
+				return !strings.HasPrefix(c.Path(), "/api/")
+			},
+			Authenticators: authenticators,
+		}))
+	}
+
+	// Rate limiting per client, after authentication so principals are known.
+	// Limits are reloadable.
+	rateLimitRuleSet := ratelimit.NewRuleSet(rateLimitRules(cfg.RateLimit))
+	if cfg.RateLimit.Enabled {
+		e.Use(ratelimit.Middleware(ratelimit.Config{
+			Skipper: func(c echo.Context) bool {
+				return !strings.HasPrefix(c.Path(), "/api/")
+			},
+			RuleSet: rateLimitRuleSet,
+		}))
+	}
+
+	// Idempotency-Key middleware for POST retries. Imports are skipped: their
+	// uploads are streamed to disk and would otherwise be read into memory.
+	idempotencyConfig := idempotency.DefaultConfig
+	idempotencyConfig.Skipper = func(c echo.Context) bool {
+		return c.Request().Method != http.MethodPost || c.Path() == "/api/v1/users/import"
+	}
+	// Keys are held no longer than a request can run
+	idempotencyConfig.LockTTL = 2 * cfg.Server.RequestTimeout
+	e.Use(idempotency.MiddlewareWithConfig(idempotencyConfig))
+
 	// Initialize user service
-	userRepository := gouser.NewInMemoryUserRepository()
-	userEvents := &UserEventsLogger{}
-	userService := gouser.NewUserService(userRepository, userEvents)
+	userTracer := tracing.NewUserTracer(tracerProvider)
+	clock := gouser.SystemClock
+	store, err := storage.Open(ctx, cfg.Storage, gouser.WithClock(clock))
+	if err != nil {
+		fatal("Failed to open storage", err)
+	}
+	defer store.Close()
+	if cfg.Storage.Migrate {
+		if err := migrateOnStartup(ctx, store); err != nil {
+			fatal("Failed to migrate storage", err)
+		}
+	}
+	userRepository := gouser.NewInstrumentedRepository(
+		gouser.NewTracingRepository(store.Repository, userTracer),
+		appMetrics,
+	)
+	userEvents := gouser.MultiUserEvents{&UserEventsLogger{}, appMetrics.UserEvents()}
+	appMetrics.RegisterUserCount(userRepository)
+	// The cache sits outside the instrumentation so repository metrics only
+	// count lookups that reach storage
+	var serviceRepository gouser.UserRepository = userRepository
+	if cfg.Cache.Enabled {
+		cache := gouser.NewCachingRepository(userRepository, gouser.CacheOptions{
+			Size:        cfg.Cache.Size,
+			TTL:         cfg.Cache.TTL,
+			NegativeTTL: cfg.Cache.NegativeTTL,
+			Clock:       clock,
+			Observer:    appMetrics,
+		})
+		serviceRepository = cache
+		userEvents = append(userEvents, cache)
+	}
+	// Faults wrap the cache so cached reads fail too. Injected errors and
+	// latency never reach storage, so repository metrics and spans omit them.
+	var faults *gouser.FaultInjectingRepository
+	if cfg.Faults.Configured() {
+		faults = gouser.NewFaultInjectingRepository(serviceRepository, cfg.Faults.FaultConfig())
+		faults.SetEnabled(cfg.Faults.Enabled)
+		serviceRepository = faults
+		slog.Warn("Storage fault injection configured", "enabled", cfg.Faults.Enabled, "admin", cfg.Faults.Admin)
+	}
+	serviceOpts := []gouser.UserServiceOption{gouser.WithTracer(userTracer), gouser.WithServiceClock(clock)}
+	if cfg.Auth.PolicyFile != "" {
+		policy, err := gouser.LoadRBACPolicy(cfg.Auth.PolicyFile)
+		if err != nil {
+			fatal("Failed to load authorization policy", err)
+		}
+		serviceOpts = append(serviceOpts, gouser.WithPolicy(policy))
+	}
+	userService := gouser.NewUserService(serviceRepository, userEvents, serviceOpts...)
+	checks.Register(gohealth.Check{
+		Name:     "repository",
+		Checker:  gohealth.Ping(userRepository),
+		Critical: true,
+		CacheTTL: time.Second,
+	}, gohealth.ProbeReadiness, gohealth.ProbeStartup)
+	if cfg.Notifier.URL != "" {
+		notifierClient := &http.Client{Transport: correlation.NewTransport(tracing.NewTransport(nil))}
+		checks.Register(gohealth.Check{
+			Name:     "notifier",
+			Checker:  gohealth.HTTP(notifierClient, strings.TrimSuffix(cfg.Notifier.URL, "/")+"/health"),
+			Timeout:  cfg.Notifier.Timeout,
+			CacheTTL: 10 * time.Second,
+		})
+	}
 
 	// Initialize handlers
-	healthHandler := handlers.NewHealthHandler(version)
-	userHandler := handlers.NewUserHandler(userService)
+	healthHandler := handlers.NewHealthHandler(version).WithChecks(checks).WithClock(clock)
+	userHandler := handlers.NewUserHandler(userService).
+		WithIDValidator(gouser.WellFormedID).
+		WithCacheControl(cfg.Server.CacheControl)
+	importHandler := handlers.NewImportHandler(userService)
 
 	// Routes
-	setupRoutes(e, healthHandler, userHandler)
+	setupRoutes(e, healthHandler, userHandler, importHandler)
+	e.GET("/metrics", echo.WrapHandler(appMetrics.Handler()))
+	if cfg.Faults.Admin {
+		setupFaultRoutes(e, handlers.NewFaultsHandler(faults))
+	}
+
+	// Log level, CORS origins and rate limits follow SIGHUP and config file changes
+	watcher := config.NewWatcher(cfg, config.LoadOptions{Args: configArgs})
+	watcher.OnReload(func(cfg *config.Config) {
+		logLevel.Set(logging.ParseLevel(cfg.Log.Level))
+		corsOrigins.Set(cfg.Server.CORSOrigins)
+		rateLimitRuleSet.Set(rateLimitRules(cfg.RateLimit))
+		ipRateLimitRuleSet.Set(ipRateLimitRules(cfg.RateLimit))
+	})
+	watchCtx, stopWatching := context.WithCancel(ctx)
+	defer stopWatching()
+	go watcher.Run(watchCtx, 5*time.Second)
 
 	// Start server
-	startServer(e, cfg.Port)
+	checks.MarkStarted()
+	startServer(e, cfg.Server, checks, tracerProvider.Shutdown)
+	closeLogs()
+}
+
+// ipRateLimitRules limits every API request per client IP
+func ipRateLimitRules(cfg config.RateLimitConfig) []ratelimit.Rule {
+	return []ratelimit.Rule{{Name: "ip", Limit: ratelimit.Limit(cfg.IP)}}
+}
+
+// ipExtractor reads the client IP from X-Forwarded-For when the peer is one
+// of the trusted proxies, and uses the peer address otherwise
+func ipExtractor(trustedProxies []string) echo.IPExtractor {
+	if len(trustedProxies) == 0 {
+		return echo.ExtractIPDirect()
+	}
+	options := []echo.TrustOption{echo.TrustLoopback(false), echo.TrustLinkLocal(false), echo.TrustPrivateNet(false)}
+	for _, proxy := range trustedProxies {
+		// Validated by config.Validate
+		if _, network, err := net.ParseCIDR(proxy); err == nil {
+			options = append(options, echo.TrustIPRange(network))
+		}
+	}
+	return echo.ExtractIPFromXFFHeader(options...)
+}
+
+// rateLimitRules maps the configured limits to route groups. Imports are
+// matched first so they do not also consume the write limit.
+func rateLimitRules(cfg config.RateLimitConfig) []ratelimit.Rule {
+	return []ratelimit.Rule{
+		{
+			Name:  "import",
+			Match: ratelimit.MatchRoutes([]string{http.MethodPost}, "/api/v1/users/import"),
+			Limit: ratelimit.Limit(cfg.Import),
+		},
+		{
+			Name:  "write",
+			Match: ratelimit.MatchRoutes([]string{http.MethodPost, http.MethodPut, http.MethodDelete}),
+			Limit: ratelimit.Limit(cfg.Write),
+		},
+		{
+			Name:  "read",
+			Match: ratelimit.MatchRoutes([]string{http.MethodGet, http.MethodHead}),
+			Limit: ratelimit.Limit(cfg.Read),
+		},
+	}
+}
+
+// originList holds the allowed CORS origins; "*" allows any origin
+type originList struct {
+	origins atomic.Pointer[[]string]
+}
+
+func newOriginList(origins []string) *originList {
+	list := &originList{}
+	list.Set(origins)
+	return list
+}
+
+// Set replaces the allowed origins
+func (l *originList) Set(origins []string) {
+	l.origins.Store(&origins)
+}
+
+// Allow reports whether origin is allowed, as CORSConfig.AllowOriginFunc
+func (l *originList) Allow(origin string) (bool, error) {
+	for _, allowed := range *l.origins.Load() {
+		if allowed == "*" || allowed == origin {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// buildAuthenticators creates the configured authenticators, JWT first. A
+// remote JWKS endpoint is registered as a non-critical readiness check since
+// cached keys keep verifying tokens while it is down.
+func buildAuthenticators(cfg config.AuthConfig, checks *gohealth.Health) ([]auth.Authenticator, error) {
+	authenticators := make([]auth.Authenticator, 0, 2)
+
+	var keySet auth.KeySet
+	switch {
+	case cfg.JWKSFile != "":
+		set, err := auth.LoadJWKSFile(cfg.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		keySet = set
+	case cfg.JWKSURL != "":
+		remote := auth.NewRemoteKeySet(cfg.JWKSURL, 5*time.Minute).WithClient(&http.Client{
+			Timeout:   5 * time.Second,
+			Transport: correlation.NewTransport(tracing.NewTransport(nil)),
+		})
+		checks.Register(gohealth.Check{Name: "jwks", Checker: gohealth.Ping(remote), CacheTTL: 30 * time.Second})
+		keySet = remote
+	case cfg.JWTSecret != "":
+		keySet = auth.NewHMACKeySet([]byte(cfg.JWTSecret))
+	}
+	if keySet != nil {
+		authenticators = append(authenticators, auth.NewJWTAuthenticator(auth.JWTConfig{
+			KeySet:    keySet,
+			Issuer:    cfg.JWTIssuer,
+			Audience:  cfg.JWTAudience,
+			ClockSkew: cfg.ClockSkew,
+		}))
+	}
+
+	if cfg.APIKeys != "" {
+		keys, err := auth.ParseAPIKeys(cfg.APIKeys)
+		if err != nil {
+			return nil, err
+		}
+		apiKeyAuthenticator, err := auth.NewAPIKeyAuthenticator(keys)
+		if err != nil {
+			return nil, err
+		}
+		authenticators = append(authenticators, apiKeyAuthenticator)
+	}
+
+	return authenticators, nil
+}
+
+// migrateOnStartup applies the pending schema migrations of the store.
+// Drivers without migrations are left as they are.
+func migrateOnStartup(ctx context.Context, store *storage.Store) error {
+	migrator, err := store.Migrator()
+	if errors.Is(err, storage.ErrNoMigrations) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	applied, err := migrator.Up(ctx)
+	for _, migration := range applied {
+		slog.Info("Migration applied", "version", migration.Version, "name", migration.Name)
+	}
+	return err
+}
+
+// logSink buffers JSON logs outside development. It is closed on exit so
+// buffered records are not lost.
+var logSink gologger.Sink
+
+// newLogSink writes logger-node entries to stdout, or to a rotating file
+// when configured, through an async buffer. Development keeps text output.
+func newLogSink(cfg *config.Config) (gologger.Sink, error) {
+	if logging.FormatFor(cfg.Environment) != logging.FormatJSON {
+		return nil, nil
+	}
+
+	// Failed file rotations count as errors of the async sink
+	sinkMetrics := gologger.NewMetrics()
+	var sink gologger.Sink = gologger.NewStdoutSink()
+	if cfg.Log.File != "" {
+		fileSink, err := gologger.NewFileSink(gologger.FileOptions{Path: cfg.Log.File, Metrics: sinkMetrics})
+		if err != nil {
+			return nil, err
+		}
+		sink = fileSink
+	}
+	sink = gologger.Enrich(sink, gologger.Enrichment{Environment: cfg.Environment, Version: version})
+	return gologger.NewAsyncSink(sink, gologger.AsyncOptions{FlushInterval: time.Second, Metrics: sinkMetrics}), nil
+}
+
+// closeLogs flushes and closes the log sink, if any
+func closeLogs() {
+	if logSink == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := logSink.Close(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to close log sink: %v\n", err)
+	}
+}
+
+// newRedactor builds the log redactor from the rules file, or from the rules
+// shared with logger-node. User contact fields keep enough of their value to
+// tell records apart.
+func newRedactor(rulesFile string) (*redaction.Redactor, error) {
+	rules := redaction.DefaultRules()
+	if rulesFile != "" {
+		var err error
+		if rules, err = redaction.LoadRules(rulesFile); err != nil {
+			return nil, err
+		}
+	}
+	return redaction.New(rules,
+		redaction.WithKey(gouser.FieldEmail, redaction.Email()),
+		redaction.WithKey(gouser.FieldPhone, redaction.Partial(0, 4)),
+		redaction.WithKey(gouser.FieldAddress, nil),
+		redaction.WithPatternStrategy("email", redaction.Email()),
+	)
 }
 
 // UserEventsLogger implements UserEvents interface for logging
 type UserEventsLogger struct{}
 
 func (l *UserEventsLogger) OnUserCreated(user *gouser.User) {
-	log.Printf("User created: ID=%s, Name=%s, Email=%s", user.ID, user.Name, user.Email)
+	l.OnUserCreatedContext(context.Background(), user)
 }
 
 func (l *UserEventsLogger) OnUserUpdated(user *gouser.User) {
-	log.Printf("User updated: ID=%s, Name=%s, Email=%s", user.ID, user.Name, user.Email)
+	l.OnUserUpdatedContext(context.Background(), user)
 }
 
 func (l *UserEventsLogger) OnUserDeleted(userID string) {
-	log.Printf("User deleted: ID=%s", userID)
+	l.OnUserDeletedContext(context.Background(), userID)
+}
+
+func (l *UserEventsLogger) OnUserCreatedContext(ctx context.Context, user *gouser.User) {
+	slog.InfoContext(ctx, "User created", "userId", user.ID, "name", user.Name, "email", user.Email, "actor", actor(ctx))
+}
+
+func (l *UserEventsLogger) OnUserUpdatedContext(ctx context.Context, user *gouser.User) {
+	slog.InfoContext(ctx, "User updated", "userId", user.ID, "name", user.Name, "email", user.Email, "actor", actor(ctx))
+}
+
+func (l *UserEventsLogger) OnUserDeletedContext(ctx context.Context, userID string) {
+	slog.InfoContext(ctx, "User deleted", "userId", userID, "actor", actor(ctx))
+}
+
+// actor describes the principal of ctx for log lines
+func actor(ctx context.Context) string {
+	if principal, ok := gouser.PrincipalFromContext(ctx); ok {
+		return principal.Type + ":" + principal.ID
+	}
+	return "anonymous"
 }
 
 // setupRoutes configures all routes
-func setupRoutes(e *echo.Echo, healthHandler *handlers.HealthHandler, userHandler *handlers.UserHandler) {
+func setupRoutes(e *echo.Echo, healthHandler *handlers.HealthHandler, userHandler *handlers.UserHandler, importHandler *handlers.ImportHandler) {
 	// Health check routes
 	e.GET("/health", healthHandler.Health)
 	e.GET("/health/ready", healthHandler.Ready)
 	e.GET("/health/live", healthHandler.Live)
+	e.GET("/health/startup", healthHandler.Startup)
 
 	// API routes
 	api := e.Group("/api/v1")
 	{
 		users := api.Group("/users")
 		{
-			users.POST("", userHandler.Create)
-			users.GET("", userHandler.GetAll)
-			users.GET("/:id", userHandler.GetByID)
-			users.PUT("/:id", userHandler.Update)
-			users.DELETE("/:id", userHandler.Delete)
+			// Exports and imports pick their own formats
+			users.POST("", userHandler.Create, userHandler.Negotiate)
+			users.GET("", userHandler.GetAll, userHandler.Negotiate)
+			users.HEAD("", userHandler.GetAll, userHandler.Negotiate)
+			users.GET("/export", userHandler.Export)
+			users.GET("/:id", userHandler.GetByID, userHandler.Negotiate)
+			users.HEAD("/:id", userHandler.GetByID, userHandler.Negotiate)
+			users.PUT("/:id", userHandler.Update, userHandler.Negotiate)
+			users.DELETE("/:id", userHandler.Delete, userHandler.Negotiate)
+
+			// Bulk import runs as an async job polled by ID
+			users.POST("/import", importHandler.Create)
+			users.GET("/import/:jobId", importHandler.Get)
 		}
 	}
 
@@ -121,31 +552,54 @@ func setupRoutes(e *echo.Echo, healthHandler *handlers.HealthHandler, userHandle
 	})
 }
 
-// startServer starts the HTTP server with graceful shutdown
-func startServer(e *echo.Echo, port string) {
+// setupFaultRoutes exposes fault injection to QA in non-production deployments
+func setupFaultRoutes(e *echo.Echo, faultsHandler *handlers.FaultsHandler) {
+	e.GET("/api/v1/admin/faults", faultsHandler.Get)
+	e.PUT("/api/v1/admin/faults", faultsHandler.Update)
+}
+
+// startServer starts the HTTP server with graceful shutdown. Readiness fails
+// as soon as a signal arrives; the server keeps serving for the shutdown
+// delay so load balancers stop routing to it before connections are closed.
+func startServer(e *echo.Echo, cfg config.ServerConfig, checks *gohealth.Health, shutdownHooks ...func(context.Context) error) {
+	port := cfg.Port
 	// Start server in a goroutine
 	go func() {
 		if err := e.Start(":" + port); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Failed to start server: %v", err)
+			fatal("Failed to start server", err)
 		}
 	}()
 
-	log.Printf("Server started on port %s", port)
+	slog.Info("Server started", "port", port)
 
 	// Wait for interrupt signal to gracefully shutdown the server
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
 	<-quit
 
-	log.Println("Shutting down server...")
+	slog.Info("Shutting down server", "drainDelay", cfg.ShutdownDelay.String())
+	checks.Shutdown()
+	time.Sleep(cfg.ShutdownDelay)
 
 	// Graceful shutdown with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
 	defer cancel()
 
 	if err := e.Shutdown(ctx); err != nil {
-		log.Fatalf("Server forced to shutdown: %v", err)
+		fatal("Server forced to shutdown", err)
 	}
+	for _, hook := range shutdownHooks {
+		if err := hook(ctx); err != nil {
+			slog.Error("Shutdown hook failed", "error", err)
+		}
+	}
+
+	slog.Info("Server exited")
+}
 
-	log.Println("Server exited")
+// fatal logs err and exits
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	closeLogs()
+	os.Exit(1)
 }
@@ -1,8 +1,11 @@
 package main
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
+	"github.com/labstack/echo/v4"
 	gouser "github.com/mateusmacedo/scouts/libs/user-go"
 )
 
@@ -12,3 +15,28 @@ func TestGoUser(t *testing.T) {
 		t.Error("Expected GoUser to append 'john doe', got:", result)
 	}
 }
+
+func TestIPExtractor(t *testing.T) {
+	request := func(remoteAddr string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set(echo.HeaderXForwardedFor, "203.0.113.9")
+		return req
+	}
+
+	t.Run("should ignore X-Forwarded-For without trusted proxies", func(t *testing.T) {
+		if ip := ipExtractor(nil)(request("10.0.0.1:1234")); ip != "10.0.0.1" {
+			t.Errorf("Expected 10.0.0.1, got %s", ip)
+		}
+	})
+
+	t.Run("should only trust X-Forwarded-For from trusted proxies", func(t *testing.T) {
+		extract := ipExtractor([]string{"10.0.0.0/8"})
+		if ip := extract(request("10.0.0.1:1234")); ip != "203.0.113.9" {
+			t.Errorf("Expected 203.0.113.9, got %s", ip)
+		}
+		if ip := extract(request("192.168.0.1:1234")); ip != "192.168.0.1" {
+			t.Errorf("Expected 192.168.0.1, got %s", ip)
+		}
+	})
+}
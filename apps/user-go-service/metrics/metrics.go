@@ -0,0 +1,176 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	gouser "github.com/mateusmacedo/scouts/libs/user-go"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// unmatchedRoute labels requests that matched no route, keeping label cardinality bounded
+const unmatchedRoute = "unmatched"
+
+// Metrics holds the Prometheus collectors of the service
+type Metrics struct {
+	registry *prometheus.Registry
+
+	requests        *prometheus.CounterVec
+	requestErrors   *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+
+	userEvents         *prometheus.CounterVec
+	repositoryDuration *prometheus.HistogramVec
+	cacheLookups       *prometheus.CounterVec
+}
+
+// New creates the service metrics on a dedicated registry, including Go
+// runtime and process collectors
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "Total HTTP requests by method, route and status code.",
+		}, []string{"method", "route", "status"}),
+		requestErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_request_errors_total",
+			Help: "Total HTTP requests answered with a 5xx status code.",
+		}, []string{"method", "route"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "HTTP request duration in seconds.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+		userEvents: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "gouser_user_events_total",
+			Help: "Total user domain events by type.",
+		}, []string{"event"}),
+		repositoryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "gouser_repository_operation_duration_seconds",
+			Help:    "User repository operation duration in seconds.",
+			Buckets: []float64{.0001, .0005, .001, .005, .01, .05, .1, .5, 1},
+		}, []string{"operation", "outcome"}),
+		cacheLookups: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "gouser_cache_lookups_total",
+			Help: "Total user cache lookups by result.",
+		}, []string{"result"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests,
+		m.requestErrors,
+		m.requestDuration,
+		m.userEvents,
+		m.repositoryDuration,
+		m.cacheLookups,
+	)
+	return m
+}
+
+// Registry returns the registry backing the metrics endpoint
+func (m *Metrics) Registry() *prometheus.Registry {
+	return m.registry
+}
+
+// Handler serves the metrics in Prometheus exposition format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// Middleware records rate, errors and duration of requests per route template
+func (m *Metrics) Middleware(skipper middleware.Skipper) echo.MiddlewareFunc {
+	if skipper == nil {
+		skipper = middleware.DefaultSkipper
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if skipper(c) {
+				return next(c)
+			}
+
+			start := time.Now()
+			err := next(c)
+			if err != nil {
+				// Let Echo's error handler set the final status before recording
+				c.Error(err)
+			}
+
+			route := c.Path()
+			if route == "" {
+				route = unmatchedRoute
+			}
+			method := c.Request().Method
+			status := c.Response().Status
+
+			m.requests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+			if status >= http.StatusInternalServerError {
+				m.requestErrors.WithLabelValues(method, route).Inc()
+			}
+			m.requestDuration.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+			return nil
+		}
+	}
+}
+
+// ObserveRepositoryOperation implements gouser.RepositoryObserver
+func (m *Metrics) ObserveRepositoryOperation(operation string, duration time.Duration, err error) {
+	outcome := "success"
+	if err != nil {
+		outcome = "error"
+	}
+	m.repositoryDuration.WithLabelValues(operation, outcome).Observe(duration.Seconds())
+}
+
+// ObserveCacheLookup implements gouser.CacheObserver
+func (m *Metrics) ObserveCacheLookup(result string) {
+	m.cacheLookups.WithLabelValues(result).Inc()
+}
+
+// RegisterUserCount exposes the current number of users, counted at scrape time
+func (m *Metrics) RegisterUserCount(repository gouser.UserRepository) {
+	m.registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "gouser_users",
+		Help: "Current number of users.",
+	}, func() float64 {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		count, err := gouser.CountUsers(ctx, repository)
+		if err != nil {
+			return 0
+		}
+		return float64(count)
+	}))
+}
+
+// UserEvents returns a gouser.UserEvents handler counting domain events
+func (m *Metrics) UserEvents() gouser.UserEvents {
+	return userEventsCounter{events: m.userEvents}
+}
+
+// userEventsCounter counts user domain events
+type userEventsCounter struct {
+	events *prometheus.CounterVec
+}
+
+func (u userEventsCounter) OnUserCreated(user *gouser.User) {
+	u.events.WithLabelValues("created").Inc()
+}
+
+func (u userEventsCounter) OnUserUpdated(user *gouser.User) {
+	u.events.WithLabelValues("updated").Inc()
+}
+
+func (u userEventsCounter) OnUserDeleted(userID string) {
+	u.events.WithLabelValues("deleted").Inc()
+}
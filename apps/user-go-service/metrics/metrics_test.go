@@ -0,0 +1,102 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	gouser "github.com/mateusmacedo/scouts/libs/user-go"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware(t *testing.T) {
+	m := New()
+	e := echo.New()
+	e.HideBanner = true
+	e.Use(m.Middleware(func(c echo.Context) bool { return c.Path() == "/metrics" }))
+	e.GET("/users/:id", func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+	e.GET("/fail", func(c echo.Context) error { return errors.New("boom") })
+	e.GET("/metrics", echo.WrapHandler(m.Handler()))
+
+	for _, path := range []string{"/users/1", "/users/2", "/fail", "/missing"} {
+		e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	t.Run("should count requests per route template", func(t *testing.T) {
+		assert.Equal(t, 2.0, testutil.ToFloat64(m.requests.WithLabelValues("GET", "/users/:id", "200")))
+		assert.Equal(t, 1.0, testutil.ToFloat64(m.requests.WithLabelValues("GET", "/fail", "500")))
+	})
+
+	t.Run("should count server errors", func(t *testing.T) {
+		assert.Equal(t, 1.0, testutil.ToFloat64(m.requestErrors.WithLabelValues("GET", "/fail")))
+		assert.Equal(t, 0.0, testutil.ToFloat64(m.requestErrors.WithLabelValues("GET", "/users/:id")))
+	})
+
+	t.Run("should expose metrics in exposition format", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		body := rec.Body.String()
+		assert.Contains(t, body, `http_request_duration_seconds_bucket{method="GET",route="/users/:id",le="0.005"}`)
+		assert.Contains(t, body, "go_goroutines")
+		assert.NotContains(t, body, `route="/metrics"`)
+		assert.NotContains(t, body, `route="/missing"`)
+	})
+}
+
+func TestDomainMetrics(t *testing.T) {
+	ctx := context.Background()
+	m := New()
+	repository := gouser.NewInstrumentedRepository(gouser.NewInMemoryUserRepository(), m)
+	m.RegisterUserCount(repository)
+	service := gouser.NewUserService(repository, m.UserEvents())
+
+	user, err := service.Create(ctx, gouser.CreateUserData{Name: "John Doe", Email: "john@example.com"})
+	require.NoError(t, err)
+	service.Create(ctx, gouser.CreateUserData{Name: "Jane Doe", Email: "jane@example.com"})
+	name := "John Smith"
+	service.Update(ctx, user.ID, gouser.UpdateUserData{Name: &name})
+	service.Delete(ctx, user.ID)
+
+	t.Run("should count user events", func(t *testing.T) {
+		assert.Equal(t, 2.0, testutil.ToFloat64(m.userEvents.WithLabelValues("created")))
+		assert.Equal(t, 1.0, testutil.ToFloat64(m.userEvents.WithLabelValues("updated")))
+		assert.Equal(t, 1.0, testutil.ToFloat64(m.userEvents.WithLabelValues("deleted")))
+	})
+
+	t.Run("should observe repository operations and user count", func(t *testing.T) {
+		m.ObserveRepositoryOperation(gouser.RepositoryOpFindAll, time.Millisecond, errors.New("down"))
+
+		expected := `
+# HELP gouser_users Current number of users.
+# TYPE gouser_users gauge
+gouser_users 1
+`
+		assert.NoError(t, testutil.GatherAndCompare(m.Registry(), strings.NewReader(expected), "gouser_users"))
+
+		count, err := testutil.GatherAndCount(m.Registry(), "gouser_repository_operation_duration_seconds")
+		require.NoError(t, err)
+		// create, find_all, update, find_by_id, delete, count, plus the find_all error
+		assert.GreaterOrEqual(t, count, 6)
+	})
+}
+
+func TestCacheMetrics(t *testing.T) {
+	m := New()
+	repository := gouser.NewCachingRepository(gouser.NewInMemoryUserRepository(), gouser.CacheOptions{Observer: m})
+	repository.FindByID(context.Background(), "1")
+	repository.FindByID(context.Background(), "1")
+
+	t.Run("should count cache lookups by result", func(t *testing.T) {
+		assert.Equal(t, 1.0, testutil.ToFloat64(m.cacheLookups.WithLabelValues(gouser.CacheMiss)))
+		assert.Equal(t, 1.0, testutil.ToFloat64(m.cacheLookups.WithLabelValues(gouser.CacheNegativeHit)))
+	})
+}
@@ -0,0 +1,178 @@
+package ratelimit
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/mateusmacedo/scouts/apps/user-go-service/handlers"
+	gouser "github.com/mateusmacedo/scouts/libs/user-go"
+)
+
+// Rate limit response headers (draft-ietf-httpapi-ratelimit-headers)
+const (
+	HeaderRateLimitLimit     = "RateLimit-Limit"
+	HeaderRateLimitRemaining = "RateLimit-Remaining"
+	HeaderRateLimitReset     = "RateLimit-Reset"
+	HeaderRateLimitPolicy    = "RateLimit-Policy"
+	HeaderRetryAfter         = "Retry-After"
+)
+
+// Rule applies a limit to the requests it matches. Each rule has its own
+// buckets, so route groups are limited independently.
+type Rule struct {
+	// Name identifies the rule in bucket keys
+	Name string
+	// Match selects the requests limited by this rule. Nil matches all.
+	Match func(c echo.Context) bool
+	Limit Limit
+}
+
+// KeyFunc identifies the client a request is charged to
+type KeyFunc func(c echo.Context) string
+
+// Config defines the config for the rate limit middleware
+type Config struct {
+	// Skipper defines a function to skip middleware
+	Skipper middleware.Skipper
+	// Store keeps the token buckets. Defaults to a MemoryStore.
+	Store Store
+	// KeyFunc identifies the client. Defaults to ClientKey.
+	KeyFunc KeyFunc
+	// Rules are evaluated in order; the first matching rule applies
+	Rules []Rule
+	// RuleSet replaces Rules with rules that can change at runtime
+	RuleSet *RuleSet
+}
+
+// RuleSet holds rules that can be replaced while requests are served, e.g.
+// on configuration reload. Buckets are kept, so clients are not reset.
+type RuleSet struct {
+	rules atomic.Pointer[[]Rule]
+}
+
+// NewRuleSet creates a rule set holding rules
+func NewRuleSet(rules []Rule) *RuleSet {
+	set := &RuleSet{}
+	set.Set(rules)
+	return set
+}
+
+// Set replaces the rules
+func (s *RuleSet) Set(rules []Rule) {
+	s.rules.Store(&rules)
+}
+
+// Rules returns the current rules
+func (s *RuleSet) Rules() []Rule {
+	return *s.rules.Load()
+}
+
+// Middleware returns a token bucket rate limit middleware. Requests matching
+// no rule are not limited. Store errors fail open so an unavailable shared
+// store does not take the API down.
+func Middleware(config Config) echo.MiddlewareFunc {
+	if config.Skipper == nil {
+		config.Skipper = middleware.DefaultSkipper
+	}
+	if config.Store == nil {
+		config.Store = NewMemoryStore()
+	}
+	if config.KeyFunc == nil {
+		config.KeyFunc = ClientKey
+	}
+	if config.RuleSet == nil {
+		config.RuleSet = NewRuleSet(config.Rules)
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) {
+				return next(c)
+			}
+
+			rule := matchRule(c, config.RuleSet.Rules())
+			if rule == nil {
+				return next(c)
+			}
+
+			key := rule.Name + ":" + config.KeyFunc(c)
+			result, err := config.Store.Take(c.Request().Context(), key, rule.Limit)
+			if err != nil {
+				slog.ErrorContext(c.Request().Context(), "Rate limit store error", "error", err)
+				return next(c)
+			}
+
+			header := c.Response().Header()
+			header.Set(HeaderRateLimitLimit, strconv.Itoa(result.Limit))
+			header.Set(HeaderRateLimitRemaining, strconv.Itoa(result.Remaining))
+			header.Set(HeaderRateLimitReset, strconv.Itoa(ceilSeconds(result.Reset)))
+			header.Set(HeaderRateLimitPolicy, fmt.Sprintf("%d;w=%d", rule.Limit.Requests, ceilSeconds(rule.Limit.Period)))
+
+			if !result.Allowed {
+				header.Set(HeaderRetryAfter, strconv.Itoa(ceilSeconds(result.RetryAfter)))
+				return c.JSON(http.StatusTooManyRequests, handlers.ErrorResponse{
+					Error:   "rate_limit_exceeded",
+					Message: "Too many requests, retry later",
+				})
+			}
+			return next(c)
+		}
+	}
+}
+
+// matchRule returns the first rule matching the request
+func matchRule(c echo.Context, rules []Rule) *Rule {
+	for i := range rules {
+		if rules[i].Match == nil || rules[i].Match(c) {
+			return &rules[i]
+		}
+	}
+	return nil
+}
+
+// ClientKey charges requests to the authenticated principal, then to the
+// client IP. Credentials are only trusted once authentication has validated
+// them, so the middleware must run after it.
+func ClientKey(c echo.Context) string {
+	if principal, ok := gouser.PrincipalFromContext(c.Request().Context()); ok {
+		return "principal:" + principal.Type + ":" + principal.ID
+	}
+	return IPKey(c)
+}
+
+// IPKey charges requests to the client IP, as resolved by the Echo
+// IPExtractor. Limits placed before authentication use it.
+func IPKey(c echo.Context) string {
+	return "ip:" + c.RealIP()
+}
+
+// MatchRoutes matches requests whose method and route path are listed.
+// An empty methods list matches any method.
+func MatchRoutes(methods []string, paths ...string) func(c echo.Context) bool {
+	return func(c echo.Context) bool {
+		if len(methods) > 0 && !contains(methods, c.Request().Method) {
+			return false
+		}
+		return len(paths) == 0 || contains(paths, c.Path())
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,185 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mateusmacedo/scouts/apps/user-go-service/auth"
+	gouser "github.com/mateusmacedo/scouts/libs/user-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestServer registers read and write routes behind the middleware
+func newTestServer(config Config) *echo.Echo {
+	e := echo.New()
+	e.HideBanner = true
+	e.Use(Middleware(config))
+	ok := func(c echo.Context) error { return c.NoContent(http.StatusOK) }
+	e.GET("/users", ok)
+	e.POST("/users", ok)
+	e.GET("/health", ok)
+	return e
+}
+
+func doRequest(e *echo.Echo, method, path string, setup func(req *http.Request)) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	req.RemoteAddr = "192.0.2.1:1234"
+	if setup != nil {
+		setup(req)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestMemoryStore(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+	limit := Limit{Requests: 2, Period: time.Second}
+	ctx := context.Background()
+
+	t.Run("should allow bursts up to capacity", func(t *testing.T) {
+		first, err := store.Take(ctx, "client", limit)
+		require.NoError(t, err)
+		second, _ := store.Take(ctx, "client", limit)
+		third, _ := store.Take(ctx, "client", limit)
+
+		assert.True(t, first.Allowed)
+		assert.Equal(t, 1, first.Remaining)
+		assert.True(t, second.Allowed)
+		assert.Equal(t, 0, second.Remaining)
+		assert.False(t, third.Allowed)
+		assert.Equal(t, 500*time.Millisecond, third.RetryAfter)
+		assert.Equal(t, time.Second, third.Reset)
+	})
+
+	t.Run("should refill tokens over time", func(t *testing.T) {
+		now = now.Add(500 * time.Millisecond)
+		result, _ := store.Take(ctx, "client", limit)
+		assert.True(t, result.Allowed)
+
+		result, _ = store.Take(ctx, "client", limit)
+		assert.False(t, result.Allowed)
+	})
+
+	t.Run("should keep separate buckets per key", func(t *testing.T) {
+		result, _ := store.Take(ctx, "other", limit)
+		assert.True(t, result.Allowed)
+	})
+
+	t.Run("should honor burst", func(t *testing.T) {
+		burst := Limit{Requests: 1, Period: time.Minute, Burst: 3}
+		for i := 0; i < 3; i++ {
+			result, _ := store.Take(ctx, "bursty", burst)
+			assert.True(t, result.Allowed)
+			assert.Equal(t, 3, result.Limit)
+		}
+		result, _ := store.Take(ctx, "bursty", burst)
+		assert.False(t, result.Allowed)
+	})
+}
+
+func TestMiddleware(t *testing.T) {
+	config := Config{
+		Skipper: func(c echo.Context) bool { return c.Path() == "/health" },
+		Rules: []Rule{
+			{Name: "write", Match: MatchRoutes([]string{http.MethodPost}), Limit: Limit{Requests: 1, Period: time.Minute}},
+			{Name: "read", Match: MatchRoutes([]string{http.MethodGet}), Limit: Limit{Requests: 2, Period: time.Minute}},
+		},
+	}
+
+	t.Run("should set rate limit headers and reject with 429", func(t *testing.T) {
+		e := newTestServer(config)
+
+		first := doRequest(e, http.MethodPost, "/users", nil)
+		assert.Equal(t, http.StatusOK, first.Code)
+		assert.Equal(t, "1", first.Header().Get(HeaderRateLimitLimit))
+		assert.Equal(t, "0", first.Header().Get(HeaderRateLimitRemaining))
+		assert.Equal(t, "60", first.Header().Get(HeaderRateLimitReset))
+		assert.Equal(t, "1;w=60", first.Header().Get(HeaderRateLimitPolicy))
+
+		second := doRequest(e, http.MethodPost, "/users", nil)
+		assert.Equal(t, http.StatusTooManyRequests, second.Code)
+		assert.Equal(t, "60", second.Header().Get(HeaderRetryAfter))
+		assert.Contains(t, second.Body.String(), "rate_limit_exceeded")
+	})
+
+	t.Run("should limit route groups independently", func(t *testing.T) {
+		e := newTestServer(config)
+
+		assert.Equal(t, http.StatusOK, doRequest(e, http.MethodPost, "/users", nil).Code)
+		read := doRequest(e, http.MethodGet, "/users", nil)
+		assert.Equal(t, http.StatusOK, read.Code)
+		assert.Equal(t, "2", read.Header().Get(HeaderRateLimitLimit))
+		assert.Equal(t, "1", read.Header().Get(HeaderRateLimitRemaining))
+	})
+
+	t.Run("should charge principals and IPs separately", func(t *testing.T) {
+		e := newTestServer(config)
+
+		assert.Equal(t, http.StatusOK, doRequest(e, http.MethodPost, "/users", nil).Code)
+		assert.Equal(t, http.StatusOK, doRequest(e, http.MethodPost, "/users", func(req *http.Request) {
+			*req = *req.WithContext(gouser.WithPrincipal(req.Context(), &gouser.Principal{ID: "1", Type: gouser.PrincipalTypeUser}))
+		}).Code)
+		assert.Equal(t, http.StatusOK, doRequest(e, http.MethodPost, "/users", func(req *http.Request) {
+			req.RemoteAddr = "192.0.2.2:1234"
+		}).Code)
+	})
+
+	t.Run("should not trust unauthenticated credentials or forwarded IPs", func(t *testing.T) {
+		e := newTestServer(config)
+		e.IPExtractor = echo.ExtractIPDirect()
+
+		assert.Equal(t, http.StatusOK, doRequest(e, http.MethodPost, "/users", nil).Code)
+		assert.Equal(t, http.StatusTooManyRequests, doRequest(e, http.MethodPost, "/users", func(req *http.Request) {
+			req.Header.Set(auth.HeaderAPIKey, "random")
+		}).Code)
+		assert.Equal(t, http.StatusTooManyRequests, doRequest(e, http.MethodPost, "/users", func(req *http.Request) {
+			req.Header.Set(echo.HeaderXForwardedFor, "203.0.113.9")
+			req.Header.Set(echo.HeaderXRealIP, "203.0.113.9")
+		}).Code)
+	})
+
+	t.Run("should apply rule set changes", func(t *testing.T) {
+		rules := NewRuleSet(config.Rules)
+		e := newTestServer(Config{RuleSet: rules})
+
+		assert.Equal(t, "2", doRequest(e, http.MethodGet, "/users", nil).Header().Get(HeaderRateLimitLimit))
+		rules.Set([]Rule{{Name: "read", Limit: Limit{Requests: 1, Period: time.Minute}}})
+
+		rec := doRequest(e, http.MethodGet, "/users", nil)
+		assert.Equal(t, "1", rec.Header().Get(HeaderRateLimitLimit))
+		assert.Equal(t, "1;w=60", rec.Header().Get(HeaderRateLimitPolicy))
+	})
+
+	t.Run("should skip configured routes", func(t *testing.T) {
+		e := newTestServer(config)
+
+		rec := doRequest(e, http.MethodGet, "/health", nil)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, rec.Header().Get(HeaderRateLimitLimit))
+	})
+
+	t.Run("should fail open when the store is unavailable", func(t *testing.T) {
+		failing := config
+		failing.Store = failingStore{}
+		e := newTestServer(failing)
+
+		assert.Equal(t, http.StatusOK, doRequest(e, http.MethodPost, "/users", nil).Code)
+		assert.Equal(t, http.StatusOK, doRequest(e, http.MethodPost, "/users", nil).Code)
+	})
+}
+
+// failingStore always returns an error
+type failingStore struct{}
+
+func (failingStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	return Result{}, errors.New("store down")
+}
@@ -0,0 +1,125 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Limit is a token bucket allowing Requests per Period with bursts up to Burst
+type Limit struct {
+	Requests int
+	Period   time.Duration
+	// Burst is the bucket capacity. Defaults to Requests.
+	Burst int
+}
+
+// capacity returns the bucket size
+func (l Limit) capacity() int {
+	if l.Burst > 0 {
+		return l.Burst
+	}
+	return l.Requests
+}
+
+// Result is the outcome of taking a token from a bucket
+type Result struct {
+	Allowed bool
+	// Limit is the bucket capacity
+	Limit int
+	// Remaining is the number of tokens left after this request
+	Remaining int
+	// Reset is the time until the bucket is full again
+	Reset time.Duration
+	// RetryAfter is the time until the next token, set when not allowed
+	RetryAfter time.Duration
+}
+
+// Store keeps token buckets. Implementations must make Take atomic so that
+// concurrent requests cannot spend the same token; shared stores (e.g. Redis)
+// let several replicas enforce one limit.
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// sweepInterval bounds how often the memory store scans for idle buckets
+const sweepInterval = time.Minute
+
+// bucket is the state of one token bucket
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	// full is when the bucket is back to capacity and can be dropped
+	full time.Time
+}
+
+// MemoryStore is a thread-safe in-memory implementation of Store
+type MemoryStore struct {
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+	mutex     sync.Mutex
+}
+
+// NewMemoryStore creates a new in-memory rate limit store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// Take spends one token from the bucket for key
+func (s *MemoryStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	if err := ctx.Err(); err != nil {
+		return Result{}, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	capacity := float64(limit.capacity())
+	rate := float64(limit.Requests) / limit.Period.Seconds()
+
+	b, exists := s.buckets[key]
+	if !exists {
+		b = &bucket{tokens: capacity, updated: now}
+		s.buckets[key] = b
+	}
+	b.tokens = math.Min(capacity, b.tokens+now.Sub(b.updated).Seconds()*rate)
+	b.updated = now
+
+	result := Result{Limit: limit.capacity()}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = secondsToDuration((1 - b.tokens) / rate)
+	}
+	result.Remaining = int(b.tokens)
+	result.Reset = secondsToDuration((capacity - b.tokens) / rate)
+	b.full = now.Add(result.Reset)
+	return result, nil
+}
+
+// sweep removes buckets that have refilled completely. Callers must hold the lock.
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for key, b := range s.buckets {
+		if !now.Before(b.full) {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(math.Ceil(seconds * float64(time.Second)))
+}
@@ -0,0 +1,52 @@
+package redaction
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Handler masks sensitive attributes before delegating to the wrapped
+// handler. Messages are left untouched, as in logger-node; sensitive data
+// belongs in attributes.
+type Handler struct {
+	next     slog.Handler
+	redactor *Redactor
+	groups   []string
+}
+
+// NewHandler wraps next with redactor
+func NewHandler(next slog.Handler, redactor *Redactor) *Handler {
+	return &Handler{next: next, redactor: redactor}
+}
+
+// Enabled delegates to the wrapped handler
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle masks the record attributes and delegates to the wrapped handler
+func (h *Handler) Handle(ctx context.Context, record slog.Record) error {
+	redacted := slog.NewRecord(record.Time, record.Level, record.Message, record.PC)
+	record.Attrs(func(attr slog.Attr) bool {
+		redacted.AddAttrs(h.redactor.Attr(h.groups, attr))
+		return true
+	})
+	return h.next.Handle(ctx, redacted)
+}
+
+// WithAttrs masks attrs once, when they are bound to the logger
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, attr := range attrs {
+		redacted[i] = h.redactor.Attr(h.groups, attr)
+	}
+	return &Handler{next: h.next.WithAttrs(redacted), redactor: h.redactor, groups: h.groups}
+}
+
+// WithGroup keeps track of the group so path rules see the full path
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &Handler{next: h.next.WithGroup(name), redactor: h.redactor, groups: appendPath(h.groups, name)}
+}
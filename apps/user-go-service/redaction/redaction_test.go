@@ -0,0 +1,153 @@
+package redaction
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultRules(t *testing.T) {
+	t.Run("should match the logger-node rule set", func(t *testing.T) {
+		shared, err := LoadRules(filepath.Join("..", "..", "..", "libs", "logger-node", "src", "lib", "redactor", "default-rules.json"))
+		require.NoError(t, err)
+
+		assert.Equal(t, shared, DefaultRules())
+	})
+
+	t.Run("should load rules from a file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "rules.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{"mask":"[REDACTED]","keys":["pin"]}`), 0o600))
+
+		rules, err := LoadRules(path)
+		require.NoError(t, err)
+		r, err := New(rules)
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string]any{"pin": "[REDACTED]", "password": "x"},
+			r.Value(nil, map[string]any{"pin": "1234", "password": "x"}))
+	})
+
+	t.Run("should reject invalid patterns", func(t *testing.T) {
+		_, err := New(Rules{Patterns: []Pattern{{Name: "broken", Source: "("}}})
+		assert.Error(t, err)
+	})
+}
+
+func TestRedactor(t *testing.T) {
+	r := Default()
+
+	t.Run("should mask sensitive keys case-insensitively", func(t *testing.T) {
+		got := r.Value(nil, map[string]any{"user": "john", "Password": "secret123", "apiKey": 42})
+
+		assert.Equal(t, map[string]any{"user": "john", "Password": "***", "apiKey": "***"}, got)
+	})
+
+	t.Run("should mask values matching the default patterns", func(t *testing.T) {
+		cases := map[string]string{
+			"contact john@example.com":              "contact ***",
+			"cpf 123.456.789-09":                    "cpf ***",
+			"cnpj 12.345.678/0001-95":               "cnpj ***",
+			"call +55 11 98765-4321 today":          "call *** today",
+			"hash 4bf92f3577b34da6a3ce929d0e0e4736": "hash ***",
+			"order 123456789":                       "order 123456789",
+		}
+		for input, want := range cases {
+			assert.Equal(t, want, r.String(input), input)
+		}
+	})
+
+	t.Run("should walk nested values and structs", func(t *testing.T) {
+		type account struct {
+			Email  string            `json:"email"`
+			Tokens map[string]string `json:"tokens"`
+		}
+		got := r.Value(nil, []any{account{Email: "a@b.io", Tokens: map[string]string{"token": "t"}}})
+
+		assert.Equal(t, []any{map[string]any{"email": "***", "tokens": map[string]any{"token": "***"}}}, got)
+	})
+
+	t.Run("should stop at the maximum depth", func(t *testing.T) {
+		r := Default(WithMaxDepth(1))
+		got := r.Value(nil, map[string]any{"a": map[string]any{"b": "c"}})
+
+		assert.Equal(t, map[string]any{"a": MaxDepthValue}, got)
+	})
+
+	t.Run("should apply path and pattern strategies", func(t *testing.T) {
+		r := Default(
+			WithKey("user.phone", Partial(0, 4)),
+			WithPatternStrategy("email", Email()),
+		)
+		got := r.Value(nil, map[string]any{
+			"user":  map[string]any{"phone": "11987654321", "note": "mail john@example.com"},
+			"phone": "kept",
+		})
+
+		assert.Equal(t, map[string]any{
+			"user":  map[string]any{"phone": "*******4321", "note": "mail j***@example.com"},
+			"phone": "kept",
+		}, got)
+	})
+
+	t.Run("should redact error messages", func(t *testing.T) {
+		assert.Equal(t, "duplicate ***", r.Value(nil, errors.New("duplicate john@example.com")))
+	})
+}
+
+func TestStrategies(t *testing.T) {
+	t.Run("should mask partially", func(t *testing.T) {
+		assert.Equal(t, "ab****yz", Partial(2, 2)("abcdefyz"))
+		assert.Equal(t, "***", Partial(2, 2)("abc"))
+	})
+
+	t.Run("should keep the length", func(t *testing.T) {
+		assert.Equal(t, "*****", KeepLength()("sécre"))
+	})
+
+	t.Run("should keep the email domain", func(t *testing.T) {
+		assert.Equal(t, "j***@example.com", Email()("john@example.com"))
+		assert.Equal(t, "***", Email()("not-an-email"))
+	})
+}
+
+func TestHandler(t *testing.T) {
+	var buf bytes.Buffer
+	r := Default(WithKey("email", Email()))
+	logger := slog.New(NewHandler(slog.NewJSONHandler(&buf, nil), r))
+
+	decode := func() map[string]any {
+		t.Helper()
+		var record map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+		buf.Reset()
+		return record
+	}
+
+	t.Run("should mask record attributes", func(t *testing.T) {
+		logger.Info("User created", "userId", "1", "email", "john@example.com", "note", "cpf 123.456.789-09")
+		record := decode()
+
+		assert.Equal(t, "User created", record["msg"])
+		assert.Equal(t, "1", record["userId"])
+		assert.Equal(t, "j***@example.com", record["email"])
+		assert.Equal(t, "cpf ***", record["note"])
+	})
+
+	t.Run("should mask bound attributes and groups", func(t *testing.T) {
+		logger.With("token", "abc").WithGroup("auth").Info("login", "password", "p", slog.Group("client", "secret", "s", "id", "c1"))
+		record := decode()
+
+		assert.Equal(t, "***", record["token"])
+		assert.Equal(t, map[string]any{
+			"password": "***",
+			"client":   map[string]any{"secret": "***", "id": "c1"},
+		}, record["auth"])
+	})
+}
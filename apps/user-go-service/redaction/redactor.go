@@ -0,0 +1,296 @@
+package redaction
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DefaultMaxDepth matches the logger-node redactor default
+const DefaultMaxDepth = 5
+
+// Placeholders used when a value cannot be walked
+const (
+	MaxDepthValue     = "[MaxDepth]"
+	UnredactableValue = "[Unredactable]"
+)
+
+// Option configures a Redactor
+type Option func(*Redactor)
+
+// WithMask sets the strategy used when no key or pattern strategy applies
+func WithMask(strategy Strategy) Option {
+	return func(r *Redactor) {
+		r.mask = strategy
+	}
+}
+
+// WithKey masks the values of key, or of the dotted path when key contains
+// dots, using strategy. A nil strategy uses the default mask.
+func WithKey(key string, strategy Strategy) Option {
+	return func(r *Redactor) {
+		r.addKey(key, strategy)
+	}
+}
+
+// WithPatternStrategy masks matches of the named pattern using strategy
+func WithPatternStrategy(name string, strategy Strategy) Option {
+	return func(r *Redactor) {
+		r.patternStrategies[name] = strategy
+	}
+}
+
+// WithMaxDepth limits how deep nested values are walked
+func WithMaxDepth(depth int) Option {
+	return func(r *Redactor) {
+		r.maxDepth = depth
+	}
+}
+
+// Redactor masks sensitive values by key, by dotted path and by pattern
+type Redactor struct {
+	mask              Strategy
+	keys              map[string]*Strategy
+	paths             map[string]*Strategy
+	pattern           *regexp.Regexp
+	patternGroups     map[int]string
+	patternStrategies map[string]Strategy
+	maxDepth          int
+}
+
+// New creates a Redactor for rules
+func New(rules Rules, opts ...Option) (*Redactor, error) {
+	r := &Redactor{
+		mask:              Fixed(rules.Mask),
+		keys:              make(map[string]*Strategy),
+		paths:             make(map[string]*Strategy),
+		patternGroups:     make(map[int]string),
+		patternStrategies: make(map[string]Strategy),
+		maxDepth:          DefaultMaxDepth,
+	}
+	if rules.Mask == "" {
+		r.mask = Fixed("***")
+	}
+	for _, key := range rules.Keys {
+		r.addKey(key, nil)
+	}
+
+	// Patterns are combined into one alternation, like logger-node, so
+	// overlapping patterns resolve the same way in both stacks
+	if len(rules.Patterns) > 0 {
+		sources := make([]string, len(rules.Patterns))
+		for i, p := range rules.Patterns {
+			if _, err := regexp.Compile(p.Source); err != nil {
+				return nil, fmt.Errorf("invalid redaction pattern %q: %w", p.Name, err)
+			}
+			sources[i] = fmt.Sprintf("(?P<p%d>%s)", i, p.Source)
+		}
+		r.pattern = regexp.MustCompile("(?i)" + strings.Join(sources, "|"))
+		for i, p := range rules.Patterns {
+			r.patternGroups[r.pattern.SubexpIndex(fmt.Sprintf("p%d", i))] = p.Name
+		}
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r, nil
+}
+
+// Default creates a Redactor for DefaultRules
+func Default(opts ...Option) *Redactor {
+	r, err := New(DefaultRules(), opts...)
+	if err != nil {
+		panic(fmt.Sprintf("redaction: invalid default rules: %v", err))
+	}
+	return r
+}
+
+func (r *Redactor) addKey(key string, strategy Strategy) {
+	var s *Strategy
+	if strategy != nil {
+		s = &strategy
+	}
+	if strings.Contains(key, ".") {
+		r.paths[strings.ToLower(key)] = s
+		return
+	}
+	r.keys[strings.ToLower(key)] = s
+}
+
+// strategyFor returns the strategy for the value at path, if it is sensitive
+func (r *Redactor) strategyFor(path []string) (Strategy, bool) {
+	if len(path) == 0 {
+		return nil, false
+	}
+	s, ok := r.paths[strings.ToLower(strings.Join(path, "."))]
+	if !ok {
+		s, ok = r.keys[strings.ToLower(path[len(path)-1])]
+	}
+	if !ok {
+		return nil, false
+	}
+	if s == nil {
+		return r.mask, true
+	}
+	return *s, true
+}
+
+// String masks every pattern match in s
+func (r *Redactor) String(s string) string {
+	if r.pattern == nil {
+		return s
+	}
+	matches := r.pattern.FindAllStringSubmatchIndex(s, -1)
+	if len(matches) == 0 {
+		return s
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		b.WriteString(s[last:m[0]])
+		b.WriteString(r.patternStrategy(m)(s[m[0]:m[1]]))
+		last = m[1]
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// patternStrategy returns the strategy of the pattern that produced match
+func (r *Redactor) patternStrategy(match []int) Strategy {
+	for group, name := range r.patternGroups {
+		if match[2*group] >= 0 {
+			if s, ok := r.patternStrategies[name]; ok {
+				return s
+			}
+			break
+		}
+	}
+	return r.mask
+}
+
+// Value returns a copy of v with sensitive keys and patterns masked. Maps,
+// slices and structs are walked through their JSON form.
+func (r *Redactor) Value(path []string, v any) any {
+	if s, ok := r.strategyFor(path); ok {
+		return s(stringify(v))
+	}
+	return r.walk(path, v, 0)
+}
+
+func (r *Redactor) walk(path []string, v any, depth int) any {
+	switch value := v.(type) {
+	case nil, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64,
+		float32, float64, json.Number, time.Time, time.Duration:
+		return value
+	case string:
+		return r.String(value)
+	case error:
+		return r.String(value.Error())
+	case map[string]any:
+		if depth >= r.maxDepth {
+			return MaxDepthValue
+		}
+		out := make(map[string]any, len(value))
+		for key, item := range value {
+			itemPath := appendPath(path, key)
+			if s, ok := r.strategyFor(itemPath); ok {
+				out[key] = s(stringify(item))
+				continue
+			}
+			out[key] = r.walk(itemPath, item, depth+1)
+		}
+		return out
+	case []any:
+		if depth >= r.maxDepth {
+			return MaxDepthValue
+		}
+		out := make([]any, len(value))
+		for i, item := range value {
+			out[i] = r.walk(path, item, depth+1)
+		}
+		return out
+	}
+
+	switch reflect.ValueOf(v).Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return v
+	case reflect.String:
+		return r.String(reflect.ValueOf(v).String())
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return UnredactableValue
+	}
+	var generic any
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return UnredactableValue
+	}
+	return r.walk(path, generic, depth)
+}
+
+// Attr returns a copy of attr with sensitive values masked. groups is the
+// path of the groups attr belongs to.
+func (r *Redactor) Attr(groups []string, attr slog.Attr) slog.Attr {
+	return r.attr(groups, attr, len(groups))
+}
+
+func (r *Redactor) attr(groups []string, attr slog.Attr, depth int) slog.Attr {
+	attr.Value = attr.Value.Resolve()
+	path := groups
+	if attr.Key != "" {
+		path = appendPath(groups, attr.Key)
+		if s, ok := r.strategyFor(path); ok {
+			return slog.String(attr.Key, s(attrString(attr.Value)))
+		}
+	}
+
+	switch attr.Value.Kind() {
+	case slog.KindString:
+		attr.Value = slog.StringValue(r.String(attr.Value.String()))
+	case slog.KindGroup:
+		if depth >= r.maxDepth {
+			attr.Value = slog.StringValue(MaxDepthValue)
+			break
+		}
+		members := attr.Value.Group()
+		redacted := make([]slog.Attr, len(members))
+		for i, member := range members {
+			redacted[i] = r.attr(path, member, depth+1)
+		}
+		attr.Value = slog.GroupValue(redacted...)
+	case slog.KindAny:
+		attr.Value = slog.AnyValue(r.walk(path, attr.Value.Any(), depth))
+	}
+	return attr
+}
+
+// appendPath returns path extended with key without aliasing path
+func appendPath(path []string, key string) []string {
+	out := make([]string, len(path)+1)
+	copy(out, path)
+	out[len(path)] = key
+	return out
+}
+
+func stringify(v any) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return fmt.Sprint(v)
+}
+
+func attrString(v slog.Value) string {
+	if v.Kind() == slog.KindString {
+		return v.String()
+	}
+	return fmt.Sprint(v.Any())
+}
@@ -0,0 +1,63 @@
+// Package redaction masks sensitive values in logs. Its default rule set is
+// shared with the logger-node redactor so both stacks redact identically.
+package redaction
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// defaultRules mirrors libs/logger-node/src/lib/redactor/default-rules.json
+//
+//go:embed default_rules.json
+var defaultRules []byte
+
+// Rules lists the keys whose values are always masked and the patterns
+// masked wherever they appear inside string values
+type Rules struct {
+	// Mask replaces redacted values when no other strategy applies
+	Mask string `json:"mask"`
+	// Keys are matched case-insensitively against attribute names. Keys
+	// containing dots are matched against the full dotted path instead.
+	Keys []string `json:"keys"`
+	// Patterns are regular expressions matched case-insensitively
+	Patterns []Pattern `json:"patterns"`
+}
+
+// Pattern is a named regular expression detecting sensitive values
+type Pattern struct {
+	Name   string `json:"name"`
+	Source string `json:"source"`
+}
+
+// DefaultRules returns the rule set shared with logger-node
+func DefaultRules() Rules {
+	rules, err := parseRules(defaultRules)
+	if err != nil {
+		panic(fmt.Sprintf("redaction: invalid default rules: %v", err))
+	}
+	return rules
+}
+
+// LoadRules reads a rule set from a JSON file using the default-rules.json format
+func LoadRules(path string) (Rules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Rules{}, fmt.Errorf("failed to read redaction rules: %w", err)
+	}
+	rules, err := parseRules(data)
+	if err != nil {
+		return Rules{}, fmt.Errorf("failed to parse redaction rules: %w", err)
+	}
+	return rules, nil
+}
+
+func parseRules(data []byte) (Rules, error) {
+	var rules Rules
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return Rules{}, err
+	}
+	return rules, nil
+}
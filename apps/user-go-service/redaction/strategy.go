@@ -0,0 +1,46 @@
+package redaction
+
+import "strings"
+
+// Strategy returns the masked form of a sensitive value
+type Strategy func(value string) string
+
+// Fixed replaces the whole value with mask
+func Fixed(mask string) Strategy {
+	return func(string) string {
+		return mask
+	}
+}
+
+// KeepLength replaces every character with an asterisk
+func KeepLength() Strategy {
+	return func(value string) string {
+		return strings.Repeat("*", len([]rune(value)))
+	}
+}
+
+// Partial keeps the first keepStart and last keepEnd characters and masks
+// the rest. Values too short to hide anything are fully masked.
+func Partial(keepStart, keepEnd int) Strategy {
+	return func(value string) string {
+		runes := []rune(value)
+		if len(runes) <= keepStart+keepEnd {
+			return strings.Repeat("*", len(runes))
+		}
+		masked := strings.Repeat("*", len(runes)-keepStart-keepEnd)
+		return string(runes[:keepStart]) + masked + string(runes[len(runes)-keepEnd:])
+	}
+}
+
+// Email keeps the first character of the local part and the domain, so
+// john@example.com becomes j***@example.com. Other values are fully masked.
+func Email() Strategy {
+	return func(value string) string {
+		at := strings.LastIndex(value, "@")
+		if at < 1 {
+			return "***"
+		}
+		local := []rune(value[:at])
+		return string(local[0]) + "***" + value[at:]
+	}
+}
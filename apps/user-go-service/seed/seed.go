@@ -0,0 +1,23 @@
+package seed
+
+import (
+	gouser "github.com/mateusmacedo/scouts/libs/user-go"
+	"github.com/mateusmacedo/scouts/libs/user-go/gousertest"
+)
+
+// Generator creates realistic fake users with Brazilian and American names.
+// The same seed yields the same users.
+type Generator struct {
+	seed int64
+}
+
+// NewGenerator creates a generator from seed
+func NewGenerator(seed int64) *Generator {
+	return &Generator{seed: seed}
+}
+
+// Users returns count fake users numbered from 1. Emails include the number
+// so distinct users never collide.
+func (g *Generator) Users(count int) []gouser.CreateUserData {
+	return gousertest.NewFaker(g.seed, gousertest.LocalePtBR, gousertest.LocaleEnUS).Users(count)
+}
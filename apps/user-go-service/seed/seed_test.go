@@ -0,0 +1,26 @@
+package seed
+
+import (
+	"testing"
+
+	gouser "github.com/mateusmacedo/scouts/libs/user-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerator(t *testing.T) {
+	t.Run("should generate valid unique users", func(t *testing.T) {
+		users := NewGenerator(1).Users(200)
+		emails := map[string]bool{}
+		for _, user := range users {
+			assert.NoError(t, gouser.ValidateCreateUserData(user), user.Email)
+			assert.NotEmpty(t, user.Address)
+			assert.False(t, emails[user.Email], "duplicate %s", user.Email)
+			emails[user.Email] = true
+		}
+	})
+
+	t.Run("should be reproducible", func(t *testing.T) {
+		assert.Equal(t, NewGenerator(7).Users(5), NewGenerator(7).Users(5))
+		assert.NotEqual(t, NewGenerator(7).Users(5), NewGenerator(8).Users(5))
+	})
+}
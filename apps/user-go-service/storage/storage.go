@@ -0,0 +1,106 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/mateusmacedo/scouts/apps/user-go-service/config"
+	gouser "github.com/mateusmacedo/scouts/libs/user-go"
+	"github.com/mateusmacedo/scouts/libs/user-go/migrate"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// ErrNoMigrations is returned by Store.Migrator for drivers without a schema
+var ErrNoMigrations = errors.New("storage driver has no schema migrations")
+
+// Store is an open storage driver
+type Store struct {
+	// Repository holds the users
+	Repository gouser.UserRepository
+	// IDs generates the user IDs of Repository
+	IDs      gouser.IDGenerator
+	migrator *migrate.Migrator
+	close    func() error
+}
+
+// Open opens the storage driver selected by cfg, applying opts to its
+// repository after the configured ID generator. SQL drivers are not
+// migrated; run the Migrator first when the schema may be outdated.
+func Open(ctx context.Context, cfg config.StorageConfig, opts ...gouser.RepositoryOption) (*Store, error) {
+	switch cfg.Driver {
+	case config.StorageDriverMemory:
+		ids, err := idGenerator(cfg.IDStrategy, gouser.IDStrategySequential)
+		if err != nil {
+			return nil, err
+		}
+		repository := gouser.NewInMemoryUserRepository(append([]gouser.RepositoryOption{gouser.WithIDGenerator(ids)}, opts...)...)
+		return &Store{Repository: repository, IDs: ids}, nil
+	case config.StorageDriverSQLite:
+		ids, err := idGenerator(cfg.IDStrategy, gouser.IDStrategyUUIDv7)
+		if err != nil {
+			return nil, err
+		}
+		return openSQL(ctx, "sqlite3", cfg.DSN, migrate.SQLite{}, gouser.SQLiteMigrations, ids, opts)
+	default:
+		return nil, fmt.Errorf("unsupported storage driver %q", cfg.Driver)
+	}
+}
+
+// idGenerator returns the generator of strategy, or of fallback when empty
+func idGenerator(strategy, fallback string) (gouser.IDGenerator, error) {
+	if strategy == "" {
+		strategy = fallback
+	}
+	return gouser.NewIDGenerator(strategy)
+}
+
+// openSQL opens a database/sql driver with its repository and migrator
+func openSQL(ctx context.Context, driver, dsn string, dialect migrate.Dialect, migrationsDir string, ids gouser.IDGenerator, opts []gouser.RepositoryOption) (*Store, error) {
+	migrations, err := migrate.Load(gouser.Migrations, migrationsDir)
+	if err != nil {
+		return nil, err
+	}
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("connect to %s: %w", driver, err)
+	}
+	migrator, err := migrate.New(db, migrations, migrate.Options{Dialect: dialect})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &Store{
+		Repository: gouser.NewSQLUserRepository(db, dialect, append([]gouser.RepositoryOption{gouser.WithIDGenerator(ids)}, opts...)...),
+		IDs:        ids,
+		migrator:   migrator,
+		close:      db.Close,
+	}, nil
+}
+
+// Migrator returns the schema migrator of the driver, or ErrNoMigrations
+func (s *Store) Migrator() (*migrate.Migrator, error) {
+	if s.migrator == nil {
+		return nil, ErrNoMigrations
+	}
+	return s.migrator, nil
+}
+
+// Persistent reports whether users outlive the process
+func (s *Store) Persistent() bool {
+	_, inMemory := s.Repository.(*gouser.InMemoryUserRepository)
+	return !inMemory
+}
+
+// Close releases the driver resources
+func (s *Store) Close() error {
+	if s.close == nil {
+		return nil
+	}
+	return s.close()
+}
@@ -0,0 +1,96 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+
+	gouser "github.com/mateusmacedo/scouts/libs/user-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// UserTracer adapts an OpenTelemetry tracer to gouser.Tracer
+type UserTracer struct {
+	tracer trace.Tracer
+}
+
+// NewUserTracer creates a gouser.Tracer backed by provider
+func NewUserTracer(provider trace.TracerProvider) *UserTracer {
+	return &UserTracer{tracer: provider.Tracer(InstrumentationName)}
+}
+
+// Start starts an internal span as a child of the span in ctx
+func (t *UserTracer) Start(ctx context.Context, name string, attributes ...gouser.Attribute) (context.Context, gouser.Span) {
+	attrs := make([]attribute.KeyValue, 0, len(attributes))
+	for _, attr := range attributes {
+		attrs = append(attrs, attribute.String(attr.Key, attr.Value))
+	}
+	ctx, span := t.tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+	return ctx, userSpan{span: span}
+}
+
+// userSpan adapts an OpenTelemetry span to gouser.Span
+type userSpan struct {
+	span trace.Span
+}
+
+func (s userSpan) End(err error) {
+	if err != nil {
+		s.span.RecordError(err)
+		s.span.SetStatus(codes.Error, err.Error())
+	}
+	s.span.End()
+}
+
+// Transport is an http.RoundTripper that wraps outbound requests in client
+// spans and propagates the trace context (traceparent) to the callee
+type Transport struct {
+	base       http.RoundTripper
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+}
+
+// NewTransport wraps base, defaulting to http.DefaultTransport, using the
+// global tracer provider and propagator
+func NewTransport(base http.RoundTripper) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &Transport{
+		base:       base,
+		tracer:     otel.GetTracerProvider().Tracer(InstrumentationName),
+		propagator: otel.GetTextMapPropagator(),
+	}
+}
+
+// RoundTrip performs the request inside a client span
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, span := t.tracer.Start(req.Context(), "HTTP "+req.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.request.method", req.Method),
+			attribute.String("server.address", req.URL.Host),
+			attribute.String("url.full", req.URL.Redacted()),
+		),
+	)
+	defer span.End()
+
+	// RoundTrippers must not modify the caller's request
+	req = req.Clone(ctx)
+	t.propagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	res, err := t.base.RoundTrip(req)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	span.SetAttributes(attribute.Int("http.response.status_code", res.StatusCode))
+	if res.StatusCode >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, http.StatusText(res.StatusCode))
+	}
+	return res, nil
+}
@@ -0,0 +1,83 @@
+package tracing
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Config defines the config for the tracing middleware
+type Config struct {
+	// Skipper defines a function to skip middleware
+	Skipper middleware.Skipper
+	// TracerProvider creates the server spans. Defaults to the global provider.
+	TracerProvider trace.TracerProvider
+	// Propagator extracts the incoming trace context. Defaults to the global propagator.
+	Propagator propagation.TextMapPropagator
+}
+
+// Middleware returns a middleware that continues the caller's W3C trace
+// (traceparent/tracestate) in a server span per request. The span context is
+// stored in the request context so service and repository spans nest under it.
+func Middleware(config Config) echo.MiddlewareFunc {
+	if config.Skipper == nil {
+		config.Skipper = middleware.DefaultSkipper
+	}
+	if config.TracerProvider == nil {
+		config.TracerProvider = otel.GetTracerProvider()
+	}
+	if config.Propagator == nil {
+		config.Propagator = otel.GetTextMapPropagator()
+	}
+	tracer := config.TracerProvider.Tracer(InstrumentationName)
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) {
+				return next(c)
+			}
+
+			req := c.Request()
+			ctx := config.Propagator.Extract(req.Context(), propagation.HeaderCarrier(req.Header))
+
+			route := c.Path()
+			name := req.Method + " " + route
+			if route == "" {
+				name = req.Method
+			}
+			ctx, span := tracer.Start(ctx, name,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					attribute.String("http.request.method", req.Method),
+					attribute.String("http.route", route),
+					attribute.String("url.path", req.URL.Path),
+				),
+			)
+			defer span.End()
+
+			c.SetRequest(req.WithContext(ctx))
+
+			err := next(c)
+			if err != nil {
+				// Let Echo's error handler set the final status before recording
+				c.Error(err)
+			}
+
+			status := c.Response().Status
+			span.SetAttributes(attribute.Int("http.response.status_code", status))
+			if status >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(status))
+			}
+			if err != nil {
+				span.RecordError(err)
+			}
+			return nil
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/mateusmacedo/scouts/apps/user-go-service/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// InstrumentationName identifies the spans created by this service
+const InstrumentationName = "github.com/mateusmacedo/scouts/apps/user-go-service"
+
+// Provider owns the tracer provider and its exporter
+type Provider struct {
+	trace.TracerProvider
+	shutdown func(ctx context.Context) error
+}
+
+// Shutdown flushes pending spans and releases the exporter
+func (p *Provider) Shutdown(ctx context.Context) error {
+	return p.shutdown(ctx)
+}
+
+// Setup creates the tracer provider selected by cfg.Exporter and installs it,
+// with W3C trace context and baggage propagation, as the global provider.
+// The "none" exporter installs a no-op provider.
+func Setup(ctx context.Context, cfg config.TracingConfig) (*Provider, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	if cfg.Exporter == config.TracingExporterNone {
+		provider := &Provider{
+			TracerProvider: noop.NewTracerProvider(),
+			shutdown:       func(context.Context) error { return nil },
+		}
+		otel.SetTracerProvider(provider)
+		return provider, nil
+	}
+
+	exporter, closer, err := newExporter(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	sdkProvider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(
+			attribute.String("service.name", cfg.ServiceName),
+			attribute.String("service.version", cfg.ServiceVersion),
+		)),
+	)
+	otel.SetTracerProvider(sdkProvider)
+
+	return &Provider{
+		TracerProvider: sdkProvider,
+		shutdown: func(ctx context.Context) error {
+			err := sdkProvider.Shutdown(ctx)
+			if closer != nil {
+				if closeErr := closer.Close(); err == nil {
+					err = closeErr
+				}
+			}
+			return err
+		},
+	}, nil
+}
+
+// newExporter builds the configured span exporter. The returned closer, if
+// any, must be closed after the provider shuts down.
+func newExporter(ctx context.Context, cfg config.TracingConfig) (sdktrace.SpanExporter, io.Closer, error) {
+	switch cfg.Exporter {
+	case config.TracingExporterOTLP:
+		// Endpoint, headers and TLS also honor the standard OTEL_EXPORTER_OTLP_* variables
+		var opts []otlptracehttp.Option
+		if cfg.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpointURL(cfg.Endpoint))
+		}
+		exporter, err := otlptracehttp.New(ctx, opts...)
+		return exporter, nil, err
+	case config.TracingExporterStdout:
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+		return exporter, nil, err
+	case config.TracingExporterFile:
+		file, err := os.OpenFile(cfg.FilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open trace file: %w", err)
+		}
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(file))
+		if err != nil {
+			file.Close()
+			return nil, nil, err
+		}
+		return exporter, file, nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported tracing exporter %q", cfg.Exporter)
+	}
+}
@@ -0,0 +1,146 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mateusmacedo/scouts/apps/user-go-service/config"
+	gouser "github.com/mateusmacedo/scouts/libs/user-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+const incomingTraceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func newRecordingProvider() (*sdktrace.TracerProvider, *tracetest.SpanRecorder) {
+	recorder := tracetest.NewSpanRecorder()
+	return sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)), recorder
+}
+
+func spanByName(spans []sdktrace.ReadOnlySpan, name string) sdktrace.ReadOnlySpan {
+	for _, span := range spans {
+		if span.Name() == name {
+			return span
+		}
+	}
+	return nil
+}
+
+func TestMiddleware(t *testing.T) {
+	provider, recorder := newRecordingProvider()
+	userTracer := NewUserTracer(provider)
+	service := gouser.NewUserService(
+		gouser.NewTracingRepository(gouser.NewInMemoryUserRepository(), userTracer),
+		nil,
+		gouser.WithTracer(userTracer),
+	)
+
+	e := echo.New()
+	e.HideBanner = true
+	e.Use(Middleware(Config{TracerProvider: provider, Propagator: propagation.TraceContext{}}))
+	e.GET("/users/:id", func(c echo.Context) error {
+		service.FindByID(c.Request().Context(), c.Param("id"))
+		return c.NoContent(http.StatusNotFound)
+	})
+	e.GET("/fail", func(c echo.Context) error { return errors.New("boom") })
+
+	t.Run("should continue the incoming trace and nest service spans", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+		req.Header.Set("traceparent", incomingTraceparent)
+		e.ServeHTTP(httptest.NewRecorder(), req)
+
+		spans := recorder.Ended()
+		server := spanByName(spans, "GET /users/:id")
+		require.NotNil(t, server)
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.SpanContext().TraceID().String())
+		assert.Equal(t, "00f067aa0ba902b7", server.Parent().SpanID().String())
+		assert.Equal(t, trace.SpanKindServer, server.SpanKind())
+
+		serviceSpan := spanByName(spans, "UserService.FindByID")
+		require.NotNil(t, serviceSpan)
+		assert.Equal(t, server.SpanContext().SpanID(), serviceSpan.Parent().SpanID())
+		assert.Equal(t, codes.Error, serviceSpan.Status().Code)
+
+		repoSpan := spanByName(spans, "UserRepository.FindByID")
+		require.NotNil(t, repoSpan)
+		assert.Equal(t, serviceSpan.SpanContext().SpanID(), repoSpan.Parent().SpanID())
+	})
+
+	t.Run("should mark server errors", func(t *testing.T) {
+		e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/fail", nil))
+
+		span := spanByName(recorder.Ended(), "GET /fail")
+		require.NotNil(t, span)
+		assert.Equal(t, codes.Error, span.Status().Code)
+	})
+}
+
+func TestTransport(t *testing.T) {
+	provider, recorder := newRecordingProvider()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTracerProvider(noop.NewTracerProvider()) })
+
+	var received string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Get("traceparent")
+	}))
+	defer server.Close()
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "parent")
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	client := &http.Client{Transport: NewTransport(nil)}
+	res, err := client.Do(req)
+	require.NoError(t, err)
+	res.Body.Close()
+	parent.End()
+
+	clientSpan := spanByName(recorder.Ended(), "HTTP GET")
+	require.NotNil(t, clientSpan)
+	assert.Equal(t, parent.SpanContext().SpanID(), clientSpan.Parent().SpanID())
+	assert.Contains(t, received, clientSpan.SpanContext().SpanID().String())
+	assert.Empty(t, req.Header.Get("traceparent"), "caller request must not be modified")
+}
+
+func TestSetup(t *testing.T) {
+	t.Run("should write spans to a file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "traces.jsonl")
+		provider, err := Setup(context.Background(), config.TracingConfig{
+			Exporter:    config.TracingExporterFile,
+			FilePath:    path,
+			ServiceName: "user-go-service",
+			SampleRatio: 1,
+		})
+		require.NoError(t, err)
+
+		_, span := provider.Tracer("test").Start(context.Background(), "file-span")
+		span.End()
+		require.NoError(t, provider.Shutdown(context.Background()))
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "file-span")
+	})
+
+	t.Run("should install a no-op provider when disabled", func(t *testing.T) {
+		provider, err := Setup(context.Background(), config.TracingConfig{Exporter: config.TracingExporterNone})
+		require.NoError(t, err)
+
+		_, span := provider.Tracer("test").Start(context.Background(), "ignored")
+		assert.False(t, span.SpanContext().IsValid())
+		assert.NoError(t, provider.Shutdown(context.Background()))
+	})
+}
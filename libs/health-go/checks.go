@@ -0,0 +1,104 @@
+package gohealth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"runtime"
+)
+
+// Pinger is implemented by stores that can be probed, such as *sql.DB and
+// gouser repositories
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// ContextPinger is implemented by *sql.DB and *sql.Conn
+type ContextPinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// Ping checks a Pinger
+func Ping(pinger Pinger) Checker {
+	return CheckerFunc(func(ctx context.Context) (Details, error) {
+		return nil, pinger.Ping(ctx)
+	})
+}
+
+// SQL checks a database connection pool, e.g. *sql.DB
+func SQL(db ContextPinger) Checker {
+	return CheckerFunc(func(ctx context.Context) (Details, error) {
+		return nil, db.PingContext(ctx)
+	})
+}
+
+// HTTP checks an upstream with a GET request, failing on transport errors
+// and 5xx responses like Terminus pingCheck
+func HTTP(client *http.Client, url string) Checker {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return CheckerFunc(func(ctx context.Context) (Details, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		resp.Body.Close()
+		details := Details{"statusCode": resp.StatusCode}
+		if resp.StatusCode >= http.StatusInternalServerError {
+			return details, fmt.Errorf("unexpected status %d", resp.StatusCode)
+		}
+		return details, nil
+	})
+}
+
+// Memory fails when the heap in use exceeds heapThreshold bytes
+func Memory(heapThreshold uint64) Checker {
+	return CheckerFunc(func(context.Context) (Details, error) {
+		var stats runtime.MemStats
+		runtime.ReadMemStats(&stats)
+		details := Details{"heapUsed": stats.HeapAlloc, "heapThreshold": heapThreshold}
+		if stats.HeapAlloc > heapThreshold {
+			return details, fmt.Errorf("heap used %d exceeds threshold %d", stats.HeapAlloc, heapThreshold)
+		}
+		return details, nil
+	})
+}
+
+// Goroutines fails when more than max goroutines are running, a sign of
+// leaked requests or workers
+func Goroutines(max int) Checker {
+	return CheckerFunc(func(context.Context) (Details, error) {
+		count := runtime.NumGoroutine()
+		details := Details{"goroutines": count, "threshold": max}
+		if count > max {
+			return details, fmt.Errorf("%d goroutines exceed threshold %d", count, max)
+		}
+		return details, nil
+	})
+}
+
+// DiskSpace fails when the filesystem holding path is more than
+// thresholdPercent used, a fraction between 0 and 1 like the utils-nest
+// disk indicator
+func DiskSpace(path string, thresholdPercent float64) Checker {
+	return CheckerFunc(func(context.Context) (Details, error) {
+		total, free, err := diskUsage(path)
+		if err != nil {
+			return nil, err
+		}
+		used := 0.0
+		if total > 0 {
+			used = float64(total-free) / float64(total)
+		}
+		details := Details{"path": path, "totalBytes": total, "freeBytes": free, "usedPercent": used, "thresholdPercent": thresholdPercent}
+		if used > thresholdPercent {
+			return details, fmt.Errorf("disk usage %.2f exceeds threshold %.2f", used, thresholdPercent)
+		}
+		return details, nil
+	})
+}
@@ -0,0 +1,78 @@
+package gohealth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// fakePinger returns err from Ping and PingContext
+type fakePinger struct {
+	err error
+}
+
+func (p fakePinger) Ping(context.Context) error { return p.err }
+
+func (p fakePinger) PingContext(context.Context) error { return p.err }
+
+func TestChecks(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should ping stores", func(t *testing.T) {
+		if _, err := Ping(fakePinger{}).Check(ctx); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+		refused := errors.New("connection refused")
+		if _, err := SQL(fakePinger{err: refused}).Check(ctx); !errors.Is(err, refused) {
+			t.Errorf("Expected connection refused, got %v", err)
+		}
+	})
+
+	t.Run("should check HTTP upstreams", func(t *testing.T) {
+		status := http.StatusNoContent
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+		}))
+		defer server.Close()
+		checker := HTTP(nil, server.URL)
+
+		details, err := checker.Check(ctx)
+		if err != nil || details["statusCode"] != http.StatusNoContent {
+			t.Errorf("Expected up with status 204, got %v (%v)", details, err)
+		}
+		status = http.StatusBadGateway
+		if _, err := checker.Check(ctx); err == nil || err.Error() != "unexpected status 502" {
+			t.Errorf("Expected unexpected status 502, got %v", err)
+		}
+	})
+
+	t.Run("should apply memory and goroutine thresholds", func(t *testing.T) {
+		if _, err := Memory(1 << 40).Check(ctx); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+		if _, err := Memory(1).Check(ctx); err == nil {
+			t.Error("Expected the heap to exceed 1 byte")
+		}
+		if _, err := Goroutines(100000).Check(ctx); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+		if details, err := Goroutines(0).Check(ctx); err == nil || details["goroutines"].(int) < 1 {
+			t.Errorf("Expected the goroutine count to exceed 0, got %v (%v)", details, err)
+		}
+	})
+
+	t.Run("should check disk space", func(t *testing.T) {
+		details, err := DiskSpace(t.TempDir(), 1).Check(ctx)
+		if err != nil {
+			t.Skipf("Disk space is not available here: %v", err)
+		}
+		if details["totalBytes"].(uint64) == 0 {
+			t.Errorf("Expected the filesystem size, got %v", details)
+		}
+		if _, err := DiskSpace(t.TempDir(), -1).Check(ctx); err == nil {
+			t.Error("Expected a negative threshold to fail")
+		}
+	})
+}
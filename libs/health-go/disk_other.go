@@ -0,0 +1,13 @@
+//go:build !linux && !darwin && !freebsd
+
+package gohealth
+
+import (
+	"errors"
+	"runtime"
+)
+
+// diskUsage is not supported on this platform
+func diskUsage(string) (total, free uint64, err error) {
+	return 0, 0, errors.New("disk space check is not supported on " + runtime.GOOS)
+}
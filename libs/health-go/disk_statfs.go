@@ -0,0 +1,14 @@
+//go:build linux || darwin || freebsd
+
+package gohealth
+
+import "syscall"
+
+// diskUsage returns the total and available bytes of the filesystem at path
+func diskUsage(path string) (total, free uint64, err error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, 0, err
+	}
+	return uint64(stat.Blocks) * uint64(stat.Bsize), uint64(stat.Bavail) * uint64(stat.Bsize), nil
+}
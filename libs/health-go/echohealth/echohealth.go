@@ -0,0 +1,28 @@
+// Package echohealth serves gohealth probes from Echo routers
+package echohealth
+
+import (
+	"github.com/labstack/echo/v4"
+	gohealth "github.com/mateusmacedo/scouts/libs/health-go"
+)
+
+// Router is implemented by *echo.Echo and *echo.Group
+type Router interface {
+	GET(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+}
+
+// Handler serves the result of probe as JSON
+func Handler(h *gohealth.Health, probe gohealth.Probe) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		result := h.Run(c.Request().Context(), probe)
+		c.Response().Header().Set(echo.HeaderCacheControl, "no-store")
+		return c.JSON(gohealth.StatusCode(result), result)
+	}
+}
+
+// Register serves the probes on router at the default paths
+func Register(router Router, h *gohealth.Health) {
+	router.GET(gohealth.DefaultLivenessPath, Handler(h, gohealth.ProbeLiveness))
+	router.GET(gohealth.DefaultReadinessPath, Handler(h, gohealth.ProbeReadiness))
+	router.GET(gohealth.DefaultStartupPath, Handler(h, gohealth.ProbeStartup))
+}
@@ -0,0 +1,42 @@
+package echohealth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	gohealth "github.com/mateusmacedo/scouts/libs/health-go"
+)
+
+func TestRegister(t *testing.T) {
+	h := gohealth.New()
+	h.Register(gohealth.Check{
+		Name:     "database",
+		Critical: true,
+		Checker: gohealth.CheckerFunc(func(context.Context) (gohealth.Details, error) {
+			return nil, errors.New("down")
+		}),
+	})
+	e := echo.New()
+	Register(e, h)
+
+	t.Run("should serve the probes", func(t *testing.T) {
+		for path, want := range map[string]int{
+			gohealth.DefaultLivenessPath:  http.StatusOK,
+			gohealth.DefaultReadinessPath: http.StatusServiceUnavailable,
+			gohealth.DefaultStartupPath:   http.StatusServiceUnavailable,
+		} {
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+			if rec.Code != want {
+				t.Errorf("Expected %d for %s, got %d", want, path, rec.Code)
+			}
+			if rec.Header().Get(echo.HeaderCacheControl) != "no-store" {
+				t.Errorf("Expected no-store for %s", path)
+			}
+		}
+	})
+}
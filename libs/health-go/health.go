@@ -0,0 +1,250 @@
+package gohealth
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Report statuses, matching @nestjs/terminus
+const (
+	StatusOK           = "ok"
+	StatusError        = "error"
+	StatusShuttingDown = "shutting_down"
+)
+
+// Indicator statuses, matching @nestjs/terminus
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+// DefaultTimeout bounds checks registered without a timeout. It matches the
+// utils-nest DEFAULT_TIMEOUT.
+const DefaultTimeout = 3 * time.Second
+
+// Probe selects which endpoint runs a check
+type Probe string
+
+// Kubernetes probes
+const (
+	ProbeLiveness  Probe = "liveness"
+	ProbeReadiness Probe = "readiness"
+	ProbeStartup   Probe = "startup"
+)
+
+// Details are extra values reported by a check, e.g. the measured usage
+type Details map[string]any
+
+// Checker probes a dependency, returning an error when it is unavailable
+type Checker interface {
+	Check(ctx context.Context) (Details, error)
+}
+
+// CheckerFunc adapts a function to a Checker
+type CheckerFunc func(ctx context.Context) (Details, error)
+
+// Check calls f(ctx)
+func (f CheckerFunc) Check(ctx context.Context) (Details, error) {
+	return f(ctx)
+}
+
+// Check is a named dependency probe
+type Check struct {
+	Name    string
+	Checker Checker
+	// Timeout bounds each run. Defaults to DefaultTimeout.
+	Timeout time.Duration
+	// Critical checks fail the probe when they are down. Other failures are
+	// listed under "error" but keep the status ok.
+	Critical bool
+	// CacheTTL reuses the last result for this long. Zero runs the check on
+	// every request.
+	CacheTTL time.Duration
+}
+
+// IndicatorResult is the outcome of one check: its status, the latency, an
+// error message when down and the checker details
+type IndicatorResult map[string]any
+
+// Status returns the indicator status, up or down
+func (r IndicatorResult) Status() string {
+	status, _ := r["status"].(string)
+	return status
+}
+
+// Result is a probe report with the @nestjs/terminus HealthCheckResult schema
+type Result struct {
+	Status  string                     `json:"status"`
+	Info    map[string]IndicatorResult `json:"info"`
+	Error   map[string]IndicatorResult `json:"error"`
+	Details map[string]IndicatorResult `json:"details"`
+}
+
+// OK reports whether the probe passed
+func (r Result) OK() bool {
+	return r.Status == StatusOK
+}
+
+// registered holds a check and its cached result
+type registered struct {
+	check     Check
+	probes    []Probe
+	mutex     sync.Mutex
+	last      IndicatorResult
+	expiresAt time.Time
+}
+
+// Health runs registered checks for the liveness, readiness and startup
+// probes
+type Health struct {
+	checks       []*registered
+	mutex        sync.RWMutex
+	started      atomic.Bool
+	shuttingDown atomic.Bool
+}
+
+// New creates a Health without checks
+func New() *Health {
+	return &Health{}
+}
+
+// Register adds a check to the given probes, readiness by default
+func (h *Health) Register(check Check, probes ...Probe) {
+	if check.Timeout <= 0 {
+		check.Timeout = DefaultTimeout
+	}
+	if len(probes) == 0 {
+		probes = []Probe{ProbeReadiness}
+	}
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.checks = append(h.checks, &registered{check: check, probes: probes})
+}
+
+// MarkStarted lets the startup probe pass once its checks do
+func (h *Health) MarkStarted() {
+	h.started.Store(true)
+}
+
+// Shutdown makes the readiness probe report shutting_down without probing
+// dependencies, so traffic stops before the server closes
+func (h *Health) Shutdown() {
+	h.shuttingDown.Store(true)
+}
+
+// ShuttingDown reports whether Shutdown was called
+func (h *Health) ShuttingDown() bool {
+	return h.shuttingDown.Load()
+}
+
+// Run executes the checks of probe concurrently, reusing cached results. The
+// result is an error when any critical check is down.
+func (h *Health) Run(ctx context.Context, probe Probe) Result {
+	result := Result{
+		Status:  StatusOK,
+		Info:    map[string]IndicatorResult{},
+		Error:   map[string]IndicatorResult{},
+		Details: map[string]IndicatorResult{},
+	}
+	if probe == ProbeReadiness && h.ShuttingDown() {
+		result.Status = StatusShuttingDown
+		return result
+	}
+
+	checks := h.checksFor(probe)
+	indicators := make([]IndicatorResult, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check *registered) {
+			defer wg.Done()
+			indicators[i] = check.run(ctx)
+		}(i, check)
+	}
+	wg.Wait()
+
+	if probe == ProbeStartup && !h.started.Load() {
+		indicator := IndicatorResult{"status": StatusDown, "message": "not started"}
+		result.Status = StatusError
+		result.Error["startup"] = indicator
+		result.Details["startup"] = indicator
+	}
+	for i, check := range checks {
+		name, indicator := check.check.Name, indicators[i]
+		result.Details[name] = indicator
+		if indicator.Status() == StatusUp {
+			result.Info[name] = indicator
+			continue
+		}
+		result.Error[name] = indicator
+		if check.check.Critical {
+			result.Status = StatusError
+		}
+	}
+	return result
+}
+
+// checksFor returns the checks registered for probe
+func (h *Health) checksFor(probe Probe) []*registered {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	var checks []*registered
+	for _, check := range h.checks {
+		for _, p := range check.probes {
+			if p == probe {
+				checks = append(checks, check)
+				break
+			}
+		}
+	}
+	return checks
+}
+
+// run executes the check unless a cached result is still fresh
+func (c *registered) run(ctx context.Context) IndicatorResult {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	now := time.Now()
+	if now.Before(c.expiresAt) {
+		return c.last
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.check.Timeout)
+	defer cancel()
+
+	// Checkers that ignore ctx still cannot hold the probe past the timeout
+	type outcome struct {
+		details Details
+		err     error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		details, err := c.check.Checker.Check(ctx)
+		done <- outcome{details, err}
+	}()
+	var out outcome
+	select {
+	case out = <-done:
+	case <-ctx.Done():
+		out.err = fmt.Errorf("timed out after %s", c.check.Timeout)
+	}
+
+	indicator := IndicatorResult{}
+	for key, value := range out.details {
+		indicator[key] = value
+	}
+	indicator["status"] = StatusUp
+	indicator["latencyMs"] = float64(time.Since(now).Microseconds()) / 1000
+	if out.err != nil {
+		indicator["status"] = StatusDown
+		indicator["message"] = out.err.Error()
+	}
+
+	c.last = indicator
+	c.expiresAt = now.Add(c.check.CacheTTL)
+	return indicator
+}
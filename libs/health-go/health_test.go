@@ -0,0 +1,177 @@
+package gohealth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func up(context.Context) (Details, error) { return nil, nil }
+
+func down(context.Context) (Details, error) { return nil, errors.New("down") }
+
+func TestHealth(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should report up checks under info", func(t *testing.T) {
+		h := New()
+		h.Register(Check{Name: "database", Checker: CheckerFunc(up), Critical: true})
+
+		result := h.Run(ctx, ProbeReadiness)
+
+		if result.Status != StatusOK {
+			t.Errorf("Expected ok, got %s", result.Status)
+		}
+		if result.Info["database"].Status() != StatusUp || result.Details["database"].Status() != StatusUp {
+			t.Errorf("Expected database up in info and details, got %+v", result)
+		}
+		if _, ok := result.Info["database"]["latencyMs"]; !ok {
+			t.Error("Expected latencyMs in the indicator")
+		}
+		if len(result.Error) != 0 {
+			t.Errorf("Expected no errors, got %v", result.Error)
+		}
+	})
+
+	t.Run("should fail on critical checks only", func(t *testing.T) {
+		h := New()
+		h.Register(Check{Name: "notifier", Checker: CheckerFunc(down)})
+
+		result := h.Run(ctx, ProbeReadiness)
+		if result.Status != StatusOK || result.Error["notifier"]["message"] != "down" {
+			t.Errorf("Expected ok with the notifier listed as down, got %+v", result)
+		}
+
+		h.Register(Check{Name: "database", Checker: CheckerFunc(down), Critical: true})
+		if result := h.Run(ctx, ProbeReadiness); result.Status != StatusError {
+			t.Errorf("Expected error, got %s", result.Status)
+		}
+	})
+
+	t.Run("should run checks for their probes only", func(t *testing.T) {
+		h := New()
+		h.Register(Check{Name: "memory", Checker: CheckerFunc(up)}, ProbeLiveness, ProbeReadiness)
+		h.Register(Check{Name: "database", Checker: CheckerFunc(up)})
+
+		if live := h.Run(ctx, ProbeLiveness); len(live.Details) != 1 {
+			t.Errorf("Expected only memory in liveness, got %v", live.Details)
+		}
+		if ready := h.Run(ctx, ProbeReadiness); len(ready.Details) != 2 {
+			t.Errorf("Expected memory and database in readiness, got %v", ready.Details)
+		}
+	})
+
+	t.Run("should time out slow checks", func(t *testing.T) {
+		h := New()
+		h.Register(Check{
+			Name:     "slow",
+			Critical: true,
+			Timeout:  10 * time.Millisecond,
+			Checker: CheckerFunc(func(context.Context) (Details, error) {
+				time.Sleep(time.Second)
+				return nil, nil
+			}),
+		})
+
+		start := time.Now()
+		result := h.Run(ctx, ProbeReadiness)
+
+		if time.Since(start) > 500*time.Millisecond {
+			t.Errorf("Expected the timeout to bound the probe, took %v", time.Since(start))
+		}
+		if result.Status != StatusError || result.Error["slow"]["message"] != "timed out after 10ms" {
+			t.Errorf("Expected a timeout error, got %+v", result.Error)
+		}
+	})
+
+	t.Run("should cache results", func(t *testing.T) {
+		var calls atomic.Int32
+		h := New()
+		h.Register(Check{Name: "cached", CacheTTL: time.Minute, Checker: CheckerFunc(func(context.Context) (Details, error) {
+			calls.Add(1)
+			return nil, nil
+		})})
+
+		h.Run(ctx, ProbeReadiness)
+		h.Run(ctx, ProbeReadiness)
+
+		if calls.Load() != 1 {
+			t.Errorf("Expected 1 call, got %d", calls.Load())
+		}
+	})
+
+	t.Run("should report shutting_down on readiness", func(t *testing.T) {
+		var calls atomic.Int32
+		h := New()
+		h.Register(Check{Name: "database", Checker: CheckerFunc(func(context.Context) (Details, error) {
+			calls.Add(1)
+			return nil, nil
+		})})
+
+		h.Shutdown()
+
+		if result := h.Run(ctx, ProbeReadiness); result.Status != StatusShuttingDown {
+			t.Errorf("Expected shutting_down, got %s", result.Status)
+		}
+		if calls.Load() != 0 {
+			t.Error("Expected no probing during shutdown")
+		}
+		if result := h.Run(ctx, ProbeLiveness); result.Status != StatusOK {
+			t.Errorf("Expected liveness to pass during shutdown, got %s", result.Status)
+		}
+	})
+
+	t.Run("should fail startup until started", func(t *testing.T) {
+		h := New()
+
+		if result := h.Run(ctx, ProbeStartup); result.Status != StatusError || result.Error["startup"].Status() != StatusDown {
+			t.Errorf("Expected startup to fail, got %+v", result)
+		}
+		h.MarkStarted()
+		if result := h.Run(ctx, ProbeStartup); result.Status != StatusOK {
+			t.Errorf("Expected startup to pass, got %+v", result)
+		}
+	})
+}
+
+func TestHandler(t *testing.T) {
+	h := New()
+	h.Register(Check{Name: "database", Checker: CheckerFunc(up), Critical: true})
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	t.Run("should serve the terminus schema", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, DefaultReadinessPath, nil))
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("Expected 200, got %d", rec.Code)
+		}
+		var decoded struct {
+			Status  string                    `json:"status"`
+			Info    map[string]map[string]any `json:"info"`
+			Error   map[string]map[string]any `json:"error"`
+			Details map[string]map[string]any `json:"details"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &decoded); err != nil {
+			t.Fatalf("Expected valid JSON, got %v", err)
+		}
+		if decoded.Status != "ok" || decoded.Info["database"]["status"] != "up" || decoded.Error == nil {
+			t.Errorf("Unexpected body %s", rec.Body.String())
+		}
+	})
+
+	t.Run("should return 503 when the probe fails", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, DefaultStartupPath, nil))
+
+		if rec.Code != http.StatusServiceUnavailable {
+			t.Errorf("Expected 503, got %d", rec.Code)
+		}
+	})
+}
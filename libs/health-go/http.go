@@ -0,0 +1,39 @@
+package gohealth
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Default probe paths, matching the utils-nest health controller
+const (
+	DefaultLivenessPath  = "/health/live"
+	DefaultReadinessPath = "/health/ready"
+	DefaultStartupPath   = "/health/startup"
+)
+
+// StatusCode returns 200 for passing results and 503 otherwise
+func StatusCode(result Result) int {
+	if result.OK() {
+		return http.StatusOK
+	}
+	return http.StatusServiceUnavailable
+}
+
+// Handler serves the result of probe as JSON
+func (h *Health) Handler(probe Probe) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		result := h.Run(r.Context(), probe)
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(StatusCode(result))
+		json.NewEncoder(w).Encode(result)
+	})
+}
+
+// RegisterRoutes serves the probes on mux at the default paths
+func (h *Health) RegisterRoutes(mux *http.ServeMux) {
+	mux.Handle(DefaultLivenessPath, h.Handler(ProbeLiveness))
+	mux.Handle(DefaultReadinessPath, h.Handler(ProbeReadiness))
+	mux.Handle(DefaultStartupPath, h.Handler(ProbeStartup))
+}
@@ -0,0 +1,180 @@
+package gologger
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"time"
+)
+
+// ErrBufferFull is returned when an AsyncSink rejects an entry
+var ErrBufferFull = errors.New("log buffer is full")
+
+// AsyncOptions configures an AsyncSink
+type AsyncOptions struct {
+	// BufferSize is the number of entries held before they are flushed.
+	// Defaults to 1000.
+	BufferSize int
+	// FlushInterval flushes buffered entries periodically. Defaults to 5s.
+	FlushInterval time.Duration
+	// Retry is applied to each write to the wrapped sink. Zero values use
+	// the Retry defaults.
+	Retry RetryOptions
+	// Backpressure writes directly to the wrapped sink when the buffer is
+	// full instead of dropping the entry
+	Backpressure bool
+	// Metrics receives dropped, error, flush and buffer utilization counts
+	Metrics *Metrics
+}
+
+// AsyncSink buffers entries and writes them to the wrapped sink in the
+// background, like the logger-node LogBuffer. Entries that still fail after
+// the retries are dropped.
+type AsyncSink struct {
+	next    Sink
+	opts    AsyncOptions
+	metrics *Metrics
+
+	mu      sync.Mutex
+	buffer  []Entry
+	closed  bool
+	flushMu sync.Mutex
+
+	full chan struct{}
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewAsyncSink wraps next and starts the background flusher
+func NewAsyncSink(next Sink, opts AsyncOptions) *AsyncSink {
+	if opts.BufferSize <= 0 {
+		opts.BufferSize = 1000
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = 5 * time.Second
+	}
+	metrics := opts.Metrics
+	if metrics == nil {
+		metrics = NewMetrics()
+	}
+
+	s := &AsyncSink{
+		next:    next,
+		opts:    opts,
+		metrics: metrics,
+		buffer:  make([]Entry, 0, opts.BufferSize),
+		full:    make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+	s.wg.Add(1)
+	go s.run()
+	return s
+}
+
+// Write buffers entry. When the buffer is full the entry is written
+// directly with Backpressure, or rejected with ErrBufferFull otherwise.
+// Rejected entries are counted by the caller; entries failing in the
+// background are counted as errors and drops by the sink.
+func (s *AsyncSink) Write(ctx context.Context, entry Entry) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return os.ErrClosed
+	}
+	if len(s.buffer) >= s.opts.BufferSize {
+		s.mu.Unlock()
+		if s.opts.Backpressure {
+			return s.write(ctx, entry)
+		}
+		return ErrBufferFull
+	}
+
+	s.buffer = append(s.buffer, entry)
+	count := len(s.buffer)
+	s.mu.Unlock()
+
+	s.metrics.SetBufferUtilization(float64(count) / float64(s.opts.BufferSize))
+	if count >= s.opts.BufferSize {
+		select {
+		case s.full <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// Flush writes every buffered entry and flushes the wrapped sink
+func (s *AsyncSink) Flush(ctx context.Context) error {
+	s.flushBuffer(ctx)
+	return s.next.Flush(ctx)
+}
+
+// Close stops the background flusher, flushes and closes the wrapped sink
+func (s *AsyncSink) Close(ctx context.Context) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	s.mu.Unlock()
+
+	close(s.done)
+	s.wg.Wait()
+	s.flushBuffer(ctx)
+	return errors.Join(s.next.Flush(ctx), s.next.Close(ctx))
+}
+
+// Metrics returns the collector used by the sink
+func (s *AsyncSink) Metrics() *Metrics {
+	return s.metrics
+}
+
+func (s *AsyncSink) run() {
+	defer s.wg.Done()
+	ticker := time.NewTicker(s.opts.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			s.flushBuffer(context.Background())
+		case <-s.full:
+			s.flushBuffer(context.Background())
+		}
+	}
+}
+
+// flushBuffer writes the buffered entries in order. Flushes are serialized
+// so entries are never reordered.
+func (s *AsyncSink) flushBuffer(ctx context.Context) {
+	s.flushMu.Lock()
+	defer s.flushMu.Unlock()
+
+	s.mu.Lock()
+	entries := s.buffer
+	s.buffer = make([]Entry, 0, s.opts.BufferSize)
+	s.mu.Unlock()
+	s.metrics.SetBufferUtilization(0)
+
+	if len(entries) == 0 {
+		return
+	}
+	for _, entry := range entries {
+		if err := s.write(ctx, entry); err != nil {
+			s.metrics.RecordError()
+			s.metrics.RecordDropped()
+		}
+	}
+	s.metrics.RecordFlush()
+}
+
+// write writes entry to the wrapped sink with retries
+func (s *AsyncSink) write(ctx context.Context, entry Entry) error {
+	return Retry(ctx, s.opts.Retry, func() error {
+		return s.next.Write(ctx, entry)
+	}).Err
+}
@@ -0,0 +1,55 @@
+package gologger
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// MaxCorrelationIDLength matches the logger-node ensureCid default
+const MaxCorrelationIDLength = 128
+
+var correlationIDCharset = regexp.MustCompile(`^[A-Za-z0-9._:-]+$`)
+
+type correlationIDKey struct{}
+
+// WithCorrelationID returns a context carrying the correlation ID
+func WithCorrelationID(ctx context.Context, correlationID string) context.Context {
+	return context.WithValue(ctx, correlationIDKey{}, correlationID)
+}
+
+// CorrelationIDFromContext returns the correlation ID stored in ctx, if any
+func CorrelationIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	correlationID, _ := ctx.Value(correlationIDKey{}).(string)
+	return correlationID
+}
+
+// EnsureCorrelationID returns incoming when it is a valid correlation ID
+// and a new one otherwise. Like logger-node's ensureCid, values are trimmed,
+// truncated to MaxCorrelationIDLength and limited to [A-Za-z0-9._:-].
+func EnsureCorrelationID(incoming string) string {
+	normalized := strings.TrimSpace(incoming)
+	if len(normalized) > MaxCorrelationIDLength {
+		normalized = normalized[:MaxCorrelationIDLength]
+	}
+	if normalized == "" || !correlationIDCharset.MatchString(normalized) {
+		return NewCorrelationID()
+	}
+	return normalized
+}
+
+// NewCorrelationID returns a random UUID v4
+func NewCorrelationID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(fmt.Sprintf("gologger: failed to generate correlation ID: %v", err))
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
@@ -0,0 +1,169 @@
+package gologger
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Level is the severity of a log entry
+type Level int
+
+// Levels, from least to most severe, matching logger-node
+const (
+	LevelTrace Level = iota
+	LevelDebug
+	LevelInfo
+	LevelWarn
+	LevelError
+	LevelFatal
+)
+
+var levelNames = [...]string{"trace", "debug", "info", "warn", "error", "fatal"}
+
+// String returns the lowercase level name
+func (l Level) String() string {
+	if l < LevelTrace || l > LevelFatal {
+		return fmt.Sprintf("level(%d)", int(l))
+	}
+	return levelNames[l]
+}
+
+// MarshalText encodes the level as its name
+func (l Level) MarshalText() ([]byte, error) {
+	if l < LevelTrace || l > LevelFatal {
+		return nil, fmt.Errorf("invalid log level %d", int(l))
+	}
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText decodes a level name
+func (l *Level) UnmarshalText(text []byte) error {
+	level, err := ParseLevel(string(text))
+	if err != nil {
+		return err
+	}
+	*l = level
+	return nil
+}
+
+// ParseLevel parses a level name case-insensitively
+func ParseLevel(name string) (Level, error) {
+	for i, levelName := range levelNames {
+		if strings.EqualFold(name, levelName) {
+			return Level(i), nil
+		}
+	}
+	return LevelInfo, fmt.Errorf("invalid log level %q", name)
+}
+
+// Outcome reports whether the logged operation succeeded
+type Outcome string
+
+// Outcomes
+const (
+	OutcomeSuccess Outcome = "success"
+	OutcomeFailure Outcome = "failure"
+)
+
+// Fields are structured key/value pairs attached to an entry
+type Fields map[string]any
+
+// Scope identifies where an entry was produced
+type Scope struct {
+	ClassName  string `json:"className,omitempty"`
+	MethodName string `json:"methodName"`
+}
+
+// ErrorInfo describes an error attached to an entry
+type ErrorInfo struct {
+	Name    string `json:"name"`
+	Message string `json:"message"`
+	Stack   string `json:"stack,omitempty"`
+}
+
+// Entry is a log record. Its JSON form matches the logger-node LogEntry, so
+// records from both stacks can be parsed by the same pipeline.
+type Entry struct {
+	Timestamp     string     `json:"timestamp"`
+	Level         Level      `json:"level"`
+	Scope         Scope      `json:"scope"`
+	Outcome       Outcome    `json:"outcome"`
+	Args          []any      `json:"args,omitempty"`
+	Result        any        `json:"result,omitempty"`
+	Error         *ErrorInfo `json:"error,omitempty"`
+	CorrelationID string     `json:"correlationId,omitempty"`
+	DurationMs    int64      `json:"durationMs"`
+}
+
+// NewEntry creates an entry carrying message and fields the way the
+// logger-node ComposedLogger does: args holds [message, fields]
+func NewEntry(level Level, message string, fields Fields) Entry {
+	if fields == nil {
+		fields = Fields{}
+	}
+	return Entry{
+		Timestamp: FormatTimestamp(time.Now()),
+		Level:     level,
+		Scope:     Scope{MethodName: level.String()},
+		Outcome:   OutcomeSuccess,
+		Args:      []any{message, fields},
+	}
+}
+
+// Message returns the entry message, if any
+func (e Entry) Message() string {
+	if len(e.Args) == 0 {
+		return ""
+	}
+	message, _ := e.Args[0].(string)
+	return message
+}
+
+// Fields returns the entry fields, if any
+func (e Entry) Fields() Fields {
+	fields, _ := lastFields(e.Args)
+	return fields
+}
+
+// WithFields returns a copy of the entry whose fields also include fields
+func (e Entry) WithFields(fields Fields) Entry {
+	merged := make(Fields, len(e.Fields())+len(fields))
+	for k, v := range e.Fields() {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+
+	args := make([]any, len(e.Args))
+	copy(args, e.Args)
+	if _, ok := lastFields(args); ok {
+		args[len(args)-1] = merged
+	} else {
+		args = append(args, merged)
+	}
+	e.Args = args
+	return e
+}
+
+func lastFields(args []any) (Fields, bool) {
+	if len(args) == 0 {
+		return nil, false
+	}
+	fields, ok := args[len(args)-1].(Fields)
+	return fields, ok
+}
+
+// FormatTimestamp formats t like JavaScript's Date.toISOString
+func FormatTimestamp(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05.000Z")
+}
+
+// FormatError describes err for Entry.Error, using its Go type as the name
+func FormatError(err error) *ErrorInfo {
+	if err == nil {
+		return nil
+	}
+	return &ErrorInfo{Name: strings.TrimPrefix(fmt.Sprintf("%T", err), "*"), Message: err.Error()}
+}
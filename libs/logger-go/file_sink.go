@@ -0,0 +1,176 @@
+package gologger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// Rotating file defaults
+const (
+	DefaultMaxFileSize    = 10 << 20
+	DefaultMaxFileBackups = 5
+)
+
+// FileOptions configures a FileSink
+type FileOptions struct {
+	// Path of the active log file
+	Path string
+	// MaxSize is the size in bytes that triggers a rotation. Defaults to 10MiB.
+	MaxSize int64
+	// MaxBackups is how many rotated files (Path.1 ... Path.N) are kept.
+	// Defaults to 5.
+	MaxBackups int
+	// Metrics counts failed rotations as errors
+	Metrics *Metrics
+	// ErrorOutput is told when rotation starts failing and when it recovers.
+	// Defaults to os.Stderr.
+	ErrorOutput io.Writer
+}
+
+// FileSink writes JSON lines to a file, rotating it when it grows past
+// MaxSize. Rotated files are named Path.1 (newest) to Path.N (oldest).
+type FileSink struct {
+	mu   sync.Mutex
+	opts FileOptions
+	file *os.File
+	size int64
+	// rotateFailing is set while rotations fail, so failures are reported once
+	rotateFailing bool
+}
+
+// NewFileSink opens, or creates, the file at opts.Path for appending
+func NewFileSink(opts FileOptions) (*FileSink, error) {
+	if opts.Path == "" {
+		return nil, errors.New("file sink path is required")
+	}
+	if opts.MaxSize <= 0 {
+		opts.MaxSize = DefaultMaxFileSize
+	}
+	if opts.MaxBackups <= 0 {
+		opts.MaxBackups = DefaultMaxFileBackups
+	}
+	if opts.ErrorOutput == nil {
+		opts.ErrorOutput = os.Stderr
+	}
+
+	s := &FileSink{opts: opts}
+	file, size, err := s.open()
+	if err != nil {
+		return nil, err
+	}
+	s.file, s.size = file, size
+	return s, nil
+}
+
+// Write appends entry, rotating first when it would exceed MaxSize. When
+// the rotation fails the entry still goes to the active file and the
+// rotation is retried on the next write. Rotation failures are reported to
+// Metrics and ErrorOutput rather than returned, so callers retrying failed
+// writes do not write the entry twice.
+func (s *FileSink) Write(_ context.Context, entry Entry) error {
+	line, err := encodeEntry(entry)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return os.ErrClosed
+	}
+	if s.size > 0 && s.size+int64(len(line)) > s.opts.MaxSize {
+		s.reportRotation(s.rotate())
+	}
+	n, err := s.file.Write(line)
+	s.size += int64(n)
+	return err
+}
+
+// reportRotation records the outcome of a rotation, writing to ErrorOutput
+// only when rotations start failing or recover
+func (s *FileSink) reportRotation(err error) {
+	if err == nil {
+		if s.rotateFailing {
+			fmt.Fprintf(s.opts.ErrorOutput, "log file %s rotated again\n", s.opts.Path)
+		}
+		s.rotateFailing = false
+		return
+	}
+	if s.opts.Metrics != nil {
+		s.opts.Metrics.RecordError()
+	}
+	if !s.rotateFailing {
+		fmt.Fprintf(s.opts.ErrorOutput, "log file %s not rotated, writing past MaxSize: %v\n", s.opts.Path, err)
+	}
+	s.rotateFailing = true
+}
+
+// Flush syncs the file to disk
+func (s *FileSink) Flush(context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	return s.file.Sync()
+}
+
+// Close syncs and closes the file
+func (s *FileSink) Close(context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	err := errors.Join(s.file.Sync(), s.file.Close())
+	s.file = nil
+	return err
+}
+
+func (s *FileSink) open() (*os.File, int64, error) {
+	file, err := os.OpenFile(s.opts.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, 0, fmt.Errorf("failed to stat log file: %w", err)
+	}
+	return file, info.Size(), nil
+}
+
+// rotate shifts the backups, moves the active file to Path.1 and reopens
+// it. The active file stays open until the new one is, so a failed rotation
+// leaves the sink writing where it was.
+func (s *FileSink) rotate() error {
+	os.Remove(s.backup(s.opts.MaxBackups))
+	for i := s.opts.MaxBackups - 1; i >= 1; i-- {
+		if err := os.Rename(s.backup(i), s.backup(i+1)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to rotate log file: %w", err)
+		}
+	}
+	if err := os.Rename(s.opts.Path, s.backup(1)); err != nil {
+		return fmt.Errorf("failed to rotate log file: %w", err)
+	}
+
+	file, size, err := s.open()
+	if err != nil {
+		// Move the active file back so the next write retries the rotation
+		return errors.Join(err, os.Rename(s.backup(1), s.opts.Path))
+	}
+	previous := s.file
+	s.file, s.size = file, size
+	if err := previous.Close(); err != nil {
+		return fmt.Errorf("failed to close rotated log file: %w", err)
+	}
+	return nil
+}
+
+func (s *FileSink) backup(n int) string {
+	return fmt.Sprintf("%s.%d", s.opts.Path, n)
+}
@@ -0,0 +1,3 @@
+module github.com/mateusmacedo/scouts/libs/logger-go
+
+go 1.22
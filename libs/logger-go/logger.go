@@ -0,0 +1,188 @@
+// Package gologger is the Go counterpart of logger-node: a Logger writing
+// logger-node compatible entries to pluggable sinks, with a context-carried
+// correlation ID and internal metrics.
+package gologger
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// KeyCorrelationID is the field holding the correlation ID
+const KeyCorrelationID = "correlationId"
+
+// Logger writes structured log entries
+type Logger interface {
+	Trace(ctx context.Context, message string, fields ...Fields)
+	Debug(ctx context.Context, message string, fields ...Fields)
+	Info(ctx context.Context, message string, fields ...Fields)
+	Warn(ctx context.Context, message string, fields ...Fields)
+	Error(ctx context.Context, message string, fields ...Fields)
+	Fatal(ctx context.Context, message string, fields ...Fields)
+	// Log writes an entry at level and reports sink failures
+	Log(ctx context.Context, level Level, message string, fields ...Fields) error
+	WithFields(fields Fields) Logger
+	WithCorrelationID(correlationID string) Logger
+	Flush(ctx context.Context) error
+	Close(ctx context.Context) error
+}
+
+// Redactor masks sensitive values in entry fields
+type Redactor interface {
+	Redact(fields Fields) Fields
+}
+
+// Option configures a ComposedLogger
+type Option func(*ComposedLogger)
+
+// WithLevel drops entries below level. Defaults to LevelInfo.
+func WithLevel(level Level) Option {
+	return func(l *ComposedLogger) {
+		l.level = level
+	}
+}
+
+// WithMetrics records written, dropped and error counts into metrics
+func WithMetrics(metrics *Metrics) Option {
+	return func(l *ComposedLogger) {
+		l.metrics = metrics
+	}
+}
+
+// WithRedactor masks fields before they reach the sink
+func WithRedactor(redactor Redactor) Option {
+	return func(l *ComposedLogger) {
+		l.redactor = redactor
+	}
+}
+
+// ComposedLogger composes a sink with optional metrics and redaction, like
+// the logger-node ComposedLogger
+type ComposedLogger struct {
+	sink       Sink
+	level      Level
+	metrics    *Metrics
+	redactor   Redactor
+	baseFields Fields
+}
+
+// New creates a logger writing to sink
+func New(sink Sink, opts ...Option) *ComposedLogger {
+	l := &ComposedLogger{sink: sink, level: LevelInfo, metrics: NewMetrics()}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// Trace logs at trace level
+func (l *ComposedLogger) Trace(ctx context.Context, message string, fields ...Fields) {
+	l.Log(ctx, LevelTrace, message, fields...)
+}
+
+// Debug logs at debug level
+func (l *ComposedLogger) Debug(ctx context.Context, message string, fields ...Fields) {
+	l.Log(ctx, LevelDebug, message, fields...)
+}
+
+// Info logs at info level
+func (l *ComposedLogger) Info(ctx context.Context, message string, fields ...Fields) {
+	l.Log(ctx, LevelInfo, message, fields...)
+}
+
+// Warn logs at warn level
+func (l *ComposedLogger) Warn(ctx context.Context, message string, fields ...Fields) {
+	l.Log(ctx, LevelWarn, message, fields...)
+}
+
+// Error logs at error level
+func (l *ComposedLogger) Error(ctx context.Context, message string, fields ...Fields) {
+	l.Log(ctx, LevelError, message, fields...)
+}
+
+// Fatal logs at fatal level. Unlike log.Fatal it does not exit.
+func (l *ComposedLogger) Fatal(ctx context.Context, message string, fields ...Fields) {
+	l.Log(ctx, LevelFatal, message, fields...)
+}
+
+// Enabled reports whether entries at level are written
+func (l *ComposedLogger) Enabled(level Level) bool {
+	return level >= l.level
+}
+
+// Log merges the base fields with fields, redacts them and writes the entry.
+// The correlation ID comes from the fields or, failing that, from ctx.
+func (l *ComposedLogger) Log(ctx context.Context, level Level, message string, fields ...Fields) error {
+	if !l.Enabled(level) {
+		return nil
+	}
+	if message == "" {
+		return errors.New("log message is required")
+	}
+
+	start := time.Now()
+	merged := make(Fields, len(l.baseFields))
+	for k, v := range l.baseFields {
+		merged[k] = v
+	}
+	for _, f := range fields {
+		for k, v := range f {
+			merged[k] = v
+		}
+	}
+
+	if l.redactor != nil {
+		redactStart := time.Now()
+		merged = l.redactor.Redact(merged)
+		l.metrics.RecordRedaction(time.Since(redactStart))
+	}
+
+	entry := NewEntry(level, message, merged)
+	entry.CorrelationID, _ = merged[KeyCorrelationID].(string)
+	if entry.CorrelationID == "" {
+		entry.CorrelationID = CorrelationIDFromContext(ctx)
+	}
+	entry.DurationMs = time.Since(start).Milliseconds()
+
+	if err := l.sink.Write(ctx, entry); err != nil {
+		l.metrics.RecordError()
+		l.metrics.RecordDropped()
+		return err
+	}
+	l.metrics.RecordWritten()
+	return nil
+}
+
+// WithFields returns a logger adding fields to every entry
+func (l *ComposedLogger) WithFields(fields Fields) Logger {
+	child := *l
+	child.baseFields = make(Fields, len(l.baseFields)+len(fields))
+	for k, v := range l.baseFields {
+		child.baseFields[k] = v
+	}
+	for k, v := range fields {
+		child.baseFields[k] = v
+	}
+	return &child
+}
+
+// WithCorrelationID returns a logger whose entries carry correlationID
+func (l *ComposedLogger) WithCorrelationID(correlationID string) Logger {
+	return l.WithFields(Fields{KeyCorrelationID: correlationID})
+}
+
+// Flush flushes the sink
+func (l *ComposedLogger) Flush(ctx context.Context) error {
+	return l.sink.Flush(ctx)
+}
+
+// Close flushes and closes the sink
+func (l *ComposedLogger) Close(ctx context.Context) error {
+	return l.sink.Close(ctx)
+}
+
+// Metrics returns the logger metrics
+func (l *ComposedLogger) Metrics() *Metrics {
+	return l.metrics
+}
@@ -0,0 +1,239 @@
+package gologger
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memorySink records written entries
+type memorySink struct {
+	mu      sync.Mutex
+	entries []Entry
+	err     error
+	fails   int
+	flushes int
+	closed  bool
+}
+
+func (s *memorySink) Write(_ context.Context, entry Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fails > 0 {
+		s.fails--
+		return s.err
+	}
+	if s.err != nil && s.fails < 0 {
+		return s.err
+	}
+	s.entries = append(s.entries, entry)
+	return nil
+}
+
+func (s *memorySink) Flush(context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.flushes++
+	return nil
+}
+
+func (s *memorySink) Close(context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return nil
+}
+
+func (s *memorySink) Entries() []Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Entry(nil), s.entries...)
+}
+
+// upperRedactor masks the password field
+type upperRedactor struct{}
+
+func (upperRedactor) Redact(fields Fields) Fields {
+	out := Fields{}
+	for k, v := range fields {
+		if k == "password" {
+			v = "***"
+		}
+		out[k] = v
+	}
+	return out
+}
+
+func TestComposedLogger(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should write logger-node entries", func(t *testing.T) {
+		sink := &memorySink{}
+		logger := New(sink)
+
+		logger.Info(ctx, "User created", Fields{"userId": "1"})
+
+		entries := sink.Entries()
+		if len(entries) != 1 {
+			t.Fatalf("Expected 1 entry, got %d", len(entries))
+		}
+		entry := entries[0]
+		if entry.Level != LevelInfo || entry.Outcome != OutcomeSuccess || entry.Scope.MethodName != "info" {
+			t.Errorf("Expected info success entry, got %+v", entry)
+		}
+		if entry.Message() != "User created" || entry.Fields()["userId"] != "1" {
+			t.Errorf("Expected message and fields in args, got %v", entry.Args)
+		}
+
+		data, err := json.Marshal(entry)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		var decoded map[string]any
+		json.Unmarshal(data, &decoded)
+		for _, key := range []string{"timestamp", "level", "scope", "outcome", "args", "durationMs"} {
+			if _, ok := decoded[key]; !ok {
+				t.Errorf("Expected key %s in %s", key, data)
+			}
+		}
+		if decoded["level"] != "info" {
+			t.Errorf("Expected level info, got %v", decoded["level"])
+		}
+		if !strings.HasSuffix(entry.Timestamp, "Z") {
+			t.Errorf("Expected UTC ISO timestamp, got %s", entry.Timestamp)
+		}
+	})
+
+	t.Run("should drop entries below the level", func(t *testing.T) {
+		sink := &memorySink{}
+		logger := New(sink, WithLevel(LevelWarn))
+
+		logger.Info(ctx, "dropped")
+		logger.Error(ctx, "kept")
+
+		if entries := sink.Entries(); len(entries) != 1 || entries[0].Message() != "kept" {
+			t.Errorf("Expected only the error entry, got %v", entries)
+		}
+	})
+
+	t.Run("should merge fields and carry the correlation ID", func(t *testing.T) {
+		sink := &memorySink{}
+		logger := New(sink).WithFields(Fields{"component": "test"}).WithCorrelationID("cid-1")
+
+		logger.Info(ctx, "hello", Fields{"a": 1})
+
+		entry := sink.Entries()[0]
+		if entry.CorrelationID != "cid-1" {
+			t.Errorf("Expected correlation ID cid-1, got %s", entry.CorrelationID)
+		}
+		if entry.Fields()["component"] != "test" || entry.Fields()["a"] != 1 {
+			t.Errorf("Expected merged fields, got %v", entry.Fields())
+		}
+	})
+
+	t.Run("should take the correlation ID from the context", func(t *testing.T) {
+		sink := &memorySink{}
+		New(sink).Info(WithCorrelationID(ctx, "cid-2"), "hello")
+
+		if got := sink.Entries()[0].CorrelationID; got != "cid-2" {
+			t.Errorf("Expected correlation ID cid-2, got %s", got)
+		}
+	})
+
+	t.Run("should redact fields", func(t *testing.T) {
+		sink := &memorySink{}
+		metrics := NewMetrics()
+		New(sink, WithRedactor(upperRedactor{}), WithMetrics(metrics)).Info(ctx, "login", Fields{"password": "secret"})
+
+		if got := sink.Entries()[0].Fields()["password"]; got != "***" {
+			t.Errorf("Expected redacted password, got %v", got)
+		}
+		if metrics.Snapshot().RedactCount != 1 {
+			t.Errorf("Expected 1 redaction, got %d", metrics.Snapshot().RedactCount)
+		}
+	})
+
+	t.Run("should count written and dropped entries", func(t *testing.T) {
+		sink := &memorySink{err: errors.New("down"), fails: 1}
+		metrics := NewMetrics()
+		logger := New(sink, WithMetrics(metrics))
+
+		if err := logger.Log(ctx, LevelInfo, "first"); err == nil {
+			t.Error("Expected sink error")
+		}
+		logger.Info(ctx, "second")
+
+		snapshot := metrics.Snapshot()
+		if snapshot.LogsWritten != 1 || snapshot.LogsDropped != 1 || snapshot.ErrorCount != 1 {
+			t.Errorf("Expected 1 written, 1 dropped and 1 error, got %+v", snapshot)
+		}
+	})
+
+	t.Run("should reject empty messages", func(t *testing.T) {
+		if err := New(&memorySink{}).Log(ctx, LevelInfo, ""); err == nil {
+			t.Error("Expected error for empty message")
+		}
+	})
+}
+
+func TestLevel(t *testing.T) {
+	t.Run("should parse level names", func(t *testing.T) {
+		level, err := ParseLevel("WARN")
+		if err != nil || level != LevelWarn {
+			t.Errorf("Expected warn, got %v (%v)", level, err)
+		}
+		if _, err := ParseLevel("verbose"); err == nil {
+			t.Error("Expected error for unknown level")
+		}
+	})
+}
+
+func TestMetrics(t *testing.T) {
+	t.Run("should snapshot and reset", func(t *testing.T) {
+		metrics := NewMetrics()
+		metrics.RecordWritten()
+		metrics.RecordFlush()
+		metrics.RecordRedaction(2 * time.Millisecond)
+		metrics.SetBufferUtilization(0.5)
+
+		snapshot := metrics.Snapshot()
+		if snapshot.LogsWritten != 1 || snapshot.FlushCount != 1 || snapshot.BufferUtilization != 0.5 {
+			t.Errorf("Unexpected snapshot %+v", snapshot)
+		}
+		if snapshot.RedactLatencyMs != 2 {
+			t.Errorf("Expected 2ms redact latency, got %v", snapshot.RedactLatencyMs)
+		}
+
+		metrics.Reset()
+		if snapshot := metrics.Snapshot(); snapshot.LogsWritten != 0 || snapshot.RedactCount != 0 {
+			t.Errorf("Expected zeroed metrics, got %+v", snapshot)
+		}
+	})
+}
+
+func TestCorrelationID(t *testing.T) {
+	t.Run("should keep valid IDs", func(t *testing.T) {
+		if got := EnsureCorrelationID("  req-1:abc  "); got != "req-1:abc" {
+			t.Errorf("Expected req-1:abc, got %s", got)
+		}
+	})
+
+	t.Run("should replace invalid IDs", func(t *testing.T) {
+		for _, incoming := range []string{"", "has space", "<script>"} {
+			got := EnsureCorrelationID(incoming)
+			if got == incoming || len(got) != 36 {
+				t.Errorf("Expected a generated UUID for %q, got %s", incoming, got)
+			}
+		}
+	})
+
+	t.Run("should truncate long IDs", func(t *testing.T) {
+		if got := EnsureCorrelationID(strings.Repeat("a", 200)); len(got) != MaxCorrelationIDLength {
+			t.Errorf("Expected %d characters, got %d", MaxCorrelationIDLength, len(got))
+		}
+	})
+}
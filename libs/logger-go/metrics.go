@@ -0,0 +1,107 @@
+package gologger
+
+import (
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// MetricsSnapshot mirrors the logger-node BaseLoggerMetrics
+type MetricsSnapshot struct {
+	LogsWritten       int64   `json:"logsWritten"`
+	LogsDropped       int64   `json:"logsDropped"`
+	ErrorCount        int64   `json:"errorCount"`
+	UptimeMs          int64   `json:"uptimeMs"`
+	RedactCount       int64   `json:"redactCount"`
+	RedactLatencyMs   float64 `json:"redactLatencyMs"`
+	FlushCount        int64   `json:"flushCount"`
+	BufferUtilization float64 `json:"bufferUtilization"`
+}
+
+// Metrics collects internal logger metrics. It is safe for concurrent use
+// and may be shared between a logger and its sinks.
+type Metrics struct {
+	logsWritten atomic.Int64
+	logsDropped atomic.Int64
+	errorCount  atomic.Int64
+	flushCount  atomic.Int64
+	utilization atomic.Uint64
+
+	mu            sync.Mutex
+	start         time.Time
+	redactCount   int64
+	redactLatency time.Duration
+}
+
+// NewMetrics creates a metrics collector
+func NewMetrics() *Metrics {
+	return &Metrics{start: time.Now()}
+}
+
+// RecordWritten counts a record accepted by the sink
+func (m *Metrics) RecordWritten() {
+	m.logsWritten.Add(1)
+}
+
+// RecordDropped counts a record that was never written
+func (m *Metrics) RecordDropped() {
+	m.logsDropped.Add(1)
+}
+
+// RecordError counts a sink failure
+func (m *Metrics) RecordError() {
+	m.errorCount.Add(1)
+}
+
+// RecordFlush counts a flush
+func (m *Metrics) RecordFlush() {
+	m.flushCount.Add(1)
+}
+
+// RecordRedaction counts a redaction and its latency
+func (m *Metrics) RecordRedaction(latency time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.redactCount++
+	m.redactLatency += latency
+}
+
+// SetBufferUtilization records how full a buffer is, between 0 and 1
+func (m *Metrics) SetBufferUtilization(utilization float64) {
+	m.utilization.Store(math.Float64bits(utilization))
+}
+
+// Snapshot returns the current values. RedactLatencyMs is the average.
+func (m *Metrics) Snapshot() MetricsSnapshot {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	snapshot := MetricsSnapshot{
+		LogsWritten:       m.logsWritten.Load(),
+		LogsDropped:       m.logsDropped.Load(),
+		ErrorCount:        m.errorCount.Load(),
+		UptimeMs:          time.Since(m.start).Milliseconds(),
+		RedactCount:       m.redactCount,
+		FlushCount:        m.flushCount.Load(),
+		BufferUtilization: math.Float64frombits(m.utilization.Load()),
+	}
+	if m.redactCount > 0 {
+		snapshot.RedactLatencyMs = float64(m.redactLatency.Microseconds()) / 1000 / float64(m.redactCount)
+	}
+	return snapshot
+}
+
+// Reset zeroes every counter and restarts the uptime
+func (m *Metrics) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.logsWritten.Store(0)
+	m.logsDropped.Store(0)
+	m.errorCount.Store(0)
+	m.flushCount.Store(0)
+	m.utilization.Store(0)
+	m.redactCount = 0
+	m.redactLatency = 0
+	m.start = time.Now()
+}
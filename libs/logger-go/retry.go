@@ -0,0 +1,90 @@
+package gologger
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// RetryOptions configures Retry with exponential backoff and jitter
+type RetryOptions struct {
+	// MaxAttempts includes the first attempt. Defaults to 3.
+	MaxAttempts int
+	// InitialDelay is the delay before the second attempt. Defaults to 100ms.
+	InitialDelay time.Duration
+	// BackoffMultiplier grows the delay between attempts. Defaults to 2.
+	BackoffMultiplier float64
+	// MaxDelay caps the delay between attempts. Defaults to 1s.
+	MaxDelay time.Duration
+	// Jitter randomizes each delay by up to this fraction
+	Jitter float64
+	// ShouldRetry decides whether err is retried. Nil retries every error.
+	ShouldRetry func(err error, attempt int) bool
+}
+
+// Retry presets matching logger-node RetryPresets
+var (
+	RetryFast       = RetryOptions{MaxAttempts: 3, InitialDelay: 50 * time.Millisecond, BackoffMultiplier: 2, MaxDelay: 200 * time.Millisecond, Jitter: 0.1}
+	RetryStandard   = RetryOptions{MaxAttempts: 3, InitialDelay: 100 * time.Millisecond, BackoffMultiplier: 2, MaxDelay: time.Second, Jitter: 0.1}
+	RetrySlow       = RetryOptions{MaxAttempts: 5, InitialDelay: 200 * time.Millisecond, BackoffMultiplier: 2, MaxDelay: 2 * time.Second, Jitter: 0.2}
+	RetryAggressive = RetryOptions{MaxAttempts: 5, InitialDelay: 50 * time.Millisecond, BackoffMultiplier: 1.5, MaxDelay: 500 * time.Millisecond, Jitter: 0.05}
+)
+
+// RetryResult reports how a retried call went
+type RetryResult struct {
+	Err        error
+	Attempts   int
+	TotalDelay time.Duration
+}
+
+// Retry calls fn until it succeeds, ShouldRetry rejects its error, the
+// attempts are exhausted or ctx is done
+func Retry(ctx context.Context, opts RetryOptions, fn func() error) RetryResult {
+	opts = opts.withDefaults()
+
+	var result RetryResult
+	for attempt := 1; attempt <= opts.MaxAttempts; attempt++ {
+		result.Attempts = attempt
+		if result.Err = fn(); result.Err == nil {
+			return result
+		}
+		if attempt == opts.MaxAttempts || (opts.ShouldRetry != nil && !opts.ShouldRetry(result.Err, attempt)) {
+			return result
+		}
+
+		delay := opts.delay(attempt)
+		result.TotalDelay += delay
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return result
+		case <-timer.C:
+		}
+	}
+	return result
+}
+
+func (o RetryOptions) withDefaults() RetryOptions {
+	if o.MaxAttempts <= 0 {
+		o.MaxAttempts = 3
+	}
+	if o.InitialDelay <= 0 {
+		o.InitialDelay = 100 * time.Millisecond
+	}
+	if o.BackoffMultiplier <= 0 {
+		o.BackoffMultiplier = 2
+	}
+	if o.MaxDelay <= 0 {
+		o.MaxDelay = time.Second
+	}
+	return o
+}
+
+// delay returns the backoff before the attempt following attempt
+func (o RetryOptions) delay(attempt int) time.Duration {
+	base := math.Min(float64(o.InitialDelay)*math.Pow(o.BackoffMultiplier, float64(attempt-1)), float64(o.MaxDelay))
+	jitter := base * o.Jitter * (rand.Float64()*2 - 1)
+	return time.Duration(math.Max(0, base+jitter))
+}
@@ -0,0 +1,119 @@
+package gologger
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+)
+
+// Sink is the final destination of log entries
+type Sink interface {
+	Write(ctx context.Context, entry Entry) error
+	Flush(ctx context.Context) error
+	Close(ctx context.Context) error
+}
+
+// WriterSink writes entries as JSON lines to an io.Writer
+type WriterSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewWriterSink creates a sink writing JSON lines to w
+func NewWriterSink(w io.Writer) *WriterSink {
+	return &WriterSink{w: w}
+}
+
+// NewStdoutSink creates a sink writing JSON lines to standard output
+func NewStdoutSink() *WriterSink {
+	return NewWriterSink(os.Stdout)
+}
+
+// Write encodes entry as one JSON line
+func (s *WriterSink) Write(_ context.Context, entry Entry) error {
+	line, err := encodeEntry(entry)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.w.Write(line)
+	return err
+}
+
+// Flush syncs the writer when it supports it
+func (s *WriterSink) Flush(context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if syncer, ok := s.w.(interface{ Sync() error }); ok && s.w != os.Stdout && s.w != os.Stderr {
+		return syncer.Sync()
+	}
+	return nil
+}
+
+// Close flushes the writer. The writer itself is owned by the caller.
+func (s *WriterSink) Close(ctx context.Context) error {
+	return s.Flush(ctx)
+}
+
+func encodeEntry(entry Entry) ([]byte, error) {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return nil, err
+	}
+	return append(line, '\n'), nil
+}
+
+// Enrichment holds the fields added to every entry, like the logger-node
+// BaseEnrichmentOptions
+type Enrichment struct {
+	Service     string
+	Environment string
+	Version     string
+	Fields      Fields
+}
+
+// EnrichingSink adds the enrichment fields to every entry before
+// delegating to the wrapped sink
+type EnrichingSink struct {
+	next   Sink
+	fields Fields
+}
+
+// Enrich wraps next so every entry carries the enrichment fields
+func Enrich(next Sink, enrichment Enrichment) *EnrichingSink {
+	fields := Fields{}
+	if enrichment.Service != "" {
+		fields["service"] = enrichment.Service
+	}
+	if enrichment.Environment != "" {
+		fields["environment"] = enrichment.Environment
+	}
+	if enrichment.Version != "" {
+		fields["version"] = enrichment.Version
+	}
+	for k, v := range enrichment.Fields {
+		fields[k] = v
+	}
+	return &EnrichingSink{next: next, fields: fields}
+}
+
+// Write enriches entry and delegates to the wrapped sink
+func (s *EnrichingSink) Write(ctx context.Context, entry Entry) error {
+	if len(s.fields) > 0 {
+		entry = entry.WithFields(s.fields)
+	}
+	return s.next.Write(ctx, entry)
+}
+
+// Flush delegates to the wrapped sink
+func (s *EnrichingSink) Flush(ctx context.Context) error {
+	return s.next.Flush(ctx)
+}
+
+// Close delegates to the wrapped sink
+func (s *EnrichingSink) Close(ctx context.Context) error {
+	return s.next.Close(ctx)
+}
@@ -0,0 +1,318 @@
+package gologger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriterSink(t *testing.T) {
+	t.Run("should write JSON lines", func(t *testing.T) {
+		var buf bytes.Buffer
+		sink := NewWriterSink(&buf)
+
+		sink.Write(context.Background(), NewEntry(LevelWarn, "first", nil))
+		sink.Write(context.Background(), NewEntry(LevelInfo, "second", Fields{"k": "v"}))
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		if len(lines) != 2 {
+			t.Fatalf("Expected 2 lines, got %d", len(lines))
+		}
+		var decoded Entry
+		if err := json.Unmarshal([]byte(lines[0]), &decoded); err != nil {
+			t.Fatalf("Expected valid JSON, got %v", err)
+		}
+		if decoded.Level != LevelWarn {
+			t.Errorf("Expected warn level, got %v", decoded.Level)
+		}
+	})
+}
+
+func TestEnrich(t *testing.T) {
+	t.Run("should add enrichment fields", func(t *testing.T) {
+		next := &memorySink{}
+		sink := Enrich(next, Enrichment{Service: "svc", Environment: "test", Version: "1.0.0", Fields: Fields{"region": "br"}})
+
+		entry := NewEntry(LevelInfo, "hello", Fields{"a": 1})
+		sink.Write(context.Background(), entry)
+
+		fields := next.Entries()[0].Fields()
+		for key, want := range map[string]any{"service": "svc", "environment": "test", "version": "1.0.0", "region": "br", "a": 1} {
+			if fields[key] != want {
+				t.Errorf("Expected %s=%v, got %v", key, want, fields[key])
+			}
+		}
+		if _, ok := entry.Fields()["service"]; ok {
+			t.Error("Expected the original entry to be left untouched")
+		}
+	})
+}
+
+func TestFileSink(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should rotate when the file grows past MaxSize", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "app.log")
+		sink, err := NewFileSink(FileOptions{Path: path, MaxSize: 200, MaxBackups: 2})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		for i := 0; i < 10; i++ {
+			if err := sink.Write(ctx, NewEntry(LevelInfo, "message", Fields{"i": i})); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+		}
+		if err := sink.Close(ctx); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		for _, name := range []string{path, path + ".1", path + ".2"} {
+			info, err := os.Stat(name)
+			if err != nil {
+				t.Errorf("Expected %s to exist, got %v", name, err)
+				continue
+			}
+			if info.Size() > 200 {
+				t.Errorf("Expected %s to be at most 200 bytes, got %d", name, info.Size())
+			}
+		}
+		if _, err := os.Stat(path + ".3"); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("Expected at most 2 backups, got %v", err)
+		}
+	})
+
+	t.Run("should keep writing and retry when rotation fails", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "app.log")
+		var output bytes.Buffer
+		metrics := NewMetrics()
+		sink, _ := NewFileSink(FileOptions{Path: path, MaxSize: 100, MaxBackups: 1, Metrics: metrics, ErrorOutput: &output})
+		defer sink.Close(ctx)
+
+		// A non-empty directory in place of the backup makes the rename fail
+		blocker := filepath.Join(path+".1", "blocker")
+		os.MkdirAll(blocker, 0o755)
+
+		sink.Write(ctx, NewEntry(LevelInfo, "first", nil))
+		if err := sink.Write(ctx, NewEntry(LevelInfo, "second", nil)); err != nil {
+			t.Errorf("Expected the written entry to succeed, got %v", err)
+		}
+		sink.Write(ctx, NewEntry(LevelInfo, "still", nil))
+		if data, _ := os.ReadFile(path); !strings.Contains(string(data), "second") {
+			t.Errorf("Expected the entry in the active file, got %q", data)
+		}
+		if metrics.Snapshot().ErrorCount != 2 || strings.Count(output.String(), "not rotated") != 1 {
+			t.Errorf("Expected 2 errors reported once, got %d and %q", metrics.Snapshot().ErrorCount, output.String())
+		}
+
+		os.RemoveAll(path + ".1")
+		if err := sink.Write(ctx, NewEntry(LevelInfo, "third", nil)); err != nil {
+			t.Fatalf("Expected the rotation to be retried, got %v", err)
+		}
+		if data, _ := os.ReadFile(path); !strings.Contains(string(data), "third") || strings.Contains(string(data), "second") {
+			t.Errorf("Expected only the new entry in the active file, got %q", data)
+		}
+		if data, _ := os.ReadFile(path + ".1"); !strings.Contains(string(data), "second") {
+			t.Errorf("Expected the rotated entries in the backup, got %q", data)
+		}
+		if !strings.Contains(output.String(), "rotated again") {
+			t.Errorf("Expected the recovery to be reported, got %q", output.String())
+		}
+	})
+
+	t.Run("should not duplicate async entries when rotation fails", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "app.log")
+		file, _ := NewFileSink(FileOptions{Path: path, MaxSize: 100, MaxBackups: 1, ErrorOutput: io.Discard})
+		os.MkdirAll(filepath.Join(path+".1", "blocker"), 0o755)
+
+		sink := NewAsyncSink(file, AsyncOptions{FlushInterval: time.Hour, Retry: RetryOptions{MaxAttempts: 3, InitialDelay: time.Millisecond}})
+		for i := 0; i < 5; i++ {
+			sink.Write(ctx, NewEntry(LevelInfo, "message", Fields{"i": i}))
+		}
+		sink.Close(ctx)
+
+		data, _ := os.ReadFile(path)
+		if lines := strings.Count(string(data), "\n"); lines != 5 {
+			t.Errorf("Expected each entry written once, got %d lines", lines)
+		}
+		if snapshot := sink.Metrics().Snapshot(); snapshot.LogsDropped != 0 || snapshot.ErrorCount != 0 {
+			t.Errorf("Expected no failed writes, got %+v", snapshot)
+		}
+	})
+
+	t.Run("should fail after close", func(t *testing.T) {
+		sink, _ := NewFileSink(FileOptions{Path: filepath.Join(t.TempDir(), "app.log")})
+		sink.Close(ctx)
+
+		if err := sink.Write(ctx, NewEntry(LevelInfo, "late", nil)); !errors.Is(err, os.ErrClosed) {
+			t.Errorf("Expected os.ErrClosed, got %v", err)
+		}
+	})
+}
+
+func TestAsyncSink(t *testing.T) {
+	ctx := context.Background()
+	fastRetry := RetryOptions{MaxAttempts: 3, InitialDelay: time.Millisecond, MaxDelay: time.Millisecond}
+
+	t.Run("should write buffered entries on flush", func(t *testing.T) {
+		next := &memorySink{}
+		sink := NewAsyncSink(next, AsyncOptions{BufferSize: 10, FlushInterval: time.Hour})
+		defer sink.Close(ctx)
+
+		sink.Write(ctx, NewEntry(LevelInfo, "one", nil))
+		sink.Write(ctx, NewEntry(LevelInfo, "two", nil))
+		if len(next.Entries()) != 0 {
+			t.Error("Expected entries to be buffered")
+		}
+
+		sink.Flush(ctx)
+		entries := next.Entries()
+		if len(entries) != 2 || entries[0].Message() != "one" || entries[1].Message() != "two" {
+			t.Errorf("Expected both entries in order, got %v", entries)
+		}
+		if sink.Metrics().Snapshot().FlushCount != 1 {
+			t.Errorf("Expected 1 flush, got %d", sink.Metrics().Snapshot().FlushCount)
+		}
+	})
+
+	t.Run("should reject entries when full", func(t *testing.T) {
+		next := &memorySink{}
+		sink := NewAsyncSink(next, AsyncOptions{BufferSize: 1, FlushInterval: time.Hour})
+		defer sink.Close(ctx)
+
+		// Hold the flush lock so the full-buffer flush cannot drain the buffer
+		sink.flushMu.Lock()
+		sink.Write(ctx, NewEntry(LevelInfo, "kept", nil))
+		err := sink.Write(ctx, NewEntry(LevelInfo, "rejected", nil))
+		sink.flushMu.Unlock()
+
+		if !errors.Is(err, ErrBufferFull) {
+			t.Errorf("Expected ErrBufferFull, got %v", err)
+		}
+	})
+
+	t.Run("should write directly with backpressure", func(t *testing.T) {
+		next := &memorySink{}
+		sink := NewAsyncSink(next, AsyncOptions{BufferSize: 1, FlushInterval: time.Hour, Backpressure: true})
+		defer sink.Close(ctx)
+
+		sink.flushMu.Lock()
+		sink.Write(ctx, NewEntry(LevelInfo, "buffered", nil))
+		err := sink.Write(ctx, NewEntry(LevelInfo, "direct", nil))
+		sink.flushMu.Unlock()
+
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if entries := next.Entries(); len(entries) == 0 || entries[0].Message() != "direct" {
+			t.Errorf("Expected the direct entry to be written, got %v", entries)
+		}
+	})
+
+	t.Run("should retry failed writes", func(t *testing.T) {
+		next := &memorySink{err: errors.New("unavailable"), fails: 2}
+		sink := NewAsyncSink(next, AsyncOptions{FlushInterval: time.Hour, Retry: fastRetry})
+		defer sink.Close(ctx)
+
+		sink.Write(ctx, NewEntry(LevelInfo, "eventually", nil))
+		sink.Flush(ctx)
+
+		if len(next.Entries()) != 1 {
+			t.Errorf("Expected the entry after retries, got %v", next.Entries())
+		}
+	})
+
+	t.Run("should drop entries failing every retry", func(t *testing.T) {
+		next := &memorySink{err: errors.New("down"), fails: -1}
+		metrics := NewMetrics()
+		sink := NewAsyncSink(next, AsyncOptions{FlushInterval: time.Hour, Retry: fastRetry, Metrics: metrics})
+		defer sink.Close(ctx)
+
+		sink.Write(ctx, NewEntry(LevelInfo, "lost", nil))
+		sink.Flush(ctx)
+
+		if snapshot := metrics.Snapshot(); snapshot.LogsDropped != 1 || snapshot.ErrorCount != 1 {
+			t.Errorf("Expected 1 dropped and 1 error, got %+v", snapshot)
+		}
+	})
+
+	t.Run("should flush periodically and on close", func(t *testing.T) {
+		next := &memorySink{}
+		sink := NewAsyncSink(next, AsyncOptions{FlushInterval: 10 * time.Millisecond})
+
+		sink.Write(ctx, NewEntry(LevelInfo, "tick", nil))
+		deadline := time.Now().Add(time.Second)
+		for len(next.Entries()) == 0 && time.Now().Before(deadline) {
+			time.Sleep(5 * time.Millisecond)
+		}
+		if len(next.Entries()) != 1 {
+			t.Fatalf("Expected the periodic flush to write the entry")
+		}
+
+		sink.Write(ctx, NewEntry(LevelInfo, "closing", nil))
+		sink.Close(ctx)
+		if len(next.Entries()) != 2 || !next.closed {
+			t.Errorf("Expected close to flush and close the wrapped sink")
+		}
+		if err := sink.Write(ctx, NewEntry(LevelInfo, "late", nil)); !errors.Is(err, os.ErrClosed) {
+			t.Errorf("Expected os.ErrClosed, got %v", err)
+		}
+	})
+}
+
+func TestRetry(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should stop on success", func(t *testing.T) {
+		calls := 0
+		result := Retry(ctx, RetryOptions{MaxAttempts: 5, InitialDelay: time.Millisecond}, func() error {
+			calls++
+			if calls < 3 {
+				return errors.New("retry")
+			}
+			return nil
+		})
+		if result.Err != nil || result.Attempts != 3 {
+			t.Errorf("Expected success on attempt 3, got %+v", result)
+		}
+	})
+
+	t.Run("should honor ShouldRetry", func(t *testing.T) {
+		permanent := errors.New("permanent")
+		result := Retry(ctx, RetryOptions{
+			MaxAttempts: 5,
+			ShouldRetry: func(err error, attempt int) bool { return !errors.Is(err, permanent) },
+		}, func() error { return permanent })
+		if result.Attempts != 1 || !errors.Is(result.Err, permanent) {
+			t.Errorf("Expected a single attempt, got %+v", result)
+		}
+	})
+
+	t.Run("should stop when the context is done", func(t *testing.T) {
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		result := Retry(cancelled, RetryOptions{MaxAttempts: 5, InitialDelay: time.Hour}, func() error {
+			return errors.New("fail")
+		})
+		if result.Attempts != 1 {
+			t.Errorf("Expected 1 attempt, got %d", result.Attempts)
+		}
+	})
+
+	t.Run("should cap the backoff", func(t *testing.T) {
+		opts := RetryOptions{InitialDelay: 100 * time.Millisecond, BackoffMultiplier: 2, MaxDelay: 250 * time.Millisecond}.withDefaults()
+		if got := opts.delay(1); got != 100*time.Millisecond {
+			t.Errorf("Expected 100ms, got %v", got)
+		}
+		if got := opts.delay(5); got != 250*time.Millisecond {
+			t.Errorf("Expected 250ms, got %v", got)
+		}
+	})
+}
@@ -0,0 +1,135 @@
+package gologger
+
+import (
+	"context"
+	"log/slog"
+)
+
+// SlogHandler is a slog.Handler writing through a Logger, so code using
+// log/slog produces logger-node compatible entries
+type SlogHandler struct {
+	logger Logger
+	attrs  Fields
+	groups []string
+}
+
+// NewSlogHandler creates a slog handler writing to logger
+func NewSlogHandler(logger Logger) *SlogHandler {
+	return &SlogHandler{logger: logger, attrs: Fields{}}
+}
+
+// LevelFromSlog maps a slog level to the closest Level. Levels below debug
+// map to trace and levels above error map to fatal.
+func LevelFromSlog(level slog.Level) Level {
+	switch {
+	case level < slog.LevelDebug:
+		return LevelTrace
+	case level < slog.LevelInfo:
+		return LevelDebug
+	case level < slog.LevelWarn:
+		return LevelInfo
+	case level < slog.LevelError:
+		return LevelWarn
+	case level == slog.LevelError:
+		return LevelError
+	default:
+		return LevelFatal
+	}
+}
+
+// Enabled reports whether the logger writes entries at level
+func (h *SlogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	if leveled, ok := h.logger.(interface{ Enabled(Level) bool }); ok {
+		return leveled.Enabled(LevelFromSlog(level))
+	}
+	return true
+}
+
+// Handle converts record into an entry. Groups become nested fields.
+func (h *SlogHandler) Handle(ctx context.Context, record slog.Record) error {
+	fields := copyFields(h.attrs)
+	target := groupFields(fields, h.groups)
+	record.Attrs(func(attr slog.Attr) bool {
+		addAttr(target, attr)
+		return true
+	})
+	return h.logger.Log(ctx, LevelFromSlog(record.Level), record.Message, fields)
+}
+
+// WithAttrs returns a handler adding attrs to every entry
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	fields := copyFields(h.attrs)
+	target := groupFields(fields, h.groups)
+	for _, attr := range attrs {
+		addAttr(target, attr)
+	}
+	return &SlogHandler{logger: h.logger, attrs: fields, groups: h.groups}
+}
+
+// WithGroup returns a handler nesting later attributes under name
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	groups := make([]string, len(h.groups)+1)
+	copy(groups, h.groups)
+	groups[len(h.groups)] = name
+	return &SlogHandler{logger: h.logger, attrs: copyFields(h.attrs), groups: groups}
+}
+
+// groupFields returns the nested fields of groups inside fields, creating them as needed
+func groupFields(fields Fields, groups []string) Fields {
+	for _, group := range groups {
+		nested, ok := fields[group].(Fields)
+		if !ok {
+			nested = Fields{}
+			fields[group] = nested
+		}
+		fields = nested
+	}
+	return fields
+}
+
+func addAttr(fields Fields, attr slog.Attr) {
+	value := attr.Value.Resolve()
+	if value.Kind() == slog.KindGroup {
+		target := fields
+		if attr.Key != "" {
+			target = groupFields(fields, []string{attr.Key})
+		}
+		for _, member := range value.Group() {
+			addAttr(target, member)
+		}
+		return
+	}
+	if attr.Key == "" {
+		return
+	}
+
+	switch value.Kind() {
+	case slog.KindTime:
+		fields[attr.Key] = FormatTimestamp(value.Time())
+	case slog.KindDuration:
+		fields[attr.Key] = value.Duration().String()
+	case slog.KindAny:
+		if err, ok := value.Any().(error); ok {
+			fields[attr.Key] = err.Error()
+			return
+		}
+		fields[attr.Key] = value.Any()
+	default:
+		fields[attr.Key] = value.Any()
+	}
+}
+
+// copyFields deep-copies nested Fields so derived handlers never share maps
+func copyFields(fields Fields) Fields {
+	out := make(Fields, len(fields))
+	for k, v := range fields {
+		if nested, ok := v.(Fields); ok {
+			v = copyFields(nested)
+		}
+		out[k] = v
+	}
+	return out
+}
@@ -0,0 +1,57 @@
+package gologger
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+)
+
+func TestSlogHandler(t *testing.T) {
+	t.Run("should write slog records as entries", func(t *testing.T) {
+		sink := &memorySink{}
+		logger := slog.New(NewSlogHandler(New(sink, WithLevel(LevelDebug))))
+
+		logger.With("service", "svc").WithGroup("http").InfoContext(
+			WithCorrelationID(context.Background(), "cid-1"),
+			"HTTP Response", "status", 200, slog.Group("client", "ip", "127.0.0.1"), "error", errors.New("boom"),
+		)
+
+		entries := sink.Entries()
+		if len(entries) != 1 {
+			t.Fatalf("Expected 1 entry, got %d", len(entries))
+		}
+		entry := entries[0]
+		if entry.Message() != "HTTP Response" || entry.CorrelationID != "cid-1" {
+			t.Errorf("Unexpected entry %+v", entry)
+		}
+		fields := entry.Fields()
+		if fields["service"] != "svc" {
+			t.Errorf("Expected top-level service, got %v", fields)
+		}
+		http, _ := fields["http"].(Fields)
+		if http["status"] != int64(200) || http["error"] != "boom" {
+			t.Errorf("Expected grouped fields, got %v", http)
+		}
+		if client, _ := http["client"].(Fields); client["ip"] != "127.0.0.1" {
+			t.Errorf("Expected nested group, got %v", http["client"])
+		}
+	})
+
+	t.Run("should map slog levels", func(t *testing.T) {
+		sink := &memorySink{}
+		logger := slog.New(NewSlogHandler(New(sink, WithLevel(LevelWarn))))
+
+		logger.Info("dropped")
+		logger.Warn("warn")
+		logger.Log(context.Background(), slog.LevelError+4, "fatal")
+
+		entries := sink.Entries()
+		if len(entries) != 2 || entries[0].Level != LevelWarn || entries[1].Level != LevelFatal {
+			t.Errorf("Expected warn and fatal entries, got %v", entries)
+		}
+		if LevelFromSlog(slog.LevelDebug-4) != LevelTrace {
+			t.Error("Expected levels below debug to map to trace")
+		}
+	})
+}
@@ -97,6 +97,17 @@ describe('DefaultRedactor', () => {
 			// Assert
 			expect(result).toEqual({ user: 'john', hash: '***' });
 		});
+
+		it('should_redact_phone_patterns_when_present', async () => {
+			// Arrange
+			const input = { user: 'john', contact: 'call +55 11 98765-4321 today' };
+
+			// Act
+			const result = await redactor.redact(input);
+
+			// Assert
+			expect(result).toEqual({ user: 'john', contact: 'call *** today' });
+		});
 	});
 
 	describe('Depth Limitation', () => {
@@ -1,9 +1,11 @@
+import * as defaultRules from './default-rules.json';
 import type { Redactor, RedactorOptions } from './redactor';
 
 /**
  * Implementação padrão do Redactor
  * Fornece redação segura de dados sensíveis com timeout protection contra ReDoS
  * Suporta redação por chaves e padrões com configurações avançadas
+ * As chaves e padrões padrão vêm de default-rules.json, compartilhado com o redactor Go
  *
  * @example
  * ```typescript
@@ -36,36 +38,9 @@ export class DefaultRedactor implements Redactor {
 	 */
 	constructor(opts: RedactorOptions = {}) {
 		const {
-			keys = [
-				'password',
-				'passwd',
-				'pass',
-				'pwd',
-				'token',
-				'access_token',
-				'refresh_token',
-				'authorization',
-				'auth',
-				'secret',
-				'apiKey',
-				'api_key',
-				'apikey',
-				'client_secret',
-				'card',
-				'cardNumber',
-				'cvv',
-				'cvc',
-				'ssn',
-				'cpf',
-				'cnpj',
-			],
-			patterns = [
-				/\b\d{3}\.?\d{3}\.?\d{3}-?\d{2}\b/gi,
-				/\b\d{2}\.?\d{3}\.?\d{3}\/?\d{4}-?\d{2}\b/gi,
-				/\b[a-z0-9._%+-]+@[a-z0-9.-]+\.[a-z]{2,}\b/gi,
-				/\b(?:[A-Fa-f0-9]{32,64})\b/g,
-			],
-			mask = '***',
+			keys = [...defaultRules.keys],
+			patterns = defaultRules.patterns.map((p) => new RegExp(p.source, 'gi')),
+			mask = defaultRules.mask,
 			maxDepth = 5,
 			keepLengths = false,
 			redactArrayIndices = true,
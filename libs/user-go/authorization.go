@@ -0,0 +1,196 @@
+package gouser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Operation identifies a user operation subject to authorization
+type Operation string
+
+// User operations
+const (
+	OperationCreate Operation = "create"
+	OperationRead   Operation = "read"
+	OperationList   Operation = "list"
+	OperationUpdate Operation = "update"
+	OperationDelete Operation = "delete"
+)
+
+// AuthorizationRequest describes an operation to be authorized
+type AuthorizationRequest struct {
+	Principal *Principal
+	Operation Operation
+	// Target is the user being read, updated or deleted. It is nil for create
+	// and list, and for the operation-level check that read, update and
+	// delete make before loading their target.
+	Target *User
+	// Fields lists the fields written by create and update operations
+	Fields []string
+}
+
+// Policy decides whether a principal may perform an operation. It returns
+// nil when allowed and an error wrapping ErrForbidden otherwise.
+type Policy interface {
+	Authorize(ctx context.Context, req AuthorizationRequest) error
+}
+
+// Grant scopes
+const (
+	ScopeAny  = "any"
+	ScopeSelf = "self"
+)
+
+// Grant allows a set of operations, optionally only on the principal's own
+// user and only for some fields
+type Grant struct {
+	// Operations lists allowed operations; "*" allows all
+	Operations []Operation `json:"operations"`
+	// Scope is "any" (default) or "self"
+	Scope string `json:"scope,omitempty"`
+	// Fields restricts writable fields; empty allows all
+	Fields []string `json:"fields,omitempty"`
+}
+
+// RBACConfig maps role names to their grants
+type RBACConfig struct {
+	Roles map[string][]Grant `json:"roles"`
+}
+
+// RBACPolicy is a role-based Policy
+type RBACPolicy struct {
+	roles map[string][]Grant
+}
+
+// NewRBACPolicy creates a new RBACPolicy from config
+func NewRBACPolicy(config RBACConfig) (*RBACPolicy, error) {
+	for role, grants := range config.Roles {
+		for i, grant := range grants {
+			if grant.Scope != "" && grant.Scope != ScopeAny && grant.Scope != ScopeSelf {
+				return nil, fmt.Errorf("role %q grant %d: scope must be %q or %q", role, i, ScopeAny, ScopeSelf)
+			}
+			if len(grant.Operations) == 0 {
+				return nil, fmt.Errorf("role %q grant %d: operations cannot be empty", role, i)
+			}
+		}
+	}
+	return &RBACPolicy{roles: config.Roles}, nil
+}
+
+// LoadRBACPolicy reads an RBAC policy from a JSON file
+func LoadRBACPolicy(path string) (*RBACPolicy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read RBAC policy: %w", err)
+	}
+
+	var config RBACConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("invalid RBAC policy: %w", err)
+	}
+	return NewRBACPolicy(config)
+}
+
+// Authorize allows the request when any grant of any principal role matches
+func (p *RBACPolicy) Authorize(ctx context.Context, req AuthorizationRequest) error {
+	if req.Principal == nil {
+		return fmt.Errorf("%w: no authenticated principal", ErrForbidden)
+	}
+
+	denied := ""
+	for _, role := range req.Principal.Roles {
+		for _, grant := range p.roles[role] {
+			if !grant.allows(req.Operation) || !grant.inScope(req) {
+				continue
+			}
+			if field := grant.deniedField(req.Fields); field != "" {
+				denied = field
+				continue
+			}
+			return nil
+		}
+	}
+
+	if denied != "" {
+		return fmt.Errorf("%w: field %q cannot be changed", ErrForbidden, denied)
+	}
+	return fmt.Errorf("%w: %s not allowed", ErrForbidden, req.Operation)
+}
+
+func (g Grant) allows(op Operation) bool {
+	for _, allowed := range g.Operations {
+		if allowed == "*" || allowed == op {
+			return true
+		}
+	}
+	return false
+}
+
+func (g Grant) inScope(req AuthorizationRequest) bool {
+	if g.Scope != ScopeSelf {
+		return true
+	}
+	if req.Target == nil {
+		// Operation-level checks pass when the principal can reach its own user
+		return targetsUser(req.Operation)
+	}
+	return req.Target.ID == req.Principal.ID
+}
+
+// targetsUser reports whether op acts on a single existing user
+func targetsUser(op Operation) bool {
+	return op == OperationRead || op == OperationUpdate || op == OperationDelete
+}
+
+// deniedField returns the first written field outside the grant, if any
+func (g Grant) deniedField(fields []string) string {
+	if len(g.Fields) == 0 {
+		return ""
+	}
+	for _, field := range fields {
+		allowed := false
+		for _, f := range g.Fields {
+			if f == "*" || strings.EqualFold(f, field) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return field
+		}
+	}
+	return ""
+}
+
+// createFields lists the fields set by a create operation
+func createFields(data CreateUserData) []string {
+	fields := []string{FieldName, FieldEmail}
+	if data.Phone != "" {
+		fields = append(fields, FieldPhone)
+	}
+	if data.Address != "" {
+		fields = append(fields, FieldAddress)
+	}
+	return fields
+}
+
+// updateFields lists the fields changed by an update operation
+func updateFields(data UpdateUserData, existing *User) []string {
+	fields := make([]string, 0, 4)
+	if data.Name != nil && *data.Name != existing.Name {
+		fields = append(fields, FieldName)
+	}
+	if data.Email != nil && *data.Email != existing.Email {
+		fields = append(fields, FieldEmail)
+	}
+	if data.Phone != nil && *data.Phone != existing.Phone {
+		fields = append(fields, FieldPhone)
+	}
+	if data.Address != nil && *data.Address != existing.Address {
+		fields = append(fields, FieldAddress)
+	}
+	return fields
+}
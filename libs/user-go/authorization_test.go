@@ -0,0 +1,169 @@
+package gouser
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testRBACConfig lets admins do anything and users read and update themselves,
+// except for their email
+var testRBACConfig = RBACConfig{
+	Roles: map[string][]Grant{
+		"admin": {{Operations: []Operation{"*"}}},
+		"user": {{
+			Operations: []Operation{OperationRead, OperationUpdate},
+			Scope:      ScopeSelf,
+			Fields:     []string{FieldName, FieldPhone, FieldAddress},
+		}},
+	},
+}
+
+func TestRBACPolicy_Authorize(t *testing.T) {
+	policy, err := NewRBACPolicy(testRBACConfig)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	admin := &Principal{ID: "1", Roles: []string{"admin"}}
+	user := &Principal{ID: "2", Roles: []string{"user"}}
+	self := &User{ID: "2"}
+	other := &User{ID: "3"}
+
+	tests := []struct {
+		name    string
+		req     AuthorizationRequest
+		allowed bool
+	}{
+		{"admin deletes anyone", AuthorizationRequest{Principal: admin, Operation: OperationDelete, Target: other}, true},
+		{"admin changes email", AuthorizationRequest{Principal: admin, Operation: OperationUpdate, Target: other, Fields: []string{FieldEmail}}, true},
+		{"user reads self", AuthorizationRequest{Principal: user, Operation: OperationRead, Target: self}, true},
+		{"user reads other", AuthorizationRequest{Principal: user, Operation: OperationRead, Target: other}, false},
+		{"user lists", AuthorizationRequest{Principal: user, Operation: OperationList}, false},
+		{"user may read some user", AuthorizationRequest{Principal: user, Operation: OperationRead}, true},
+		{"user may delete some user", AuthorizationRequest{Principal: user, Operation: OperationDelete}, false},
+		{"user updates own name", AuthorizationRequest{Principal: user, Operation: OperationUpdate, Target: self, Fields: []string{FieldName}}, true},
+		{"user updates own email", AuthorizationRequest{Principal: user, Operation: OperationUpdate, Target: self, Fields: []string{FieldEmail}}, false},
+		{"user deletes self", AuthorizationRequest{Principal: user, Operation: OperationDelete, Target: self}, false},
+		{"unknown role", AuthorizationRequest{Principal: &Principal{ID: "9", Roles: []string{"guest"}}, Operation: OperationRead, Target: other}, false},
+		{"anonymous", AuthorizationRequest{Operation: OperationRead, Target: other}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.Authorize(context.Background(), tt.req)
+			if tt.allowed && err != nil {
+				t.Errorf("Expected allowed, got %v", err)
+			}
+			if !tt.allowed && !errors.Is(err, ErrForbidden) {
+				t.Errorf("Expected ErrForbidden, got %v", err)
+			}
+		})
+	}
+}
+
+func TestNewRBACPolicy_Validation(t *testing.T) {
+	_, err := NewRBACPolicy(RBACConfig{Roles: map[string][]Grant{"user": {{Operations: []Operation{OperationRead}, Scope: "team"}}}})
+	if err == nil {
+		t.Error("Expected error for unknown scope")
+	}
+
+	_, err = NewRBACPolicy(RBACConfig{Roles: map[string][]Grant{"user": {{}}}})
+	if err == nil {
+		t.Error("Expected error for empty operations")
+	}
+}
+
+func TestLoadRBACPolicy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rbac.json")
+	content := `{"roles":{"admin":[{"operations":["*"]}],"user":[{"operations":["read"],"scope":"self"}]}}`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	policy, err := LoadRBACPolicy(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	err = policy.Authorize(context.Background(), AuthorizationRequest{
+		Principal: &Principal{ID: "1", Roles: []string{"user"}},
+		Operation: OperationRead,
+		Target:    &User{ID: "1"},
+	})
+	if err != nil {
+		t.Errorf("Expected allowed, got %v", err)
+	}
+
+	if _, err := LoadRBACPolicy(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("Expected error for missing file")
+	}
+}
+
+func TestUserService_WithPolicy(t *testing.T) {
+	policy, _ := NewRBACPolicy(testRBACConfig)
+	repo := NewInMemoryUserRepository()
+	service := NewUserService(repo, nil, WithPolicy(policy))
+
+	adminCtx := WithPrincipal(context.Background(), &Principal{ID: "admin", Roles: []string{"admin"}})
+	john, err := service.Create(adminCtx, CreateUserData{Name: "John Doe", Email: "john@example.com"})
+	if err != nil {
+		t.Fatalf("Expected admin to create, got %v", err)
+	}
+	jane, _ := service.Create(adminCtx, CreateUserData{Name: "Jane Doe", Email: "jane@example.com"})
+
+	johnCtx := WithPrincipal(context.Background(), &Principal{ID: john.ID, Roles: []string{"user"}})
+
+	t.Run("should allow users to read and update themselves", func(t *testing.T) {
+		if _, err := service.FindByID(johnCtx, john.ID); err != nil {
+			t.Errorf("Expected read of self, got %v", err)
+		}
+		name := "John Smith"
+		if _, err := service.Update(johnCtx, john.ID, UpdateUserData{Name: &name}); err != nil {
+			t.Errorf("Expected update of self, got %v", err)
+		}
+	})
+
+	t.Run("should forbid other operations", func(t *testing.T) {
+		email := "john.smith@example.com"
+		if _, err := service.Update(johnCtx, john.ID, UpdateUserData{Email: &email}); !errors.Is(err, ErrForbidden) {
+			t.Errorf("Expected ErrForbidden changing email, got %v", err)
+		}
+		if err := service.Delete(johnCtx, john.ID); !errors.Is(err, ErrForbidden) {
+			t.Errorf("Expected ErrForbidden deleting, got %v", err)
+		}
+		if _, err := service.FindAll(johnCtx); !errors.Is(err, ErrForbidden) {
+			t.Errorf("Expected ErrForbidden listing, got %v", err)
+		}
+		it := service.Iterate(johnCtx, UserFilter{})
+		if it.Next() || !errors.Is(it.Err(), ErrForbidden) {
+			t.Errorf("Expected ErrForbidden iterating, got %v", it.Err())
+		}
+		if _, err := service.Create(context.Background(), CreateUserData{Name: "Anon", Email: "anon@example.com"}); !errors.Is(err, ErrForbidden) {
+			t.Errorf("Expected ErrForbidden without principal, got %v", err)
+		}
+	})
+
+	t.Run("should not reveal which users exist", func(t *testing.T) {
+		for _, id := range []string{jane.ID, "missing"} {
+			if _, err := service.FindByID(johnCtx, id); err != ErrUserNotFound {
+				t.Errorf("Expected ErrUserNotFound reading %s, got %v", id, err)
+			}
+			name := "Renamed"
+			if _, err := service.Update(johnCtx, id, UpdateUserData{Name: &name}); err != ErrUserNotFound {
+				t.Errorf("Expected ErrUserNotFound updating %s, got %v", id, err)
+			}
+			if err := service.Delete(johnCtx, id); !errors.Is(err, ErrForbidden) {
+				t.Errorf("Expected ErrForbidden deleting %s, got %v", id, err)
+			}
+		}
+		if user, err := service.FindByEmail(johnCtx, jane.Email); user != nil || err != nil {
+			t.Errorf("Expected no user by email, got %+v (%v)", user, err)
+		}
+		if _, err := service.FindByEmail(context.Background(), jane.Email); !errors.Is(err, ErrForbidden) {
+			t.Errorf("Expected ErrForbidden without principal, got %v", err)
+		}
+	})
+}
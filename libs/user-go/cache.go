@@ -0,0 +1,359 @@
+package gouser
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Defaults of CacheOptions
+const (
+	DefaultCacheSize        = 1000
+	DefaultCacheTTL         = time.Minute
+	DefaultCacheNegativeTTL = 5 * time.Second
+)
+
+// Cache lookup results reported to a CacheObserver
+const (
+	CacheHit         = "hit"
+	CacheNegativeHit = "negative_hit"
+	CacheMiss        = "miss"
+)
+
+// CacheObserver receives the result of every cached lookup
+type CacheObserver interface {
+	ObserveCacheLookup(result string)
+}
+
+// CacheOptions configures a CachingRepository
+type CacheOptions struct {
+	// Size is the maximum number of cached IDs, found or not (0 uses DefaultCacheSize)
+	Size int
+	// TTL bounds how long a found user is served from the cache (0 uses DefaultCacheTTL)
+	TTL time.Duration
+	// NegativeTTL bounds how long a missing user is remembered (0 uses
+	// DefaultCacheNegativeTTL, negative disables negative caching)
+	NegativeTTL time.Duration
+	// Clock tells the expiry time (nil uses SystemClock)
+	Clock Clock
+	// Observer receives the lookup results (optional)
+	Observer CacheObserver
+}
+
+// CacheStats holds the counters of a CachingRepository
+type CacheStats struct {
+	Hits         uint64 `json:"hits"`
+	NegativeHits uint64 `json:"negativeHits"`
+	Misses       uint64 `json:"misses"`
+	Evictions    uint64 `json:"evictions"`
+	Size         int    `json:"size"`
+}
+
+// cacheEntry is a cached FindByID result; user is nil for a missing user
+type cacheEntry struct {
+	id      string
+	user    *User
+	expires time.Time
+}
+
+// cacheCall is a FindByID in flight that concurrent misses wait for
+type cacheCall struct {
+	done chan struct{}
+	user *User
+	err  error
+}
+
+// CachingRepository decorates a UserRepository with a read-through LRU
+// cache of FindByID, including misses. Writes made through it, and the
+// UserEvents it receives, invalidate the affected IDs. Concurrent misses of
+// an ID share a single repository call, including its error when the first
+// caller's context ends. Users are copied in and out of the cache, so
+// callers can modify them freely.
+type CachingRepository struct {
+	repository UserRepository
+	options    CacheOptions
+
+	entries map[string]*list.Element
+	lru     *list.List
+	calls   map[string]*cacheCall
+	// generation is bumped by every invalidation, so a lookup that raced
+	// with a write does not cache what it read before the write
+	generation uint64
+	mutex      sync.Mutex
+
+	hits, negativeHits, misses, evictions atomic.Uint64
+}
+
+// NewCachingRepository creates a new CachingRepository
+func NewCachingRepository(repository UserRepository, options CacheOptions) *CachingRepository {
+	if options.Size <= 0 {
+		options.Size = DefaultCacheSize
+	}
+	if options.TTL <= 0 {
+		options.TTL = DefaultCacheTTL
+	}
+	if options.NegativeTTL == 0 {
+		options.NegativeTTL = DefaultCacheNegativeTTL
+	}
+	if options.Clock == nil {
+		options.Clock = SystemClock
+	}
+	return &CachingRepository{
+		repository: repository,
+		options:    options,
+		entries:    make(map[string]*list.Element),
+		lru:        list.New(),
+		calls:      make(map[string]*cacheCall),
+	}
+}
+
+// FindByID returns the cached user, loading it from the decorated
+// repository on a miss
+func (r *CachingRepository) FindByID(ctx context.Context, id string) (*User, error) {
+	r.mutex.Lock()
+	if entry, ok := r.lookup(id); ok {
+		r.mutex.Unlock()
+		if entry.user == nil {
+			r.observe(CacheNegativeHit, &r.negativeHits)
+			return nil, nil
+		}
+		r.observe(CacheHit, &r.hits)
+		return copyUser(entry.user), nil
+	}
+	r.observe(CacheMiss, &r.misses)
+
+	if call, ok := r.calls[id]; ok {
+		r.mutex.Unlock()
+		select {
+		case <-call.done:
+			return copyUser(call.user), call.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	call := &cacheCall{done: make(chan struct{})}
+	r.calls[id] = call
+	generation := r.generation
+	r.mutex.Unlock()
+
+	call.user, call.err = r.repository.FindByID(ctx, id)
+
+	r.mutex.Lock()
+	delete(r.calls, id)
+	if call.err == nil && generation == r.generation {
+		r.store(id, call.user)
+	}
+	r.mutex.Unlock()
+	close(call.done)
+
+	return copyUser(call.user), call.err
+}
+
+// lookup returns the live entry of id, dropping it when expired. The
+// caller must hold the lock.
+func (r *CachingRepository) lookup(id string) (*cacheEntry, bool) {
+	element, ok := r.entries[id]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*cacheEntry)
+	if !r.options.Clock.Now().Before(entry.expires) {
+		r.remove(element)
+		return nil, false
+	}
+	r.lru.MoveToFront(element)
+	return entry, true
+}
+
+// store caches a copy of user, or a miss when user is nil. The caller must
+// hold the lock.
+func (r *CachingRepository) store(id string, user *User) {
+	ttl := r.options.TTL
+	if user == nil {
+		if r.options.NegativeTTL < 0 {
+			return
+		}
+		ttl = r.options.NegativeTTL
+	}
+	entry := &cacheEntry{id: id, user: copyUser(user), expires: r.options.Clock.Now().Add(ttl)}
+	if element, ok := r.entries[id]; ok {
+		element.Value = entry
+		r.lru.MoveToFront(element)
+		return
+	}
+	r.entries[id] = r.lru.PushFront(entry)
+	for r.lru.Len() > r.options.Size {
+		r.remove(r.lru.Back())
+		r.evictions.Add(1)
+	}
+}
+
+// remove drops an entry. The caller must hold the lock.
+func (r *CachingRepository) remove(element *list.Element) {
+	r.lru.Remove(element)
+	delete(r.entries, element.Value.(*cacheEntry).id)
+}
+
+func (r *CachingRepository) observe(result string, counter *atomic.Uint64) {
+	counter.Add(1)
+	if r.options.Observer != nil {
+		r.options.Observer.ObserveCacheLookup(result)
+	}
+}
+
+// Invalidate drops the cached entries of ids
+func (r *CachingRepository) Invalidate(ids ...string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.generation++
+	for _, id := range ids {
+		if element, ok := r.entries[id]; ok {
+			r.remove(element)
+		}
+	}
+}
+
+// Purge drops every cached entry
+func (r *CachingRepository) Purge() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.generation++
+	r.entries = make(map[string]*list.Element)
+	r.lru.Init()
+}
+
+// Stats returns the cache counters
+func (r *CachingRepository) Stats() CacheStats {
+	r.mutex.Lock()
+	size := r.lru.Len()
+	r.mutex.Unlock()
+	return CacheStats{
+		Hits:         r.hits.Load(),
+		NegativeHits: r.negativeHits.Load(),
+		Misses:       r.misses.Load(),
+		Evictions:    r.evictions.Load(),
+		Size:         size,
+	}
+}
+
+// Create creates a user and caches it, replacing a cached miss of its ID
+func (r *CachingRepository) Create(ctx context.Context, data CreateUserData) (*User, error) {
+	user, err := r.repository.Create(ctx, data)
+	if err != nil || user == nil {
+		return user, err
+	}
+	r.mutex.Lock()
+	r.generation++
+	r.store(user.ID, user)
+	r.mutex.Unlock()
+	return user, nil
+}
+
+// FindAll finds all users in the decorated repository. Listings are not cached.
+func (r *CachingRepository) FindAll(ctx context.Context) ([]*User, error) {
+	return r.repository.FindAll(ctx)
+}
+
+// Update updates a user and invalidates its cached entry
+func (r *CachingRepository) Update(ctx context.Context, id string, data UpdateUserData) (*User, error) {
+	defer r.Invalidate(id)
+	return r.repository.Update(ctx, id, data)
+}
+
+// Delete deletes a user and invalidates its cached entry
+func (r *CachingRepository) Delete(ctx context.Context, id string) error {
+	defer r.Invalidate(id)
+	return r.repository.Delete(ctx, id)
+}
+
+// Count counts users through the decorated repository
+func (r *CachingRepository) Count(ctx context.Context) (int, error) {
+	return CountUsers(ctx, r.repository)
+}
+
+// FindByEmail finds a user by email through the decorated repository;
+// lookups by email are not cached
+func (r *CachingRepository) FindByEmail(ctx context.Context, email string) (*User, error) {
+	return FindUserByEmail(ctx, r.repository, email)
+}
+
+// Ping probes the decorated repository
+func (r *CachingRepository) Ping(ctx context.Context) error {
+	return PingRepository(ctx, r.repository)
+}
+
+// Iterate streams users from the decorated repository
+func (r *CachingRepository) Iterate(ctx context.Context, filter UserFilter) UserIterator {
+	if iterable, ok := r.repository.(UserIteratorRepository); ok {
+		return iterable.Iterate(ctx, filter)
+	}
+	users, err := r.repository.FindAll(ctx)
+	return newSliceUserIterator(users, filter, err)
+}
+
+// RunInTx runs fn in a transaction of the decorated repository. Reads made
+// through repos bypass the cache, which could otherwise hold uncommitted
+// users, and the IDs written are invalidated once the transaction ends.
+func (r *CachingRepository) RunInTx(ctx context.Context, fn TxFunc) error {
+	var written []string
+	defer func() { r.Invalidate(written...) }()
+	return RunInTx(ctx, r.repository, func(ctx context.Context, repos Repositories) error {
+		repos.Users = &cacheTxRepository{UserRepository: repos.Users, written: &written}
+		return fn(ctx, repos)
+	})
+}
+
+// cacheTxRepository records the IDs written in a transaction
+type cacheTxRepository struct {
+	UserRepository
+	written *[]string
+}
+
+func (r *cacheTxRepository) FindByEmail(ctx context.Context, email string) (*User, error) {
+	return FindUserByEmail(ctx, r.UserRepository, email)
+}
+
+func (r *cacheTxRepository) Create(ctx context.Context, data CreateUserData) (*User, error) {
+	user, err := r.UserRepository.Create(ctx, data)
+	if user != nil {
+		*r.written = append(*r.written, user.ID)
+	}
+	return user, err
+}
+
+func (r *cacheTxRepository) Update(ctx context.Context, id string, data UpdateUserData) (*User, error) {
+	*r.written = append(*r.written, id)
+	return r.UserRepository.Update(ctx, id, data)
+}
+
+func (r *cacheTxRepository) Delete(ctx context.Context, id string) error {
+	*r.written = append(*r.written, id)
+	return r.UserRepository.Delete(ctx, id)
+}
+
+// OnUserCreated invalidates the user, so the cache follows writes made
+// through other repositories or processes
+func (r *CachingRepository) OnUserCreated(user *User) {
+	r.Invalidate(user.ID)
+}
+
+// OnUserUpdated invalidates the user
+func (r *CachingRepository) OnUserUpdated(user *User) {
+	r.Invalidate(user.ID)
+}
+
+// OnUserDeleted invalidates the user
+func (r *CachingRepository) OnUserDeleted(userID string) {
+	r.Invalidate(userID)
+}
+
+// copyUser returns a copy of user, or nil
+func copyUser(user *User) *User {
+	if user == nil {
+		return nil
+	}
+	userCopy := *user
+	return &userCopy
+}
@@ -0,0 +1,184 @@
+package gouser
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingRepository counts FindByID calls, optionally blocking them until release is closed
+type countingRepository struct {
+	UserRepository
+	finds   atomic.Int32
+	release chan struct{}
+}
+
+func (r *countingRepository) FindByID(ctx context.Context, id string) (*User, error) {
+	r.finds.Add(1)
+	if r.release != nil {
+		<-r.release
+	}
+	return r.UserRepository.FindByID(ctx, id)
+}
+
+// cacheResults records the lookups reported to a CacheObserver
+type cacheResults struct {
+	results []string
+	mutex   sync.Mutex
+}
+
+func (o *cacheResults) ObserveCacheLookup(result string) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	o.results = append(o.results, result)
+}
+
+func TestCachingRepository(t *testing.T) {
+	ctx := context.Background()
+	data := CreateUserData{Name: "John Doe", Email: "john@example.com"}
+
+	t.Run("should serve repeated lookups from the cache as copies", func(t *testing.T) {
+		inner := &countingRepository{UserRepository: NewInMemoryUserRepository()}
+		user, _ := inner.Create(ctx, data)
+		observer := &cacheResults{}
+		repo := NewCachingRepository(inner, CacheOptions{Observer: observer})
+
+		first, _ := repo.FindByID(ctx, user.ID)
+		first.Name = "Modified"
+		second, err := repo.FindByID(ctx, user.ID)
+		if err != nil || second.Name != "John Doe" {
+			t.Errorf("Expected an unmodified copy, got %+v and %v", second, err)
+		}
+		if finds := inner.finds.Load(); finds != 1 {
+			t.Errorf("Expected 1 repository call, got %d", finds)
+		}
+		if stats := repo.Stats(); stats.Hits != 1 || stats.Misses != 1 || stats.Size != 1 {
+			t.Errorf("Expected 1 hit and 1 miss, got %+v", stats)
+		}
+		if len(observer.results) != 2 || observer.results[0] != CacheMiss || observer.results[1] != CacheHit {
+			t.Errorf("Expected a miss then a hit, got %v", observer.results)
+		}
+	})
+
+	t.Run("should cache misses until the user is created", func(t *testing.T) {
+		inner := &countingRepository{UserRepository: NewInMemoryUserRepository()}
+		repo := NewCachingRepository(inner, CacheOptions{})
+
+		for i := 0; i < 2; i++ {
+			if user, err := repo.FindByID(ctx, "1"); user != nil || err != nil {
+				t.Fatalf("Expected no user, got %+v and %v", user, err)
+			}
+		}
+		if stats := repo.Stats(); stats.NegativeHits != 1 || inner.finds.Load() != 1 {
+			t.Errorf("Expected the miss to be cached, got %+v", stats)
+		}
+
+		created, _ := repo.Create(ctx, data)
+		if user, _ := repo.FindByID(ctx, created.ID); user == nil || user.Email != data.Email {
+			t.Errorf("Expected the created user, got %+v", user)
+		}
+	})
+
+	t.Run("should expire entries after their TTL", func(t *testing.T) {
+		now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		inner := &countingRepository{UserRepository: NewInMemoryUserRepository()}
+		user, _ := inner.Create(ctx, data)
+		repo := NewCachingRepository(inner, CacheOptions{TTL: time.Minute, Clock: ClockFunc(func() time.Time { return now })})
+
+		repo.FindByID(ctx, user.ID)
+		now = now.Add(59 * time.Second)
+		repo.FindByID(ctx, user.ID)
+		now = now.Add(time.Second)
+		repo.FindByID(ctx, user.ID)
+
+		if finds := inner.finds.Load(); finds != 2 {
+			t.Errorf("Expected 2 repository calls, got %d", finds)
+		}
+	})
+
+	t.Run("should evict the least recently used entry", func(t *testing.T) {
+		repo := NewCachingRepository(NewInMemoryUserRepository(), CacheOptions{Size: 2})
+		repo.FindByID(ctx, "a")
+		repo.FindByID(ctx, "b")
+		repo.FindByID(ctx, "a")
+		repo.FindByID(ctx, "c")
+
+		repo.FindByID(ctx, "a")
+		if stats := repo.Stats(); stats.Evictions != 1 || stats.Size != 2 || stats.NegativeHits != 2 {
+			t.Errorf("Expected b to be evicted and a to stay cached, got %+v", stats)
+		}
+	})
+
+	t.Run("should invalidate on writes and events", func(t *testing.T) {
+		repo := NewCachingRepository(NewInMemoryUserRepository(), CacheOptions{})
+		user, _ := repo.Create(ctx, data)
+
+		name := "Jane Doe"
+		repo.Update(ctx, user.ID, UpdateUserData{Name: &name})
+		if found, _ := repo.FindByID(ctx, user.ID); found.Name != name {
+			t.Errorf("Expected the updated user, got %+v", found)
+		}
+
+		repo.OnUserUpdated(user)
+		if stats := repo.Stats(); stats.Size != 0 {
+			t.Errorf("Expected the event to invalidate the user, got %+v", stats)
+		}
+
+		repo.FindByID(ctx, user.ID)
+		repo.Delete(ctx, user.ID)
+		if found, _ := repo.FindByID(ctx, user.ID); found != nil {
+			t.Errorf("Expected the deleted user to be gone, got %+v", found)
+		}
+	})
+
+	t.Run("should invalidate users written by service transactions", func(t *testing.T) {
+		repo := NewCachingRepository(NewInMemoryUserRepository(), CacheOptions{})
+		service := NewUserService(repo, nil)
+		user, _ := service.Create(ctx, data)
+		service.FindByID(ctx, user.ID)
+
+		name := "Jane Doe"
+		if _, err := service.Update(ctx, user.ID, UpdateUserData{Name: &name}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if found, _ := service.FindByID(ctx, user.ID); found.Name != name {
+			t.Errorf("Expected the updated user, got %+v", found)
+		}
+	})
+
+	t.Run("should collapse concurrent misses", func(t *testing.T) {
+		inner := &countingRepository{UserRepository: NewInMemoryUserRepository(), release: make(chan struct{})}
+		user, _ := inner.Create(ctx, data)
+		repo := NewCachingRepository(inner, CacheOptions{})
+
+		var wg sync.WaitGroup
+		results := make([]*User, 10)
+		for i := range results {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				results[i], _ = repo.FindByID(ctx, user.ID)
+			}(i)
+		}
+		// Let every goroutine reach the cache before the lookup completes
+		for repo.Stats().Misses < uint64(len(results)) {
+			time.Sleep(time.Millisecond)
+		}
+		close(inner.release)
+		wg.Wait()
+
+		if finds := inner.finds.Load(); finds != 1 {
+			t.Errorf("Expected 1 repository call, got %d", finds)
+		}
+		for _, result := range results {
+			if result == nil || result.ID != user.ID {
+				t.Fatalf("Expected every caller to get the user, got %+v", result)
+			}
+		}
+		if results[0] == results[1] {
+			t.Error("Expected every caller to get its own copy")
+		}
+	})
+}
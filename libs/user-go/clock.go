@@ -0,0 +1,40 @@
+package gouser
+
+import "time"
+
+// TimestampPrecision is the resolution of the timestamps stored by the
+// repositories. Microseconds survive a round trip through every supported
+// database, so a user reads back exactly as it was returned on write.
+const TimestampPrecision = time.Microsecond
+
+// Clock tells the current time. Repositories and services read it instead of
+// calling time.Now so tests can control it; see gousertest.FakeClock.
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc adapts a function to a Clock
+type ClockFunc func() time.Time
+
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// SystemClock is the Clock used when none is configured
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// Timestamp returns the current time of clock normalized for storage
+func Timestamp(clock Clock) time.Time {
+	return NormalizeTime(clock.Now())
+}
+
+// NormalizeTime converts t to UTC at TimestampPrecision
+func NormalizeTime(t time.Time) time.Time {
+	return t.UTC().Truncate(TimestampPrecision)
+}
@@ -0,0 +1,59 @@
+package gouser
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestNormalizeTime(t *testing.T) {
+	t.Run("should convert to UTC at microsecond precision", func(t *testing.T) {
+		local := time.Date(2024, 1, 2, 3, 4, 5, 123456789, time.FixedZone("BRT", -3*60*60))
+
+		normalized := NormalizeTime(local)
+		if normalized.Location() != time.UTC {
+			t.Errorf("Expected UTC, got %v", normalized.Location())
+		}
+		if !normalized.Equal(time.Date(2024, 1, 2, 6, 4, 5, 123456000, time.UTC)) {
+			t.Errorf("Expected truncated instant, got %v", normalized)
+		}
+	})
+}
+
+func TestRepositoryClock(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 2, 3, 4, 5, 123456789, time.FixedZone("BRT", -3*60*60))
+	clock := ClockFunc(func() time.Time { return now })
+	want := NormalizeTime(now)
+
+	repositories := map[string]UserRepository{
+		"memory": NewInMemoryUserRepository(WithClock(clock)),
+		"sqlite": newSQLiteRepository(t, WithClock(clock)),
+	}
+	for name, repo := range repositories {
+		t.Run("should stamp normalized clock time in "+name, func(t *testing.T) {
+			user, err := repo.Create(ctx, CreateUserData{Name: "John Doe", Email: "john@example.com"})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if !user.CreatedAt.Equal(want) || user.CreatedAt.Location() != time.UTC {
+				t.Errorf("Expected CreatedAt %v, got %v", want, user.CreatedAt)
+			}
+
+			found, _ := repo.FindByID(ctx, user.ID)
+			if found == nil || found.CreatedAt != user.CreatedAt || found.UpdatedAt != user.UpdatedAt {
+				t.Errorf("Expected stored timestamps to read back unchanged, got %+v and %+v", found, user)
+			}
+		})
+	}
+
+	t.Run("should expose the service clock", func(t *testing.T) {
+		if NewUserService(NewInMemoryUserRepository(), nil).Clock() != SystemClock {
+			t.Error("Expected the system clock by default")
+		}
+		service := NewUserService(NewInMemoryUserRepository(), nil, WithServiceClock(clock))
+		if got := service.Clock().Now(); !got.Equal(now) {
+			t.Errorf("Expected %v, got %v", now, got)
+		}
+	})
+}
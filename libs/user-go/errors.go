@@ -10,4 +10,11 @@ var (
 	ErrInvalidPhone      = errors.New("invalid phone format")
 	ErrEmptyName         = errors.New("name cannot be empty")
 	ErrEmptyEmail        = errors.New("email cannot be empty")
+	ErrForbidden         = errors.New("operation not allowed")
+)
+
+// Errors for the bulk import subsystem
+var (
+	ErrUnsupportedImportFormat = errors.New("unsupported import format")
+	ErrMissingEmailColumn      = errors.New("import header must map an email column")
 )
@@ -0,0 +1,383 @@
+package gouser
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// ErrInjectedFault is returned by FaultInjectingRepository for injected errors
+var ErrInjectedFault = errors.New("injected repository fault")
+
+// Latency distributions of a LatencyConfig
+const (
+	LatencyFixed       = "fixed"
+	LatencyUniform     = "uniform"
+	LatencyNormal      = "normal"
+	LatencyExponential = "exponential"
+)
+
+// LatencyDistributions lists the distributions accepted by LatencyConfig
+var LatencyDistributions = []string{LatencyFixed, LatencyUniform, LatencyNormal, LatencyExponential}
+
+// LatencyConfig describes the delay added to each call. Samples below zero
+// are clamped to zero. In JSON, durations are strings such as "150ms".
+type LatencyConfig struct {
+	// Distribution is fixed, uniform, normal or exponential. Empty adds no latency.
+	Distribution string
+	// Mean is the fixed delay, the center of the uniform range or the mean of
+	// the normal and exponential distributions
+	Mean time.Duration
+	// Spread is the half width of the uniform range or the standard deviation
+	// of the normal distribution
+	Spread time.Duration
+}
+
+// latencyJSON is the JSON form of LatencyConfig
+type latencyJSON struct {
+	Distribution string `json:"distribution,omitempty"`
+	Mean         string `json:"mean,omitempty"`
+	Spread       string `json:"spread,omitempty"`
+}
+
+func (l LatencyConfig) MarshalJSON() ([]byte, error) {
+	return json.Marshal(latencyJSON{
+		Distribution: l.Distribution,
+		Mean:         formatLatency(l.Mean),
+		Spread:       formatLatency(l.Spread),
+	})
+}
+
+func (l *LatencyConfig) UnmarshalJSON(data []byte) error {
+	var raw latencyJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	latency := LatencyConfig{Distribution: raw.Distribution}
+	for _, field := range []struct {
+		name  string
+		value string
+		dest  *time.Duration
+	}{{"mean", raw.Mean, &latency.Mean}, {"spread", raw.Spread, &latency.Spread}} {
+		if field.value == "" {
+			continue
+		}
+		d, err := time.ParseDuration(field.value)
+		if err != nil {
+			return fmt.Errorf("latency %s must be a duration, got %q", field.name, field.value)
+		}
+		*field.dest = d
+	}
+	*l = latency
+	return nil
+}
+
+// formatLatency formats d, leaving zero out of the JSON form
+func formatLatency(d time.Duration) string {
+	if d == 0 {
+		return ""
+	}
+	return d.String()
+}
+
+// Validate reports an unknown distribution or negative durations
+func (l LatencyConfig) Validate() error {
+	switch l.Distribution {
+	case "", LatencyFixed, LatencyUniform, LatencyNormal, LatencyExponential:
+	default:
+		return fmt.Errorf("unknown latency distribution %q", l.Distribution)
+	}
+	if l.Mean < 0 || l.Spread < 0 {
+		return errors.New("latency durations cannot be negative")
+	}
+	return nil
+}
+
+// sample draws a delay from the distribution
+func (l LatencyConfig) sample(r *rand.Rand) time.Duration {
+	var d float64
+	switch l.Distribution {
+	case LatencyFixed:
+		d = float64(l.Mean)
+	case LatencyUniform:
+		d = float64(l.Mean-l.Spread) + r.Float64()*float64(2*l.Spread)
+	case LatencyNormal:
+		d = float64(l.Mean) + r.NormFloat64()*float64(l.Spread)
+	case LatencyExponential:
+		d = r.ExpFloat64() * float64(l.Mean)
+	}
+	return time.Duration(math.Max(d, 0))
+}
+
+// FaultRule describes the faults injected into an operation
+type FaultRule struct {
+	Latency LatencyConfig `json:"latency"`
+	// ErrorRate is the probability, between 0 and 1, of failing with ErrInjectedFault
+	ErrorRate float64 `json:"errorRate"`
+	// TimeoutRate is the probability, between 0 and 1, of hanging until the
+	// context is done, as a stuck database would
+	TimeoutRate float64 `json:"timeoutRate"`
+}
+
+// Validate reports rates outside [0, 1] and invalid latencies
+func (f FaultRule) Validate() error {
+	if f.ErrorRate < 0 || f.ErrorRate > 1 || f.TimeoutRate < 0 || f.TimeoutRate > 1 {
+		return errors.New("rates must be between 0 and 1")
+	}
+	return f.Latency.Validate()
+}
+
+// FaultConfig configures a FaultInjectingRepository
+type FaultConfig struct {
+	// Seed makes the injected faults reproducible. Zero seeds from the clock.
+	Seed int64 `json:"seed,omitempty"`
+	// Default applies to operations without a rule of their own
+	Default FaultRule `json:"default"`
+	// Operations overrides Default per RepositoryOp operation
+	Operations map[string]FaultRule `json:"operations,omitempty"`
+}
+
+// Validate validates every rule
+func (c FaultConfig) Validate() error {
+	if err := c.Default.Validate(); err != nil {
+		return fmt.Errorf("default: %w", err)
+	}
+	for operation, rule := range c.Operations {
+		if err := rule.Validate(); err != nil {
+			return fmt.Errorf("%s: %w", operation, err)
+		}
+	}
+	return nil
+}
+
+// rule returns the rule of operation
+func (c FaultConfig) rule(operation string) FaultRule {
+	if rule, ok := c.Operations[operation]; ok {
+		return rule
+	}
+	return c.Default
+}
+
+// ScriptedFault is the outcome of one scripted call. The zero value lets
+// the call through unchanged.
+type ScriptedFault struct {
+	// Latency delays the call
+	Latency time.Duration
+	// Err fails the call after the latency
+	Err error
+	// Timeout hangs the call until the context is done
+	Timeout bool
+}
+
+// FaultInjectingRepository decorates a UserRepository with latency, errors
+// and timeouts, to exercise how callers handle slow or failing storage.
+// Scripted faults are consumed first, in order; the random rules of the
+// FaultConfig apply to the remaining calls. Nothing is injected while the
+// repository is disabled.
+type FaultInjectingRepository struct {
+	*faultInjector
+	repository UserRepository
+}
+
+// faultInjector holds the state shared by a repository and the copies bound
+// to its transactions
+type faultInjector struct {
+	config  FaultConfig
+	enabled bool
+	rand    *rand.Rand
+	scripts map[string][]ScriptedFault
+	mutex   sync.Mutex
+}
+
+// NewFaultInjectingRepository creates an enabled FaultInjectingRepository.
+// Validate config first; Configure rejects invalid configurations.
+func NewFaultInjectingRepository(repository UserRepository, config FaultConfig) *FaultInjectingRepository {
+	injector := &faultInjector{enabled: true, scripts: make(map[string][]ScriptedFault)}
+	injector.configure(config)
+	return &FaultInjectingRepository{faultInjector: injector, repository: repository}
+}
+
+// Configure replaces the fault configuration, reseeding the random source
+func (r *FaultInjectingRepository) Configure(config FaultConfig) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.configure(config)
+	return nil
+}
+
+// configure sets config. The caller must hold the lock unless the injector is new.
+func (f *faultInjector) configure(config FaultConfig) {
+	seed := config.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	f.config = config
+	f.rand = rand.New(rand.NewSource(seed))
+}
+
+// Config returns the fault configuration
+func (r *FaultInjectingRepository) Config() FaultConfig {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.config
+}
+
+// SetEnabled turns fault injection on or off
+func (r *FaultInjectingRepository) SetEnabled(enabled bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.enabled = enabled
+}
+
+// Enabled reports whether faults are injected
+func (r *FaultInjectingRepository) Enabled() bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.enabled
+}
+
+// Script queues faults for the next calls of operation, after those
+// already queued
+func (r *FaultInjectingRepository) Script(operation string, faults ...ScriptedFault) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.scripts[operation] = append(r.scripts[operation], faults...)
+}
+
+// next decides the fault of a call of operation
+func (f *faultInjector) next(operation string) ScriptedFault {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if !f.enabled {
+		return ScriptedFault{}
+	}
+	if script := f.scripts[operation]; len(script) > 0 {
+		f.scripts[operation] = script[1:]
+		return script[0]
+	}
+
+	rule := f.config.rule(operation)
+	fault := ScriptedFault{Latency: rule.Latency.sample(f.rand)}
+	if rule.TimeoutRate > 0 && f.rand.Float64() < rule.TimeoutRate {
+		fault.Timeout = true
+	} else if rule.ErrorRate > 0 && f.rand.Float64() < rule.ErrorRate {
+		fault.Err = ErrInjectedFault
+	}
+	return fault
+}
+
+// inject applies the next fault of operation, returning the error the call
+// must fail with, if any
+func (f *faultInjector) inject(ctx context.Context, operation string) error {
+	fault := f.next(operation)
+	if fault.Latency > 0 {
+		timer := time.NewTimer(fault.Latency)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if fault.Timeout {
+		// Without a deadline the call would hang forever; fail as if it had passed
+		if _, ok := ctx.Deadline(); !ok {
+			return context.DeadlineExceeded
+		}
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	return fault.Err
+}
+
+// Create creates a new user
+func (r *FaultInjectingRepository) Create(ctx context.Context, data CreateUserData) (*User, error) {
+	if err := r.inject(ctx, RepositoryOpCreate); err != nil {
+		return nil, err
+	}
+	return r.repository.Create(ctx, data)
+}
+
+// FindByID finds a user by ID
+func (r *FaultInjectingRepository) FindByID(ctx context.Context, id string) (*User, error) {
+	if err := r.inject(ctx, RepositoryOpFindByID); err != nil {
+		return nil, err
+	}
+	return r.repository.FindByID(ctx, id)
+}
+
+// FindAll finds all users
+func (r *FaultInjectingRepository) FindAll(ctx context.Context) ([]*User, error) {
+	if err := r.inject(ctx, RepositoryOpFindAll); err != nil {
+		return nil, err
+	}
+	return r.repository.FindAll(ctx)
+}
+
+// Update updates a user
+func (r *FaultInjectingRepository) Update(ctx context.Context, id string, data UpdateUserData) (*User, error) {
+	if err := r.inject(ctx, RepositoryOpUpdate); err != nil {
+		return nil, err
+	}
+	return r.repository.Update(ctx, id, data)
+}
+
+// Delete deletes a user
+func (r *FaultInjectingRepository) Delete(ctx context.Context, id string) error {
+	if err := r.inject(ctx, RepositoryOpDelete); err != nil {
+		return err
+	}
+	return r.repository.Delete(ctx, id)
+}
+
+// Count counts users through the decorated repository
+func (r *FaultInjectingRepository) Count(ctx context.Context) (int, error) {
+	if err := r.inject(ctx, RepositoryOpCount); err != nil {
+		return 0, err
+	}
+	return CountUsers(ctx, r.repository)
+}
+
+// FindByEmail finds a user by email through the decorated repository
+func (r *FaultInjectingRepository) FindByEmail(ctx context.Context, email string) (*User, error) {
+	if err := r.inject(ctx, RepositoryOpFindByEmail); err != nil {
+		return nil, err
+	}
+	return FindUserByEmail(ctx, r.repository, email)
+}
+
+// Ping probes the decorated repository. Faults are not injected into
+// probes, so readiness is not affected.
+func (r *FaultInjectingRepository) Ping(ctx context.Context) error {
+	return PingRepository(ctx, r.repository)
+}
+
+// Iterate streams users from the decorated repository. Faults of the
+// find_all operation are injected when the iteration starts.
+func (r *FaultInjectingRepository) Iterate(ctx context.Context, filter UserFilter) UserIterator {
+	if iterable, ok := r.repository.(UserIteratorRepository); ok {
+		if err := r.inject(ctx, RepositoryOpFindAll); err != nil {
+			return newSliceUserIterator(nil, filter, err)
+		}
+		return iterable.Iterate(ctx, filter)
+	}
+	users, err := r.FindAll(ctx)
+	return newSliceUserIterator(users, filter, err)
+}
+
+// RunInTx runs fn in a transaction of the decorated repository, injecting
+// faults into the operations made through repos
+func (r *FaultInjectingRepository) RunInTx(ctx context.Context, fn TxFunc) error {
+	return RunInTx(ctx, r.repository, func(ctx context.Context, repos Repositories) error {
+		repos.Users = &FaultInjectingRepository{faultInjector: r.faultInjector, repository: repos.Users}
+		return fn(ctx, repos)
+	})
+}
@@ -0,0 +1,189 @@
+package gouser
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestFaultInjectingRepository(t *testing.T) {
+	ctx := context.Background()
+	data := CreateUserData{Name: "John Doe", Email: "john@example.com"}
+
+	t.Run("should apply scripted faults in order", func(t *testing.T) {
+		boom := errors.New("boom")
+		repo := NewFaultInjectingRepository(NewInMemoryUserRepository(), FaultConfig{})
+		repo.Script(RepositoryOpCreate, ScriptedFault{Err: boom}, ScriptedFault{})
+
+		if _, err := repo.Create(ctx, data); err != boom {
+			t.Errorf("Expected boom, got %v", err)
+		}
+		for i := 0; i < 2; i++ {
+			if _, err := repo.Create(ctx, CreateUserData{Name: "John Doe", Email: "john" + string(rune('a'+i)) + "@example.com"}); err != nil {
+				t.Errorf("Expected call %d to pass, got %v", i+2, err)
+			}
+		}
+	})
+
+	t.Run("should fail operations by error rate", func(t *testing.T) {
+		repo := NewFaultInjectingRepository(NewInMemoryUserRepository(), FaultConfig{
+			Operations: map[string]FaultRule{RepositoryOpFindAll: {ErrorRate: 1}},
+		})
+
+		if _, err := repo.FindAll(ctx); !errors.Is(err, ErrInjectedFault) {
+			t.Errorf("Expected ErrInjectedFault, got %v", err)
+		}
+		if it := repo.Iterate(ctx, UserFilter{}); it.Next() || !errors.Is(it.Err(), ErrInjectedFault) {
+			t.Errorf("Expected the iteration to fail, got %v", it.Err())
+		}
+		if _, err := repo.Create(ctx, data); err != nil {
+			t.Errorf("Expected the default rule to pass, got %v", err)
+		}
+	})
+
+	t.Run("should repeat faults with the same seed", func(t *testing.T) {
+		outcomes := func() []bool {
+			repo := NewFaultInjectingRepository(NewInMemoryUserRepository(), FaultConfig{Seed: 42, Default: FaultRule{ErrorRate: 0.5}})
+			failed := make([]bool, 50)
+			for i := range failed {
+				_, err := repo.FindByID(ctx, "1")
+				failed[i] = err != nil
+			}
+			return failed
+		}
+		first, second := outcomes(), outcomes()
+		failures := 0
+		for i := range first {
+			if first[i] != second[i] {
+				t.Fatalf("Expected the same outcome for call %d", i)
+			}
+			if first[i] {
+				failures++
+			}
+		}
+		if failures == 0 || failures == len(first) {
+			t.Errorf("Expected some calls to fail, got %d of %d", failures, len(first))
+		}
+	})
+
+	t.Run("should delay calls", func(t *testing.T) {
+		repo := NewFaultInjectingRepository(NewInMemoryUserRepository(), FaultConfig{
+			Default: FaultRule{Latency: LatencyConfig{Distribution: LatencyFixed, Mean: 20 * time.Millisecond}},
+		})
+
+		start := time.Now()
+		if _, err := repo.FindAll(ctx); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+			t.Errorf("Expected at least 20ms, got %v", elapsed)
+		}
+
+		deadline, cancel := context.WithTimeout(ctx, 5*time.Millisecond)
+		defer cancel()
+		if _, err := repo.FindAll(deadline); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected the deadline to cut the latency, got %v", err)
+		}
+	})
+
+	t.Run("should hang until the deadline", func(t *testing.T) {
+		repo := NewFaultInjectingRepository(NewInMemoryUserRepository(), FaultConfig{Default: FaultRule{TimeoutRate: 1}})
+
+		deadline, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		if _, err := repo.FindByID(deadline, "1"); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected DeadlineExceeded, got %v", err)
+		}
+		if elapsed := time.Since(start); elapsed < 10*time.Millisecond {
+			t.Errorf("Expected to wait for the deadline, got %v", elapsed)
+		}
+		if _, err := repo.FindByID(ctx, "1"); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected DeadlineExceeded without a deadline, got %v", err)
+		}
+	})
+
+	t.Run("should inject nothing while disabled", func(t *testing.T) {
+		repo := NewFaultInjectingRepository(NewInMemoryUserRepository(), FaultConfig{Default: FaultRule{ErrorRate: 1}})
+		repo.SetEnabled(false)
+
+		if _, err := repo.Create(ctx, data); err != nil || repo.Enabled() {
+			t.Errorf("Expected no fault while disabled, got %v", err)
+		}
+	})
+
+	t.Run("should inject faults inside service transactions", func(t *testing.T) {
+		inner := NewInMemoryUserRepository()
+		repo := NewFaultInjectingRepository(inner, FaultConfig{})
+		repo.Script(RepositoryOpCreate, ScriptedFault{Err: ErrInjectedFault})
+
+		if _, err := NewUserService(repo, nil).Create(ctx, data); !errors.Is(err, ErrInjectedFault) {
+			t.Errorf("Expected ErrInjectedFault, got %v", err)
+		}
+		if count, _ := inner.Count(ctx); count != 0 {
+			t.Errorf("Expected no stored user, got %d", count)
+		}
+	})
+
+	t.Run("should reject invalid configurations", func(t *testing.T) {
+		repo := NewFaultInjectingRepository(NewInMemoryUserRepository(), FaultConfig{})
+		invalid := []FaultConfig{
+			{Default: FaultRule{ErrorRate: 1.5}},
+			{Operations: map[string]FaultRule{RepositoryOpCreate: {TimeoutRate: -1}}},
+			{Default: FaultRule{Latency: LatencyConfig{Distribution: "pareto"}}},
+			{Default: FaultRule{Latency: LatencyConfig{Distribution: LatencyFixed, Mean: -time.Second}}},
+		}
+		for _, config := range invalid {
+			if err := repo.Configure(config); err == nil {
+				t.Errorf("Expected %+v to be rejected", config)
+			}
+		}
+	})
+}
+
+func TestLatencyConfig(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	t.Run("should sample within the uniform range", func(t *testing.T) {
+		latency := LatencyConfig{Distribution: LatencyUniform, Mean: 100 * time.Millisecond, Spread: 50 * time.Millisecond}
+		for i := 0; i < 1000; i++ {
+			if d := latency.sample(r); d < 50*time.Millisecond || d > 150*time.Millisecond {
+				t.Fatalf("Expected a sample between 50ms and 150ms, got %v", d)
+			}
+		}
+	})
+
+	t.Run("should clamp negative samples", func(t *testing.T) {
+		latency := LatencyConfig{Distribution: LatencyNormal, Mean: time.Millisecond, Spread: time.Second}
+		for i := 0; i < 1000; i++ {
+			if d := latency.sample(r); d < 0 {
+				t.Fatalf("Expected no negative sample, got %v", d)
+			}
+		}
+	})
+
+	t.Run("should encode durations as strings in JSON", func(t *testing.T) {
+		latency := LatencyConfig{Distribution: LatencyNormal, Mean: 150 * time.Millisecond, Spread: 20 * time.Millisecond}
+		data, err := json.Marshal(latency)
+		if err != nil || string(data) != `{"distribution":"normal","mean":"150ms","spread":"20ms"}` {
+			t.Fatalf("Expected string durations, got %s and %v", data, err)
+		}
+
+		var decoded LatencyConfig
+		if err := json.Unmarshal(data, &decoded); err != nil || decoded != latency {
+			t.Errorf("Expected %+v, got %+v and %v", latency, decoded, err)
+		}
+		if err := json.Unmarshal([]byte(`{"mean":"soon"}`), &decoded); err == nil {
+			t.Error("Expected an error for a malformed duration")
+		}
+	})
+
+	t.Run("should add no latency without a distribution", func(t *testing.T) {
+		if d := (LatencyConfig{Mean: time.Second}).sample(r); d != 0 {
+			t.Errorf("Expected 0, got %v", d)
+		}
+	})
+}
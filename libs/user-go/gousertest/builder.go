@@ -0,0 +1,134 @@
+package gousertest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	gouser "github.com/mateusmacedo/scouts/libs/user-go"
+)
+
+// Epoch is the default timestamp of built users
+var Epoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// UserBuilder builds users and their creation data for tests. Every field
+// starts with a valid default, so tests only set the fields they exercise.
+type UserBuilder struct {
+	user gouser.User
+}
+
+// NewUser returns a builder for John Doe <john@example.com>
+func NewUser() *UserBuilder {
+	return &UserBuilder{user: gouser.User{
+		ID:        "1",
+		Name:      "John Doe",
+		Email:     "john@example.com",
+		CreatedAt: Epoch,
+		UpdatedAt: Epoch,
+	}}
+}
+
+// FromData returns a builder starting from data, e.g. a Faker user
+func FromData(data gouser.CreateUserData) *UserBuilder {
+	b := NewUser()
+	b.user.Name, b.user.Email, b.user.Phone, b.user.Address = data.Name, data.Email, data.Phone, data.Address
+	return b
+}
+
+func (b *UserBuilder) WithID(id string) *UserBuilder {
+	b.user.ID = id
+	return b
+}
+
+func (b *UserBuilder) WithName(name string) *UserBuilder {
+	b.user.Name = name
+	return b
+}
+
+func (b *UserBuilder) WithEmail(email string) *UserBuilder {
+	b.user.Email = email
+	return b
+}
+
+func (b *UserBuilder) WithPhone(phone string) *UserBuilder {
+	b.user.Phone = phone
+	return b
+}
+
+func (b *UserBuilder) WithAddress(address string) *UserBuilder {
+	b.user.Address = address
+	return b
+}
+
+// WithTimestamps sets CreatedAt and UpdatedAt
+func (b *UserBuilder) WithTimestamps(createdAt, updatedAt time.Time) *UserBuilder {
+	b.user.CreatedAt, b.user.UpdatedAt = createdAt, updatedAt
+	return b
+}
+
+// Build returns the user
+func (b *UserBuilder) Build() *gouser.User {
+	user := b.user
+	return &user
+}
+
+// Data returns the creation data of the user
+func (b *UserBuilder) Data() gouser.CreateUserData {
+	return gouser.CreateUserData{
+		Name:    b.user.Name,
+		Email:   b.user.Email,
+		Phone:   b.user.Phone,
+		Address: b.user.Address,
+	}
+}
+
+// UserCreator is implemented by gouser.UserRepository and *gouser.UserService
+type UserCreator interface {
+	Create(ctx context.Context, data gouser.CreateUserData) (*gouser.User, error)
+}
+
+// Create stores the user through creator, failing the test on error. The
+// ID and timestamps are assigned by creator.
+func (b *UserBuilder) Create(t testing.TB, creator UserCreator) *gouser.User {
+	t.Helper()
+	user, err := creator.Create(context.Background(), b.Data())
+	if err != nil {
+		t.Fatalf("Expected no error creating %s, got %v", b.user.Email, err)
+	}
+	return user
+}
+
+// UpdateBuilder builds partial updates for tests
+type UpdateBuilder struct {
+	data gouser.UpdateUserData
+}
+
+// NewUpdate returns a builder of an empty update
+func NewUpdate() *UpdateBuilder {
+	return &UpdateBuilder{}
+}
+
+func (b *UpdateBuilder) WithName(name string) *UpdateBuilder {
+	b.data.Name = &name
+	return b
+}
+
+func (b *UpdateBuilder) WithEmail(email string) *UpdateBuilder {
+	b.data.Email = &email
+	return b
+}
+
+func (b *UpdateBuilder) WithPhone(phone string) *UpdateBuilder {
+	b.data.Phone = &phone
+	return b
+}
+
+func (b *UpdateBuilder) WithAddress(address string) *UpdateBuilder {
+	b.data.Address = &address
+	return b
+}
+
+// Data returns the update
+func (b *UpdateBuilder) Data() gouser.UpdateUserData {
+	return b.data
+}
@@ -0,0 +1,52 @@
+// Package gousertest provides test doubles for code built on gouser
+package gousertest
+
+import (
+	"sync"
+	"time"
+)
+
+// FakeClock is a gouser.Clock that only moves when told to. It is safe for
+// concurrent use.
+type FakeClock struct {
+	now  time.Time
+	step time.Duration
+	mu   sync.Mutex
+}
+
+// NewFakeClock creates a clock stopped at now
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+// Now returns the current fake time, then advances it by the auto step
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now
+	c.now = c.now.Add(c.step)
+	return now
+}
+
+// Set moves the clock to now
+func (c *FakeClock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = now
+}
+
+// Advance moves the clock forward by d
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// AutoAdvance makes every Now call move the clock forward by step, so
+// successive timestamps differ without explicit Advance calls. A zero step
+// stops the clock again.
+func (c *FakeClock) AutoAdvance(step time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.step = step
+}
@@ -0,0 +1,60 @@
+package gousertest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	gouser "github.com/mateusmacedo/scouts/libs/user-go"
+)
+
+func TestFakeClock(t *testing.T) {
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	t.Run("should only move when told to", func(t *testing.T) {
+		clock := NewFakeClock(start)
+
+		if now := clock.Now(); !now.Equal(start) {
+			t.Errorf("Expected %v, got %v", start, now)
+		}
+		clock.Advance(time.Hour)
+		if now := clock.Now(); !now.Equal(start.Add(time.Hour)) {
+			t.Errorf("Expected %v, got %v", start.Add(time.Hour), now)
+		}
+		clock.Set(start)
+		if now := clock.Now(); !now.Equal(start) {
+			t.Errorf("Expected %v, got %v", start, now)
+		}
+	})
+
+	t.Run("should advance on every call when auto advancing", func(t *testing.T) {
+		clock := NewFakeClock(start)
+		clock.AutoAdvance(time.Second)
+
+		clock.Now()
+		if now := clock.Now(); !now.Equal(start.Add(time.Second)) {
+			t.Errorf("Expected %v, got %v", start.Add(time.Second), now)
+		}
+	})
+
+	t.Run("should drive repository timestamps", func(t *testing.T) {
+		clock := NewFakeClock(start)
+		repo := gouser.NewInMemoryUserRepository(gouser.WithClock(clock))
+		ctx := context.Background()
+
+		user, err := repo.Create(ctx, gouser.CreateUserData{Name: "John Doe", Email: "john@example.com"})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if !user.CreatedAt.Equal(start) || !user.UpdatedAt.Equal(start) {
+			t.Errorf("Expected timestamps at %v, got %+v", start, user)
+		}
+
+		clock.Advance(24 * time.Hour)
+		name := "Jane Doe"
+		updated, _ := repo.Update(ctx, user.ID, gouser.UpdateUserData{Name: &name})
+		if !updated.CreatedAt.Equal(start) || !updated.UpdatedAt.Equal(start.Add(24*time.Hour)) {
+			t.Errorf("Expected UpdatedAt one day after CreatedAt, got %+v", updated)
+		}
+	})
+}
@@ -0,0 +1,137 @@
+package gousertest
+
+import (
+	"sync"
+	"testing"
+
+	gouser "github.com/mateusmacedo/scouts/libs/user-go"
+)
+
+// Event types recorded by RecordingEvents
+const (
+	EventCreated = "created"
+	EventUpdated = "updated"
+	EventDeleted = "deleted"
+)
+
+// Event is a user event received by RecordingEvents
+type Event struct {
+	Type   string
+	UserID string
+	// User is a copy of the created or updated user; nil for deletions
+	User *gouser.User
+}
+
+// RecordingEvents is a gouser.UserEvents that records every event for
+// assertions. It is safe for concurrent use.
+type RecordingEvents struct {
+	events []Event
+	mu     sync.Mutex
+}
+
+// NewRecordingEvents creates an empty recorder
+func NewRecordingEvents() *RecordingEvents {
+	return &RecordingEvents{}
+}
+
+func (r *RecordingEvents) OnUserCreated(user *gouser.User) {
+	r.record(Event{Type: EventCreated, UserID: user.ID, User: copyUser(user)})
+}
+
+func (r *RecordingEvents) OnUserUpdated(user *gouser.User) {
+	r.record(Event{Type: EventUpdated, UserID: user.ID, User: copyUser(user)})
+}
+
+func (r *RecordingEvents) OnUserDeleted(userID string) {
+	r.record(Event{Type: EventDeleted, UserID: userID})
+}
+
+func (r *RecordingEvents) record(event Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func copyUser(user *gouser.User) *gouser.User {
+	userCopy := *user
+	return &userCopy
+}
+
+// Events returns the recorded events in order, optionally only those of
+// the given types
+func (r *RecordingEvents) Events(types ...string) []Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	events := []Event{}
+	for _, event := range r.events {
+		if len(types) == 0 || containsType(types, event.Type) {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+func containsType(types []string, eventType string) bool {
+	for _, t := range types {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Reset drops the recorded events
+func (r *RecordingEvents) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = nil
+}
+
+// AssertCreated fails the test unless a user with email was created
+func (r *RecordingEvents) AssertCreated(t testing.TB, email string) {
+	t.Helper()
+	for _, event := range r.Events(EventCreated) {
+		if event.User.Email == email {
+			return
+		}
+	}
+	t.Errorf("Expected a created event for %s, got %v", email, r.Events())
+}
+
+// AssertUpdated fails the test unless the user with id was updated
+func (r *RecordingEvents) AssertUpdated(t testing.TB, id string) {
+	t.Helper()
+	r.assertEvent(t, EventUpdated, id)
+}
+
+// AssertDeleted fails the test unless the user with id was deleted
+func (r *RecordingEvents) AssertDeleted(t testing.TB, id string) {
+	t.Helper()
+	r.assertEvent(t, EventDeleted, id)
+}
+
+func (r *RecordingEvents) assertEvent(t testing.TB, eventType, id string) {
+	t.Helper()
+	for _, event := range r.Events(eventType) {
+		if event.UserID == id {
+			return
+		}
+	}
+	t.Errorf("Expected a %s event for user %s, got %v", eventType, id, r.Events())
+}
+
+// AssertCount fails the test unless count events of eventType were recorded
+func (r *RecordingEvents) AssertCount(t testing.TB, eventType string, count int) {
+	t.Helper()
+	if got := len(r.Events(eventType)); got != count {
+		t.Errorf("Expected %d %s events, got %d", count, eventType, got)
+	}
+}
+
+// AssertNoEvents fails the test if any event was recorded
+func (r *RecordingEvents) AssertNoEvents(t testing.TB) {
+	t.Helper()
+	if events := r.Events(); len(events) > 0 {
+		t.Errorf("Expected no events, got %v", events)
+	}
+}
@@ -0,0 +1,143 @@
+package gousertest
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+
+	gouser "github.com/mateusmacedo/scouts/libs/user-go"
+)
+
+// Locale selects the names, phones and addresses generated by a Faker
+type Locale string
+
+// Supported locales
+const (
+	LocalePtBR Locale = "pt-BR"
+	LocaleEnUS Locale = "en-US"
+)
+
+// localeData holds the values a Faker picks from for one locale
+type localeData struct {
+	firstNames []string
+	lastNames  []string
+	streets    []string
+	cities     []string
+	// phone returns an E.164 number accepted by gouser.PhoneRegex
+	phone func(r *rand.Rand) string
+	// address formats a street, number and city
+	address func(street string, number int, city string) string
+}
+
+var knownLocales = map[Locale]localeData{
+	LocalePtBR: {
+		firstNames: []string{
+			"Ana", "Beatriz", "Bruno", "Camila", "Carlos", "Daniel", "Eduarda", "Felipe",
+			"Fernanda", "Gabriel", "Helena", "Isabela", "João", "Julia", "Larissa", "Lucas",
+			"Marcos", "Mariana", "Mateus", "Natália", "Paulo", "Rafael", "Sofia", "Thiago",
+		},
+		lastNames: []string{
+			"Almeida", "Barbosa", "Cardoso", "Costa", "Dias", "Ferreira", "Gomes", "Lima",
+			"Macedo", "Martins", "Oliveira", "Pereira", "Ribeiro", "Rocha", "Santos", "Silva",
+			"Souza", "Teixeira", "Araújo", "Conceição",
+		},
+		streets: []string{
+			"Rua das Flores", "Avenida Paulista", "Rua Augusta", "Avenida Brasil",
+			"Rua XV de Novembro", "Rua da Consolação", "Avenida Atlântica",
+		},
+		cities: []string{
+			"São Paulo - SP", "Rio de Janeiro - RJ", "Belo Horizonte - MG", "Curitiba - PR",
+			"Porto Alegre - RS", "Recife - PE", "Salvador - BA", "Florianópolis - SC",
+		},
+		phone: func(r *rand.Rand) string {
+			// Mobile numbers: area code, a leading 9 and eight digits
+			return fmt.Sprintf("+55%d9%08d", 11+r.Intn(89), r.Intn(100000000))
+		},
+		address: func(street string, number int, city string) string {
+			return fmt.Sprintf("%s, %d - %s", street, number, city)
+		},
+	},
+	LocaleEnUS: {
+		firstNames: []string{
+			"Alice", "James", "Emma", "Oliver", "Noah", "Olivia", "Liam", "Grace",
+			"Ethan", "Ava", "Mason", "Chloe",
+		},
+		lastNames: []string{
+			"Brown", "Clarke", "Evans", "Johnson", "Smith", "Walker", "Miller", "Davis",
+		},
+		streets: []string{"Main Street", "Oak Avenue", "Maple Road", "Elm Street", "Park Avenue"},
+		cities:  []string{"Springfield, IL", "Portland, OR", "Austin, TX", "Denver, CO", "Boston, MA"},
+		phone: func(r *rand.Rand) string {
+			return fmt.Sprintf("+1%d%07d", 201+r.Intn(789), r.Intn(10000000))
+		},
+		address: func(street string, number int, city string) string {
+			return fmt.Sprintf("%d %s, %s", number, street, city)
+		},
+	},
+}
+
+// domains are reserved for documentation (RFC 2606), so generated emails never reach anyone
+var domains = []string{"example.com", "example.org", "example.net"}
+
+// Faker generates realistic users. The same seed and locales yield the same
+// users, so failures can be reproduced. It is not safe for concurrent use.
+type Faker struct {
+	rand    *rand.Rand
+	locales []Locale
+	count   int
+}
+
+// NewFaker creates a faker from seed picking each user from one of locales,
+// pt-BR when none is given. It panics on an unknown locale.
+func NewFaker(seed int64, locales ...Locale) *Faker {
+	if len(locales) == 0 {
+		locales = []Locale{LocalePtBR}
+	}
+	for _, locale := range locales {
+		if _, ok := knownLocales[locale]; !ok {
+			panic(fmt.Sprintf("gousertest: unknown locale %q", locale))
+		}
+	}
+	return &Faker{rand: rand.New(rand.NewSource(seed)), locales: locales}
+}
+
+// User returns valid creation data. Users are numbered in their email, so
+// the users of one Faker never collide.
+func (f *Faker) User() gouser.CreateUserData {
+	f.count++
+	data := knownLocales[f.locales[f.rand.Intn(len(f.locales))]]
+	first := f.pick(data.firstNames)
+	last := f.pick(data.lastNames)
+	return gouser.CreateUserData{
+		Name:    first + " " + last,
+		Email:   fmt.Sprintf("%s.%s%d@%s", slug(first), slug(last), f.count, f.pick(domains)),
+		Phone:   data.phone(f.rand),
+		Address: data.address(f.pick(data.streets), 1+f.rand.Intn(2000), f.pick(data.cities)),
+	}
+}
+
+// Users returns the next count users
+func (f *Faker) Users(count int) []gouser.CreateUserData {
+	users := make([]gouser.CreateUserData, count)
+	for i := range users {
+		users[i] = f.User()
+	}
+	return users
+}
+
+// Builder returns a UserBuilder starting from the next user
+func (f *Faker) Builder() *UserBuilder {
+	return FromData(f.User())
+}
+
+func (f *Faker) pick(values []string) string {
+	return values[f.rand.Intn(len(values))]
+}
+
+// unaccent replaces the accented letters used in the name lists
+var unaccent = strings.NewReplacer("á", "a", "ã", "a", "â", "a", "é", "e", "ê", "e", "í", "i", "ó", "o", "ô", "o", "ú", "u", "ç", "c")
+
+// slug lowercases s and strips accents for use in email addresses
+func slug(s string) string {
+	return unaccent.Replace(strings.ToLower(s))
+}
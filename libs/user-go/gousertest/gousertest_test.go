@@ -0,0 +1,185 @@
+package gousertest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	gouser "github.com/mateusmacedo/scouts/libs/user-go"
+)
+
+func TestUserBuilder(t *testing.T) {
+	t.Run("should build valid defaults", func(t *testing.T) {
+		if err := gouser.ValidateCreateUserData(NewUser().Data()); err != nil {
+			t.Errorf("Expected valid data, got %v", err)
+		}
+	})
+
+	t.Run("should set fields fluently", func(t *testing.T) {
+		user := NewUser().WithID("42").WithName("Jane Doe").WithEmail("jane@example.com").WithPhone("+5511999999999").Build()
+		if user.ID != "42" || user.Name != "Jane Doe" || user.Email != "jane@example.com" || user.Phone != "+5511999999999" {
+			t.Errorf("Expected the set fields, got %+v", user)
+		}
+	})
+
+	t.Run("should create users through a repository", func(t *testing.T) {
+		repo := gouser.NewInMemoryUserRepository()
+		user := NewUser().WithEmail("jane@example.com").Create(t, repo)
+
+		if found, _ := repo.FindByID(context.Background(), user.ID); found == nil || found.Email != "jane@example.com" {
+			t.Errorf("Expected the created user, got %+v", found)
+		}
+	})
+
+	t.Run("should build partial updates", func(t *testing.T) {
+		data := NewUpdate().WithName("Jane Doe").Data()
+		if data.Name == nil || *data.Name != "Jane Doe" || data.Email != nil {
+			t.Errorf("Expected only the name, got %+v", data)
+		}
+	})
+}
+
+func TestFaker(t *testing.T) {
+	t.Run("should generate valid unique users", func(t *testing.T) {
+		emails := map[string]bool{}
+		for _, user := range NewFaker(1, LocalePtBR, LocaleEnUS).Users(200) {
+			if err := gouser.ValidateCreateUserData(user); err != nil {
+				t.Errorf("Expected valid data for %+v, got %v", user, err)
+			}
+			if emails[user.Email] {
+				t.Errorf("Expected unique emails, got %s twice", user.Email)
+			}
+			emails[user.Email] = true
+		}
+	})
+
+	t.Run("should be reproducible", func(t *testing.T) {
+		first, second := NewFaker(7).Users(5), NewFaker(7).Users(5)
+		for i := range first {
+			if first[i] != second[i] {
+				t.Errorf("Expected the same users, got %+v and %+v", first[i], second[i])
+			}
+		}
+		if NewFaker(7).User() == NewFaker(8).User() {
+			t.Error("Expected different seeds to differ")
+		}
+	})
+
+	t.Run("should use the locale formats", func(t *testing.T) {
+		if user := NewFaker(1, LocalePtBR).User(); user.Phone[:3] != "+55" {
+			t.Errorf("Expected a Brazilian phone, got %s", user.Phone)
+		}
+		if user := NewFaker(1, LocaleEnUS).User(); user.Phone[:2] != "+1" {
+			t.Errorf("Expected a US phone, got %s", user.Phone)
+		}
+	})
+
+	t.Run("should strip accents from emails", func(t *testing.T) {
+		if got := slug("João"); got != "joao" {
+			t.Errorf("Expected joao, got %s", got)
+		}
+		if got := slug("Conceição"); got != "conceicao" {
+			t.Errorf("Expected conceicao, got %s", got)
+		}
+	})
+}
+
+func TestRecordingEvents(t *testing.T) {
+	events := NewRecordingEvents()
+	service := gouser.NewUserService(gouser.NewInMemoryUserRepository(), events)
+	ctx := context.Background()
+
+	user := NewUser().Create(t, service)
+	service.Update(ctx, user.ID, NewUpdate().WithName("Jane Doe").Data())
+	service.Delete(ctx, user.ID)
+
+	t.Run("should record events in order", func(t *testing.T) {
+		recorded := events.Events()
+		if len(recorded) != 3 || recorded[0].Type != EventCreated || recorded[1].Type != EventUpdated || recorded[2].Type != EventDeleted {
+			t.Errorf("Expected created, updated and deleted, got %v", recorded)
+		}
+		events.AssertCreated(t, "john@example.com")
+		events.AssertUpdated(t, user.ID)
+		events.AssertDeleted(t, user.ID)
+		events.AssertCount(t, EventUpdated, 1)
+	})
+
+	t.Run("should reset", func(t *testing.T) {
+		events.Reset()
+		events.AssertNoEvents(t)
+	})
+}
+
+func TestFaultyRepository(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should fail the configured operations", func(t *testing.T) {
+		repo := NewFaultyRepository(gouser.NewInMemoryUserRepository()).FailOn(gouser.RepositoryOpFindAll, nil)
+
+		if _, err := repo.FindAll(ctx); !errors.Is(err, ErrInjected) {
+			t.Errorf("Expected ErrInjected, got %v", err)
+		}
+		if _, err := repo.Create(ctx, NewUser().Data()); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+	})
+
+	t.Run("should fail a number of times then heal", func(t *testing.T) {
+		boom := errors.New("boom")
+		repo := NewFaultyRepository(gouser.NewInMemoryUserRepository()).FailTimes(gouser.RepositoryOpCreate, 1, boom)
+
+		if _, err := repo.Create(ctx, NewUser().Data()); err != boom {
+			t.Errorf("Expected boom, got %v", err)
+		}
+		if _, err := repo.Create(ctx, NewUser().Data()); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+		repo.FailOn(gouser.RepositoryOpDelete, boom).Heal()
+		if err := repo.Delete(ctx, "1"); err != nil {
+			t.Errorf("Expected no error after healing, got %v", err)
+		}
+		if calls := repo.Calls(gouser.RepositoryOpCreate); calls != 2 {
+			t.Errorf("Expected 2 calls, got %d", calls)
+		}
+	})
+
+	t.Run("should surface failures through the service", func(t *testing.T) {
+		repo := NewFaultyRepository(gouser.NewInMemoryUserRepository()).FailOn(gouser.RepositoryOpCreate, nil)
+		service := gouser.NewUserService(repo, NewRecordingEvents())
+
+		if _, err := service.Create(ctx, NewUser().Data()); !errors.Is(err, ErrInjected) {
+			t.Errorf("Expected ErrInjected, got %v", err)
+		}
+	})
+}
+
+func TestHarness(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/echo", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", r.Header.Get("Content-Type"))
+		w.Header().Set("X-Tenant", r.Header.Get("X-Tenant"))
+		w.WriteHeader(http.StatusCreated)
+	})
+	harness := NewHarness(t, mux)
+	harness.Header.Set("X-Tenant", "acme")
+
+	t.Run("should send JSON bodies and default headers", func(t *testing.T) {
+		res := harness.Post("/echo", map[string]string{"name": "John"}).AssertStatus(http.StatusCreated)
+
+		if res.Header().Get("Content-Type") != "application/json" || res.Header().Get("X-Tenant") != "acme" {
+			t.Errorf("Expected JSON content type and tenant, got %v", res.Header())
+		}
+	})
+
+	t.Run("should decode JSON responses", func(t *testing.T) {
+		mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"id":"1","name":"John Doe"}`))
+		})
+		var user gouser.User
+		harness.Get("/user").AssertStatus(http.StatusOK).Decode(&user)
+		if user.Name != "John Doe" {
+			t.Errorf("Expected John Doe, got %+v", user)
+		}
+	})
+}
@@ -0,0 +1,101 @@
+package gousertest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// Harness sends requests to an HTTP handler, typically a fully routed
+// Echo instance, and records the responses
+type Harness struct {
+	t       testing.TB
+	handler http.Handler
+	// Header is sent with every request
+	Header http.Header
+}
+
+// NewHarness creates a harness serving requests with handler
+func NewHarness(t testing.TB, handler http.Handler) *Harness {
+	return &Harness{t: t, handler: handler, Header: http.Header{}}
+}
+
+// Do sends a request and returns the recorded response. A string or []byte
+// body is sent as is; any other non-nil body is encoded as JSON.
+func (h *Harness) Do(method, target string, body any) *Response {
+	h.t.Helper()
+	var reader io.Reader
+	contentType := ""
+	switch b := body.(type) {
+	case nil:
+	case string:
+		reader = bytes.NewReader([]byte(b))
+	case []byte:
+		reader = bytes.NewReader(b)
+	default:
+		encoded, err := json.Marshal(b)
+		if err != nil {
+			h.t.Fatalf("Expected a JSON body, got %v", err)
+		}
+		reader = bytes.NewReader(encoded)
+		contentType = "application/json"
+	}
+
+	req := httptest.NewRequest(method, target, reader)
+	for key, values := range h.Header {
+		req.Header[key] = values
+	}
+	if contentType != "" && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	rec := httptest.NewRecorder()
+	h.handler.ServeHTTP(rec, req)
+	return &Response{ResponseRecorder: rec, t: h.t}
+}
+
+func (h *Harness) Get(target string) *Response {
+	h.t.Helper()
+	return h.Do(http.MethodGet, target, nil)
+}
+
+func (h *Harness) Post(target string, body any) *Response {
+	h.t.Helper()
+	return h.Do(http.MethodPost, target, body)
+}
+
+func (h *Harness) Put(target string, body any) *Response {
+	h.t.Helper()
+	return h.Do(http.MethodPut, target, body)
+}
+
+func (h *Harness) Delete(target string) *Response {
+	h.t.Helper()
+	return h.Do(http.MethodDelete, target, nil)
+}
+
+// Response is a recorded response
+type Response struct {
+	*httptest.ResponseRecorder
+	t testing.TB
+}
+
+// Decode decodes the JSON body into v, failing the test on error
+func (r *Response) Decode(v any) *Response {
+	r.t.Helper()
+	if err := json.Unmarshal(r.Body.Bytes(), v); err != nil {
+		r.t.Fatalf("Expected a JSON body, got %v in %q", err, r.Body.String())
+	}
+	return r
+}
+
+// AssertStatus fails the test unless the response has status code
+func (r *Response) AssertStatus(code int) *Response {
+	r.t.Helper()
+	if r.Code != code {
+		r.t.Errorf("Expected status %d, got %d with %q", code, r.Code, r.Body.String())
+	}
+	return r
+}
@@ -0,0 +1,130 @@
+package gousertest
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	gouser "github.com/mateusmacedo/scouts/libs/user-go"
+)
+
+// ErrInjected is the default error returned by FaultyRepository
+var ErrInjected = errors.New("gousertest: injected repository failure")
+
+// fault is an error returned by the next calls of an operation
+type fault struct {
+	err   error
+	times int // remaining calls to fail; negative fails forever
+}
+
+// FaultyRepository wraps a UserRepository and fails the operations it is
+// told to, so tests can cover storage errors. Operations are named by the
+// gouser.RepositoryOp constants. Transactions are not forwarded, so every
+// call of the service goes through the faults. It is safe for concurrent use.
+// For latency, random and timeout faults use gouser.FaultInjectingRepository.
+type FaultyRepository struct {
+	repository gouser.UserRepository
+	faults     map[string]*fault
+	calls      map[string]int
+	mu         sync.Mutex
+}
+
+// NewFaultyRepository wraps repository without faults
+func NewFaultyRepository(repository gouser.UserRepository) *FaultyRepository {
+	return &FaultyRepository{
+		repository: repository,
+		faults:     make(map[string]*fault),
+		calls:      make(map[string]int),
+	}
+}
+
+// FailOn makes every call of operation return err, or ErrInjected when nil
+func (r *FaultyRepository) FailOn(operation string, err error) *FaultyRepository {
+	return r.FailTimes(operation, -1, err)
+}
+
+// FailTimes makes the next times calls of operation return err, or
+// ErrInjected when nil; later calls reach the wrapped repository
+func (r *FaultyRepository) FailTimes(operation string, times int, err error) *FaultyRepository {
+	if err == nil {
+		err = ErrInjected
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.faults[operation] = &fault{err: err, times: times}
+	return r
+}
+
+// Heal removes the faults of operations, or of every operation when none is given
+func (r *FaultyRepository) Heal(operations ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(operations) == 0 {
+		r.faults = make(map[string]*fault)
+	}
+	for _, operation := range operations {
+		delete(r.faults, operation)
+	}
+}
+
+// Calls returns how many times operation was called, failed or not
+func (r *FaultyRepository) Calls(operation string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.calls[operation]
+}
+
+// call counts a call of operation and returns its injected error, if any
+func (r *FaultyRepository) call(operation string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls[operation]++
+	f, ok := r.faults[operation]
+	if !ok || f.times == 0 {
+		return nil
+	}
+	if f.times > 0 {
+		f.times--
+	}
+	return f.err
+}
+
+// Create creates a new user
+func (r *FaultyRepository) Create(ctx context.Context, data gouser.CreateUserData) (*gouser.User, error) {
+	if err := r.call(gouser.RepositoryOpCreate); err != nil {
+		return nil, err
+	}
+	return r.repository.Create(ctx, data)
+}
+
+// FindByID finds a user by ID
+func (r *FaultyRepository) FindByID(ctx context.Context, id string) (*gouser.User, error) {
+	if err := r.call(gouser.RepositoryOpFindByID); err != nil {
+		return nil, err
+	}
+	return r.repository.FindByID(ctx, id)
+}
+
+// FindAll finds all users
+func (r *FaultyRepository) FindAll(ctx context.Context) ([]*gouser.User, error) {
+	if err := r.call(gouser.RepositoryOpFindAll); err != nil {
+		return nil, err
+	}
+	return r.repository.FindAll(ctx)
+}
+
+// Update updates a user
+func (r *FaultyRepository) Update(ctx context.Context, id string, data gouser.UpdateUserData) (*gouser.User, error) {
+	if err := r.call(gouser.RepositoryOpUpdate); err != nil {
+		return nil, err
+	}
+	return r.repository.Update(ctx, id, data)
+}
+
+// Delete deletes a user
+func (r *FaultyRepository) Delete(ctx context.Context, id string) error {
+	if err := r.call(gouser.RepositoryOpDelete); err != nil {
+		return err
+	}
+	return r.repository.Delete(ctx, id)
+}
@@ -0,0 +1,229 @@
+package gouser
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// IDGenerator creates user IDs for a repository
+type IDGenerator interface {
+	// NewID returns a new unique ID
+	NewID() string
+	// ValidID reports whether id has the format of the generated IDs, so
+	// malformed IDs can be rejected before reaching the repository
+	ValidID(id string) bool
+}
+
+// ID generation strategies accepted by NewIDGenerator
+const (
+	IDStrategySequential = "sequential"
+	IDStrategyUUIDv7     = "uuidv7"
+	IDStrategyULID       = "ulid"
+	IDStrategyKSUID      = "ksuid"
+)
+
+// IDStrategies lists the strategies accepted by NewIDGenerator
+var IDStrategies = []string{IDStrategySequential, IDStrategyUUIDv7, IDStrategyULID, IDStrategyKSUID}
+
+// NewIDGenerator returns the generator of the named strategy
+func NewIDGenerator(strategy string) (IDGenerator, error) {
+	switch strategy {
+	case IDStrategySequential:
+		return NewSequentialIDGenerator(), nil
+	case IDStrategyUUIDv7:
+		return UUIDv7Generator{}, nil
+	case IDStrategyULID:
+		return ULIDGenerator{}, nil
+	case IDStrategyKSUID:
+		return KSUIDGenerator{}, nil
+	default:
+		return nil, fmt.Errorf("unknown ID strategy %q, expected one of: %s", strategy, strings.Join(IDStrategies, ", "))
+	}
+}
+
+// MaxIDLength bounds the length of well-formed IDs
+const MaxIDLength = 64
+
+// WellFormedID reports whether id could have been generated by any strategy:
+// 1 to MaxIDLength letters, digits, dashes or underscores. Unlike ValidID it
+// accepts IDs of other strategies, such as those kept after changing the
+// strategy of a store or migrated from integer IDs.
+func WellFormedID(id string) bool {
+	if id == "" || len(id) > MaxIDLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// SequentialIDGenerator returns "1", "2", "3"... IDs are short but reveal how
+// many users exist and restart with the process, so they only suit a single
+// in-memory store.
+type SequentialIDGenerator struct {
+	last atomic.Uint64
+}
+
+// NewSequentialIDGenerator creates a generator starting at 1
+func NewSequentialIDGenerator() *SequentialIDGenerator {
+	return &SequentialIDGenerator{}
+}
+
+func (g *SequentialIDGenerator) NewID() string {
+	return strconv.FormatUint(g.last.Add(1), 10)
+}
+
+func (g *SequentialIDGenerator) ValidID(id string) bool {
+	n, err := strconv.ParseUint(id, 10, 64)
+	return err == nil && n > 0 && id[0] != '0'
+}
+
+// UUIDv7Generator returns RFC 9562 version 7 UUIDs: a millisecond timestamp
+// followed by random bits, formatted as lowercase 8-4-4-4-12 hex
+type UUIDv7Generator struct{}
+
+func (UUIDv7Generator) NewID() string {
+	var uuid [16]byte
+	randomBytes(uuid[6:])
+	putMillis(uuid[:6], time.Now())
+	uuid[6] = 0x70 | uuid[6]&0x0f // version 7
+	uuid[8] = 0x80 | uuid[8]&0x3f // RFC 9562 variant
+
+	var s [36]byte
+	hex.Encode(s[0:8], uuid[0:4])
+	s[8] = '-'
+	hex.Encode(s[9:13], uuid[4:6])
+	s[13] = '-'
+	hex.Encode(s[14:18], uuid[6:8])
+	s[18] = '-'
+	hex.Encode(s[19:23], uuid[8:10])
+	s[23] = '-'
+	hex.Encode(s[24:], uuid[10:])
+	return string(s[:])
+}
+
+func (UUIDv7Generator) ValidID(id string) bool {
+	if len(id) != 36 || id[8] != '-' || id[13] != '-' || id[18] != '-' || id[23] != '-' {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if i == 8 || i == 13 || i == 18 || i == 23 {
+			continue
+		}
+		if !strings.ContainsRune("0123456789abcdef", rune(id[i])) {
+			return false
+		}
+	}
+	return id[14] == '7' && strings.ContainsRune("89ab", rune(id[19]))
+}
+
+// crockford is the Crockford base32 alphabet used by ULIDs
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ULIDGenerator returns ULIDs: a millisecond timestamp and 80 random bits in
+// 26 Crockford base32 characters that sort by creation time
+type ULIDGenerator struct{}
+
+func (ULIDGenerator) NewID() string {
+	var data [16]byte
+	randomBytes(data[6:])
+	putMillis(data[:6], time.Now())
+
+	// 128 bits as 26 characters of 5 bits, the first holding the top 3 bits
+	hi := binary.BigEndian.Uint64(data[:8])
+	lo := binary.BigEndian.Uint64(data[8:])
+	var s [26]byte
+	for i := 25; i >= 0; i-- {
+		s[i] = crockford[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(s[:])
+}
+
+func (ULIDGenerator) ValidID(id string) bool {
+	if len(id) != 26 || id[0] > '7' {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if strings.IndexByte(crockford, id[i]) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// base62 is the KSUID alphabet, in ASCII order so encoded IDs sort like their bytes
+const base62 = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// ksuidEpoch is the KSUID timestamp origin, 2014-05-13T16:53:20Z
+const ksuidEpoch = 1400000000
+
+// maxKSUID is the encoding of the largest 20-byte KSUID
+const maxKSUID = "aWgEPTl1tmebfsQzFP4bxwgy80V"
+
+// KSUIDGenerator returns KSUIDs: a second timestamp and 128 random bits in 27
+// base62 characters that sort by creation time
+type KSUIDGenerator struct{}
+
+func (KSUIDGenerator) NewID() string {
+	var data [20]byte
+	randomBytes(data[4:])
+	binary.BigEndian.PutUint32(data[:4], uint32(time.Now().Unix()-ksuidEpoch))
+
+	// Convert base 2^32 digits to base 62 by repeated long division
+	digits := make([]uint32, 5)
+	for i := range digits {
+		digits[i] = binary.BigEndian.Uint32(data[i*4:])
+	}
+	var s [27]byte
+	for i := len(s) - 1; i >= 0; i-- {
+		var remainder uint64
+		for j := range digits {
+			value := remainder<<32 | uint64(digits[j])
+			digits[j] = uint32(value / 62)
+			remainder = value % 62
+		}
+		s[i] = base62[remainder]
+	}
+	return string(s[:])
+}
+
+func (KSUIDGenerator) ValidID(id string) bool {
+	if len(id) != 27 || id > maxKSUID {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if strings.IndexByte(base62, id[i]) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// putMillis writes the Unix milliseconds of t to b as a 48-bit big-endian integer
+func putMillis(b []byte, t time.Time) {
+	ms := uint64(t.UnixMilli())
+	for i := 5; i >= 0; i-- {
+		b[i] = byte(ms)
+		ms >>= 8
+	}
+}
+
+// randomBytes fills b from the system CSPRNG
+func randomBytes(b []byte) {
+	if _, err := rand.Read(b); err != nil {
+		panic("gouser: reading random bytes: " + err.Error())
+	}
+}
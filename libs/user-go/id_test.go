@@ -0,0 +1,114 @@
+package gouser
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIDGenerators(t *testing.T) {
+	for _, strategy := range IDStrategies {
+		t.Run("should generate unique valid "+strategy+" IDs", func(t *testing.T) {
+			ids, err := NewIDGenerator(strategy)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			seen := map[string]bool{}
+			for i := 0; i < 1000; i++ {
+				id := ids.NewID()
+				if !ids.ValidID(id) {
+					t.Fatalf("Expected %s to be valid", id)
+				}
+				if seen[id] {
+					t.Fatalf("Expected unique IDs, got %s twice", id)
+				}
+				seen[id] = true
+			}
+		})
+	}
+
+	t.Run("should reject unknown strategies", func(t *testing.T) {
+		if _, err := NewIDGenerator("uuidv4"); err == nil || !strings.Contains(err.Error(), "ulid") {
+			t.Errorf("Expected error listing the strategies, got %v", err)
+		}
+	})
+
+	t.Run("should reject malformed IDs", func(t *testing.T) {
+		cases := map[IDGenerator][]string{
+			NewSequentialIDGenerator(): {"", "0", "01", "-1", "abc", "1.5", "99999999999999999999"},
+			UUIDv7Generator{}:          {"", "1", "01a150fd-3fda-4475-8474-888eb50fbedc", "01A150FD-3FDA-7475-8474-888EB50FBEDC", "01a150fd-3fda-7475-c474-888eb50fbedc", "01a150fd3fda74758474888eb50fbedc"},
+			ULIDGenerator{}:            {"", "1", "01ARZ3NDEKTSV4RRFFQ69G5FAVX", "81ARZ3NDEKTSV4RRFFQ69G5FAV", "01ARZ3NDEKTSV4RRFFQ69G5FAU", "01arz3ndektsv4rrffq69g5fav"},
+			KSUIDGenerator{}:           {"", "1", "0ujtsYcgvSTl8PAuAdqWYSMnLO", "aWgEPTl1tmebfsQzFP4bxwgy80W", "0ujtsYcgvSTl8PAuAdqWYSMnLO-"},
+		}
+		for ids, malformed := range cases {
+			for _, id := range malformed {
+				if ids.ValidID(id) {
+					t.Errorf("Expected %T to reject %q", ids, id)
+				}
+			}
+		}
+	})
+
+	t.Run("should accept reference IDs", func(t *testing.T) {
+		valid := map[IDGenerator]string{
+			NewSequentialIDGenerator(): "42",
+			UUIDv7Generator{}:          "01a150fd-3fda-7475-8474-888eb50fbedc",
+			ULIDGenerator{}:            "01ARZ3NDEKTSV4RRFFQ69G5FAV",
+			KSUIDGenerator{}:           "0ujtsYcgvSTl8PAuAdqWYSMnLOv",
+		}
+		for ids, id := range valid {
+			if !ids.ValidID(id) {
+				t.Errorf("Expected %T to accept %q", ids, id)
+			}
+		}
+	})
+
+	t.Run("should sort time-based IDs by creation time", func(t *testing.T) {
+		for _, ids := range []IDGenerator{UUIDv7Generator{}, ULIDGenerator{}, KSUIDGenerator{}} {
+			first := ids.NewID()
+			if _, ok := ids.(KSUIDGenerator); ok {
+				time.Sleep(time.Second)
+			} else {
+				time.Sleep(2 * time.Millisecond)
+			}
+			second := ids.NewID()
+			if !sort.StringsAreSorted([]string{first, second}) {
+				t.Errorf("Expected %T IDs to sort by time, got %s before %s", ids, first, second)
+			}
+		}
+	})
+}
+
+func TestWellFormedID(t *testing.T) {
+	t.Run("should accept the IDs of every strategy", func(t *testing.T) {
+		for _, strategy := range IDStrategies {
+			ids, _ := NewIDGenerator(strategy)
+			if id := ids.NewID(); !WellFormedID(id) {
+				t.Errorf("Expected %s ID %s to be well formed", strategy, id)
+			}
+		}
+	})
+
+	t.Run("should reject malformed IDs", func(t *testing.T) {
+		for _, id := range []string{"", "a.b", "a b", "1/2", "ü", strings.Repeat("a", MaxIDLength+1)} {
+			if WellFormedID(id) {
+				t.Errorf("Expected %q to be malformed", id)
+			}
+		}
+	})
+}
+
+func TestInMemoryUserRepository_WithIDGenerator(t *testing.T) {
+	repo := NewInMemoryUserRepository(WithIDGenerator(ULIDGenerator{}))
+
+	user, err := repo.Create(context.Background(), CreateUserData{Name: "John Doe", Email: "john@example.com"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !(ULIDGenerator{}).ValidID(user.ID) {
+		t.Errorf("Expected a ULID, got %s", user.ID)
+	}
+}
@@ -0,0 +1,386 @@
+package gouser
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ImportFormat identifies the encoding of a bulk import stream
+type ImportFormat string
+
+// Supported import formats
+const (
+	ImportFormatCSV    ImportFormat = "csv"
+	ImportFormatNDJSON ImportFormat = "ndjson"
+)
+
+// ImportRowStatus describes the outcome of a single imported row
+type ImportRowStatus string
+
+// Possible row outcomes
+const (
+	ImportRowCreated   ImportRowStatus = "created"
+	ImportRowDuplicate ImportRowStatus = "duplicate"
+	ImportRowInvalid   ImportRowStatus = "invalid"
+	ImportRowFailed    ImportRowStatus = "failed"
+)
+
+// DefaultImportMaxErrors bounds the number of row errors kept in a report
+const DefaultImportMaxErrors = 1000
+
+// ImportOptions configures a bulk import run
+type ImportOptions struct {
+	Format ImportFormat
+	// DryRun validates and deduplicates rows without writing to the repository
+	DryRun bool
+	// ColumnMapping maps source columns (CSV header or NDJSON key) to
+	// CreateUserData fields. Keys are matched case-insensitively. Columns that
+	// are not mapped are matched by field name and otherwise ignored.
+	ColumnMapping map[string]string
+	// MaxErrors caps the row errors kept in the report (0 uses DefaultImportMaxErrors)
+	MaxErrors int
+	// OnProgress is called after each processed row
+	OnProgress func(progress ImportProgress)
+}
+
+// ImportProgress holds running counters of an import
+type ImportProgress struct {
+	Processed  int `json:"processed"`
+	Created    int `json:"created"`
+	Duplicates int `json:"duplicates"`
+	Invalid    int `json:"invalid"`
+	Failed     int `json:"failed"`
+}
+
+// ImportRowError describes why a row was not imported
+type ImportRowError struct {
+	Row     int             `json:"row"`
+	Email   string          `json:"email,omitempty"`
+	Status  ImportRowStatus `json:"status"`
+	Message string          `json:"message"`
+}
+
+// ImportReport summarizes a finished import
+type ImportReport struct {
+	ImportProgress
+	DryRun          bool             `json:"dryRun"`
+	Errors          []ImportRowError `json:"errors"`
+	ErrorsTruncated bool             `json:"errorsTruncated,omitempty"`
+}
+
+// ImportRowReader yields CreateUserData rows from a stream. Next returns io.EOF
+// when the stream is exhausted and an *ImportParseError for a malformed row
+// that can be skipped.
+type ImportRowReader interface {
+	Next() (CreateUserData, error)
+}
+
+// ImportParseError reports a row that could not be decoded
+type ImportParseError struct {
+	Err error
+}
+
+func (e *ImportParseError) Error() string {
+	return fmt.Sprintf("malformed row: %v", e.Err)
+}
+
+func (e *ImportParseError) Unwrap() error {
+	return e.Err
+}
+
+// NewImportRowReader creates a row reader for the given format
+func NewImportRowReader(r io.Reader, format ImportFormat, mapping map[string]string) (ImportRowReader, error) {
+	switch format {
+	case ImportFormatCSV:
+		return newCSVRowReader(r, mapping)
+	case ImportFormatNDJSON:
+		return newNDJSONRowReader(r, mapping), nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedImportFormat, format)
+	}
+}
+
+// UserImporter streams rows into a UserService
+type UserImporter struct {
+	service *UserService
+}
+
+// NewUserImporter creates a new UserImporter instance
+func NewUserImporter(service *UserService) *UserImporter {
+	return &UserImporter{
+		service: service,
+	}
+}
+
+// Import reads every row of r, validates it, skips emails already present in
+// the repository or earlier in the stream and creates the remaining users.
+// Row-level problems are collected in the report; only stream or context
+// errors abort the import.
+func (i *UserImporter) Import(ctx context.Context, r io.Reader, opts ImportOptions) (*ImportReport, error) {
+	rows, err := NewImportRowReader(r, opts.Format, opts.ColumnMapping)
+	if err != nil {
+		return nil, err
+	}
+
+	maxErrors := opts.MaxErrors
+	if maxErrors <= 0 {
+		maxErrors = DefaultImportMaxErrors
+	}
+
+	report := &ImportReport{
+		DryRun: opts.DryRun,
+		Errors: make([]ImportRowError, 0),
+	}
+	seen := make(map[string]struct{})
+
+	record := func(row int, email string, status ImportRowStatus, message string) {
+		switch status {
+		case ImportRowCreated:
+			report.Created++
+			return
+		case ImportRowDuplicate:
+			report.Duplicates++
+		case ImportRowInvalid:
+			report.Invalid++
+		case ImportRowFailed:
+			report.Failed++
+		}
+		if len(report.Errors) >= maxErrors {
+			report.ErrorsTruncated = true
+			return
+		}
+		report.Errors = append(report.Errors, ImportRowError{
+			Row:     row,
+			Email:   email,
+			Status:  status,
+			Message: message,
+		})
+	}
+
+	for row := 1; ; row++ {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+
+		data, err := rows.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		var parseErr *ImportParseError
+		switch {
+		case errors.As(err, &parseErr):
+			record(row, "", ImportRowInvalid, parseErr.Error())
+		case err != nil:
+			return report, fmt.Errorf("failed to read import row %d: %w", row, err)
+		default:
+			status, message, err := i.importRow(ctx, data, seen, opts.DryRun)
+			if err != nil {
+				return report, err
+			}
+			record(row, data.Email, status, message)
+		}
+
+		report.Processed++
+		if opts.OnProgress != nil {
+			opts.OnProgress(report.ImportProgress)
+		}
+	}
+
+	return report, nil
+}
+
+// importRow handles a single decoded row. A non-nil error aborts the import.
+func (i *UserImporter) importRow(ctx context.Context, data CreateUserData, seen map[string]struct{}, dryRun bool) (ImportRowStatus, string, error) {
+	if err := ValidateCreateUserData(data); err != nil {
+		return ImportRowInvalid, err.Error(), nil
+	}
+
+	if _, dup := seen[data.Email]; dup {
+		return ImportRowDuplicate, "email appears earlier in the import", nil
+	}
+	seen[data.Email] = struct{}{}
+
+	if dryRun {
+		if err := i.service.authorize(ctx, OperationCreate, nil, createFields(data)); err != nil {
+			return ImportRowFailed, err.Error(), nil
+		}
+		existingUser, err := i.service.findByEmail(ctx, data.Email)
+		if err != nil {
+			if ctx.Err() != nil {
+				return "", "", ctx.Err()
+			}
+			return ImportRowFailed, fmt.Sprintf("failed to validate email uniqueness: %v", err), nil
+		}
+		if existingUser != nil {
+			return ImportRowDuplicate, ErrUserAlreadyExists.Error(), nil
+		}
+		return ImportRowCreated, "", nil
+	}
+
+	if _, err := i.service.Create(ctx, data); err != nil {
+		if errors.Is(err, ErrUserAlreadyExists) {
+			return ImportRowDuplicate, err.Error(), nil
+		}
+		if ctx.Err() != nil {
+			return "", "", ctx.Err()
+		}
+		return ImportRowFailed, err.Error(), nil
+	}
+
+	return ImportRowCreated, "", nil
+}
+
+// importColumns resolves source column names to CreateUserData fields
+type importColumns map[string]string
+
+func newImportColumns(mapping map[string]string) importColumns {
+	columns := importColumns{
+		FieldName:    FieldName,
+		FieldEmail:   FieldEmail,
+		FieldPhone:   FieldPhone,
+		FieldAddress: FieldAddress,
+	}
+	for source, field := range mapping {
+		columns[normalizeColumn(source)] = strings.ToLower(strings.TrimSpace(field))
+	}
+	return columns
+}
+
+func (c importColumns) field(column string) string {
+	return c[normalizeColumn(column)]
+}
+
+func normalizeColumn(column string) string {
+	return strings.ToLower(strings.TrimSpace(column))
+}
+
+// assign sets the mapped field of data, ignoring unknown fields
+func assign(data *CreateUserData, field, value string) {
+	value = strings.TrimSpace(value)
+	switch field {
+	case FieldName:
+		data.Name = value
+	case FieldEmail:
+		data.Email = value
+	case FieldPhone:
+		data.Phone = value
+	case FieldAddress:
+		data.Address = value
+	}
+}
+
+// csvRowReader decodes CSV records using the header row for column names
+type csvRowReader struct {
+	reader *csv.Reader
+	fields []string
+}
+
+func newCSVRowReader(r io.Reader, mapping map[string]string) (*csvRowReader, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, ErrMissingEmailColumn
+		}
+		return nil, fmt.Errorf("failed to read import header: %w", err)
+	}
+
+	columns := newImportColumns(mapping)
+	fields := make([]string, len(header))
+	hasEmail := false
+	for idx, column := range header {
+		if idx == 0 {
+			column = strings.TrimPrefix(column, "\ufeff")
+		}
+		fields[idx] = columns.field(column)
+		if fields[idx] == FieldEmail {
+			hasEmail = true
+		}
+	}
+	if !hasEmail {
+		return nil, ErrMissingEmailColumn
+	}
+
+	return &csvRowReader{reader: reader, fields: fields}, nil
+}
+
+func (c *csvRowReader) Next() (CreateUserData, error) {
+	record, err := c.reader.Read()
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return CreateUserData{}, &ImportParseError{Err: err}
+		}
+		return CreateUserData{}, err
+	}
+
+	var data CreateUserData
+	for idx, value := range record {
+		if idx < len(c.fields) {
+			assign(&data, c.fields[idx], value)
+		}
+	}
+	return data, nil
+}
+
+// ndjsonMaxLineSize bounds a single NDJSON line
+const ndjsonMaxLineSize = 1 << 20
+
+// ndjsonRowReader decodes one JSON object per line
+type ndjsonRowReader struct {
+	scanner *bufio.Scanner
+	columns importColumns
+}
+
+func newNDJSONRowReader(r io.Reader, mapping map[string]string) *ndjsonRowReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), ndjsonMaxLineSize)
+	return &ndjsonRowReader{
+		scanner: scanner,
+		columns: newImportColumns(mapping),
+	}
+}
+
+func (n *ndjsonRowReader) Next() (CreateUserData, error) {
+	for n.scanner.Scan() {
+		line := strings.TrimSpace(n.scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var object map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &object); err != nil {
+			return CreateUserData{}, &ImportParseError{Err: err}
+		}
+
+		var data CreateUserData
+		for key, value := range object {
+			field := n.columns.field(key)
+			if field == "" || value == nil {
+				continue
+			}
+			str, ok := value.(string)
+			if !ok {
+				return CreateUserData{}, &ImportParseError{Err: fmt.Errorf("field %q must be a string", key)}
+			}
+			assign(&data, field, str)
+		}
+		return data, nil
+	}
+
+	if err := n.scanner.Err(); err != nil {
+		return CreateUserData{}, err
+	}
+	return CreateUserData{}, io.EOF
+}
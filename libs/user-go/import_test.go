@@ -0,0 +1,194 @@
+package gouser
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestUserImporter_ImportCSV(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should create valid rows and report invalid and duplicate ones", func(t *testing.T) {
+		repo := NewInMemoryUserRepository()
+		events := NewMockUserEvents()
+		service := NewUserService(repo, events)
+		importer := NewUserImporter(service)
+
+		_, err := service.Create(ctx, CreateUserData{Name: "Existing", Email: "existing@example.com"})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		input := strings.Join([]string{
+			"Name,Email,Phone,Address",
+			"John Doe,john@example.com,+1234567890,123 Main St",
+			"Jane Doe,invalid-email,,",
+			"Existing,existing@example.com,,",
+			"John Again,john@example.com,,",
+			"Mary,mary@example.com,,",
+		}, "\n")
+
+		report, err := importer.Import(ctx, strings.NewReader(input), ImportOptions{Format: ImportFormatCSV})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if report.Processed != 5 {
+			t.Errorf("Expected 5 processed rows, got %d", report.Processed)
+		}
+		if report.Created != 2 {
+			t.Errorf("Expected 2 created rows, got %d", report.Created)
+		}
+		if report.Duplicates != 2 {
+			t.Errorf("Expected 2 duplicate rows, got %d", report.Duplicates)
+		}
+		if report.Invalid != 1 {
+			t.Errorf("Expected 1 invalid row, got %d", report.Invalid)
+		}
+		if len(report.Errors) != 3 {
+			t.Fatalf("Expected 3 row errors, got %d", len(report.Errors))
+		}
+		if report.Errors[0].Row != 2 || report.Errors[0].Status != ImportRowInvalid {
+			t.Errorf("Expected row 2 to be invalid, got %+v", report.Errors[0])
+		}
+		if len(events.CreatedUsers) != 3 {
+			t.Errorf("Expected 3 created user events, got %d", len(events.CreatedUsers))
+		}
+
+		users, _ := repo.FindAll(ctx)
+		if len(users) != 3 {
+			t.Errorf("Expected 3 users in repository, got %d", len(users))
+		}
+	})
+
+	t.Run("should apply column mapping", func(t *testing.T) {
+		service := NewUserService(NewInMemoryUserRepository(), nil)
+		importer := NewUserImporter(service)
+
+		input := "Nome,E-mail\nJoão,joao@example.com\n"
+
+		report, err := importer.Import(ctx, strings.NewReader(input), ImportOptions{
+			Format:        ImportFormatCSV,
+			ColumnMapping: map[string]string{"nome": "name", "e-mail": "email"},
+		})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if report.Created != 1 {
+			t.Fatalf("Expected 1 created row, got %d (%+v)", report.Created, report.Errors)
+		}
+
+		user, _ := service.FindByEmail(ctx, "joao@example.com")
+		if user == nil || user.Name != "João" {
+			t.Errorf("Expected mapped user, got %+v", user)
+		}
+	})
+
+	t.Run("should reject header without email column", func(t *testing.T) {
+		importer := NewUserImporter(NewUserService(NewInMemoryUserRepository(), nil))
+
+		_, err := importer.Import(ctx, strings.NewReader("name,phone\nJohn,123\n"), ImportOptions{Format: ImportFormatCSV})
+		if !errors.Is(err, ErrMissingEmailColumn) {
+			t.Errorf("Expected ErrMissingEmailColumn, got %v", err)
+		}
+	})
+}
+
+func TestUserImporter_ImportNDJSON(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should import objects and report malformed lines", func(t *testing.T) {
+		repo := NewInMemoryUserRepository()
+		importer := NewUserImporter(NewUserService(repo, nil))
+
+		input := strings.Join([]string{
+			`{"name":"John Doe","email":"john@example.com"}`,
+			``,
+			`{"name":"Broken"`,
+			`{"name":"Numeric","email":42}`,
+			`{"name":"Jane Doe","email":"jane@example.com","phone":"+5511999999999"}`,
+		}, "\n")
+
+		report, err := importer.Import(ctx, strings.NewReader(input), ImportOptions{Format: ImportFormatNDJSON})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if report.Processed != 4 {
+			t.Errorf("Expected 4 processed rows, got %d", report.Processed)
+		}
+		if report.Created != 2 {
+			t.Errorf("Expected 2 created rows, got %d", report.Created)
+		}
+		if report.Invalid != 2 {
+			t.Errorf("Expected 2 invalid rows, got %d", report.Invalid)
+		}
+	})
+
+	t.Run("should not write in dry-run mode", func(t *testing.T) {
+		repo := NewInMemoryUserRepository()
+		importer := NewUserImporter(NewUserService(repo, nil))
+
+		input := `{"name":"John Doe","email":"john@example.com"}` + "\n" + `{"name":"John Doe","email":"john@example.com"}`
+
+		var progress []ImportProgress
+		report, err := importer.Import(ctx, strings.NewReader(input), ImportOptions{
+			Format: ImportFormatNDJSON,
+			DryRun: true,
+			OnProgress: func(p ImportProgress) {
+				progress = append(progress, p)
+			},
+		})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if !report.DryRun || report.Created != 1 || report.Duplicates != 1 {
+			t.Errorf("Unexpected dry-run report: %+v", report)
+		}
+		if len(progress) != 2 || progress[1].Processed != 2 {
+			t.Errorf("Expected 2 progress updates, got %+v", progress)
+		}
+
+		users, _ := repo.FindAll(ctx)
+		if len(users) != 0 {
+			t.Errorf("Expected no users to be written, got %d", len(users))
+		}
+	})
+
+	t.Run("should cap the error report", func(t *testing.T) {
+		importer := NewUserImporter(NewUserService(NewInMemoryUserRepository(), nil))
+
+		input := strings.Repeat(`{"name":"","email":"x@example.com"}`+"\n", 5)
+
+		report, err := importer.Import(ctx, strings.NewReader(input), ImportOptions{Format: ImportFormatNDJSON, MaxErrors: 2})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if report.Invalid != 5 || len(report.Errors) != 2 || !report.ErrorsTruncated {
+			t.Errorf("Expected truncated report with 5 invalid rows, got %+v", report)
+		}
+	})
+}
+
+func TestUserImporter_UnsupportedFormat(t *testing.T) {
+	importer := NewUserImporter(NewUserService(NewInMemoryUserRepository(), nil))
+
+	_, err := importer.Import(context.Background(), strings.NewReader(""), ImportOptions{Format: "xlsx"})
+	if !errors.Is(err, ErrUnsupportedImportFormat) {
+		t.Errorf("Expected ErrUnsupportedImportFormat, got %v", err)
+	}
+}
+
+func TestUserImporter_ContextCancellation(t *testing.T) {
+	importer := NewUserImporter(NewUserService(NewInMemoryUserRepository(), nil))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := importer.Import(ctx, strings.NewReader("email\nx@example.com\n"), ImportOptions{Format: ImportFormatCSV})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
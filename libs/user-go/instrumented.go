@@ -0,0 +1,228 @@
+package gouser
+
+import (
+	"context"
+	"time"
+)
+
+// Repository operation names reported to a RepositoryObserver
+const (
+	RepositoryOpCreate   = "create"
+	RepositoryOpFindByID = "find_by_id"
+	RepositoryOpFindAll  = "find_all"
+	RepositoryOpUpdate   = "update"
+	RepositoryOpDelete   = "delete"
+	RepositoryOpCount    = "count"
+	// RepositoryOpFindByEmail is the lookup of UserEmailFinder
+	RepositoryOpFindByEmail = "find_by_email"
+)
+
+// UserCounter is an optional extension of UserRepository for repositories
+// that can count users without loading them
+type UserCounter interface {
+	Count(ctx context.Context) (int, error)
+}
+
+// CountUsers returns the number of users, using UserCounter when available
+func CountUsers(ctx context.Context, repository UserRepository) (int, error) {
+	if counter, ok := repository.(UserCounter); ok {
+		return counter.Count(ctx)
+	}
+	users, err := repository.FindAll(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return len(users), nil
+}
+
+// UserEmailFinder is an optional extension of UserRepository for
+// repositories that can find a user by email without loading every user,
+// e.g. through the unique index of the SQL table
+type UserEmailFinder interface {
+	// FindByEmail returns the user with email, or nil when none matches
+	FindByEmail(ctx context.Context, email string) (*User, error)
+}
+
+// FindUserByEmail returns the user with email, or nil when none matches,
+// using UserEmailFinder when available
+func FindUserByEmail(ctx context.Context, repository UserRepository, email string) (*User, error) {
+	if finder, ok := repository.(UserEmailFinder); ok {
+		return finder.FindByEmail(ctx, email)
+	}
+	users, err := repository.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, user := range users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return nil, nil
+}
+
+// Pinger is an optional extension of UserRepository for repositories backed
+// by a store that can be probed, e.g. by readiness checks
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// PingRepository probes the repository store. Repositories that do not
+// implement Pinger are assumed to be available.
+func PingRepository(ctx context.Context, repository UserRepository) error {
+	if pinger, ok := repository.(Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return ctx.Err()
+}
+
+// RepositoryObserver receives the duration and outcome of repository operations
+type RepositoryObserver interface {
+	ObserveRepositoryOperation(operation string, duration time.Duration, err error)
+}
+
+// InstrumentedRepository decorates a UserRepository, reporting every
+// operation to a RepositoryObserver
+type InstrumentedRepository struct {
+	repository UserRepository
+	observer   RepositoryObserver
+}
+
+// NewInstrumentedRepository creates a new InstrumentedRepository
+func NewInstrumentedRepository(repository UserRepository, observer RepositoryObserver) *InstrumentedRepository {
+	return &InstrumentedRepository{
+		repository: repository,
+		observer:   observer,
+	}
+}
+
+// Create creates a new user
+func (r *InstrumentedRepository) Create(ctx context.Context, data CreateUserData) (*User, error) {
+	start := time.Now()
+	user, err := r.repository.Create(ctx, data)
+	r.observer.ObserveRepositoryOperation(RepositoryOpCreate, time.Since(start), err)
+	return user, err
+}
+
+// FindByID finds a user by ID
+func (r *InstrumentedRepository) FindByID(ctx context.Context, id string) (*User, error) {
+	start := time.Now()
+	user, err := r.repository.FindByID(ctx, id)
+	r.observer.ObserveRepositoryOperation(RepositoryOpFindByID, time.Since(start), err)
+	return user, err
+}
+
+// FindAll finds all users
+func (r *InstrumentedRepository) FindAll(ctx context.Context) ([]*User, error) {
+	start := time.Now()
+	users, err := r.repository.FindAll(ctx)
+	r.observer.ObserveRepositoryOperation(RepositoryOpFindAll, time.Since(start), err)
+	return users, err
+}
+
+// Update updates a user
+func (r *InstrumentedRepository) Update(ctx context.Context, id string, data UpdateUserData) (*User, error) {
+	start := time.Now()
+	user, err := r.repository.Update(ctx, id, data)
+	r.observer.ObserveRepositoryOperation(RepositoryOpUpdate, time.Since(start), err)
+	return user, err
+}
+
+// Delete deletes a user
+func (r *InstrumentedRepository) Delete(ctx context.Context, id string) error {
+	start := time.Now()
+	err := r.repository.Delete(ctx, id)
+	r.observer.ObserveRepositoryOperation(RepositoryOpDelete, time.Since(start), err)
+	return err
+}
+
+// Count counts users through the decorated repository
+func (r *InstrumentedRepository) Count(ctx context.Context) (int, error) {
+	start := time.Now()
+	count, err := CountUsers(ctx, r.repository)
+	r.observer.ObserveRepositoryOperation(RepositoryOpCount, time.Since(start), err)
+	return count, err
+}
+
+// FindByEmail finds a user by email through the decorated repository
+func (r *InstrumentedRepository) FindByEmail(ctx context.Context, email string) (*User, error) {
+	start := time.Now()
+	user, err := FindUserByEmail(ctx, r.repository, email)
+	r.observer.ObserveRepositoryOperation(RepositoryOpFindByEmail, time.Since(start), err)
+	return user, err
+}
+
+// Ping probes the decorated repository. Probes are not observed.
+func (r *InstrumentedRepository) Ping(ctx context.Context) error {
+	return PingRepository(ctx, r.repository)
+}
+
+// Iterate streams users from the decorated repository. Batches are not
+// observed individually.
+func (r *InstrumentedRepository) Iterate(ctx context.Context, filter UserFilter) UserIterator {
+	if iterable, ok := r.repository.(UserIteratorRepository); ok {
+		return iterable.Iterate(ctx, filter)
+	}
+	users, err := r.FindAll(ctx)
+	return newSliceUserIterator(users, filter, err)
+}
+
+// RunInTx runs fn in a transaction of the decorated repository, observing
+// the operations made through repos
+func (r *InstrumentedRepository) RunInTx(ctx context.Context, fn TxFunc) error {
+	return RunInTx(ctx, r.repository, func(ctx context.Context, repos Repositories) error {
+		repos.Users = NewInstrumentedRepository(repos.Users, r.observer)
+		return fn(ctx, repos)
+	})
+}
+
+// MultiUserEvents fans events out to several handlers, in order
+type MultiUserEvents []UserEvents
+
+// OnUserCreated notifies every handler
+func (m MultiUserEvents) OnUserCreated(user *User) {
+	m.OnUserCreatedContext(context.Background(), user)
+}
+
+// OnUserUpdated notifies every handler
+func (m MultiUserEvents) OnUserUpdated(user *User) {
+	m.OnUserUpdatedContext(context.Background(), user)
+}
+
+// OnUserDeleted notifies every handler
+func (m MultiUserEvents) OnUserDeleted(userID string) {
+	m.OnUserDeletedContext(context.Background(), userID)
+}
+
+// OnUserCreatedContext notifies every handler, preferring context-aware variants
+func (m MultiUserEvents) OnUserCreatedContext(ctx context.Context, user *User) {
+	for _, events := range m {
+		if contextEvents, ok := events.(ContextUserEvents); ok {
+			contextEvents.OnUserCreatedContext(ctx, user)
+			continue
+		}
+		events.OnUserCreated(user)
+	}
+}
+
+// OnUserUpdatedContext notifies every handler, preferring context-aware variants
+func (m MultiUserEvents) OnUserUpdatedContext(ctx context.Context, user *User) {
+	for _, events := range m {
+		if contextEvents, ok := events.(ContextUserEvents); ok {
+			contextEvents.OnUserUpdatedContext(ctx, user)
+			continue
+		}
+		events.OnUserUpdated(user)
+	}
+}
+
+// OnUserDeletedContext notifies every handler, preferring context-aware variants
+func (m MultiUserEvents) OnUserDeletedContext(ctx context.Context, userID string) {
+	for _, events := range m {
+		if contextEvents, ok := events.(ContextUserEvents); ok {
+			contextEvents.OnUserDeletedContext(ctx, userID)
+			continue
+		}
+		events.OnUserDeleted(userID)
+	}
+}
@@ -0,0 +1,141 @@
+package gouser
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// recordingObserver records observed repository operations
+type recordingObserver struct {
+	operations []string
+	errors     []error
+}
+
+func (o *recordingObserver) ObserveRepositoryOperation(operation string, duration time.Duration, err error) {
+	o.operations = append(o.operations, operation)
+	o.errors = append(o.errors, err)
+}
+
+func TestInstrumentedRepository(t *testing.T) {
+	ctx := context.Background()
+	observer := &recordingObserver{}
+	repo := NewInstrumentedRepository(NewInMemoryUserRepository(), observer)
+
+	user, err := repo.Create(ctx, CreateUserData{Name: "John Doe", Email: "john@example.com"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	repo.FindByID(ctx, user.ID)
+	repo.FindAll(ctx)
+	name := "John Smith"
+	repo.Update(ctx, user.ID, UpdateUserData{Name: &name})
+
+	count, err := repo.Count(ctx)
+	if err != nil || count != 1 {
+		t.Errorf("Expected count 1, got %d (%v)", count, err)
+	}
+	repo.Delete(ctx, user.ID)
+
+	expected := []string{RepositoryOpCreate, RepositoryOpFindByID, RepositoryOpFindAll, RepositoryOpUpdate, RepositoryOpCount, RepositoryOpDelete}
+	if len(observer.operations) != len(expected) {
+		t.Fatalf("Expected %d operations, got %v", len(expected), observer.operations)
+	}
+	for i, op := range expected {
+		if observer.operations[i] != op {
+			t.Errorf("Expected operation %d to be %s, got %s", i, op, observer.operations[i])
+		}
+	}
+
+	t.Run("should report errors", func(t *testing.T) {
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+
+		repo.FindAll(cancelled)
+		if last := observer.errors[len(observer.errors)-1]; last != context.Canceled {
+			t.Errorf("Expected context.Canceled, got %v", last)
+		}
+	})
+
+	t.Run("should keep streaming through the decorator", func(t *testing.T) {
+		if _, ok := interface{}(repo).(UserIteratorRepository); !ok {
+			t.Error("Expected InstrumentedRepository to implement UserIteratorRepository")
+		}
+	})
+}
+
+func TestCountUsers(t *testing.T) {
+	ctx := context.Background()
+	repo := &MockUserRepository{users: map[string]*User{"1": {ID: "1"}, "2": {ID: "2"}}}
+
+	count, err := CountUsers(ctx, repo)
+	if err != nil || count != 2 {
+		t.Errorf("Expected count 2 via FindAll, got %d (%v)", count, err)
+	}
+}
+
+func TestFindUserByEmail(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should fall back to FindAll", func(t *testing.T) {
+		repo := &MockUserRepository{users: map[string]*User{"1": {ID: "1", Email: "john@example.com"}}}
+		user, err := FindUserByEmail(ctx, repo, "john@example.com")
+		if err != nil || user == nil || user.ID != "1" {
+			t.Errorf("Expected user 1, got %+v (%v)", user, err)
+		}
+	})
+
+	t.Run("should let the service check emails without listing users", func(t *testing.T) {
+		observer := &recordingObserver{}
+		service := NewUserService(NewInstrumentedRepository(NewInMemoryUserRepository(), observer), nil)
+		service.Create(ctx, CreateUserData{Name: "John Doe", Email: "john@example.com"})
+
+		for _, op := range observer.operations {
+			if op == RepositoryOpFindAll {
+				t.Errorf("Expected no find_all, got %v", observer.operations)
+			}
+		}
+		if observer.operations[0] != RepositoryOpFindByEmail {
+			t.Errorf("Expected find_by_email first, got %v", observer.operations)
+		}
+	})
+}
+
+func TestPingRepository(t *testing.T) {
+	t.Run("should probe repositories implementing Pinger", func(t *testing.T) {
+		repo := NewTracingRepository(NewInstrumentedRepository(NewInMemoryUserRepository(), &recordingObserver{}), noopTracer{})
+		if err := PingRepository(context.Background(), repo); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+
+		cancelled, cancel := context.WithCancel(context.Background())
+		cancel()
+		if err := PingRepository(cancelled, repo); err != context.Canceled {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+	})
+
+	t.Run("should assume other repositories are available", func(t *testing.T) {
+		if err := PingRepository(context.Background(), &MockUserRepository{}); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+	})
+}
+
+func TestMultiUserEvents(t *testing.T) {
+	plain := &MockUserEvents{}
+	contextual := &contextRecordingEvents{}
+	events := MultiUserEvents{plain, contextual}
+
+	ctx := WithPrincipal(context.Background(), &Principal{ID: "admin"})
+	events.OnUserCreatedContext(ctx, &User{ID: "1"})
+	events.OnUserUpdated(&User{ID: "1"})
+	events.OnUserDeletedContext(ctx, "1")
+
+	if len(plain.CreatedUsers) != 1 || len(plain.UpdatedUsers) != 1 || len(plain.DeletedIDs) != 1 {
+		t.Errorf("Expected plain handler to receive every event, got %+v", plain)
+	}
+	if len(contextual.Actors) != 3 || contextual.Actors[0] != "admin" || contextual.Actors[1] != "" {
+		t.Errorf("Expected context handler to receive actors, got %v", contextual.Actors)
+	}
+}
@@ -0,0 +1,156 @@
+package gouser
+
+import (
+	"context"
+	"strings"
+)
+
+// DefaultIteratorBatchSize is the number of users fetched per iterator batch
+const DefaultIteratorBatchSize = 100
+
+// UserFilter restricts the users returned by listings and iterators.
+// Empty fields match every user; text fields match case-insensitive substrings.
+type UserFilter struct {
+	Name  string
+	Email string
+}
+
+// Matches reports whether the user satisfies the filter
+func (f UserFilter) Matches(user *User) bool {
+	if f.Name != "" && !containsFold(user.Name, f.Name) {
+		return false
+	}
+	if f.Email != "" && !containsFold(user.Email, f.Email) {
+		return false
+	}
+	return true
+}
+
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+// UserIterator walks over users without materializing the full result set.
+// Usage mirrors database/sql.Rows:
+//
+//	it := repo.Iterate(ctx, filter)
+//	defer it.Close()
+//	for it.Next() {
+//	    user := it.User()
+//	}
+//	if err := it.Err(); err != nil { ... }
+type UserIterator interface {
+	Next() bool
+	User() *User
+	Err() error
+	Close() error
+}
+
+// UserIteratorRepository is implemented by repositories that can stream users
+type UserIteratorRepository interface {
+	Iterate(ctx context.Context, filter UserFilter) UserIterator
+}
+
+// sliceUserIterator iterates over an already loaded slice of users
+type sliceUserIterator struct {
+	users   []*User
+	filter  UserFilter
+	current *User
+	err     error
+}
+
+func newSliceUserIterator(users []*User, filter UserFilter, err error) *sliceUserIterator {
+	return &sliceUserIterator{users: users, filter: filter, err: err}
+}
+
+func (it *sliceUserIterator) Next() bool {
+	for it.err == nil && len(it.users) > 0 {
+		user := it.users[0]
+		it.users = it.users[1:]
+		if it.filter.Matches(user) {
+			it.current = user
+			return true
+		}
+	}
+	it.current = nil
+	return false
+}
+
+func (it *sliceUserIterator) User() *User {
+	return it.current
+}
+
+func (it *sliceUserIterator) Err() error {
+	return it.err
+}
+
+func (it *sliceUserIterator) Close() error {
+	it.users = nil
+	return nil
+}
+
+// batchFetcher loads the next batch of users after the given cursor
+type batchFetcher func(ctx context.Context, cursor uint64, limit int) (batch []*User, next uint64, err error)
+
+// batchUserIterator pulls users in fixed-size batches from a fetcher
+type batchUserIterator struct {
+	ctx     context.Context
+	fetch   batchFetcher
+	filter  UserFilter
+	limit   int
+	cursor  uint64
+	batch   []*User
+	current *User
+	done    bool
+	err     error
+}
+
+func newBatchUserIterator(ctx context.Context, fetch batchFetcher, filter UserFilter, limit int) *batchUserIterator {
+	return &batchUserIterator{ctx: ctx, fetch: fetch, filter: filter, limit: limit}
+}
+
+func (it *batchUserIterator) Next() bool {
+	for it.err == nil {
+		for len(it.batch) > 0 {
+			user := it.batch[0]
+			it.batch = it.batch[1:]
+			if it.filter.Matches(user) {
+				it.current = user
+				return true
+			}
+		}
+		if it.done {
+			break
+		}
+
+		if err := it.ctx.Err(); err != nil {
+			it.err = err
+			break
+		}
+
+		batch, next, err := it.fetch(it.ctx, it.cursor, it.limit)
+		if err != nil {
+			it.err = err
+			break
+		}
+		it.batch = batch
+		it.cursor = next
+		it.done = len(batch) < it.limit
+	}
+	it.current = nil
+	return false
+}
+
+func (it *batchUserIterator) User() *User {
+	return it.current
+}
+
+func (it *batchUserIterator) Err() error {
+	return it.err
+}
+
+func (it *batchUserIterator) Close() error {
+	it.batch = nil
+	it.done = true
+	return nil
+}
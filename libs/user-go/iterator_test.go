@@ -0,0 +1,141 @@
+package gouser
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func collect(t *testing.T, it UserIterator) []*User {
+	t.Helper()
+	defer it.Close()
+
+	users := make([]*User, 0)
+	for it.Next() {
+		users = append(users, it.User())
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("Expected no iterator error, got %v", err)
+	}
+	return users
+}
+
+func TestUserFilter_Matches(t *testing.T) {
+	user := &User{Name: "John Doe", Email: "john@example.com"}
+
+	tests := []struct {
+		name     string
+		filter   UserFilter
+		expected bool
+	}{
+		{"empty filter", UserFilter{}, true},
+		{"name substring", UserFilter{Name: "doe"}, true},
+		{"email substring", UserFilter{Email: "EXAMPLE"}, true},
+		{"both match", UserFilter{Name: "john", Email: "john@"}, true},
+		{"name mismatch", UserFilter{Name: "jane"}, false},
+		{"email mismatch", UserFilter{Email: "jane@"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Matches(user); got != tt.expected {
+				t.Errorf("Matches() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestInMemoryUserRepository_Iterate(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should iterate in creation order across batches", func(t *testing.T) {
+		repo := NewInMemoryUserRepository()
+		total := DefaultIteratorBatchSize*2 + 5
+		for i := 0; i < total; i++ {
+			_, err := repo.Create(ctx, CreateUserData{
+				Name:  fmt.Sprintf("User %d", i),
+				Email: fmt.Sprintf("user%d@example.com", i),
+			})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+		}
+
+		users := collect(t, repo.Iterate(ctx, UserFilter{}))
+
+		if len(users) != total {
+			t.Fatalf("Expected %d users, got %d", total, len(users))
+		}
+		for i, user := range users {
+			if user.Name != fmt.Sprintf("User %d", i) {
+				t.Fatalf("Expected User %d at position %d, got %s", i, i, user.Name)
+			}
+		}
+	})
+
+	t.Run("should apply filter and skip deleted users", func(t *testing.T) {
+		repo := NewInMemoryUserRepository()
+		john, _ := repo.Create(ctx, CreateUserData{Name: "John Doe", Email: "john@example.com"})
+		repo.Create(ctx, CreateUserData{Name: "Jane Doe", Email: "jane@example.com"})
+		repo.Create(ctx, CreateUserData{Name: "Mary Smith", Email: "mary@example.com"})
+
+		if err := repo.Delete(ctx, john.ID); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		users := collect(t, repo.Iterate(ctx, UserFilter{Name: "doe"}))
+
+		if len(users) != 1 || users[0].Name != "Jane Doe" {
+			t.Errorf("Expected only Jane Doe, got %+v", users)
+		}
+	})
+
+	t.Run("should return copies", func(t *testing.T) {
+		repo := NewInMemoryUserRepository()
+		created, _ := repo.Create(ctx, CreateUserData{Name: "John Doe", Email: "john@example.com"})
+
+		users := collect(t, repo.Iterate(ctx, UserFilter{}))
+		users[0].Name = "Modified"
+
+		found, _ := repo.FindByID(ctx, created.ID)
+		if found.Name != "John Doe" {
+			t.Errorf("Expected stored user to be unchanged, got %s", found.Name)
+		}
+	})
+
+	t.Run("should stop on context cancellation", func(t *testing.T) {
+		repo := NewInMemoryUserRepository()
+		repo.Create(ctx, CreateUserData{Name: "John Doe", Email: "john@example.com"})
+
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+
+		it := repo.Iterate(cancelled, UserFilter{})
+		defer it.Close()
+
+		if it.Next() {
+			t.Error("Expected no users after cancellation")
+		}
+		if it.Err() != context.Canceled {
+			t.Errorf("Expected context.Canceled, got %v", it.Err())
+		}
+	})
+}
+
+func TestUserService_Iterate(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should fall back to FindAll for repositories without iterator", func(t *testing.T) {
+		repo := NewMockUserRepository()
+		service := NewUserService(repo, nil)
+
+		repo.Create(ctx, CreateUserData{Name: "John Doe", Email: "john@example.com"})
+		repo.Create(ctx, CreateUserData{Name: "Jane Roe", Email: "jane@example.com"})
+
+		users := collect(t, service.Iterate(ctx, UserFilter{Email: "jane"}))
+
+		if len(users) != 1 || users[0].Name != "Jane Roe" {
+			t.Errorf("Expected only Jane Roe, got %+v", users)
+		}
+	})
+}
@@ -0,0 +1,86 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"time"
+)
+
+// Dialect adapts the migrator to a database
+type Dialect interface {
+	// Placeholder returns the nth (1-based) bind parameter
+	Placeholder(n int) string
+	// Lock blocks until conn holds the migration lock for table or ctx is done
+	Lock(ctx context.Context, conn *sql.Conn, table string) error
+	// Unlock releases the lock taken by Lock
+	Unlock(ctx context.Context, conn *sql.Conn, table string) error
+}
+
+// SQLite locks by inserting a row in a <table>_lock table, polling while
+// another connection holds it. A lock left by a crashed process must be
+// removed by hand.
+type SQLite struct {
+	// PollInterval is the wait between lock attempts. Defaults to 100ms.
+	PollInterval time.Duration
+}
+
+func (SQLite) Placeholder(int) string {
+	return "?"
+}
+
+func (d SQLite) Lock(ctx context.Context, conn *sql.Conn, table string) error {
+	lockTable := table + "_lock"
+	create := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (id INTEGER PRIMARY KEY CHECK (id = 1), locked_at TIMESTAMP NOT NULL)", lockTable)
+	if _, err := conn.ExecContext(ctx, create); err != nil {
+		return err
+	}
+
+	interval := d.PollInterval
+	if interval <= 0 {
+		interval = 100 * time.Millisecond
+	}
+	insert := fmt.Sprintf("INSERT INTO %s (id, locked_at) VALUES (1, ?)", lockTable)
+	for {
+		if _, err := conn.ExecContext(ctx, insert, time.Now().UTC()); err == nil {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return errors.Join(errors.New("migrations are locked by another process"), ctx.Err())
+		case <-time.After(interval):
+		}
+	}
+}
+
+func (SQLite) Unlock(ctx context.Context, conn *sql.Conn, table string) error {
+	_, err := conn.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s_lock WHERE id = 1", table))
+	return err
+}
+
+// Postgres locks with a session advisory lock keyed on the table name
+type Postgres struct{}
+
+func (Postgres) Placeholder(n int) string {
+	return "$" + strconv.Itoa(n)
+}
+
+func (Postgres) Lock(ctx context.Context, conn *sql.Conn, table string) error {
+	_, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", lockKey(table))
+	return err
+}
+
+func (Postgres) Unlock(ctx context.Context, conn *sql.Conn, table string) error {
+	_, err := conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", lockKey(table))
+	return err
+}
+
+// lockKey hashes the table name into an advisory lock key
+func lockKey(table string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte("migrate:" + table))
+	return int64(h.Sum64())
+}
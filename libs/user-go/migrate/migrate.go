@@ -0,0 +1,382 @@
+// Package migrate applies ordered, checksummed SQL schema migrations. Each
+// migration runs in a transaction together with its record in the
+// migrations table, under a lock so only one replica migrates at a time.
+package migrate
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultTable records the applied migrations
+const DefaultTable = "schema_migrations"
+
+// DefaultLockTimeout bounds the wait for another replica to finish migrating
+const DefaultLockTimeout = time.Minute
+
+// ErrDrift is matched by errors reporting applied migrations that were
+// edited or removed from the source
+var ErrDrift = errors.New("migrations drifted")
+
+// Migration is a versioned schema change read from <version>_<name>.up.sql
+// and the optional <version>_<name>.down.sql
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+	// Checksum is the hex SHA-256 of Up, recorded when the migration is applied
+	Checksum string
+}
+
+// State describes a migration relative to the database
+type State string
+
+// Migration states
+const (
+	StatePending State = "pending"
+	StateApplied State = "applied"
+	// StateDrifted is an applied migration whose source changed since
+	StateDrifted State = "drifted"
+	// StateMissing is an applied migration no longer in the source
+	StateMissing State = "missing"
+)
+
+// Status reports a migration and whether it is applied
+type Status struct {
+	Version   int64      `json:"version"`
+	Name      string     `json:"name"`
+	State     State      `json:"state"`
+	Checksum  string     `json:"checksum"`
+	AppliedAt *time.Time `json:"appliedAt,omitempty"`
+}
+
+// DriftError lists the migrations that drifted or went missing
+type DriftError struct {
+	Migrations []Status
+}
+
+func (e *DriftError) Error() string {
+	parts := make([]string, len(e.Migrations))
+	for i, status := range e.Migrations {
+		parts[i] = fmt.Sprintf("%d_%s is %s", status.Version, status.Name, status.State)
+	}
+	return "migrations drifted: " + strings.Join(parts, ", ")
+}
+
+func (e *DriftError) Is(target error) bool {
+	return target == ErrDrift
+}
+
+var fileName = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+// Load reads the migrations in dir of fsys, usually an embed.FS, sorted by
+// version. Files not named <version>_<name>.up.sql or .down.sql are ignored.
+func Load(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migration %s: %w", entry.Name(), err)
+		}
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration version %d is used by %s and %s", version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", migration.Version, migration.Name)
+		}
+		sum := sha256.Sum256([]byte(migration.Up))
+		migration.Checksum = hex.EncodeToString(sum[:])
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// Options configures a Migrator
+type Options struct {
+	// Dialect adapts statements and locking to the database. Required.
+	Dialect Dialect
+	// Table records the applied migrations. Defaults to DefaultTable.
+	Table string
+	// LockTimeout bounds the wait for the migration lock. Defaults to DefaultLockTimeout.
+	LockTimeout time.Duration
+}
+
+// Migrator applies migrations to a database
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+	options    Options
+	dryRun     io.Writer
+}
+
+// New creates a migrator applying migrations, as returned by Load, to db
+func New(db *sql.DB, migrations []Migration, opts Options) (*Migrator, error) {
+	if opts.Dialect == nil {
+		return nil, errors.New("migrate: dialect is required")
+	}
+	if opts.Table == "" {
+		opts.Table = DefaultTable
+	}
+	if opts.LockTimeout <= 0 {
+		opts.LockTimeout = DefaultLockTimeout
+	}
+	for i := 1; i < len(migrations); i++ {
+		if migrations[i].Version <= migrations[i-1].Version {
+			return nil, fmt.Errorf("migrate: migrations must be sorted by unique version, got %d after %d", migrations[i].Version, migrations[i-1].Version)
+		}
+	}
+	return &Migrator{db: db, migrations: migrations, options: opts}, nil
+}
+
+// WithDryRun returns a migrator that writes the SQL Up and Down would run
+// to w instead of executing it
+func (m *Migrator) WithDryRun(w io.Writer) *Migrator {
+	dryRun := *m
+	dryRun.dryRun = w
+	return &dryRun
+}
+
+// Status lists the source migrations followed by applied migrations missing
+// from the source
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.applied(ctx, m.db)
+	if err != nil {
+		return nil, err
+	}
+	return m.status(applied), nil
+}
+
+// Up applies the pending migrations in version order and returns them. It
+// refuses to run when applied migrations drifted.
+func (m *Migrator) Up(ctx context.Context) ([]Status, error) {
+	var done []Status
+	err := m.locked(ctx, func(conn *sql.Conn, applied map[int64]Status) error {
+		for _, migration := range m.migrations {
+			if _, ok := applied[migration.Version]; ok {
+				continue
+			}
+			insert := fmt.Sprintf("INSERT INTO %s (version, name, checksum, applied_at) VALUES (%s, %s, %s, %s)",
+				m.options.Table, m.placeholder(1), m.placeholder(2), m.placeholder(3), m.placeholder(4))
+			now := time.Now().UTC()
+			if err := m.run(ctx, conn, migration, "up", migration.Up, insert, migration.Version, migration.Name, migration.Checksum, now); err != nil {
+				return err
+			}
+			status := Status{Version: migration.Version, Name: migration.Name, State: StateApplied, Checksum: migration.Checksum}
+			if m.dryRun == nil {
+				status.AppliedAt = &now
+			} else {
+				status.State = StatePending
+			}
+			done = append(done, status)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Down reverts the last steps applied migrations, newest first, and returns
+// them. It refuses to run when applied migrations drifted.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Status, error) {
+	var done []Status
+	err := m.locked(ctx, func(conn *sql.Conn, applied map[int64]Status) error {
+		for i := len(m.migrations) - 1; i >= 0 && len(done) < steps; i-- {
+			migration := m.migrations[i]
+			if _, ok := applied[migration.Version]; !ok {
+				continue
+			}
+			if migration.Down == "" {
+				return fmt.Errorf("migration %d_%s has no down file", migration.Version, migration.Name)
+			}
+			remove := fmt.Sprintf("DELETE FROM %s WHERE version = %s", m.options.Table, m.placeholder(1))
+			if err := m.run(ctx, conn, migration, "down", migration.Down, remove, migration.Version); err != nil {
+				return err
+			}
+			state := StatePending
+			if m.dryRun != nil {
+				state = StateApplied
+			}
+			done = append(done, Status{Version: migration.Version, Name: migration.Name, State: state, Checksum: migration.Checksum})
+		}
+		return nil
+	})
+	return done, err
+}
+
+// locked runs fn holding the migration lock, after creating the migrations
+// table and checking for drift. Dry runs neither lock nor create anything.
+func (m *Migrator) locked(ctx context.Context, fn func(conn *sql.Conn, applied map[int64]Status) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if m.dryRun == nil {
+		lockCtx, cancel := context.WithTimeout(ctx, m.options.LockTimeout)
+		defer cancel()
+		if err := m.options.Dialect.Lock(lockCtx, conn, m.options.Table); err != nil {
+			return fmt.Errorf("acquire migration lock: %w", err)
+		}
+		defer m.options.Dialect.Unlock(context.WithoutCancel(ctx), conn, m.options.Table)
+
+		create := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (version BIGINT PRIMARY KEY, name VARCHAR(255) NOT NULL, checksum VARCHAR(64) NOT NULL, applied_at TIMESTAMP NOT NULL)", m.options.Table)
+		if _, err := conn.ExecContext(ctx, create); err != nil {
+			return fmt.Errorf("create migrations table: %w", err)
+		}
+	}
+
+	rows, err := m.applied(ctx, conn)
+	if err != nil {
+		return err
+	}
+	var drifted []Status
+	for _, status := range m.status(rows) {
+		if status.State == StateDrifted || status.State == StateMissing {
+			drifted = append(drifted, status)
+		}
+	}
+	if len(drifted) > 0 {
+		return &DriftError{Migrations: drifted}
+	}
+	return fn(conn, rows)
+}
+
+// run executes a migration and its bookkeeping statement in a transaction,
+// or writes them to the dry run output
+func (m *Migrator) run(ctx context.Context, conn *sql.Conn, migration Migration, direction, script, record string, args ...any) error {
+	if m.dryRun != nil {
+		_, err := fmt.Fprintf(m.dryRun, "-- %d_%s (%s)\n%s\n%s;\n\n", migration.Version, migration.Name, direction, strings.TrimSpace(script), record)
+		return err
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return fmt.Errorf("migration %d_%s (%s): %w", migration.Version, migration.Name, direction, err)
+	}
+	if _, err := tx.ExecContext(ctx, record, args...); err != nil {
+		return fmt.Errorf("record migration %d_%s: %w", migration.Version, migration.Name, err)
+	}
+	return tx.Commit()
+}
+
+// queryer is implemented by *sql.DB and *sql.Conn
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// applied reads the migrations table. A missing table means nothing is applied.
+func (m *Migrator) applied(ctx context.Context, q queryer) (map[int64]Status, error) {
+	applied := map[int64]Status{}
+	rows, err := q.QueryContext(ctx, fmt.Sprintf("SELECT version, name, checksum, applied_at FROM %s", m.options.Table))
+	if err != nil {
+		if m.tableExists(ctx, q) {
+			return nil, err
+		}
+		return applied, nil
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var status Status
+		var appliedAt time.Time
+		if err := rows.Scan(&status.Version, &status.Name, &status.Checksum, &appliedAt); err != nil {
+			return nil, err
+		}
+		appliedAt = appliedAt.UTC()
+		status.AppliedAt = &appliedAt
+		applied[status.Version] = status
+	}
+	return applied, rows.Err()
+}
+
+// tableExists reports whether the migrations table can be selected from
+func (m *Migrator) tableExists(ctx context.Context, q queryer) bool {
+	rows, err := q.QueryContext(ctx, fmt.Sprintf("SELECT 1 FROM %s WHERE 1 = 0", m.options.Table))
+	if err != nil {
+		return false
+	}
+	rows.Close()
+	return true
+}
+
+// status merges the source migrations with the applied ones
+func (m *Migrator) status(applied map[int64]Status) []Status {
+	statuses := make([]Status, 0, len(m.migrations))
+	known := map[int64]bool{}
+	for _, migration := range m.migrations {
+		known[migration.Version] = true
+		status := Status{Version: migration.Version, Name: migration.Name, State: StatePending, Checksum: migration.Checksum}
+		if record, ok := applied[migration.Version]; ok {
+			status.AppliedAt = record.AppliedAt
+			status.State = StateApplied
+			if record.Checksum != migration.Checksum {
+				status.State = StateDrifted
+			}
+		}
+		statuses = append(statuses, status)
+	}
+
+	var missing []Status
+	for version, record := range applied {
+		if !known[version] {
+			record.State = StateMissing
+			missing = append(missing, record)
+		}
+	}
+	sort.Slice(missing, func(i, j int) bool {
+		return missing[i].Version < missing[j].Version
+	})
+	return append(statuses, missing...)
+}
+
+func (m *Migrator) placeholder(n int) string {
+	return m.options.Dialect.Placeholder(n)
+}
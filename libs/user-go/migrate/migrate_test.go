@@ -0,0 +1,265 @@
+package migrate
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+var testFS = fstest.MapFS{
+	"sql/0001_create_teams.up.sql":     {Data: []byte("CREATE TABLE teams (id INTEGER PRIMARY KEY, name TEXT NOT NULL);")},
+	"sql/0001_create_teams.down.sql":   {Data: []byte("DROP TABLE teams;")},
+	"sql/0002_add_team_slug.up.sql":    {Data: []byte("ALTER TABLE teams ADD COLUMN slug TEXT;")},
+	"sql/0002_add_team_slug.down.sql":  {Data: []byte("ALTER TABLE teams DROP COLUMN slug;")},
+	"sql/README.md":                    {Data: []byte("ignored")},
+	"broken/0001_missing_up.down.sql":  {Data: []byte("SELECT 1;")},
+	"conflict/0001_first.up.sql":       {Data: []byte("SELECT 1;")},
+	"conflict/0001_second.up.sql":      {Data: []byte("SELECT 1;")},
+	"failing/0001_create_teams.up.sql": {Data: []byte("CREATE TABLE teams (id INTEGER PRIMARY KEY);")},
+	"failing/0002_invalid.up.sql":      {Data: []byte("CREATE TABLE teams (id INTEGER PRIMARY KEY);")},
+}
+
+func openDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func newMigrator(t *testing.T, db *sql.DB, dir string) *Migrator {
+	t.Helper()
+	migrations, err := Load(testFS, dir)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	migrator, err := New(db, migrations, Options{Dialect: SQLite{PollInterval: 10 * time.Millisecond}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return migrator
+}
+
+func states(statuses []Status) string {
+	parts := make([]string, len(statuses))
+	for i, status := range statuses {
+		parts[i] = string(status.State)
+	}
+	return strings.Join(parts, ",")
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("should load migrations sorted by version with checksums", func(t *testing.T) {
+		migrations, err := Load(testFS, "sql")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(migrations) != 2 {
+			t.Fatalf("Expected 2 migrations, got %d", len(migrations))
+		}
+		if migrations[0].Version != 1 || migrations[0].Name != "create_teams" {
+			t.Errorf("Expected 1_create_teams, got %d_%s", migrations[0].Version, migrations[0].Name)
+		}
+		if migrations[1].Down != "ALTER TABLE teams DROP COLUMN slug;" {
+			t.Errorf("Expected down script, got %q", migrations[1].Down)
+		}
+		if len(migrations[0].Checksum) != 64 || migrations[0].Checksum == migrations[1].Checksum {
+			t.Errorf("Expected distinct SHA-256 checksums, got %q and %q", migrations[0].Checksum, migrations[1].Checksum)
+		}
+	})
+
+	t.Run("should reject migrations without up file", func(t *testing.T) {
+		if _, err := Load(testFS, "broken"); err == nil {
+			t.Error("Expected error for missing up file")
+		}
+	})
+
+	t.Run("should reject duplicate versions", func(t *testing.T) {
+		if _, err := Load(testFS, "conflict"); err == nil {
+			t.Error("Expected error for duplicate version")
+		}
+	})
+}
+
+func TestMigrator(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should apply pending migrations once", func(t *testing.T) {
+		db := openDB(t)
+		migrator := newMigrator(t, db, "sql")
+
+		status, err := migrator.Status(ctx)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if got := states(status); got != "pending,pending" {
+			t.Errorf("Expected pending,pending, got %s", got)
+		}
+
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(applied) != 2 || applied[1].AppliedAt == nil {
+			t.Fatalf("Expected 2 applied migrations, got %+v", applied)
+		}
+		if _, err := db.Exec("INSERT INTO teams (name, slug) VALUES ('core', 'core')"); err != nil {
+			t.Errorf("Expected migrated schema, got %v", err)
+		}
+
+		applied, err = migrator.Up(ctx)
+		if err != nil || len(applied) != 0 {
+			t.Errorf("Expected nothing to apply, got %d migrations and %v", len(applied), err)
+		}
+
+		status, _ = migrator.Status(ctx)
+		if got := states(status); got != "applied,applied" {
+			t.Errorf("Expected applied,applied, got %s", got)
+		}
+	})
+
+	t.Run("should revert the last migrations", func(t *testing.T) {
+		db := openDB(t)
+		migrator := newMigrator(t, db, "sql")
+		if _, err := migrator.Up(ctx); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		reverted, err := migrator.Down(ctx, 1)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(reverted) != 1 || reverted[0].Version != 2 {
+			t.Errorf("Expected migration 2 reverted, got %+v", reverted)
+		}
+		status, _ := migrator.Status(ctx)
+		if got := states(status); got != "applied,pending" {
+			t.Errorf("Expected applied,pending, got %s", got)
+		}
+
+		if _, err := migrator.Down(ctx, 5); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if _, err := db.Exec("SELECT 1 FROM teams"); err == nil {
+			t.Error("Expected teams table to be dropped")
+		}
+	})
+
+	t.Run("should print SQL on dry run without applying it", func(t *testing.T) {
+		db := openDB(t)
+		var out bytes.Buffer
+		planned, err := newMigrator(t, db, "sql").WithDryRun(&out).Up(ctx)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(planned) != 2 {
+			t.Errorf("Expected 2 planned migrations, got %d", len(planned))
+		}
+		if !strings.Contains(out.String(), "-- 1_create_teams (up)") || !strings.Contains(out.String(), "INSERT INTO schema_migrations") {
+			t.Errorf("Expected migration SQL, got %q", out.String())
+		}
+		if _, err := db.Exec("SELECT 1 FROM teams"); err == nil {
+			t.Error("Expected dry run to leave the schema untouched")
+		}
+	})
+
+	t.Run("should detect drift", func(t *testing.T) {
+		db := openDB(t)
+		migrator := newMigrator(t, db, "sql")
+		if _, err := migrator.Up(ctx); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if _, err := db.Exec("UPDATE schema_migrations SET checksum = 'edited' WHERE version = 1"); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if _, err := db.Exec("INSERT INTO schema_migrations (version, name, checksum, applied_at) VALUES (3, 'removed', 'x', ?)", time.Now()); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		status, err := migrator.Status(ctx)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if got := states(status); got != "drifted,applied,missing" {
+			t.Errorf("Expected drifted,applied,missing, got %s", got)
+		}
+
+		_, err = migrator.Up(ctx)
+		var drift *DriftError
+		if !errors.Is(err, ErrDrift) || !errors.As(err, &drift) || len(drift.Migrations) != 2 {
+			t.Errorf("Expected drift error with 2 migrations, got %v", err)
+		}
+		if _, err := migrator.Down(ctx, 1); !errors.Is(err, ErrDrift) {
+			t.Errorf("Expected drift error on down, got %v", err)
+		}
+	})
+
+	t.Run("should roll back failed migrations", func(t *testing.T) {
+		db := openDB(t)
+		applied, err := newMigrator(t, db, "failing").Up(ctx)
+		if err == nil || !strings.Contains(err.Error(), "2_invalid") {
+			t.Errorf("Expected error from migration 2, got %v", err)
+		}
+		if len(applied) != 1 {
+			t.Errorf("Expected the first migration applied, got %d", len(applied))
+		}
+
+		var count int
+		db.QueryRow("SELECT COUNT(*) FROM schema_migrations").Scan(&count)
+		if count != 1 {
+			t.Errorf("Expected 1 recorded migration, got %d", count)
+		}
+	})
+
+	t.Run("should wait for the migration lock", func(t *testing.T) {
+		db := openDB(t)
+		if _, err := db.Exec("CREATE TABLE schema_migrations_lock (id INTEGER PRIMARY KEY, locked_at TIMESTAMP NOT NULL); INSERT INTO schema_migrations_lock VALUES (1, CURRENT_TIMESTAMP)"); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		migrations, _ := Load(testFS, "sql")
+		migrator, _ := New(db, migrations, Options{Dialect: SQLite{PollInterval: 10 * time.Millisecond}, LockTimeout: 50 * time.Millisecond})
+
+		if _, err := migrator.Up(ctx); err == nil || !strings.Contains(err.Error(), "locked by another process") {
+			t.Errorf("Expected lock timeout, got %v", err)
+		}
+
+		db.Exec("DELETE FROM schema_migrations_lock")
+		if _, err := migrator.Up(ctx); err != nil {
+			t.Errorf("Expected migrations once unlocked, got %v", err)
+		}
+	})
+
+	t.Run("should require a dialect and sorted migrations", func(t *testing.T) {
+		if _, err := New(nil, nil, Options{}); err == nil {
+			t.Error("Expected error without dialect")
+		}
+		migrations := []Migration{{Version: 2}, {Version: 1}}
+		if _, err := New(nil, migrations, Options{Dialect: SQLite{}}); err == nil {
+			t.Error("Expected error for unsorted migrations")
+		}
+	})
+}
+
+func TestPostgres(t *testing.T) {
+	t.Run("should number placeholders", func(t *testing.T) {
+		if got := (Postgres{}).Placeholder(3); got != "$3" {
+			t.Errorf("Expected $3, got %s", got)
+		}
+	})
+
+	t.Run("should derive a stable lock key per table", func(t *testing.T) {
+		if lockKey("schema_migrations") != lockKey("schema_migrations") || lockKey("a") == lockKey("b") {
+			t.Error("Expected stable, distinct lock keys")
+		}
+	})
+}
@@ -0,0 +1,17 @@
+package gouser
+
+import "embed"
+
+// Migrations holds the users schema, one directory per database:
+// migrations/sqlite and migrations/postgres. Apply them with the migrate package:
+//
+//	migrations, err := migrate.Load(gouser.Migrations, gouser.SQLiteMigrations)
+//
+//go:embed migrations
+var Migrations embed.FS
+
+// Migration directories in Migrations
+const (
+	SQLiteMigrations   = "migrations/sqlite"
+	PostgresMigrations = "migrations/postgres"
+)
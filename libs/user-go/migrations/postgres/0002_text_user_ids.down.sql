@@ -0,0 +1,6 @@
+-- Generated IDs cannot be kept as integers; users get their seq as ID.
+ALTER TABLE users DROP CONSTRAINT users_pkey;
+ALTER TABLE users DROP COLUMN id;
+ALTER TABLE users DROP CONSTRAINT users_seq_key;
+ALTER TABLE users RENAME COLUMN seq TO id;
+ALTER TABLE users ADD PRIMARY KEY (id);
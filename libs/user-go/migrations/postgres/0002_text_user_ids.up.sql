@@ -0,0 +1,9 @@
+-- User IDs become text assigned by the application's ID generator. seq keeps
+-- the creation order for listings and iteration cursors.
+ALTER TABLE users DROP CONSTRAINT users_pkey;
+ALTER TABLE users RENAME COLUMN id TO seq;
+ALTER TABLE users ADD CONSTRAINT users_seq_key UNIQUE (seq);
+ALTER TABLE users ADD COLUMN id TEXT;
+UPDATE users SET id = seq::text;
+ALTER TABLE users ALTER COLUMN id SET NOT NULL;
+ALTER TABLE users ADD PRIMARY KEY (id);
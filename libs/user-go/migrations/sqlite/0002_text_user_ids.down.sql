@@ -0,0 +1,16 @@
+-- Generated IDs cannot be kept as integers; users get their seq as ID.
+CREATE TABLE users_integer_ids (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    email TEXT NOT NULL UNIQUE,
+    phone TEXT NOT NULL DEFAULT '',
+    address TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+INSERT INTO users_integer_ids (id, name, email, phone, address, created_at, updated_at)
+SELECT seq, name, email, phone, address, created_at, updated_at FROM users;
+
+DROP TABLE users;
+ALTER TABLE users_integer_ids RENAME TO users;
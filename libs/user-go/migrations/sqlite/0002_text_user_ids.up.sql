@@ -0,0 +1,18 @@
+-- User IDs become text assigned by the application's ID generator. seq keeps
+-- the creation order for listings and iteration cursors.
+CREATE TABLE users_text_ids (
+    seq INTEGER PRIMARY KEY AUTOINCREMENT,
+    id TEXT NOT NULL UNIQUE,
+    name TEXT NOT NULL,
+    email TEXT NOT NULL UNIQUE,
+    phone TEXT NOT NULL DEFAULT '',
+    address TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+INSERT INTO users_text_ids (seq, id, name, email, phone, address, created_at, updated_at)
+SELECT id, CAST(id AS TEXT), name, email, phone, address, created_at, updated_at FROM users;
+
+DROP TABLE users;
+ALTER TABLE users_text_ids RENAME TO users;
@@ -0,0 +1,45 @@
+package gouser
+
+import "context"
+
+// Principal types
+const (
+	PrincipalTypeUser    = "user"
+	PrincipalTypeService = "service"
+)
+
+// Principal represents the authenticated actor of an operation
+type Principal struct {
+	ID    string   `json:"id"`
+	Type  string   `json:"type"`
+	Roles []string `json:"roles,omitempty"`
+	// AuthMethod records how the principal was authenticated (e.g. "jwt", "api_key")
+	AuthMethod string `json:"authMethod,omitempty"`
+}
+
+// HasRole reports whether the principal holds the given role
+func (p *Principal) HasRole(role string) bool {
+	if p == nil {
+		return false
+	}
+	for _, r := range p.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// principalContextKey is the context key for the authenticated principal
+type principalContextKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying the principal
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalContextKey{}, principal)
+}
+
+// PrincipalFromContext returns the principal stored in ctx, if any
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalContextKey{}).(*Principal)
+	return principal, ok && principal != nil
+}
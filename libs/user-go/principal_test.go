@@ -0,0 +1,91 @@
+package gouser
+
+import (
+	"context"
+	"testing"
+)
+
+// contextRecordingEvents records the principal seen by each context-aware event
+type contextRecordingEvents struct {
+	MockUserEvents
+	Actors []string
+}
+
+func (e *contextRecordingEvents) OnUserCreatedContext(ctx context.Context, user *User) {
+	e.record(ctx)
+	e.OnUserCreated(user)
+}
+
+func (e *contextRecordingEvents) OnUserUpdatedContext(ctx context.Context, user *User) {
+	e.record(ctx)
+	e.OnUserUpdated(user)
+}
+
+func (e *contextRecordingEvents) OnUserDeletedContext(ctx context.Context, userID string) {
+	e.record(ctx)
+	e.OnUserDeleted(userID)
+}
+
+func (e *contextRecordingEvents) record(ctx context.Context) {
+	actor := ""
+	if principal, ok := PrincipalFromContext(ctx); ok {
+		actor = principal.ID
+	}
+	e.Actors = append(e.Actors, actor)
+}
+
+func TestPrincipalFromContext(t *testing.T) {
+	t.Run("should return principal stored in context", func(t *testing.T) {
+		principal := &Principal{ID: "42", Type: PrincipalTypeUser, Roles: []string{"admin"}}
+		ctx := WithPrincipal(context.Background(), principal)
+
+		got, ok := PrincipalFromContext(ctx)
+		if !ok || got != principal {
+			t.Fatalf("Expected stored principal, got %+v (ok=%v)", got, ok)
+		}
+		if !got.HasRole("admin") || got.HasRole("user") {
+			t.Errorf("Unexpected roles check for %+v", got.Roles)
+		}
+	})
+
+	t.Run("should report missing principal", func(t *testing.T) {
+		if _, ok := PrincipalFromContext(context.Background()); ok {
+			t.Error("Expected no principal")
+		}
+		if _, ok := PrincipalFromContext(WithPrincipal(context.Background(), nil)); ok {
+			t.Error("Expected nil principal to be reported as missing")
+		}
+	})
+}
+
+func TestUserService_ContextUserEvents(t *testing.T) {
+	repo := NewMockUserRepository()
+	events := &contextRecordingEvents{}
+	service := NewUserService(repo, events)
+	ctx := WithPrincipal(context.Background(), &Principal{ID: "admin-1"})
+
+	user, err := service.Create(ctx, CreateUserData{Name: "John Doe", Email: "john@example.com"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	name := "John Smith"
+	if _, err := service.Update(ctx, user.ID, UpdateUserData{Name: &name}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := service.Delete(context.Background(), user.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []string{"admin-1", "admin-1", ""}
+	if len(events.Actors) != len(expected) {
+		t.Fatalf("Expected %d events, got %d", len(expected), len(events.Actors))
+	}
+	for i, actor := range expected {
+		if events.Actors[i] != actor {
+			t.Errorf("Event %d: expected actor %q, got %q", i, actor, events.Actors[i])
+		}
+	}
+	if len(events.CreatedUsers) != 1 || len(events.UpdatedUsers) != 1 || len(events.DeletedIDs) != 1 {
+		t.Errorf("Expected one event of each kind, got %+v", events.MockUserEvents)
+	}
+}
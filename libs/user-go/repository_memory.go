@@ -2,23 +2,38 @@ package gouser
 
 import (
 	"context"
-	"strconv"
+	"sort"
 	"sync"
 	"time"
 )
 
+// orderedID keeps the creation sequence of a stored user for iteration
+type orderedID struct {
+	seq uint64
+	id  string
+}
+
 // InMemoryUserRepository is a thread-safe in-memory implementation of UserRepository
 type InMemoryUserRepository struct {
-	users  map[string]*User
-	nextID int
-	mutex  sync.RWMutex
+	users   map[string]*User
+	order   []orderedID
+	seqs    map[string]uint64
+	nextSeq uint64
+	ids     IDGenerator
+	clock   Clock
+	mutex   sync.RWMutex
 }
 
-// NewInMemoryUserRepository creates a new In-memory user repository
-func NewInMemoryUserRepository() *InMemoryUserRepository {
+// NewInMemoryUserRepository creates a new In-memory user repository. IDs are
+// sequential unless WithIDGenerator is given and timestamps come from the
+// system clock unless WithClock is given.
+func NewInMemoryUserRepository(opts ...RepositoryOption) *InMemoryUserRepository {
+	options := newRepositoryOptions(repositoryOptions{ids: NewSequentialIDGenerator()}, opts)
 	return &InMemoryUserRepository{
-		users:  make(map[string]*User),
-		nextID: 1,
+		users: make(map[string]*User),
+		seqs:  make(map[string]uint64),
+		ids:   options.ids,
+		clock: options.clock,
 	}
 }
 
@@ -39,18 +54,22 @@ func (r *InMemoryUserRepository) Create(ctx context.Context, data CreateUserData
 	default:
 	}
 
+	now := Timestamp(r.clock)
 	user := &User{
 		ID:        r.generateID(),
 		Name:      data.Name,
 		Email:     data.Email,
 		Phone:     data.Phone,
 		Address:   data.Address,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		CreatedAt: now,
+		UpdatedAt: now,
 	}
 
-	r.users[user.ID] = user
-	return user, nil
+	r.insert(user)
+
+	// Return a copy to prevent external modification
+	userCopy := *user
+	return &userCopy, nil
 }
 
 // FindByID finds a user by ID
@@ -97,6 +116,37 @@ func (r *InMemoryUserRepository) FindAll(ctx context.Context) ([]*User, error) {
 	return users, nil
 }
 
+// FindByEmail finds a user by email. The store has no email index, but the
+// scan copies only the matching user.
+func (r *InMemoryUserRepository) FindByEmail(ctx context.Context, email string) (*User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	for _, user := range r.users {
+		if user.Email == email {
+			userCopy := *user
+			return &userCopy, nil
+		}
+	}
+	return nil, nil
+}
+
+// Count returns the number of stored users
+func (r *InMemoryUserRepository) Count(ctx context.Context) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return len(r.users), nil
+}
+
 // Update updates a user
 func (r *InMemoryUserRepository) Update(ctx context.Context, id string, data UpdateUserData) (*User, error) {
 	// Validate input data
@@ -119,21 +169,7 @@ func (r *InMemoryUserRepository) Update(ctx context.Context, id string, data Upd
 		return nil, nil
 	}
 
-	// Update only provided fields
-	if data.Name != nil {
-		user.Name = *data.Name
-	}
-	if data.Email != nil {
-		user.Email = *data.Email
-	}
-	if data.Phone != nil {
-		user.Phone = *data.Phone
-	}
-	if data.Address != nil {
-		user.Address = *data.Address
-	}
-
-	user.UpdatedAt = time.Now()
+	applyUpdate(user, data, Timestamp(r.clock))
 
 	// Return a copy
 	userCopy := *user
@@ -157,22 +193,307 @@ func (r *InMemoryUserRepository) Delete(ctx context.Context, id string) error {
 		return nil // User not found, but no error
 	}
 
+	r.remove(id)
+	return nil
+}
+
+// applyUpdate sets the provided fields of data on user
+func applyUpdate(user *User, data UpdateUserData, now time.Time) {
+	if data.Name != nil {
+		user.Name = *data.Name
+	}
+	if data.Email != nil {
+		user.Email = *data.Email
+	}
+	if data.Phone != nil {
+		user.Phone = *data.Phone
+	}
+	if data.Address != nil {
+		user.Address = *data.Address
+	}
+	user.UpdatedAt = now
+}
+
+// insert stores a new user after the existing ones. The caller must hold the lock.
+func (r *InMemoryUserRepository) insert(user *User) {
+	r.users[user.ID] = user
+	r.nextSeq++
+	r.seqs[user.ID] = r.nextSeq
+	r.order = append(r.order, orderedID{seq: r.nextSeq, id: user.ID})
+}
+
+// remove drops a stored user. The caller must hold the lock.
+func (r *InMemoryUserRepository) remove(id string) {
 	delete(r.users, id)
+	seq, exists := r.seqs[id]
+	if !exists {
+		return
+	}
+	delete(r.seqs, id)
+	if pos := r.position(seq); pos < len(r.order) && r.order[pos].seq == seq {
+		r.order = append(r.order[:pos], r.order[pos+1:]...)
+	}
+}
+
+// Iterate returns an iterator that walks users in creation order, copying
+// them in small batches instead of snapshotting the whole store.
+// Users created while iterating are visited; deleted ones are skipped.
+func (r *InMemoryUserRepository) Iterate(ctx context.Context, filter UserFilter) UserIterator {
+	return newBatchUserIterator(ctx, r.fetchBatch, filter, DefaultIteratorBatchSize)
+}
+
+// fetchBatch copies up to limit users created after the cursor sequence
+func (r *InMemoryUserRepository) fetchBatch(ctx context.Context, cursor uint64, limit int) ([]*User, uint64, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	start := r.position(cursor + 1)
+	end := start + limit
+	if end > len(r.order) {
+		end = len(r.order)
+	}
+
+	batch := make([]*User, 0, end-start)
+	for _, entry := range r.order[start:end] {
+		// Return copies to prevent external modification
+		userCopy := *r.users[entry.id]
+		batch = append(batch, &userCopy)
+		cursor = entry.seq
+	}
+
+	return batch, cursor, nil
+}
+
+// position returns the index of the first entry with a sequence >= seq
+func (r *InMemoryUserRepository) position(seq uint64) int {
+	return sort.Search(len(r.order), func(i int) bool {
+		return r.order[i].seq >= seq
+	})
+}
+
+// RunInTx runs fn against a write overlay of the store, applying the
+// overlay when fn succeeds and dropping it otherwise. Transactions are
+// serialized and block other operations until they finish, so fn must only
+// use repos.
+func (r *InMemoryUserRepository) RunInTx(ctx context.Context, fn TxFunc) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	tx := newInMemoryTx(r)
+	if err := fn(ctx, Repositories{Users: tx}); err != nil {
+		return err
+	}
+
+	tx.commit()
 	return nil
 }
 
-// generateID generates the next sequential ID
+// Ping reports the repository as available while ctx is alive
+func (r *InMemoryUserRepository) Ping(ctx context.Context) error {
+	return ctx.Err()
+}
+
+// generateID generates the next ID
 func (r *InMemoryUserRepository) generateID() string {
-	id := r.nextID
-	r.nextID++
-	return strconv.Itoa(id) // Proper integer-to-string conversion
+	return r.ids.NewID()
 }
 
-// Clear clears all users (useful for testing)
+// Clear clears all users (useful for testing). IDs keep coming from the same
+// generator, so users created afterwards never reuse the IDs of cleared ones.
 func (r *InMemoryUserRepository) Clear() {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
 	r.users = make(map[string]*User)
-	r.nextID = 1
+	r.order = nil
+	r.seqs = make(map[string]uint64)
+	r.nextSeq = 0
+}
+
+// inMemoryTx is the write overlay of an InMemoryUserRepository transaction.
+// Reads see the store through the pending changes, which are only applied
+// to the store on commit. The repository lock is held for its whole life.
+type inMemoryTx struct {
+	repo    *InMemoryUserRepository
+	users   map[string]*User
+	created []string
+	deleted map[string]bool
+}
+
+func newInMemoryTx(repo *InMemoryUserRepository) *inMemoryTx {
+	return &inMemoryTx{
+		repo:    repo,
+		users:   make(map[string]*User),
+		deleted: make(map[string]bool),
+	}
+}
+
+// Create creates a user in the overlay. IDs come from the store's generator
+// so rolled back IDs are not reused.
+func (tx *inMemoryTx) Create(ctx context.Context, data CreateUserData) (*User, error) {
+	if err := ValidateCreateUserData(data); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	now := Timestamp(tx.repo.clock)
+	user := &User{
+		ID:        tx.repo.generateID(),
+		Name:      data.Name,
+		Email:     data.Email,
+		Phone:     data.Phone,
+		Address:   data.Address,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	tx.users[user.ID] = user
+	tx.created = append(tx.created, user.ID)
+
+	userCopy := *user
+	return &userCopy, nil
+}
+
+// FindByID finds a user by ID
+func (tx *inMemoryTx) FindByID(ctx context.Context, id string) (*User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	user := tx.lookup(id)
+	if user == nil {
+		return nil, nil
+	}
+	userCopy := *user
+	return &userCopy, nil
+}
+
+// FindAll finds all users
+func (tx *inMemoryTx) FindAll(ctx context.Context) ([]*User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	users := make([]*User, 0, len(tx.repo.users)+len(tx.created))
+	tx.each(func(user *User) bool {
+		userCopy := *user
+		users = append(users, &userCopy)
+		return true
+	})
+	return users, nil
+}
+
+// FindByEmail finds a user by email
+func (tx *inMemoryTx) FindByEmail(ctx context.Context, email string) (*User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var found *User
+	tx.each(func(user *User) bool {
+		if user.Email == email {
+			userCopy := *user
+			found = &userCopy
+			return false
+		}
+		return true
+	})
+	return found, nil
+}
+
+// Count returns the number of users the transaction sees
+func (tx *inMemoryTx) Count(ctx context.Context) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	return len(tx.repo.users) - len(tx.deleted) + len(tx.created), nil
+}
+
+// Update updates a user in the overlay
+func (tx *inMemoryTx) Update(ctx context.Context, id string, data UpdateUserData) (*User, error) {
+	if err := ValidateUpdateUserData(data); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	stored := tx.lookup(id)
+	if stored == nil {
+		return nil, nil
+	}
+
+	// Stored users belong to the store until commit, so update a copy
+	updated := *stored
+	applyUpdate(&updated, data, Timestamp(tx.repo.clock))
+	tx.users[id] = &updated
+
+	userCopy := updated
+	return &userCopy, nil
+}
+
+// Delete deletes a user in the overlay
+func (tx *inMemoryTx) Delete(ctx context.Context, id string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	delete(tx.users, id)
+	for i, created := range tx.created {
+		if created == id {
+			tx.created = append(tx.created[:i], tx.created[i+1:]...)
+			return nil
+		}
+	}
+	if _, exists := tx.repo.users[id]; exists {
+		tx.deleted[id] = true
+	}
+	return nil
+}
+
+// lookup returns the user the transaction sees for id, or nil
+func (tx *inMemoryTx) lookup(id string) *User {
+	if tx.deleted[id] {
+		return nil
+	}
+	if user, exists := tx.users[id]; exists {
+		return user
+	}
+	return tx.repo.users[id]
+}
+
+// each calls fn with every user the transaction sees until fn returns false
+func (tx *inMemoryTx) each(fn func(user *User) bool) {
+	for id, user := range tx.repo.users {
+		if tx.deleted[id] {
+			continue
+		}
+		if updated, exists := tx.users[id]; exists {
+			user = updated
+		}
+		if !fn(user) {
+			return
+		}
+	}
+	for _, id := range tx.created {
+		if !fn(tx.users[id]) {
+			return
+		}
+	}
+}
+
+// commit applies the pending deletes, updates and creates to the store
+func (tx *inMemoryTx) commit() {
+	for id := range tx.deleted {
+		tx.repo.remove(id)
+	}
+	for id, user := range tx.users {
+		if _, exists := tx.repo.users[id]; exists {
+			tx.repo.users[id] = user
+		}
+	}
+	for _, id := range tx.created {
+		tx.repo.insert(tx.users[id])
+	}
 }
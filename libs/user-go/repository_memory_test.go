@@ -385,7 +385,7 @@ func TestInMemoryUserRepository_Clear(t *testing.T) {
 		t.Errorf("Expected no users after clear, got %d", len(users))
 	}
 
-	// Verify next ID is reset
+	// Verify IDs of cleared users are not reused
 	user, err := repo.Create(ctx, CreateUserData{
 		Name:  "New User",
 		Email: "new@example.com",
@@ -395,9 +395,8 @@ func TestInMemoryUserRepository_Clear(t *testing.T) {
 		t.Fatalf("Expected no error, got %v", err)
 	}
 
-	// Should start from ID 1 again
-	if user.ID != "1" {
-		t.Errorf("Expected ID 1, got %s", user.ID)
+	if user.ID == "1" || user.ID == "2" {
+		t.Errorf("Expected a new ID, got %s", user.ID)
 	}
 }
 
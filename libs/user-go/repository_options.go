@@ -0,0 +1,33 @@
+package gouser
+
+// RepositoryOption configures the repositories of this package
+type RepositoryOption func(*repositoryOptions)
+
+// repositoryOptions holds the collaborators shared by the repositories
+type repositoryOptions struct {
+	ids   IDGenerator
+	clock Clock
+}
+
+// WithIDGenerator makes the repository assign IDs from ids
+func WithIDGenerator(ids IDGenerator) RepositoryOption {
+	return func(o *repositoryOptions) {
+		o.ids = ids
+	}
+}
+
+// WithClock makes the repository read CreatedAt and UpdatedAt from clock
+func WithClock(clock Clock) RepositoryOption {
+	return func(o *repositoryOptions) {
+		o.clock = clock
+	}
+}
+
+// newRepositoryOptions applies opts over the defaults
+func newRepositoryOptions(defaults repositoryOptions, opts []RepositoryOption) repositoryOptions {
+	defaults.clock = SystemClock
+	for _, opt := range opts {
+		opt(&defaults)
+	}
+	return defaults
+}
@@ -0,0 +1,257 @@
+package gouser
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// SQLDialect adapts SQLUserRepository statements to a database.
+// The dialects of the migrate package implement it.
+type SQLDialect interface {
+	// Placeholder returns the nth (1-based) bind parameter
+	Placeholder(n int) string
+}
+
+const userColumns = "id, name, email, phone, address, created_at, updated_at"
+
+// sqlQueryer is implemented by *sql.DB and *sql.Tx
+type sqlQueryer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// SQLUserRepository stores users in the users table created by Migrations
+type SQLUserRepository struct {
+	db      *sql.DB
+	q       sqlQueryer
+	tx      *sql.Tx
+	dialect SQLDialect
+	ids     IDGenerator
+	clock   Clock
+}
+
+// NewSQLUserRepository creates a repository over db, which must already be
+// migrated. IDs are UUIDv7 unless WithIDGenerator is given; the generator
+// must not repeat IDs across restarts or replicas. Timestamps come from the
+// system clock unless WithClock is given.
+func NewSQLUserRepository(db *sql.DB, dialect SQLDialect, opts ...RepositoryOption) *SQLUserRepository {
+	options := newRepositoryOptions(repositoryOptions{ids: UUIDv7Generator{}}, opts)
+	return &SQLUserRepository{db: db, q: db, dialect: dialect, ids: options.ids, clock: options.clock}
+}
+
+// Create creates a new user
+func (r *SQLUserRepository) Create(ctx context.Context, data CreateUserData) (*User, error) {
+	if err := ValidateCreateUserData(data); err != nil {
+		return nil, err
+	}
+
+	now := Timestamp(r.clock)
+	query := fmt.Sprintf("INSERT INTO users (id, name, email, phone, address, created_at, updated_at) VALUES (%s) RETURNING %s",
+		r.placeholders(1, 7), userColumns)
+	user, err := scanUser(r.q.QueryRowContext(ctx, query, r.ids.NewID(), data.Name, data.Email, data.Phone, data.Address, now, now))
+	if isUniqueViolation(err) {
+		return nil, ErrUserAlreadyExists
+	}
+	return user, err
+}
+
+// FindByID finds a user by ID
+func (r *SQLUserRepository) FindByID(ctx context.Context, id string) (*User, error) {
+	query := fmt.Sprintf("SELECT %s FROM users WHERE id = %s", userColumns, r.dialect.Placeholder(1))
+	user, err := scanUser(r.q.QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return user, err
+}
+
+// FindByEmail finds a user by email through the unique email index
+func (r *SQLUserRepository) FindByEmail(ctx context.Context, email string) (*User, error) {
+	query := fmt.Sprintf("SELECT %s FROM users WHERE email = %s", userColumns, r.dialect.Placeholder(1))
+	user, err := scanUser(r.q.QueryRowContext(ctx, query, email))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return user, err
+}
+
+// FindAll finds all users in creation order
+func (r *SQLUserRepository) FindAll(ctx context.Context) ([]*User, error) {
+	rows, err := r.q.QueryContext(ctx, fmt.Sprintf("SELECT %s FROM users ORDER BY seq", userColumns))
+	if err != nil {
+		return nil, err
+	}
+	return scanUsers(rows)
+}
+
+// Count returns the number of stored users
+func (r *SQLUserRepository) Count(ctx context.Context) (int, error) {
+	var count int
+	err := r.q.QueryRowContext(ctx, "SELECT COUNT(*) FROM users").Scan(&count)
+	return count, err
+}
+
+// Update updates the provided fields of a user
+func (r *SQLUserRepository) Update(ctx context.Context, id string, data UpdateUserData) (*User, error) {
+	if err := ValidateUpdateUserData(data); err != nil {
+		return nil, err
+	}
+
+	var sets []string
+	var args []any
+	set := func(column string, value any) {
+		args = append(args, value)
+		sets = append(sets, column+" = "+r.dialect.Placeholder(len(args)))
+	}
+	if data.Name != nil {
+		set(FieldName, *data.Name)
+	}
+	if data.Email != nil {
+		set(FieldEmail, *data.Email)
+	}
+	if data.Phone != nil {
+		set(FieldPhone, *data.Phone)
+	}
+	if data.Address != nil {
+		set(FieldAddress, *data.Address)
+	}
+	set("updated_at", Timestamp(r.clock))
+	args = append(args, id)
+
+	query := fmt.Sprintf("UPDATE users SET %s WHERE id = %s RETURNING %s",
+		strings.Join(sets, ", "), r.dialect.Placeholder(len(args)), userColumns)
+	user, err := scanUser(r.q.QueryRowContext(ctx, query, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if isUniqueViolation(err) {
+		return nil, ErrUserAlreadyExists
+	}
+	return user, err
+}
+
+// Delete deletes a user. Unknown IDs are not an error.
+func (r *SQLUserRepository) Delete(ctx context.Context, id string) error {
+	_, err := r.q.ExecContext(ctx, "DELETE FROM users WHERE id = "+r.dialect.Placeholder(1), id)
+	return err
+}
+
+// Iterate returns an iterator that walks users in creation order, querying
+// them in batches keyed on the creation sequence.
+func (r *SQLUserRepository) Iterate(ctx context.Context, filter UserFilter) UserIterator {
+	return newBatchUserIterator(ctx, r.fetchBatch, filter, DefaultIteratorBatchSize)
+}
+
+// fetchBatch queries up to limit users created after the cursor sequence
+func (r *SQLUserRepository) fetchBatch(ctx context.Context, cursor uint64, limit int) ([]*User, uint64, error) {
+	query := fmt.Sprintf("SELECT seq, %s FROM users WHERE seq > %s ORDER BY seq LIMIT %s",
+		userColumns, r.dialect.Placeholder(1), r.dialect.Placeholder(2))
+	rows, err := r.q.QueryContext(ctx, query, int64(cursor), limit)
+	if err != nil {
+		return nil, cursor, err
+	}
+	defer rows.Close()
+
+	batch := make([]*User, 0, limit)
+	for rows.Next() {
+		var seq int64
+		var user User
+		if err := rows.Scan(append([]any{&seq}, userFields(&user)...)...); err != nil {
+			return nil, cursor, err
+		}
+		batch = append(batch, normalizeUser(&user))
+		cursor = uint64(seq)
+	}
+	return batch, cursor, rows.Err()
+}
+
+// RunInTx runs fn in a database transaction, committing when it returns
+// nil. Called on a repository already bound to a transaction, fn joins it.
+func (r *SQLUserRepository) RunInTx(ctx context.Context, fn TxFunc) error {
+	if r.tx != nil {
+		return fn(ctx, Repositories{Users: r})
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	// Rolls back on errors and panics; a no-op once committed
+	defer tx.Rollback()
+
+	if err := fn(ctx, Repositories{Users: &SQLUserRepository{db: r.db, q: tx, tx: tx, dialect: r.dialect, ids: r.ids, clock: r.clock}}); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Ping checks the database connection
+func (r *SQLUserRepository) Ping(ctx context.Context) error {
+	return r.db.PingContext(ctx)
+}
+
+// placeholders returns count comma-separated bind parameters starting at from
+func (r *SQLUserRepository) placeholders(from, count int) string {
+	params := make([]string, count)
+	for i := range params {
+		params[i] = r.dialect.Placeholder(from + i)
+	}
+	return strings.Join(params, ", ")
+}
+
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// isUniqueViolation reports whether err is a unique constraint violation,
+// such as a concurrent create with the same email passing the service check.
+// Drivers are recognized without importing them: Postgres drivers expose
+// SQLSTATE 23505 and SQLite drivers report the constraint in the message.
+func isUniqueViolation(err error) bool {
+	if err == nil {
+		return false
+	}
+	var state interface{ SQLState() string }
+	if errors.As(err, &state) {
+		return state.SQLState() == "23505"
+	}
+	return strings.Contains(err.Error(), "UNIQUE constraint failed")
+}
+
+// userFields returns the scan destinations of userColumns
+func userFields(user *User) []any {
+	return []any{&user.ID, &user.Name, &user.Email, &user.Phone, &user.Address, &user.CreatedAt, &user.UpdatedAt}
+}
+
+// normalizeUser converts the timestamps read by the driver with NormalizeTime
+func normalizeUser(user *User) *User {
+	user.CreatedAt = NormalizeTime(user.CreatedAt)
+	user.UpdatedAt = NormalizeTime(user.UpdatedAt)
+	return user
+}
+
+func scanUser(row rowScanner) (*User, error) {
+	var user User
+	if err := row.Scan(userFields(&user)...); err != nil {
+		return nil, err
+	}
+	return normalizeUser(&user), nil
+}
+
+func scanUsers(rows *sql.Rows) ([]*User, error) {
+	defer rows.Close()
+	users := []*User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
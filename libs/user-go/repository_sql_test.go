@@ -0,0 +1,233 @@
+package gouser
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/mateusmacedo/scouts/libs/user-go/migrate"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// newSQLiteRepository returns a repository over a migrated SQLite database
+func newSQLiteRepository(t *testing.T, opts ...RepositoryOption) *SQLUserRepository {
+	t.Helper()
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "users.db"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	migrations, err := migrate.Load(Migrations, SQLiteMigrations)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	migrator, err := migrate.New(db, migrations, migrate.Options{Dialect: migrate.SQLite{}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := migrator.Up(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return NewSQLUserRepository(db, migrate.SQLite{}, opts...)
+}
+
+func TestSQLUserRepository(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should create and find users", func(t *testing.T) {
+		repo := newSQLiteRepository(t)
+
+		user, err := repo.Create(ctx, CreateUserData{Name: "John Doe", Email: "john@example.com", Phone: "+1234567890"})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if !(UUIDv7Generator{}).ValidID(user.ID) {
+			t.Errorf("Expected a UUIDv7 ID, got %s", user.ID)
+		}
+		if user.CreatedAt.IsZero() || user.CreatedAt.Location() != time.UTC {
+			t.Errorf("Expected UTC CreatedAt, got %v", user.CreatedAt)
+		}
+
+		found, err := repo.FindByID(ctx, user.ID)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if found == nil || found.Email != "john@example.com" || found.Phone != "+1234567890" {
+			t.Errorf("Expected stored user, got %+v", found)
+		}
+	})
+
+	t.Run("should return nil for unknown users", func(t *testing.T) {
+		repo := newSQLiteRepository(t)
+
+		for _, id := range []string{"42", "abc", ""} {
+			user, err := repo.FindByID(ctx, id)
+			if err != nil || user != nil {
+				t.Errorf("Expected nil user for %q, got %+v and %v", id, user, err)
+			}
+		}
+		name := "Jane"
+		if user, err := repo.Update(ctx, "42", UpdateUserData{Name: &name}); err != nil || user != nil {
+			t.Errorf("Expected nil update result, got %+v and %v", user, err)
+		}
+		if err := repo.Delete(ctx, "42"); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+	})
+
+	t.Run("should update provided fields only", func(t *testing.T) {
+		repo := newSQLiteRepository(t)
+		user, _ := repo.Create(ctx, CreateUserData{Name: "John Doe", Email: "john@example.com", Address: "123 Main St"})
+
+		name := "Jane Doe"
+		updated, err := repo.Update(ctx, user.ID, UpdateUserData{Name: &name})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if updated.Name != name || updated.Address != "123 Main St" {
+			t.Errorf("Expected name updated and address kept, got %+v", updated)
+		}
+		if updated.UpdatedAt.Before(user.UpdatedAt) {
+			t.Errorf("Expected UpdatedAt to advance, got %v before %v", updated.UpdatedAt, user.UpdatedAt)
+		}
+	})
+
+	t.Run("should reject duplicate emails", func(t *testing.T) {
+		repo := newSQLiteRepository(t)
+		repo.Create(ctx, CreateUserData{Name: "John", Email: "john@example.com"})
+
+		if _, err := repo.Create(ctx, CreateUserData{Name: "Other", Email: "john@example.com"}); err != ErrUserAlreadyExists {
+			t.Errorf("Expected ErrUserAlreadyExists, got %v", err)
+		}
+
+		jane, _ := repo.Create(ctx, CreateUserData{Name: "Jane", Email: "jane@example.com"})
+		email := "john@example.com"
+		if _, err := repo.Update(ctx, jane.ID, UpdateUserData{Email: &email}); err != ErrUserAlreadyExists {
+			t.Errorf("Expected ErrUserAlreadyExists, got %v", err)
+		}
+	})
+
+	t.Run("should find users by email", func(t *testing.T) {
+		repo := newSQLiteRepository(t)
+		john, _ := repo.Create(ctx, CreateUserData{Name: "John", Email: "john@example.com"})
+
+		found, err := FindUserByEmail(ctx, repo, "john@example.com")
+		if err != nil || found == nil || found.ID != john.ID {
+			t.Errorf("Expected %s, got %+v and %v", john.ID, found, err)
+		}
+		if found, err := repo.FindByEmail(ctx, "jane@example.com"); err != nil || found != nil {
+			t.Errorf("Expected no user, got %+v and %v", found, err)
+		}
+	})
+
+	t.Run("should delete, count and list users", func(t *testing.T) {
+		repo := newSQLiteRepository(t)
+		first, _ := repo.Create(ctx, CreateUserData{Name: "John", Email: "john@example.com"})
+		repo.Create(ctx, CreateUserData{Name: "Jane", Email: "jane@example.com"})
+
+		if err := repo.Delete(ctx, first.ID); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		count, err := repo.Count(ctx)
+		if err != nil || count != 1 {
+			t.Errorf("Expected 1 user, got %d and %v", count, err)
+		}
+		users, err := repo.FindAll(ctx)
+		if err != nil || len(users) != 1 || users[0].Name != "Jane" {
+			t.Errorf("Expected only Jane, got %+v and %v", users, err)
+		}
+		if err := repo.Ping(ctx); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+	})
+
+	t.Run("should iterate across batches with a filter", func(t *testing.T) {
+		repo := newSQLiteRepository(t)
+		for i := 0; i < DefaultIteratorBatchSize+5; i++ {
+			name := "User"
+			if i%2 == 0 {
+				name = "Even"
+			}
+			if _, err := repo.Create(ctx, CreateUserData{Name: name, Email: "user" + strconv.Itoa(i) + "@example.com"}); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+		}
+
+		it := repo.Iterate(ctx, UserFilter{Name: "even"})
+		defer it.Close()
+		count := 0
+		for it.Next() {
+			count++
+		}
+		if err := it.Err(); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if count != (DefaultIteratorBatchSize+6)/2 {
+			t.Errorf("Expected %d users, got %d", (DefaultIteratorBatchSize+6)/2, count)
+		}
+	})
+
+	t.Run("should assign IDs from the generator", func(t *testing.T) {
+		repo := newSQLiteRepository(t)
+		repo.ids = NewSequentialIDGenerator()
+
+		user, err := repo.Create(ctx, CreateUserData{Name: "John", Email: "john@example.com"})
+		if err != nil || user.ID != "1" {
+			t.Errorf("Expected ID 1, got %+v and %v", user, err)
+		}
+	})
+
+	t.Run("should keep integer IDs when upgrading to text IDs", func(t *testing.T) {
+		db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "users.db"))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		defer db.Close()
+		migrations, _ := migrate.Load(Migrations, SQLiteMigrations)
+		first, _ := migrate.New(db, migrations[:1], migrate.Options{Dialect: migrate.SQLite{}})
+		if _, err := first.Up(ctx); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if _, err := db.Exec("INSERT INTO users (name, email, created_at, updated_at) VALUES ('John', 'john@example.com', ?, ?)", time.Now(), time.Now()); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		all, _ := migrate.New(db, migrations, migrate.Options{Dialect: migrate.SQLite{}})
+		if _, err := all.Up(ctx); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		repo := NewSQLUserRepository(db, migrate.SQLite{})
+		if user, err := repo.FindByID(ctx, "1"); err != nil || user == nil || user.Email != "john@example.com" {
+			t.Errorf("Expected the existing user as ID 1, got %+v and %v", user, err)
+		}
+		created, err := repo.Create(ctx, CreateUserData{Name: "Jane", Email: "jane@example.com"})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if users, _ := repo.FindAll(ctx); len(users) != 2 || users[1].ID != created.ID {
+			t.Errorf("Expected users in creation order, got %+v", users)
+		}
+
+		if _, err := all.Down(ctx, 1); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		var id int64
+		if err := db.QueryRow("SELECT id FROM users WHERE email = 'jane@example.com'").Scan(&id); err != nil || id != 2 {
+			t.Errorf("Expected integer ID 2 after downgrading, got %d and %v", id, err)
+		}
+	})
+
+	t.Run("should back the user service", func(t *testing.T) {
+		service := NewUserService(newSQLiteRepository(t), nil)
+		if _, err := service.Create(ctx, CreateUserData{Name: "John", Email: "john@example.com"}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if _, err := service.Create(ctx, CreateUserData{Name: "John", Email: "john@example.com"}); err != ErrUserAlreadyExists {
+			t.Errorf("Expected ErrUserAlreadyExists, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,168 @@
+package gouser
+
+import "context"
+
+// Attribute is a key/value pair recorded on a span
+type Attribute struct {
+	Key   string
+	Value string
+}
+
+// Span is a unit of traced work started by a Tracer
+type Span interface {
+	// End finishes the span, recording err when it is not nil
+	End(err error)
+}
+
+// Tracer starts spans around service and repository operations. It keeps
+// gouser independent of a tracing SDK; adapters live with the application.
+type Tracer interface {
+	Start(ctx context.Context, name string, attributes ...Attribute) (context.Context, Span)
+}
+
+// WithTracer wraps every service operation in a span
+func WithTracer(tracer Tracer) UserServiceOption {
+	return func(s *UserService) {
+		s.tracer = tracer
+	}
+}
+
+// noopTracer is used when no tracer is configured
+type noopTracer struct{}
+
+func (noopTracer) Start(ctx context.Context, name string, attributes ...Attribute) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+type noopSpan struct{}
+
+func (noopSpan) End(err error) {}
+
+// userIDAttribute records the target user of an operation
+func userIDAttribute(id string) Attribute {
+	return Attribute{Key: "user.id", Value: id}
+}
+
+// TracingRepository decorates a UserRepository with a span per call
+type TracingRepository struct {
+	repository UserRepository
+	tracer     Tracer
+}
+
+// NewTracingRepository creates a new TracingRepository
+func NewTracingRepository(repository UserRepository, tracer Tracer) *TracingRepository {
+	return &TracingRepository{
+		repository: repository,
+		tracer:     tracer,
+	}
+}
+
+// Create creates a new user
+func (r *TracingRepository) Create(ctx context.Context, data CreateUserData) (*User, error) {
+	ctx, span := r.tracer.Start(ctx, "UserRepository.Create")
+	user, err := r.repository.Create(ctx, data)
+	span.End(err)
+	return user, err
+}
+
+// FindByID finds a user by ID
+func (r *TracingRepository) FindByID(ctx context.Context, id string) (*User, error) {
+	ctx, span := r.tracer.Start(ctx, "UserRepository.FindByID", userIDAttribute(id))
+	user, err := r.repository.FindByID(ctx, id)
+	span.End(err)
+	return user, err
+}
+
+// FindAll finds all users
+func (r *TracingRepository) FindAll(ctx context.Context) ([]*User, error) {
+	ctx, span := r.tracer.Start(ctx, "UserRepository.FindAll")
+	users, err := r.repository.FindAll(ctx)
+	span.End(err)
+	return users, err
+}
+
+// Update updates a user
+func (r *TracingRepository) Update(ctx context.Context, id string, data UpdateUserData) (*User, error) {
+	ctx, span := r.tracer.Start(ctx, "UserRepository.Update", userIDAttribute(id))
+	user, err := r.repository.Update(ctx, id, data)
+	span.End(err)
+	return user, err
+}
+
+// Delete deletes a user
+func (r *TracingRepository) Delete(ctx context.Context, id string) error {
+	ctx, span := r.tracer.Start(ctx, "UserRepository.Delete", userIDAttribute(id))
+	err := r.repository.Delete(ctx, id)
+	span.End(err)
+	return err
+}
+
+// Count counts users through the decorated repository
+func (r *TracingRepository) Count(ctx context.Context) (int, error) {
+	ctx, span := r.tracer.Start(ctx, "UserRepository.Count")
+	count, err := CountUsers(ctx, r.repository)
+	span.End(err)
+	return count, err
+}
+
+// FindByEmail finds a user by email through the decorated repository
+func (r *TracingRepository) FindByEmail(ctx context.Context, email string) (*User, error) {
+	ctx, span := r.tracer.Start(ctx, "UserRepository.FindByEmail")
+	user, err := FindUserByEmail(ctx, r.repository, email)
+	span.End(err)
+	return user, err
+}
+
+// Ping probes the decorated repository
+func (r *TracingRepository) Ping(ctx context.Context) error {
+	ctx, span := r.tracer.Start(ctx, "UserRepository.Ping")
+	err := PingRepository(ctx, r.repository)
+	span.End(err)
+	return err
+}
+
+// Iterate streams users from the decorated repository. The span lasts until
+// the iterator is closed.
+func (r *TracingRepository) Iterate(ctx context.Context, filter UserFilter) UserIterator {
+	ctx, span := r.tracer.Start(ctx, "UserRepository.Iterate")
+	var it UserIterator
+	if iterable, ok := r.repository.(UserIteratorRepository); ok {
+		it = iterable.Iterate(ctx, filter)
+	} else {
+		users, err := r.repository.FindAll(ctx)
+		it = newSliceUserIterator(users, filter, err)
+	}
+	return &tracedUserIterator{UserIterator: it, span: span}
+}
+
+// RunInTx runs fn in a transaction of the decorated repository. The
+// operations made through repos are traced as children of the transaction span.
+func (r *TracingRepository) RunInTx(ctx context.Context, fn TxFunc) error {
+	ctx, span := r.tracer.Start(ctx, "UserRepository.RunInTx")
+	err := RunInTx(ctx, r.repository, func(ctx context.Context, repos Repositories) error {
+		repos.Users = NewTracingRepository(repos.Users, r.tracer)
+		return fn(ctx, repos)
+	})
+	span.End(err)
+	return err
+}
+
+// tracedUserIterator ends its span when closed
+type tracedUserIterator struct {
+	UserIterator
+	span Span
+	done bool
+}
+
+func (it *tracedUserIterator) Close() error {
+	err := it.UserIterator.Close()
+	if !it.done {
+		it.done = true
+		spanErr := it.Err()
+		if spanErr == nil {
+			spanErr = err
+		}
+		it.span.End(spanErr)
+	}
+	return err
+}
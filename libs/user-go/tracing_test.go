@@ -0,0 +1,111 @@
+package gouser
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+// spanKey marks contexts returned by recordingTracer so parenting can be checked
+type spanKey struct{}
+
+// recordedSpan is a span captured by recordingTracer
+type recordedSpan struct {
+	name       string
+	parent     string
+	attributes []Attribute
+	err        error
+	ended      bool
+}
+
+// recordingTracer records started spans in order
+type recordingTracer struct {
+	mutex sync.Mutex
+	spans []*recordedSpan
+}
+
+func (t *recordingTracer) Start(ctx context.Context, name string, attributes ...Attribute) (context.Context, Span) {
+	parent, _ := ctx.Value(spanKey{}).(string)
+	span := &recordedSpan{name: name, parent: parent, attributes: attributes}
+
+	t.mutex.Lock()
+	t.spans = append(t.spans, span)
+	t.mutex.Unlock()
+
+	return context.WithValue(ctx, spanKey{}, name), span
+}
+
+func (s *recordedSpan) End(err error) {
+	s.err = err
+	s.ended = true
+}
+
+func (t *recordingTracer) find(name string) *recordedSpan {
+	for _, span := range t.spans {
+		if span.name == name {
+			return span
+		}
+	}
+	return nil
+}
+
+func TestUserService_WithTracer(t *testing.T) {
+	tracer := &recordingTracer{}
+	repo := NewTracingRepository(NewInMemoryUserRepository(), tracer)
+	service := NewUserService(repo, nil, WithTracer(tracer))
+	ctx := context.Background()
+
+	t.Run("should nest repository spans under service spans", func(t *testing.T) {
+		user, err := service.Create(ctx, CreateUserData{Name: "John Doe", Email: "john@example.com"})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		create := tracer.find("UserService.Create")
+		if create == nil || !create.ended || create.err != nil {
+			t.Fatalf("Expected ended UserService.Create span, got %+v", create)
+		}
+		tx := tracer.find("UserRepository.RunInTx")
+		if tx == nil || tx.parent != "UserService.Create" || !tx.ended {
+			t.Errorf("Expected ended UserRepository.RunInTx child span, got %+v", tx)
+		}
+		repoCreate := tracer.find("UserRepository.Create")
+		if repoCreate == nil || repoCreate.parent != "UserRepository.RunInTx" {
+			t.Errorf("Expected UserRepository.Create span in the transaction, got %+v", repoCreate)
+		}
+
+		service.FindByID(ctx, user.ID)
+		findByID := tracer.find("UserService.FindByID")
+		if len(findByID.attributes) != 1 || findByID.attributes[0].Value != user.ID {
+			t.Errorf("Expected user.id attribute, got %+v", findByID.attributes)
+		}
+	})
+
+	t.Run("should record errors on spans", func(t *testing.T) {
+		service.Delete(ctx, "missing")
+
+		span := tracer.find("UserService.Delete")
+		if span == nil || span.err != ErrUserNotFound {
+			t.Errorf("Expected ErrUserNotFound on span, got %+v", span)
+		}
+	})
+
+	t.Run("should end iterator spans on close", func(t *testing.T) {
+		it := service.Iterate(ctx, UserFilter{})
+		for it.Next() {
+		}
+
+		span := tracer.find("UserService.Iterate")
+		if span == nil || span.ended {
+			t.Fatalf("Expected open UserService.Iterate span, got %+v", span)
+		}
+		it.Close()
+		it.Close()
+		if !span.ended {
+			t.Error("Expected span to end on Close")
+		}
+		if repoSpan := tracer.find("UserRepository.Iterate"); repoSpan == nil || repoSpan.parent != "UserService.Iterate" {
+			t.Errorf("Expected UserRepository.Iterate child span, got %+v", repoSpan)
+		}
+	})
+}
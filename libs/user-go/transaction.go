@@ -0,0 +1,29 @@
+package gouser
+
+import "context"
+
+// Repositories are the repositories available to a unit of work. Inside
+// RunInTx they are bound to the transaction.
+type Repositories struct {
+	Users UserRepository
+}
+
+// TxFunc is a unit of work. Returning an error, or panicking, rolls it back.
+type TxFunc func(ctx context.Context, repos Repositories) error
+
+// Transactor is an optional extension of UserRepository for repositories
+// that can run several operations atomically
+type Transactor interface {
+	// RunInTx runs fn in a transaction, committing when it returns nil.
+	// fn must use repos instead of the repository RunInTx was called on.
+	RunInTx(ctx context.Context, fn TxFunc) error
+}
+
+// RunInTx runs fn in a transaction when repository implements Transactor.
+// Other repositories run fn directly against themselves, without atomicity.
+func RunInTx(ctx context.Context, repository UserRepository, fn TxFunc) error {
+	if transactor, ok := repository.(Transactor); ok {
+		return transactor.RunInTx(ctx, fn)
+	}
+	return fn(ctx, Repositories{Users: repository})
+}
@@ -0,0 +1,214 @@
+package gouser
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+var errAbort = errors.New("abort")
+
+// testTransactor exercises the transaction contract on a repository
+func testTransactor(t *testing.T, newRepo func(t *testing.T) UserRepository) {
+	ctx := context.Background()
+
+	t.Run("should commit when fn succeeds", func(t *testing.T) {
+		repo := newRepo(t)
+		err := RunInTx(ctx, repo, func(ctx context.Context, repos Repositories) error {
+			_, err := repos.Users.Create(ctx, CreateUserData{Name: "John", Email: "john@example.com"})
+			return err
+		})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if count, _ := CountUsers(ctx, repo); count != 1 {
+			t.Errorf("Expected 1 user, got %d", count)
+		}
+	})
+
+	t.Run("should roll back every change when fn fails", func(t *testing.T) {
+		repo := newRepo(t)
+		existing, _ := repo.Create(ctx, CreateUserData{Name: "John", Email: "john@example.com"})
+
+		err := RunInTx(ctx, repo, func(ctx context.Context, repos Repositories) error {
+			name := "Renamed"
+			if _, err := repos.Users.Update(ctx, existing.ID, UpdateUserData{Name: &name}); err != nil {
+				return err
+			}
+			if _, err := repos.Users.Create(ctx, CreateUserData{Name: "Jane", Email: "jane@example.com"}); err != nil {
+				return err
+			}
+			if users, _ := repos.Users.FindAll(ctx); len(users) != 2 {
+				t.Errorf("Expected the transaction to see its own writes, got %d users", len(users))
+			}
+			return errAbort
+		})
+		if !errors.Is(err, errAbort) {
+			t.Fatalf("Expected errAbort, got %v", err)
+		}
+
+		users, _ := repo.FindAll(ctx)
+		if len(users) != 1 || users[0].Name != "John" {
+			t.Errorf("Expected the original user only, got %+v", users)
+		}
+	})
+
+	t.Run("should roll back when fn panics", func(t *testing.T) {
+		repo := newRepo(t)
+		func() {
+			defer func() { recover() }()
+			RunInTx(ctx, repo, func(ctx context.Context, repos Repositories) error {
+				repos.Users.Create(ctx, CreateUserData{Name: "John", Email: "john@example.com"})
+				panic("boom")
+			})
+		}()
+
+		if count, _ := CountUsers(ctx, repo); count != 0 {
+			t.Errorf("Expected no users, got %d", count)
+		}
+		if _, err := repo.Create(ctx, CreateUserData{Name: "Jane", Email: "jane@example.com"}); err != nil {
+			t.Errorf("Expected the repository to be usable after a panic, got %v", err)
+		}
+	})
+}
+
+func TestInMemoryUserRepository_RunInTx(t *testing.T) {
+	testTransactor(t, func(t *testing.T) UserRepository {
+		return NewInMemoryUserRepository()
+	})
+
+	t.Run("should apply overlay changes to the store only on commit", func(t *testing.T) {
+		repo := NewInMemoryUserRepository()
+		ctx := context.Background()
+		john, _ := repo.Create(ctx, CreateUserData{Name: "John", Email: "john@example.com"})
+		jane, _ := repo.Create(ctx, CreateUserData{Name: "Jane", Email: "jane@example.com"})
+
+		var created *User
+		err := repo.RunInTx(ctx, func(ctx context.Context, repos Repositories) error {
+			name := "Johnny"
+			repos.Users.Update(ctx, john.ID, UpdateUserData{Name: &name})
+			repos.Users.Delete(ctx, jane.ID)
+			created, _ = repos.Users.Create(ctx, CreateUserData{Name: "Bob", Email: "bob@example.com"})
+			temporary, _ := repos.Users.Create(ctx, CreateUserData{Name: "Tmp", Email: "tmp@example.com"})
+			repos.Users.Delete(ctx, temporary.ID)
+
+			if stored := repo.users[john.ID]; stored.Name != "John" {
+				t.Errorf("Expected the store to keep John until commit, got %s", stored.Name)
+			}
+			if user, _ := repos.Users.FindByID(ctx, jane.ID); user != nil {
+				t.Errorf("Expected the transaction not to see Jane, got %+v", user)
+			}
+			if user, _ := FindUserByEmail(ctx, repos.Users, "bob@example.com"); user == nil {
+				t.Error("Expected the transaction to find Bob by email")
+			}
+			if count, _ := CountUsers(ctx, repos.Users); count != 2 {
+				t.Errorf("Expected 2 users in the transaction, got %d", count)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		var names []string
+		for it := repo.Iterate(ctx, UserFilter{}); it.Next(); {
+			names = append(names, it.User().Name)
+		}
+		if len(names) != 2 || names[0] != "Johnny" || names[1] != "Bob" {
+			t.Errorf("Expected [Johnny Bob] in creation order, got %v", names)
+		}
+		if user, _ := repo.FindByID(ctx, created.ID); user == nil {
+			t.Error("Expected Bob to be committed")
+		}
+	})
+}
+
+func TestSQLUserRepository_RunInTx(t *testing.T) {
+	testTransactor(t, func(t *testing.T) UserRepository {
+		return newSQLiteRepository(t)
+	})
+}
+
+func TestRunInTx(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should run fn directly on repositories without transactions", func(t *testing.T) {
+		repo := &repositoryWithoutTx{NewInMemoryUserRepository()}
+		var got UserRepository
+		RunInTx(ctx, repo, func(ctx context.Context, repos Repositories) error {
+			got = repos.Users
+			return nil
+		})
+		if got != repo {
+			t.Errorf("Expected the repository itself, got %T", got)
+		}
+	})
+
+	t.Run("should forward transactions through decorators", func(t *testing.T) {
+		observer := &recordingObserver{}
+		tracer := &recordingTracer{}
+		repo := NewTracingRepository(NewInstrumentedRepository(NewInMemoryUserRepository(), observer), tracer)
+
+		RunInTx(ctx, repo, func(ctx context.Context, repos Repositories) error {
+			repos.Users.Create(ctx, CreateUserData{Name: "John", Email: "john@example.com"})
+			return errAbort
+		})
+
+		if count, _ := CountUsers(ctx, repo); count != 0 {
+			t.Errorf("Expected the create to be rolled back, got %d users", count)
+		}
+		if len(observer.operations) == 0 || observer.operations[0] != RepositoryOpCreate {
+			t.Errorf("Expected the create to be observed, got %v", observer.operations)
+		}
+		span := tracer.find("UserRepository.RunInTx")
+		if span == nil || span.err != errAbort {
+			t.Errorf("Expected RunInTx span with the error, got %+v", span)
+		}
+	})
+
+	t.Run("should keep the service writes atomic", func(t *testing.T) {
+		repo := &failingCreateRepository{InMemoryUserRepository: NewInMemoryUserRepository()}
+		service := NewUserService(repo, nil)
+
+		existing, _ := repo.InMemoryUserRepository.Create(ctx, CreateUserData{Name: "John", Email: "john@example.com"})
+		email := "taken@example.com"
+		repo.InMemoryUserRepository.Create(ctx, CreateUserData{Name: "Taken", Email: email})
+
+		if _, err := service.Update(ctx, existing.ID, UpdateUserData{Email: &email}); !errors.Is(err, ErrUserAlreadyExists) {
+			t.Errorf("Expected ErrUserAlreadyExists, got %v", err)
+		}
+		if _, err := service.Create(ctx, CreateUserData{Name: "Jane", Email: "jane@example.com"}); !errors.Is(err, errAbort) {
+			t.Errorf("Expected errAbort, got %v", err)
+		}
+		if repo.transactions != 2 {
+			t.Errorf("Expected 2 transactions, got %d", repo.transactions)
+		}
+	})
+}
+
+// repositoryWithoutTx hides the transaction support of a repository
+type repositoryWithoutTx struct {
+	UserRepository
+}
+
+// failingCreateRepository counts transactions and fails creates made in them
+type failingCreateRepository struct {
+	*InMemoryUserRepository
+	transactions int
+}
+
+func (r *failingCreateRepository) RunInTx(ctx context.Context, fn TxFunc) error {
+	r.transactions++
+	return r.InMemoryUserRepository.RunInTx(ctx, func(ctx context.Context, repos Repositories) error {
+		repos.Users = failingCreate{repos.Users}
+		return fn(ctx, repos)
+	})
+}
+
+type failingCreate struct {
+	UserRepository
+}
+
+func (failingCreate) Create(ctx context.Context, data CreateUserData) (*User, error) {
+	return nil, errAbort
+}
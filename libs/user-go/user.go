@@ -2,6 +2,7 @@ package gouser
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 )
@@ -17,6 +18,14 @@ type User struct {
 	UpdatedAt time.Time `json:"updatedAt"`
 }
 
+// Writable user field names, used by column mappings and field-level authorization
+const (
+	FieldName    = "name"
+	FieldEmail   = "email"
+	FieldPhone   = "phone"
+	FieldAddress = "address"
+)
+
 // CreateUserData represents data needed to create a user
 type CreateUserData struct {
 	Name    string `json:"name"`
@@ -49,57 +58,163 @@ type UserEvents interface {
 	OnUserDeleted(userID string)
 }
 
+// ContextUserEvents is an optional extension of UserEvents. When the events
+// handler implements it, the service calls these methods instead so handlers
+// can read request-scoped values such as the acting Principal.
+type ContextUserEvents interface {
+	OnUserCreatedContext(ctx context.Context, user *User)
+	OnUserUpdatedContext(ctx context.Context, user *User)
+	OnUserDeletedContext(ctx context.Context, userID string)
+}
+
 // UserService provides business logic for user operations
 type UserService struct {
 	repository UserRepository
 	events     UserEvents
+	policy     Policy
+	tracer     Tracer
+	clock      Clock
+}
+
+// UserServiceOption configures optional UserService collaborators
+type UserServiceOption func(*UserService)
+
+// WithPolicy enforces policy on every service operation. The acting principal
+// is read from the context with PrincipalFromContext.
+func WithPolicy(policy Policy) UserServiceOption {
+	return func(s *UserService) {
+		s.policy = policy
+	}
+}
+
+// WithServiceClock sets the Clock returned by UserService.Clock. Give the
+// repository the same clock with WithClock.
+func WithServiceClock(clock Clock) UserServiceOption {
+	return func(s *UserService) {
+		s.clock = clock
+	}
 }
 
 // NewUserService creates a new UserService instance
-func NewUserService(repository UserRepository, events UserEvents) *UserService {
-	return &UserService{
+func NewUserService(repository UserRepository, events UserEvents, opts ...UserServiceOption) *UserService {
+	service := &UserService{
 		repository: repository,
 		events:     events,
+		tracer:     noopTracer{},
+		clock:      SystemClock,
 	}
+	for _, opt := range opts {
+		opt(service)
+	}
+	return service
+}
+
+// Clock returns the clock of the service, so components built on it such as
+// HTTP handlers tell the same time as the stored users
+func (s *UserService) Clock() Clock {
+	return s.clock
 }
 
 // Create creates a new user
-func (s *UserService) Create(ctx context.Context, data CreateUserData) (*User, error) {
+func (s *UserService) Create(ctx context.Context, data CreateUserData) (_ *User, err error) {
+	ctx, span := s.tracer.Start(ctx, "UserService.Create")
+	defer func() { span.End(err) }()
+
 	// Validate input data
 	if err := ValidateCreateUserData(data); err != nil {
 		return nil, err
 	}
 
-	// Validate email uniqueness
-	existingUser, err := s.FindByEmail(ctx, data.Email)
-	if err != nil {
-		// Se erro ao buscar, não podemos garantir unicidade - falhar rápido
-		return nil, fmt.Errorf("failed to validate email uniqueness: %w", err)
-	}
-	if existingUser != nil {
-		return nil, ErrUserAlreadyExists
+	if err := s.authorize(ctx, OperationCreate, nil, createFields(data)); err != nil {
+		return nil, err
 	}
 
-	user, err := s.repository.Create(ctx, data)
+	// Check uniqueness and write in one transaction so the check holds for the write
+	var user *User
+	err = RunInTx(ctx, s.repository, func(ctx context.Context, repos Repositories) error {
+		existingUser, err := FindUserByEmail(ctx, repos.Users, data.Email)
+		if err != nil {
+			// Se erro ao buscar, não podemos garantir unicidade - falhar rápido
+			return fmt.Errorf("failed to validate email uniqueness: %w", err)
+		}
+		if existingUser != nil {
+			return ErrUserAlreadyExists
+		}
+
+		user, err = repos.Users.Create(ctx, data)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	if s.events != nil {
-		s.events.OnUserCreated(user)
-	}
+	s.emitCreated(ctx, user)
 
 	return user, nil
 }
 
 // FindAll retrieves all users
-func (s *UserService) FindAll(ctx context.Context) ([]*User, error) {
+func (s *UserService) FindAll(ctx context.Context) (_ []*User, err error) {
+	ctx, span := s.tracer.Start(ctx, "UserService.FindAll")
+	defer func() { span.End(err) }()
+
+	if err := s.authorize(ctx, OperationList, nil, nil); err != nil {
+		return nil, err
+	}
 	return s.repository.FindAll(ctx)
 }
 
+// Iterate streams users matching the filter. Repositories implementing
+// UserIteratorRepository are iterated in batches; others fall back to FindAll.
+// Authorization failures are reported by Err before the first call to Next.
+// The operation span lasts until the iterator is closed.
+func (s *UserService) Iterate(ctx context.Context, filter UserFilter) UserIterator {
+	ctx, span := s.tracer.Start(ctx, "UserService.Iterate")
+	return &tracedUserIterator{UserIterator: s.iterate(ctx, filter), span: span}
+}
+
+func (s *UserService) iterate(ctx context.Context, filter UserFilter) UserIterator {
+	if err := s.authorize(ctx, OperationList, nil, nil); err != nil {
+		return newSliceUserIterator(nil, filter, err)
+	}
+	if iterable, ok := s.repository.(UserIteratorRepository); ok {
+		return iterable.Iterate(ctx, filter)
+	}
+	users, err := s.repository.FindAll(ctx)
+	return newSliceUserIterator(users, filter, err)
+}
+
 // FindByID retrieves a user by ID
-func (s *UserService) FindByID(ctx context.Context, id string) (*User, error) {
-	user, err := s.repository.FindByID(ctx, id)
+func (s *UserService) FindByID(ctx context.Context, id string) (_ *User, err error) {
+	ctx, span := s.tracer.Start(ctx, "UserService.FindByID", userIDAttribute(id))
+	defer func() { span.End(err) }()
+
+	return s.findTarget(ctx, s.repository, OperationRead, id)
+}
+
+// findTarget retrieves the user op acts on. The operation is authorized
+// before the lookup and users out of the principal's reach are reported as
+// not found, so denied principals cannot tell which IDs exist.
+func (s *UserService) findTarget(ctx context.Context, repository UserRepository, op Operation, id string) (*User, error) {
+	if err := s.authorize(ctx, op, nil, nil); err != nil {
+		return nil, err
+	}
+	user, err := findUserByID(ctx, repository, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(ctx, op, user, nil); err != nil {
+		if errors.Is(err, ErrForbidden) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// findUserByID retrieves a user by ID from repository, or ErrUserNotFound
+func findUserByID(ctx context.Context, repository UserRepository, id string) (*User, error) {
+	user, err := repository.FindByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -110,62 +225,84 @@ func (s *UserService) FindByID(ctx context.Context, id string) (*User, error) {
 }
 
 // FindByEmail retrieves a user by email
-func (s *UserService) FindByEmail(ctx context.Context, email string) (*User, error) {
-	users, err := s.repository.FindAll(ctx)
-	if err != nil {
+func (s *UserService) FindByEmail(ctx context.Context, email string) (_ *User, err error) {
+	ctx, span := s.tracer.Start(ctx, "UserService.FindByEmail")
+	defer func() { span.End(err) }()
+
+	if err := s.authorize(ctx, OperationRead, nil, nil); err != nil {
 		return nil, err
 	}
-
-	for _, user := range users {
-		if user.Email == email {
-			return user, nil
+	user, err := s.findByEmail(ctx, email)
+	if err != nil || user == nil {
+		return user, err
+	}
+	if err := s.authorize(ctx, OperationRead, user, nil); err != nil {
+		if errors.Is(err, ErrForbidden) {
+			return nil, nil
 		}
+		return nil, err
 	}
+	return user, nil
+}
 
-	return nil, nil // User not found, but no error
+// findByEmail retrieves a user by email without authorization checks
+func (s *UserService) findByEmail(ctx context.Context, email string) (*User, error) {
+	return FindUserByEmail(ctx, s.repository, email)
 }
 
 // Update updates a user
-func (s *UserService) Update(ctx context.Context, id string, data UpdateUserData) (*User, error) {
+func (s *UserService) Update(ctx context.Context, id string, data UpdateUserData) (_ *User, err error) {
+	ctx, span := s.tracer.Start(ctx, "UserService.Update", userIDAttribute(id))
+	defer func() { span.End(err) }()
+
 	// Validate input data
 	if err := ValidateUpdateUserData(data); err != nil {
 		return nil, err
 	}
 
-	// Check if user exists
-	existingUser, err := s.FindByID(ctx, id)
-	if err != nil {
-		return nil, err
-	}
-
-	// If email is being updated, check for conflicts
-	if data.Email != nil && *data.Email != existingUser.Email {
-		emailUser, err := s.FindByEmail(ctx, *data.Email)
+	// Read, check and write in one transaction so the checks hold for the write
+	var user *User
+	err = RunInTx(ctx, s.repository, func(ctx context.Context, repos Repositories) error {
+		// Check if user exists
+		existingUser, err := s.findTarget(ctx, repos.Users, OperationUpdate, id)
 		if err != nil {
-			return nil, fmt.Errorf("failed to validate email uniqueness during update: %w", err)
+			return err
 		}
-		if emailUser != nil {
-			return nil, ErrUserAlreadyExists
+
+		if err := s.authorize(ctx, OperationUpdate, existingUser, updateFields(data, existingUser)); err != nil {
+			return err
 		}
-	}
 
-	user, err := s.repository.Update(ctx, id, data)
+		// If email is being updated, check for conflicts
+		if data.Email != nil && *data.Email != existingUser.Email {
+			emailUser, err := FindUserByEmail(ctx, repos.Users, *data.Email)
+			if err != nil {
+				return fmt.Errorf("failed to validate email uniqueness during update: %w", err)
+			}
+			if emailUser != nil {
+				return ErrUserAlreadyExists
+			}
+		}
+
+		user, err = repos.Users.Update(ctx, id, data)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	if s.events != nil {
-		s.events.OnUserUpdated(user)
-	}
+	s.emitUpdated(ctx, user)
 
 	return user, nil
 }
 
 // Delete deletes a user
-func (s *UserService) Delete(ctx context.Context, id string) error {
+func (s *UserService) Delete(ctx context.Context, id string) (err error) {
+	ctx, span := s.tracer.Start(ctx, "UserService.Delete", userIDAttribute(id))
+	defer func() { span.End(err) }()
+
 	// Check if user exists
-	_, err := s.FindByID(ctx, id)
-	if err != nil {
+	if _, err := s.findTarget(ctx, s.repository, OperationDelete, id); err != nil {
 		return err
 	}
 
@@ -174,11 +311,59 @@ func (s *UserService) Delete(ctx context.Context, id string) error {
 		return err
 	}
 
-	if s.events != nil {
-		s.events.OnUserDeleted(id)
-	}
+	s.emitDeleted(ctx, id)
 
 	return nil
 }
 
+// authorize evaluates the configured policy for the principal in ctx
+func (s *UserService) authorize(ctx context.Context, op Operation, target *User, fields []string) error {
+	if s.policy == nil {
+		return nil
+	}
+	principal, _ := PrincipalFromContext(ctx)
+	return s.policy.Authorize(ctx, AuthorizationRequest{
+		Principal: principal,
+		Operation: op,
+		Target:    target,
+		Fields:    fields,
+	})
+}
+
+// emitCreated notifies the events handler, preferring the context-aware variant
+func (s *UserService) emitCreated(ctx context.Context, user *User) {
+	if s.events == nil {
+		return
+	}
+	if events, ok := s.events.(ContextUserEvents); ok {
+		events.OnUserCreatedContext(ctx, user)
+		return
+	}
+	s.events.OnUserCreated(user)
+}
+
+// emitUpdated notifies the events handler, preferring the context-aware variant
+func (s *UserService) emitUpdated(ctx context.Context, user *User) {
+	if s.events == nil {
+		return
+	}
+	if events, ok := s.events.(ContextUserEvents); ok {
+		events.OnUserUpdatedContext(ctx, user)
+		return
+	}
+	s.events.OnUserUpdated(user)
+}
+
+// emitDeleted notifies the events handler, preferring the context-aware variant
+func (s *UserService) emitDeleted(ctx context.Context, userID string) {
+	if s.events == nil {
+		return
+	}
+	if events, ok := s.events.(ContextUserEvents); ok {
+		events.OnUserDeletedContext(ctx, userID)
+		return
+	}
+	s.events.OnUserDeleted(userID)
+}
+
 // Test commit for workflow validation - lib shared change
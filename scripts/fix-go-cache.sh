@@ -25,6 +25,8 @@ go 1.23
 
 use (
     ./apps/user-go-service
+    ./libs/health-go
+    ./libs/logger-go
     ./libs/user-go
 )
 EOF
@@ -40,6 +42,20 @@ if [ -d "apps/user-go-service" ]; then
     cd ../..
 fi
 
+if [ -d "libs/health-go" ]; then
+    echo "📦 Processando health-go..."
+    cd libs/health-go
+    go mod tidy
+    cd ../..
+fi
+
+if [ -d "libs/logger-go" ]; then
+    echo "📦 Processando logger-go..."
+    cd libs/logger-go
+    go mod tidy
+    cd ../..
+fi
+
 if [ -d "libs/user-go" ]; then
     echo "📦 Processando user-go..."
     cd libs/user-go